@@ -0,0 +1,17 @@
+-- +migrate Up
+CREATE TABLE password_resets (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_password_resets_user_id
+ON password_resets(user_id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_password_resets_user_id;
+
+DROP TABLE password_resets;
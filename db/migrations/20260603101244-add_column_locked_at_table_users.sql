@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE users
+ADD COLUMN locked_at TIMESTAMP DEFAULT NULL;
+
+-- +migrate Down
+ALTER TABLE users
+DROP COLUMN locked_at;
@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE TABLE password_histories (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_password_histories_user_id
+ON password_histories(user_id, created_at DESC);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_password_histories_user_id;
+
+DROP TABLE password_histories;
@@ -0,0 +1,23 @@
+-- +migrate Up
+CREATE TABLE audit_logs (
+    id SERIAL PRIMARY KEY,
+    actor_id INTEGER REFERENCES users(id),
+    action VARCHAR(100) NOT NULL,
+    target_type VARCHAR(50) NOT NULL,
+    target_id INTEGER NOT NULL,
+    metadata TEXT,
+    ip_address VARCHAR(64),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_logs_target
+ON audit_logs(target_type, target_id);
+
+CREATE INDEX idx_audit_logs_created_at
+ON audit_logs(created_at DESC);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_audit_logs_target;
+DROP INDEX IF EXISTS idx_audit_logs_created_at;
+
+DROP TABLE audit_logs;
@@ -0,0 +1,18 @@
+-- +migrate Up
+CREATE TABLE user_invitations (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    invited_by INTEGER NOT NULL REFERENCES users(id),
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    accepted_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_invitations_user_id
+ON user_invitations(user_id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_user_invitations_user_id;
+
+DROP TABLE user_invitations;
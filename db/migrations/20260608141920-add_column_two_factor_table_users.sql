@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE users
+ADD COLUMN two_factor_secret TEXT DEFAULT NULL,
+ADD COLUMN two_factor_enabled BOOLEAN DEFAULT FALSE;
+
+-- +migrate Down
+ALTER TABLE users
+DROP COLUMN two_factor_secret,
+DROP COLUMN two_factor_enabled;
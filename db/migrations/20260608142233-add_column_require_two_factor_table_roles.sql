@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE roles
+ADD COLUMN require_two_factor BOOLEAN DEFAULT FALSE;
+
+-- +migrate Down
+ALTER TABLE roles
+DROP COLUMN require_two_factor;
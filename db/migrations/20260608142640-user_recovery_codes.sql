@@ -0,0 +1,16 @@
+-- +migrate Up
+CREATE TABLE user_recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_recovery_codes_user_id
+ON user_recovery_codes(user_id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_user_recovery_codes_user_id;
+
+DROP TABLE user_recovery_codes;
@@ -0,0 +1,17 @@
+-- +migrate Up
+CREATE TABLE user_identities (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, subject)
+);
+
+CREATE INDEX idx_user_identities_user_id
+ON user_identities(user_id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_user_identities_user_id;
+
+DROP TABLE user_identities;
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE users
+ADD COLUMN is_service_account BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +migrate Down
+ALTER TABLE users
+DROP COLUMN is_service_account;
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE users
+ADD COLUMN language VARCHAR(5) NOT NULL DEFAULT 'id';
+
+-- +migrate Down
+ALTER TABLE users
+DROP COLUMN language;
@@ -0,0 +1,35 @@
+-- +migrate Up
+CREATE TYPE notification_channel AS ENUM (
+    'IN_APP',
+    'EMAIL',
+    'CHAT',
+    'PUSH'
+);
+
+CREATE TABLE notification_preferences (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    type notification_type NOT NULL,
+    channel notification_channel NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, type, channel)
+);
+
+CREATE TABLE notification_quiet_hours (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id),
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    start_time VARCHAR(5) NOT NULL DEFAULT '22:00',
+    end_time VARCHAR(5) NOT NULL DEFAULT '07:00',
+    timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Jakarta',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate Down
+DROP TABLE notification_quiet_hours;
+
+DROP TABLE notification_preferences;
+
+DROP TYPE notification_channel;
@@ -0,0 +1,56 @@
+-- +migrate Up
+CREATE TABLE webhooks (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    url TEXT NOT NULL,
+    secret VARCHAR(128) NOT NULL,
+    events TEXT NOT NULL,
+    project_ids TEXT NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE DEFAULT NULL
+);
+
+CREATE TYPE webhook_delivery_status AS ENUM (
+    'PENDING',
+    'SUCCESS',
+    'FAILED'
+);
+
+CREATE TABLE webhook_deliveries (
+    id SERIAL PRIMARY KEY,
+    webhook_id INTEGER NOT NULL REFERENCES webhooks(id),
+    event_type VARCHAR(50) NOT NULL,
+    ticket_id INTEGER DEFAULT NULL,
+    payload TEXT NOT NULL,
+    status webhook_delivery_status NOT NULL DEFAULT 'PENDING',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_status INTEGER DEFAULT NULL,
+    response_body TEXT DEFAULT NULL,
+    error TEXT DEFAULT NULL,
+    replay_of INTEGER DEFAULT NULL REFERENCES webhook_deliveries(id),
+    next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_deliveries_webhook_id
+ON webhook_deliveries(webhook_id);
+
+CREATE INDEX idx_webhook_deliveries_pending
+ON webhook_deliveries(next_attempt_at)
+WHERE status = 'PENDING';
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_webhook_deliveries_pending;
+
+DROP INDEX IF EXISTS idx_webhook_deliveries_webhook_id;
+
+DROP TABLE webhook_deliveries;
+
+DROP TYPE webhook_delivery_status;
+
+DROP TABLE webhooks;
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE users
+ADD COLUMN telegram_chat_id BIGINT DEFAULT NULL UNIQUE;
+
+-- +migrate Down
+ALTER TABLE users
+DROP COLUMN telegram_chat_id;
@@ -0,0 +1,16 @@
+-- +migrate Up
+ALTER TABLE notifications
+ADD COLUMN read_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN archived_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE notifications SET read_at = created_at WHERE is_read = TRUE;
+
+CREATE INDEX idx_notifications_user_inbox
+ON notifications(user_id, archived_at, created_at DESC);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_notifications_user_inbox;
+
+ALTER TABLE notifications
+DROP COLUMN archived_at,
+DROP COLUMN read_at;
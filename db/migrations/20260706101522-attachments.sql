@@ -0,0 +1,59 @@
+-- +migrate Up
+CREATE TABLE attachments (
+    id SERIAL PRIMARY KEY,
+    owner_type VARCHAR(30) NOT NULL,
+    owner_id INTEGER NOT NULL,
+    ticket_id INTEGER NOT NULL REFERENCES tickets(id),
+    filename VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(255) NOT NULL DEFAULT 'application/octet-stream',
+    size BIGINT NOT NULL DEFAULT 0,
+    checksum VARCHAR(64) NOT NULL DEFAULT '',
+    storage_key TEXT NOT NULL,
+    uploaded_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_attachments_owner ON attachments(owner_type, owner_id);
+CREATE INDEX idx_attachments_ticket_id ON attachments(ticket_id);
+
+-- Files uploaded before this table existed have no recorded size, type or
+-- checksum; the filename is taken from the last path segment of the key/URL.
+INSERT INTO attachments (owner_type, owner_id, ticket_id, filename, storage_key, uploaded_by, created_at)
+SELECT 'TICKET', id, id, regexp_replace(split_part(attachment, '?', 1), '^.*/', ''), attachment, reporter_id, created_at
+FROM tickets
+WHERE attachment IS NOT NULL AND attachment <> '';
+
+INSERT INTO attachments (owner_type, owner_id, ticket_id, filename, storage_key, uploaded_by, created_at)
+SELECT 'RESOLUTION', r.id, r.ticket_id, regexp_replace(split_part(r.attachment_url, '?', 1), '^.*/', ''), r.attachment_url, t.assigned_to_id, r.created_at
+FROM ticket_resolutions r
+JOIN tickets t ON t.id = r.ticket_id
+WHERE r.attachment_url IS NOT NULL AND r.attachment_url <> '';
+
+ALTER TABLE tickets DROP COLUMN attachment;
+ALTER TABLE ticket_resolutions DROP COLUMN attachment_url;
+
+-- +migrate Down
+ALTER TABLE tickets ADD COLUMN attachment TEXT NULL;
+ALTER TABLE ticket_resolutions ADD COLUMN attachment_url TEXT NULL;
+
+UPDATE tickets t
+SET attachment = a.storage_key
+FROM (
+    SELECT DISTINCT ON (owner_id) owner_id, storage_key
+    FROM attachments
+    WHERE owner_type = 'TICKET'
+    ORDER BY owner_id, id
+) a
+WHERE a.owner_id = t.id;
+
+UPDATE ticket_resolutions r
+SET attachment_url = a.storage_key
+FROM (
+    SELECT DISTINCT ON (owner_id) owner_id, storage_key
+    FROM attachments
+    WHERE owner_type = 'RESOLUTION'
+    ORDER BY owner_id, id
+) a
+WHERE a.owner_id = r.id;
+
+DROP TABLE attachments;
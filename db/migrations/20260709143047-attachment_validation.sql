@@ -0,0 +1,20 @@
+-- +migrate Up
+ALTER TABLE projects ADD COLUMN allowed_mime_types TEXT NOT NULL DEFAULT '[]';
+
+CREATE TABLE quarantined_files (
+    id SERIAL PRIMARY KEY,
+    ticket_id INTEGER,
+    project_id INTEGER REFERENCES projects(id),
+    filename VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL DEFAULT 0,
+    checksum VARCHAR(64) NOT NULL DEFAULT '',
+    signature VARCHAR(255) NOT NULL,
+    storage_key TEXT NOT NULL,
+    uploaded_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate Down
+DROP TABLE quarantined_files;
+ALTER TABLE projects DROP COLUMN allowed_mime_types;
@@ -0,0 +1,11 @@
+-- +migrate Up
+ALTER TABLE attachments ADD COLUMN width INTEGER NULL;
+ALTER TABLE attachments ADD COLUMN height INTEGER NULL;
+ALTER TABLE attachments ADD COLUMN thumbnail_key TEXT NULL;
+ALTER TABLE attachments ADD COLUMN preview_key TEXT NULL;
+
+-- +migrate Down
+ALTER TABLE attachments DROP COLUMN preview_key;
+ALTER TABLE attachments DROP COLUMN thumbnail_key;
+ALTER TABLE attachments DROP COLUMN height;
+ALTER TABLE attachments DROP COLUMN width;
@@ -0,0 +1,50 @@
+-- +migrate Up
+CREATE TYPE export_job_status AS ENUM (
+    'PENDING',
+    'PROCESSING',
+    'COMPLETED',
+    'FAILED'
+);
+
+CREATE TABLE export_jobs (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    role VARCHAR(50) NOT NULL,
+    format VARCHAR(20) NOT NULL DEFAULT 'xlsx',
+    filter TEXT NOT NULL,
+    status export_job_status NOT NULL DEFAULT 'PENDING',
+    progress INTEGER NOT NULL DEFAULT 0,
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    filename VARCHAR(255) DEFAULT NULL,
+    size BIGINT NOT NULL DEFAULT 0,
+    storage_key TEXT DEFAULT NULL,
+    error TEXT DEFAULT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    lease_until TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    started_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_export_jobs_user_id
+ON export_jobs(user_id, created_at DESC);
+
+CREATE INDEX idx_export_jobs_queue
+ON export_jobs(created_at)
+WHERE status IN ('PENDING', 'PROCESSING');
+
+CREATE INDEX idx_export_jobs_expires_at
+ON export_jobs(expires_at);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_export_jobs_expires_at;
+
+DROP INDEX IF EXISTS idx_export_jobs_queue;
+
+DROP INDEX IF EXISTS idx_export_jobs_user_id;
+
+DROP TABLE IF EXISTS export_jobs;
+
+DROP TYPE IF EXISTS export_job_status;
@@ -0,0 +1,36 @@
+-- +migrate Up
+CREATE TYPE signer_role AS ENUM (
+    'TECHNICIAN',
+    'REPORTER'
+);
+
+CREATE TABLE resolution_signatures (
+    id SERIAL PRIMARY KEY,
+    resolution_id INTEGER NOT NULL REFERENCES ticket_resolutions(id) ON DELETE CASCADE,
+    ticket_id INTEGER NOT NULL REFERENCES tickets(id),
+    signer_role signer_role NOT NULL,
+    signer_id INTEGER REFERENCES users(id),
+    signer_name VARCHAR(255) NOT NULL,
+    storage_key TEXT NOT NULL,
+    size BIGINT NOT NULL DEFAULT 0,
+    checksum VARCHAR(64) NOT NULL DEFAULT '',
+    width INTEGER NOT NULL DEFAULT 0,
+    height INTEGER NOT NULL DEFAULT 0,
+    signed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_resolution_signatures_role
+ON resolution_signatures(resolution_id, signer_role);
+
+CREATE INDEX idx_resolution_signatures_ticket_id
+ON resolution_signatures(ticket_id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_resolution_signatures_ticket_id;
+
+DROP INDEX IF EXISTS idx_resolution_signatures_role;
+
+DROP TABLE IF EXISTS resolution_signatures;
+
+DROP TYPE IF EXISTS signer_role;
@@ -0,0 +1,82 @@
+-- +migrate Up
+CREATE TYPE report_channel AS ENUM (
+    'EMAIL',
+    'WEBHOOK'
+);
+
+CREATE TABLE report_schedules (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    role VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    format VARCHAR(20) NOT NULL DEFAULT 'xlsx',
+    filter TEXT NOT NULL,
+    period VARCHAR(10) DEFAULT NULL,
+    cron VARCHAR(100) NOT NULL,
+    timezone VARCHAR(64) NOT NULL,
+    channel report_channel NOT NULL,
+    recipients TEXT NOT NULL,
+    webhook_id INTEGER DEFAULT NULL REFERENCES webhooks(id),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    last_run_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    lease_until TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_report_schedules_user_id
+ON report_schedules(user_id, created_at DESC);
+
+CREATE INDEX idx_report_schedules_due
+ON report_schedules(next_run_at)
+WHERE next_run_at IS NOT NULL;
+
+CREATE TYPE report_run_status AS ENUM (
+    'RUNNING',
+    'COMPLETED',
+    'FAILED'
+);
+
+CREATE TABLE report_runs (
+    id SERIAL PRIMARY KEY,
+    schedule_id INTEGER NOT NULL REFERENCES report_schedules(id) ON DELETE CASCADE,
+    status report_run_status NOT NULL DEFAULT 'RUNNING',
+    start_date VARCHAR(10) DEFAULT NULL,
+    end_date VARCHAR(10) DEFAULT NULL,
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    filename VARCHAR(255) DEFAULT NULL,
+    size BIGINT NOT NULL DEFAULT 0,
+    storage_key TEXT DEFAULT NULL,
+    error TEXT DEFAULT NULL,
+    delivered_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_report_runs_schedule_id
+ON report_runs(schedule_id, created_at DESC);
+
+CREATE INDEX idx_report_runs_expires_at
+ON report_runs(expires_at)
+WHERE storage_key IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_report_runs_expires_at;
+
+DROP INDEX IF EXISTS idx_report_runs_schedule_id;
+
+DROP TABLE IF EXISTS report_runs;
+
+DROP TYPE IF EXISTS report_run_status;
+
+DROP INDEX IF EXISTS idx_report_schedules_due;
+
+DROP INDEX IF EXISTS idx_report_schedules_user_id;
+
+DROP TABLE IF EXISTS report_schedules;
+
+DROP TYPE IF EXISTS report_channel;
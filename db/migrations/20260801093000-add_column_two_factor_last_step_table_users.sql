@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE users
+ADD COLUMN two_factor_last_step BIGINT DEFAULT NULL;
+
+-- +migrate Down
+ALTER TABLE users
+DROP COLUMN two_factor_last_step;
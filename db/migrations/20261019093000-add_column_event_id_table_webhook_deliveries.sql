@@ -0,0 +1,13 @@
+-- +migrate Up
+ALTER TABLE webhook_deliveries
+ADD COLUMN event_id VARCHAR(64) DEFAULT NULL;
+
+CREATE UNIQUE INDEX idx_webhook_deliveries_event
+ON webhook_deliveries(webhook_id, event_id)
+WHERE event_id IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_webhook_deliveries_event;
+
+ALTER TABLE webhook_deliveries
+DROP COLUMN event_id;
@@ -0,0 +1,29 @@
+package antivirus
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+// New builds the scanner selected by antivirus.driver.
+func New() model.IVirusScanner {
+	switch driver := config.AntivirusDriver(); driver {
+	case "clamav":
+		scanner, err := NewClamAVScanner(config.AntivirusClamAVAddress(), config.AntivirusTimeout())
+		if err != nil {
+			logrus.Error("clamav scanner init failed, uploads are not scanned:", err)
+			return NewNoopScanner()
+		}
+
+		logrus.Infof("antivirus: clamav at %s", config.AntivirusClamAVAddress())
+		return scanner
+
+	case "none":
+		return NewNoopScanner()
+
+	default:
+		logrus.Errorf("unknown antivirus driver %q, uploads are not scanned", driver)
+		return NewNoopScanner()
+	}
+}
@@ -0,0 +1,113 @@
+package antivirus
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+const clamAVChunkSize = 32 * 1024
+
+// ClamAVScanner streams files to clamd with the INSTREAM command.
+type ClamAVScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamAVScanner takes "unix:/path/to/clamd.sock" or "tcp:host:port".
+func NewClamAVScanner(address string, timeout time.Duration) (model.IVirusScanner, error) {
+	network, addr, ok := strings.Cut(address, ":")
+	if !ok || addr == "" || (network != "unix" && network != "tcp") {
+		return nil, fmt.Errorf("invalid clamav address %q", address)
+	}
+
+	return &ClamAVScanner{
+		network: network,
+		address: addr,
+		timeout: timeout,
+	}, nil
+}
+
+func (s *ClamAVScanner) Scan(ctx context.Context, r io.Reader) (*model.ScanResult, error) {
+	dialer := net.Dialer{Timeout: s.timeout}
+
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed connect clamd: %w", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(s.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, clamAVChunkSize)
+	size := make([]byte, 4)
+
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+
+			if _, err := conn.Write(size); err != nil {
+				return nil, err
+			}
+
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return nil, err
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return nil, err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	return parseClamAVReply(strings.TrimRight(reply, "\x00\n "))
+}
+
+// parseClamAVReply reads "stream: OK" or "stream: <signature> FOUND".
+func parseClamAVReply(reply string) (*model.ScanResult, error) {
+	result := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+
+	switch {
+	case result == "OK":
+		return &model.ScanResult{}, nil
+
+	case strings.HasSuffix(result, " FOUND"):
+		return &model.ScanResult{
+			Infected:  true,
+			Signature: strings.TrimSuffix(result, " FOUND"),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("clamd: %s", reply)
+}
@@ -0,0 +1,19 @@
+package antivirus
+
+import (
+	"context"
+	"io"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+// NoopScanner reports every file as clean, for development and tests.
+type NoopScanner struct{}
+
+func NewNoopScanner() model.IVirusScanner {
+	return &NoopScanner{}
+}
+
+func (s *NoopScanner) Scan(ctx context.Context, r io.Reader) (*model.ScanResult, error) {
+	return &model.ScanResult{}, nil
+}
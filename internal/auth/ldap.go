@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+var errInvalidCredentials = errors.New("email or password is incorrect")
+
+type LDAPProvider struct {
+	url            string
+	startTLS       bool
+	bindDN         string
+	bindPassword   string
+	baseDN         string
+	userFilter     string
+	emailAttribute string
+	nameAttribute  string
+	groupAttribute string
+}
+
+func NewLDAPProvider() model.IPasswordAuthProvider {
+	return &LDAPProvider{
+		url:            config.LDAPURL(),
+		startTLS:       config.LDAPStartTLS(),
+		bindDN:         config.LDAPBindDN(),
+		bindPassword:   config.LDAPBindPassword(),
+		baseDN:         config.LDAPBaseDN(),
+		userFilter:     config.LDAPUserFilter(),
+		emailAttribute: config.LDAPEmailAttribute(),
+		nameAttribute:  config.LDAPNameAttribute(),
+		groupAttribute: config.LDAPGroupAttribute(),
+	}
+}
+
+func (p *LDAPProvider) Name() string {
+	return model.AuthProviderLDAP
+}
+
+func (p *LDAPProvider) Authenticate(ctx context.Context, username string, password string) (*model.ExternalIdentity, error) {
+	// An empty password would turn the bind below into an unauthenticated
+	// bind, which most servers accept.
+	if username == "" || password == "" {
+		return nil, errInvalidCredentials
+	}
+
+	conn, err := ldap.DialURL(p.url)
+	if err != nil {
+		return nil, fmt.Errorf("connect ldap: %w", err)
+	}
+	defer conn.Close()
+
+	if p.startTLS {
+		serverURL, err := url.Parse(p.url)
+		if err != nil {
+			return nil, fmt.Errorf("parse ldap url: %w", err)
+		}
+
+		if err := conn.StartTLS(&tls.Config{ServerName: serverURL.Hostname()}); err != nil {
+			return nil, fmt.Errorf("ldap start tls: %w", err)
+		}
+	}
+
+	if p.bindDN != "" {
+		if err := conn.Bind(p.bindDN, p.bindPassword); err != nil {
+			return nil, fmt.Errorf("ldap service bind: %w", err)
+		}
+	}
+
+	result, err := conn.Search(ldap.NewSearchRequest(
+		p.baseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		2,
+		0,
+		false,
+		fmt.Sprintf(p.userFilter, ldap.EscapeFilter(username)),
+		[]string{p.emailAttribute, p.nameAttribute, p.groupAttribute},
+		nil,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("ldap search: %w", err)
+	}
+
+	if len(result.Entries) != 1 {
+		return nil, errInvalidCredentials
+	}
+
+	entry := result.Entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		return nil, errInvalidCredentials
+	}
+
+	email := entry.GetAttributeValue(p.emailAttribute)
+	if email == "" {
+		return nil, errors.New("ldap account has no email address")
+	}
+
+	var groups []string
+	for _, value := range entry.GetAttributeValues(p.groupAttribute) {
+		groups = append(groups, groupName(value))
+	}
+
+	return &model.ExternalIdentity{
+		Provider: model.AuthProviderLDAP,
+		Subject:  entry.DN,
+		Email:    email,
+		Name:     entry.GetAttributeValue(p.nameAttribute),
+		Groups:   groups,
+	}, nil
+}
+
+// groupName turns "cn=helpdesk-admins,ou=groups,dc=example,dc=com" into
+// "helpdesk-admins"; plain group names are returned unchanged.
+func groupName(value string) string {
+	dn, err := ldap.ParseDN(value)
+	if err != nil || len(dn.RDNs) == 0 {
+		return value
+	}
+
+	for _, attr := range dn.RDNs[0].Attributes {
+		if strings.EqualFold(attr.Type, "cn") {
+			return attr.Value
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,286 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+const (
+	testServiceDN       = "cn=helpdesk,ou=services,dc=example,dc=com"
+	testServicePassword = "service-secret"
+)
+
+type ldapEntry struct {
+	dn         string
+	uid        string
+	password   string
+	attributes map[string][]string
+}
+
+// fakeLDAP is a minimal LDAP server: it answers simple binds against a fixed
+// set of passwords and equality searches on uid, and records the filters it
+// was sent.
+type fakeLDAP struct {
+	listener net.Listener
+	entries  []ldapEntry
+
+	mu      sync.Mutex
+	binds   []string
+	filters []string
+}
+
+func newFakeLDAP(t *testing.T, entries ...ldapEntry) *fakeLDAP {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := &fakeLDAP{listener: listener, entries: entries}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go server.serve(conn)
+		}
+	}()
+
+	return server
+}
+
+func (s *fakeLDAP) url() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+func (s *fakeLDAP) serve(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+
+		messageID := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			code := s.bind(op.Children[1].Value.(string), op.Children[2].Data.String())
+			conn.Write(ldapResponse(messageID, ldapResult(ldap.ApplicationBindResponse, code)).Bytes())
+
+		case ldap.ApplicationSearchRequest:
+			filter, err := ldap.DecompileFilter(op.Children[6])
+			if err != nil {
+				conn.Write(ldapResponse(messageID, ldapResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultProtocolError)).Bytes())
+				continue
+			}
+
+			s.mu.Lock()
+			s.filters = append(s.filters, filter)
+			s.mu.Unlock()
+
+			for _, entry := range s.entries {
+				if filter == fmt.Sprintf("(uid=%s)", ldap.EscapeFilter(entry.uid)) {
+					conn.Write(ldapResponse(messageID, searchEntry(entry)).Bytes())
+				}
+			}
+
+			conn.Write(ldapResponse(messageID, ldapResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess)).Bytes())
+
+		default:
+			return
+		}
+	}
+}
+
+func (s *fakeLDAP) bind(dn string, password string) uint16 {
+	s.mu.Lock()
+	s.binds = append(s.binds, dn)
+	s.mu.Unlock()
+
+	if dn == testServiceDN && password == testServicePassword {
+		return ldap.LDAPResultSuccess
+	}
+
+	for _, entry := range s.entries {
+		if entry.dn == dn && entry.password == password {
+			return ldap.LDAPResultSuccess
+		}
+	}
+
+	return ldap.LDAPResultInvalidCredentials
+}
+
+// recorded returns the bind DNs and search filters seen so far.
+func (s *fakeLDAP) recorded() ([]string, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.binds...), append([]string(nil), s.filters...)
+}
+
+func ldapResponse(messageID int64, op *ber.Packet) *ber.Packet {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, ""))
+	packet.AppendChild(op)
+
+	return packet
+}
+
+func ldapResult(application ber.Tag, code uint16) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, application, nil, "")
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), ""))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+
+	return result
+}
+
+func searchEntry(entry ldapEntry) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "")
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.dn, ""))
+
+	attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+	for name, values := range entry.attributes {
+		attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, ""))
+
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, ""))
+		}
+
+		attribute.AppendChild(set)
+		attributes.AppendChild(attribute)
+	}
+
+	result.AppendChild(attributes)
+
+	return result
+}
+
+var testLDAPUser = ldapEntry{
+	dn:       "uid=jane,ou=people,dc=example,dc=com",
+	uid:      "jane",
+	password: "correct horse",
+	attributes: map[string][]string{
+		"mail": {"jane@example.com"},
+		"cn":   {"Jane Doe"},
+		"memberOf": {
+			"cn=Helpdesk-Admins,ou=groups,dc=example,dc=com",
+			"finance",
+		},
+	},
+}
+
+func newTestLDAPProvider(server *fakeLDAP) *LDAPProvider {
+	return &LDAPProvider{
+		url:            server.url(),
+		bindDN:         testServiceDN,
+		bindPassword:   testServicePassword,
+		baseDN:         "ou=people,dc=example,dc=com",
+		userFilter:     "(uid=%s)",
+		emailAttribute: "mail",
+		nameAttribute:  "cn",
+		groupAttribute: "memberOf",
+	}
+}
+
+func TestLDAPAuthenticate(t *testing.T) {
+	server := newFakeLDAP(t, testLDAPUser)
+	provider := newTestLDAPProvider(server)
+
+	identity, err := provider.Authenticate(context.Background(), "jane", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if identity.Subject != testLDAPUser.dn || identity.Email != "jane@example.com" || identity.Name != "Jane Doe" {
+		t.Fatalf("identity = %+v", identity)
+	}
+
+	if len(identity.Groups) != 2 || identity.Groups[0] != "Helpdesk-Admins" || identity.Groups[1] != "finance" {
+		t.Fatalf("groups = %v, want the cn of group DNs and plain names unchanged", identity.Groups)
+	}
+
+	binds, _ := server.recorded()
+	if len(binds) != 2 || binds[0] != testServiceDN || binds[1] != testLDAPUser.dn {
+		t.Fatalf("binds = %v, want the service account then the user", binds)
+	}
+}
+
+func TestLDAPAuthenticateRejectsBadCredentials(t *testing.T) {
+	tests := []struct {
+		name         string
+		username     string
+		password     string
+		wantUserBind bool
+	}{
+		{"wrong password", "jane", "wrong", true},
+		{"empty password", "jane", "", false},
+		{"unknown user", "john", "correct horse", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeLDAP(t, testLDAPUser)
+
+			_, err := newTestLDAPProvider(server).Authenticate(context.Background(), tt.username, tt.password)
+			if !errors.Is(err, errInvalidCredentials) {
+				t.Fatalf("err = %v, want errInvalidCredentials", err)
+			}
+
+			binds, _ := server.recorded()
+			if userBind := len(binds) == 2 && binds[1] == testLDAPUser.dn; userBind != tt.wantUserBind {
+				t.Fatalf("binds = %v", binds)
+			}
+		})
+	}
+}
+
+func TestLDAPAuthenticateEscapesUsername(t *testing.T) {
+	server := newFakeLDAP(t, testLDAPUser)
+
+	_, err := newTestLDAPProvider(server).Authenticate(context.Background(), "*)(uid=jane", "correct horse")
+	if !errors.Is(err, errInvalidCredentials) {
+		t.Fatalf("err = %v, want errInvalidCredentials", err)
+	}
+
+	binds, filters := server.recorded()
+
+	want := `(uid=\2a\29\28uid=jane)`
+	if len(filters) != 1 || filters[0] != want {
+		t.Fatalf("filters = %v, want %s", filters, want)
+	}
+
+	for _, dn := range binds {
+		if dn == testLDAPUser.dn {
+			t.Fatal("injected filter reached the user bind")
+		}
+	}
+}
+
+func TestLDAPServiceBindFailure(t *testing.T) {
+	server := newFakeLDAP(t, testLDAPUser)
+	provider := newTestLDAPProvider(server)
+	provider.bindPassword = "wrong"
+
+	_, err := provider.Authenticate(context.Background(), "jane", "correct horse")
+	if err == nil || errors.Is(err, errInvalidCredentials) {
+		t.Fatalf("err = %v, want a service bind error", err)
+	}
+}
@@ -0,0 +1,365 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+const oidcStateTTL = 10 * time.Minute
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcState struct {
+	Verifier string `json:"verifier"`
+	Nonce    string `json:"nonce"`
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type OIDCProvider struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       []string
+	groupsClaim  string
+	client       *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]*rsa.PublicKey
+}
+
+func NewOIDCProvider() model.IRedirectAuthProvider {
+	return &OIDCProvider{
+		issuer:       strings.TrimSuffix(config.OIDCIssuer(), "/"),
+		clientID:     config.OIDCClientID(),
+		clientSecret: config.OIDCClientSecret(),
+		redirectURL:  config.OIDCRedirectURL(),
+		scopes:       config.OIDCScopes(),
+		groupsClaim:  config.OIDCGroupsClaim(),
+		client:       &http.Client{Timeout: 10 * time.Second},
+		keys:         map[string]*rsa.PublicKey{},
+	}
+}
+
+func (p *OIDCProvider) Name() string {
+	return model.AuthProviderOIDC
+}
+
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context) (string, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	state, err := helper.GenerateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	verifier, err := helper.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	nonce, err := helper.GenerateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	data, _ := json.Marshal(oidcState{
+		Verifier: verifier,
+		Nonce:    nonce,
+	})
+
+	if err := config.Rdb.Set(ctx, oidcStateKey(state), data, oidcStateTTL).Err(); err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.clientID)
+	query.Set("redirect_uri", p.redirectURL)
+	query.Set("scope", strings.Join(p.scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+func (p *OIDCProvider) Exchange(ctx context.Context, state string, code string) (*model.ExternalIdentity, error) {
+	raw, err := config.Rdb.GetDel(ctx, oidcStateKey(state)).Result()
+	if err != nil {
+		return nil, errors.New("login session is invalid or expired")
+	}
+
+	var saved oidcState
+	if err := json.Unmarshal([]byte(raw), &saved); err != nil {
+		return nil, err
+	}
+
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.redirectURL)
+	form.Set("client_id", p.clientID)
+	form.Set("code_verifier", saved.Verifier)
+
+	if p.clientSecret != "" {
+		form.Set("client_secret", p.clientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc token request failed, status: %s", resp.Status)
+	}
+
+	var token struct {
+		IDToken string `json:"id_token"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("decode oidc token response: %w", err)
+	}
+
+	if token.IDToken == "" {
+		return nil, errors.New("oidc token response has no id_token")
+	}
+
+	claims, err := p.verifyIDToken(ctx, discovery, token.IDToken, saved.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.identityFromClaims(claims)
+}
+
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, discovery *oidcDiscovery, idToken string, nonce string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256"}))
+
+	_, err := parser.ParseWithClaims(idToken, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.publicKey(ctx, discovery, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+
+	if !claims.VerifyIssuer(discovery.Issuer, true) {
+		return nil, errors.New("invalid id_token issuer")
+	}
+
+	if !claims.VerifyAudience(p.clientID, true) {
+		return nil, errors.New("invalid id_token audience")
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, errors.New("invalid id_token nonce")
+	}
+
+	return claims, nil
+}
+
+func (p *OIDCProvider) identityFromClaims(claims jwt.MapClaims) (*model.ExternalIdentity, error) {
+	subject, _ := claims["sub"].(string)
+	email, _ := claims["email"].(string)
+	name, _ := claims["name"].(string)
+
+	if subject == "" {
+		return nil, errors.New("id_token has no subject")
+	}
+
+	if email == "" {
+		return nil, errors.New("id_token has no email claim")
+	}
+
+	if !emailVerified(claims["email_verified"]) {
+		return nil, errors.New("id_token email is not verified")
+	}
+
+	if name == "" {
+		name, _ = claims["preferred_username"].(string)
+	}
+
+	var groups []string
+
+	switch value := claims[p.groupsClaim].(type) {
+	case []interface{}:
+		for _, g := range value {
+			if s, ok := g.(string); ok {
+				groups = append(groups, strings.TrimPrefix(s, "/"))
+			}
+		}
+	case string:
+		groups = append(groups, strings.TrimPrefix(value, "/"))
+	}
+
+	return &model.ExternalIdentity{
+		Provider: model.AuthProviderOIDC,
+		Subject:  subject,
+		Email:    email,
+		Name:     name,
+		Groups:   groups,
+	}, nil
+}
+
+// emailVerified accepts the claim as a boolean or, as some IdPs send it,
+// the string "true".
+func emailVerified(claim interface{}) bool {
+	switch value := claim.(type) {
+	case bool:
+		return value
+	case string:
+		return strings.EqualFold(value, "true")
+	}
+
+	return false
+}
+
+func (p *OIDCProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var discovery oidcDiscovery
+	if err := p.getJSON(ctx, p.issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+
+	p.discovery = &discovery
+
+	return p.discovery, nil
+}
+
+// publicKey looks up the signing key by kid and refetches the JWKS once
+// when the IdP has rotated to a key we have not seen yet.
+func (p *OIDCProvider) publicKey(ctx context.Context, discovery *oidcDiscovery, kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	if err := p.getJSON(ctx, discovery.JWKSURI, &jwks); err != nil {
+		return nil, fmt.Errorf("fetch jwks: %w", err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			continue
+		}
+
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	p.keys = keys
+
+	key, ok := p.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	return key, nil
+}
+
+func (p *OIDCProvider) getJSON(ctx context.Context, endpoint string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func oidcStateKey(state string) string {
+	return "sso:oidc:state:" + state
+}
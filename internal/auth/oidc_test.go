@@ -0,0 +1,259 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+)
+
+const testClientID = "helpdesk"
+
+// fakeIdP is a minimal OIDC provider: it serves discovery and JWKS,
+// remembers the PKCE challenge and nonce of the last authorization request
+// and only issues an id_token for a matching code_verifier.
+type fakeIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu        sync.Mutex
+	challenge string
+	nonce     string
+	claims    jwt.MapClaims
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idp := &fakeIdP{key: key}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidcDiscovery{
+			Issuer:                idp.server.URL,
+			AuthorizationEndpoint: idp.server.URL + "/authorize",
+			TokenEndpoint:         idp.server.URL + "/token",
+			JWKSURI:               idp.server.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []jsonWebKey{{
+				Kid: "test",
+				Kty: "RSA",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		idp.mu.Lock()
+		defer idp.mu.Unlock()
+
+		sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if base64.RawURLEncoding.EncodeToString(sum[:]) != idp.challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+
+		claims := jwt.MapClaims{
+			"iss":            idp.server.URL,
+			"aud":            testClientID,
+			"sub":            "user-1",
+			"email":          "jane@example.com",
+			"email_verified": true,
+			"name":           "Jane",
+			"nonce":          idp.nonce,
+			"exp":            time.Now().Add(time.Minute).Unix(),
+		}
+		for k, v := range idp.claims {
+			claims[k] = v
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "test"
+
+		signed, err := token.SignedString(idp.key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]string{"id_token": signed})
+	})
+
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	return idp
+}
+
+// authorize plays the browser leg: it records what the IdP would have seen
+// on the authorization request and returns the state to call back with.
+func (idp *fakeIdP) authorize(t *testing.T, authURL string) string {
+	t.Helper()
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := parsed.Query()
+
+	if query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("code_challenge_method = %q, want S256", query.Get("code_challenge_method"))
+	}
+
+	idp.mu.Lock()
+	idp.challenge = query.Get("code_challenge")
+	idp.nonce = query.Get("nonce")
+	idp.mu.Unlock()
+
+	return query.Get("state")
+}
+
+func newTestProvider(t *testing.T, idp *fakeIdP) *OIDCProvider {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	previous := config.Rdb
+	config.Rdb = redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		config.Rdb.Close()
+		config.Rdb = previous
+	})
+
+	return &OIDCProvider{
+		issuer:      idp.server.URL,
+		clientID:    testClientID,
+		redirectURL: "https://helpdesk.example.com/auth/sso/oidc/callback",
+		scopes:      []string{"openid", "email"},
+		groupsClaim: "groups",
+		client:      idp.server.Client(),
+		keys:        map[string]*rsa.PublicKey{},
+	}
+}
+
+func TestOIDCExchange(t *testing.T) {
+	ctx := context.Background()
+
+	idp := newFakeIdP(t)
+	provider := newTestProvider(t, idp)
+
+	authURL, err := provider.AuthCodeURL(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := idp.authorize(t, authURL)
+
+	identity, err := provider.Exchange(ctx, state, "code")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	if identity.Subject != "user-1" || identity.Email != "jane@example.com" {
+		t.Fatalf("identity = %+v", identity)
+	}
+
+	if _, err := provider.Exchange(ctx, state, "code"); err == nil {
+		t.Fatal("state was accepted twice")
+	}
+}
+
+func TestOIDCExchangeRejectsNonceMismatch(t *testing.T) {
+	ctx := context.Background()
+
+	idp := newFakeIdP(t)
+	provider := newTestProvider(t, idp)
+
+	authURL, err := provider.AuthCodeURL(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := idp.authorize(t, authURL)
+	idp.claims = jwt.MapClaims{"nonce": "replayed"}
+
+	if _, err := provider.Exchange(ctx, state, "code"); err == nil {
+		t.Fatal("id_token with a foreign nonce was accepted")
+	}
+}
+
+func TestOIDCExchangeSendsPKCEVerifier(t *testing.T) {
+	ctx := context.Background()
+
+	idp := newFakeIdP(t)
+	provider := newTestProvider(t, idp)
+
+	authURL, err := provider.AuthCodeURL(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := idp.authorize(t, authURL)
+
+	// A code intercepted from another login carries a different challenge.
+	idp.challenge = "not-the-challenge"
+
+	if _, err := provider.Exchange(ctx, state, "code"); err == nil {
+		t.Fatal("exchange succeeded although the IdP rejected the verifier")
+	}
+}
+
+func TestOIDCExchangeRejectsBadClaims(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+	}{
+		{"unverified email", jwt.MapClaims{"email_verified": false}},
+		{"missing email_verified", jwt.MapClaims{"email_verified": nil}},
+		{"other audience", jwt.MapClaims{"aud": "someone-else"}},
+		{"other issuer", jwt.MapClaims{"iss": "https://evil.example.com"}},
+		{"expired", jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			idp := newFakeIdP(t)
+			provider := newTestProvider(t, idp)
+
+			authURL, err := provider.AuthCodeURL(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			state := idp.authorize(t, authURL)
+			idp.claims = tt.claims
+
+			if _, err := provider.Exchange(ctx, state, "code"); err == nil {
+				t.Fatal("id_token was accepted")
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+func NewPasswordProviders() map[string]model.IPasswordAuthProvider {
+	providers := map[string]model.IPasswordAuthProvider{}
+
+	if config.LDAPEnabled() {
+		providers[model.AuthProviderLDAP] = NewLDAPProvider()
+	}
+
+	return providers
+}
+
+func NewRedirectProviders() map[string]model.IRedirectAuthProvider {
+	providers := map[string]model.IRedirectAuthProvider{}
+
+	if config.OIDCEnabled() {
+		providers[model.AuthProviderOIDC] = NewOIDCProvider()
+	}
+
+	return providers
+}
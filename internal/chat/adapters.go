@@ -0,0 +1,28 @@
+package chat
+
+import (
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+// NewAdapters returns the enabled chat adapters together with the Telegram
+// bot, which is nil when Telegram is disabled.
+func NewAdapters() ([]model.IChatAdapter, model.ITelegramBot) {
+	var adapters []model.IChatAdapter
+	var bot model.ITelegramBot
+
+	if config.ChatWebhookEnabled() {
+		adapters = append(adapters, NewWebhookAdapter(config.ChatWebhookURL()))
+	}
+
+	if config.TelegramEnabled() {
+		bot = NewTelegramAdapter(
+			config.TelegramAPIURL(),
+			config.TelegramBotToken(),
+			config.TelegramGroupChatID(),
+		)
+		adapters = append(adapters, bot)
+	}
+
+	return adapters, bot
+}
@@ -0,0 +1,145 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type TelegramAdapter struct {
+	apiURL      string
+	token       string
+	groupChatID int64
+	client      *http.Client
+}
+
+type telegramButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data"`
+}
+
+type telegramReplyMarkup struct {
+	InlineKeyboard [][]telegramButton `json:"inline_keyboard"`
+}
+
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+func NewTelegramAdapter(apiURL string, token string, groupChatID int64) *TelegramAdapter {
+	return &TelegramAdapter{
+		apiURL:      apiURL,
+		token:       token,
+		groupChatID: groupChatID,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (a *TelegramAdapter) Name() string {
+	return "telegram"
+}
+
+// Send delivers to the recipient's linked private chat and, for SLA
+// breaches, also to the configured team group.
+func (a *TelegramAdapter) Send(ctx context.Context, message model.ChatMessage) error {
+	var chatIDs []int64
+
+	if message.Recipient != nil && message.Recipient.TelegramChatID != nil {
+		chatIDs = append(chatIDs, *message.Recipient.TelegramChatID)
+	}
+
+	if message.Kind == model.ChatKindSLABreach && a.groupChatID != 0 {
+		chatIDs = append(chatIDs, a.groupChatID)
+	}
+
+	var buttons []telegramButton
+	for _, action := range message.Actions {
+		if action.CallbackData == "" {
+			continue
+		}
+
+		buttons = append(buttons, telegramButton{
+			Text:         action.Label,
+			CallbackData: action.CallbackData,
+		})
+	}
+
+	payload := map[string]interface{}{
+		"text":                     strings.Join([]string{message.Text, message.Link}, "\n"),
+		"disable_web_page_preview": true,
+	}
+
+	if len(buttons) > 0 {
+		payload["reply_markup"] = telegramReplyMarkup{
+			InlineKeyboard: [][]telegramButton{buttons},
+		}
+	}
+
+	var errs []error
+	for _, chatID := range chatIDs {
+		payload["chat_id"] = chatID
+
+		if err := a.call(ctx, "sendMessage", payload); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (a *TelegramAdapter) SendText(ctx context.Context, chatID int64, text string) error {
+	return a.call(ctx, "sendMessage", map[string]interface{}{
+		"chat_id": chatID,
+		"text":    text,
+	})
+}
+
+func (a *TelegramAdapter) AnswerCallback(ctx context.Context, callbackID string, text string) error {
+	return a.call(ctx, "answerCallbackQuery", map[string]interface{}{
+		"callback_query_id": callbackID,
+		"text":              text,
+	})
+}
+
+func (a *TelegramAdapter) call(ctx context.Context, method string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/bot%s/%s", a.apiURL, a.token, method)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result telegramResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("telegram %s: status %d", method, resp.StatusCode)
+	}
+
+	if !result.OK {
+		return fmt.Errorf("telegram %s: %s", method, result.Description)
+	}
+
+	return nil
+}
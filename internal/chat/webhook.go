@@ -0,0 +1,96 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+// WebhookAdapter posts to a generic incoming webhook. The "text" field is
+// understood by Slack, Mattermost and Teams style endpoints; the remaining
+// fields are there for custom receivers.
+type WebhookAdapter struct {
+	url    string
+	client *http.Client
+}
+
+type webhookAction struct {
+	Label  string `json:"label"`
+	Action string `json:"action"`
+	URL    string `json:"url"`
+}
+
+type webhookMessage struct {
+	Text       string          `json:"text"`
+	Kind       string          `json:"kind"`
+	TicketCode string          `json:"ticket_code"`
+	Link       string          `json:"link"`
+	Actions    []webhookAction `json:"actions"`
+}
+
+func NewWebhookAdapter(url string) *WebhookAdapter {
+	return &WebhookAdapter{
+		url: url,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (a *WebhookAdapter) Name() string {
+	return "webhook"
+}
+
+func (a *WebhookAdapter) Send(ctx context.Context, message model.ChatMessage) error {
+	lines := []string{message.Text, message.Link}
+
+	var actions []webhookAction
+	for _, action := range message.Actions {
+		if action.URL == "" {
+			continue
+		}
+
+		actions = append(actions, webhookAction{
+			Label:  action.Label,
+			Action: action.Action,
+			URL:    action.URL,
+		})
+		lines = append(lines, fmt.Sprintf("%s: %s", action.Label, action.URL))
+	}
+
+	body, err := json.Marshal(webhookMessage{
+		Text:       strings.Join(lines, "\n"),
+		Kind:       message.Kind,
+		TicketCode: message.TicketCode,
+		Link:       message.Link,
+		Actions:    actions,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("chat webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -53,3 +54,1043 @@ func CloudinaryAPIKey() string {
 func CloudinaryAPISecret() string {
 	return viper.GetString("CLOUDINARY_API_SECRET")
 }
+
+func FrontendURL() string {
+	url := viper.GetString("app.frontend_url")
+	if url == "" {
+		return "http://localhost:5173"
+	}
+	return url
+}
+
+// TrustedProxies lists the reverse proxies (IPs or CIDRs) whose
+// X-Forwarded-For header is believed when resolving the client IP. When it
+// is empty the peer address of the connection is used as is.
+func TrustedProxies() []string {
+	return viper.GetStringSlice("app.trusted_proxies")
+}
+
+func MailDriver() string {
+	driver := viper.GetString("mail.driver")
+	if driver == "" {
+		return "log"
+	}
+	return driver
+}
+
+func MailFrom() string {
+	return viper.GetString("mail.from")
+}
+
+func MailFileDir() string {
+	dir := viper.GetString("mail.file_dir")
+	if dir == "" {
+		return "./storage/mails"
+	}
+	return dir
+}
+
+func SMTPHost() string {
+	return viper.GetString("smtp.host")
+}
+
+func SMTPPort() int {
+	return viper.GetInt("smtp.port")
+}
+
+func SMTPUsername() string {
+	return viper.GetString("smtp.username")
+}
+
+func SMTPPassword() string {
+	return viper.GetString("smtp.password")
+}
+
+func PasswordResetTTL() time.Duration {
+	ttl := viper.GetDuration("password_reset.ttl")
+	if ttl == 0 {
+		return 30 * time.Minute
+	}
+	return ttl
+}
+
+func PasswordMinLength() int {
+	length := viper.GetInt("password_policy.min_length")
+	if length == 0 {
+		return 8
+	}
+	return length
+}
+
+func PasswordRequireUpper() bool {
+	if !viper.IsSet("password_policy.require_upper") {
+		return true
+	}
+	return viper.GetBool("password_policy.require_upper")
+}
+
+func PasswordRequireLower() bool {
+	if !viper.IsSet("password_policy.require_lower") {
+		return true
+	}
+	return viper.GetBool("password_policy.require_lower")
+}
+
+func PasswordRequireDigit() bool {
+	if !viper.IsSet("password_policy.require_digit") {
+		return true
+	}
+	return viper.GetBool("password_policy.require_digit")
+}
+
+func PasswordRequireSymbol() bool {
+	return viper.GetBool("password_policy.require_symbol")
+}
+
+func PasswordHistorySize() int {
+	if !viper.IsSet("password_policy.history") {
+		return 5
+	}
+	return viper.GetInt("password_policy.history")
+}
+
+func LoginMaxFailures() int64 {
+	max := viper.GetInt64("login.max_failures")
+	if max == 0 {
+		return 5
+	}
+	return max
+}
+
+func LoginBackoffBase() time.Duration {
+	base := viper.GetDuration("login.backoff_base")
+	if base == 0 {
+		return time.Second
+	}
+	return base
+}
+
+func LoginBackoffMax() time.Duration {
+	max := viper.GetDuration("login.backoff_max")
+	if max == 0 {
+		return 15 * time.Minute
+	}
+	return max
+}
+
+func LoginFailureWindow() time.Duration {
+	window := viper.GetDuration("login.failure_window")
+	if window == 0 {
+		return time.Hour
+	}
+	return window
+}
+
+func RegistrationEnabled() bool {
+	return viper.GetBool("registration.enabled")
+}
+
+func RegistrationAllowedDomains() []string {
+	return viper.GetStringSlice("registration.allowed_domains")
+}
+
+func InvitationTTL() time.Duration {
+	ttl := viper.GetDuration("invitation.ttl")
+	if ttl == 0 {
+		return 72 * time.Hour
+	}
+	return ttl
+}
+
+func TwoFactorIssuer() string {
+	issuer := viper.GetString("two_factor.issuer")
+	if issuer == "" {
+		return "Helpdesk"
+	}
+	return issuer
+}
+
+func SSODefaultRole() string {
+	role := viper.GetString("sso.default_role")
+	if role == "" {
+		return "USER"
+	}
+	return role
+}
+
+// SSOLinkExistingAccounts lets a first external login take over a local
+// account with the same email, as long as that account has no password.
+// Accounts with a password are never linked automatically.
+func SSOLinkExistingAccounts() bool {
+	return viper.GetBool("sso.link_existing_accounts")
+}
+
+func SSOGroupRoles() map[string]string {
+	return viper.GetStringMapString("sso.group_roles")
+}
+
+func SSOGroupProjects() map[string]string {
+	return viper.GetStringMapString("sso.group_projects")
+}
+
+func OIDCEnabled() bool {
+	return viper.GetBool("oidc.enabled")
+}
+
+func OIDCIssuer() string {
+	return viper.GetString("oidc.issuer")
+}
+
+func OIDCClientID() string {
+	return viper.GetString("oidc.client_id")
+}
+
+func OIDCClientSecret() string {
+	return viper.GetString("oidc.client_secret")
+}
+
+func OIDCRedirectURL() string {
+	return viper.GetString("oidc.redirect_url")
+}
+
+func OIDCScopes() []string {
+	scopes := viper.GetStringSlice("oidc.scopes")
+	if len(scopes) == 0 {
+		return []string{"openid", "profile", "email"}
+	}
+	return scopes
+}
+
+func OIDCGroupsClaim() string {
+	claim := viper.GetString("oidc.groups_claim")
+	if claim == "" {
+		return "groups"
+	}
+	return claim
+}
+
+func LDAPEnabled() bool {
+	return viper.GetBool("ldap.enabled")
+}
+
+func LDAPURL() string {
+	return viper.GetString("ldap.url")
+}
+
+func LDAPStartTLS() bool {
+	return viper.GetBool("ldap.start_tls")
+}
+
+func LDAPBindDN() string {
+	return viper.GetString("ldap.bind_dn")
+}
+
+func LDAPBindPassword() string {
+	return viper.GetString("ldap.bind_password")
+}
+
+func LDAPBaseDN() string {
+	return viper.GetString("ldap.base_dn")
+}
+
+func LDAPUserFilter() string {
+	filter := viper.GetString("ldap.user_filter")
+	if filter == "" {
+		return "(uid=%s)"
+	}
+	return filter
+}
+
+func LDAPEmailAttribute() string {
+	attr := viper.GetString("ldap.email_attribute")
+	if attr == "" {
+		return "mail"
+	}
+	return attr
+}
+
+func LDAPNameAttribute() string {
+	attr := viper.GetString("ldap.name_attribute")
+	if attr == "" {
+		return "cn"
+	}
+	return attr
+}
+
+func LDAPGroupAttribute() string {
+	attr := viper.GetString("ldap.group_attribute")
+	if attr == "" {
+		return "memberOf"
+	}
+	return attr
+}
+
+func APIKeyDefaultTTL() time.Duration {
+	ttl := viper.GetDuration("api_key.default_ttl")
+	if ttl == 0 {
+		return 90 * 24 * time.Hour
+	}
+	return ttl
+}
+
+func APIKeyMaxTTL() time.Duration {
+	ttl := viper.GetDuration("api_key.max_ttl")
+	if ttl == 0 {
+		return 365 * 24 * time.Hour
+	}
+	return ttl
+}
+
+func MailIngestEnabled() bool {
+	return viper.GetBool("mail_ingest.enabled")
+}
+
+func MailIngestAddr() string {
+	addr := viper.GetString("mail_ingest.addr")
+	if addr == "" {
+		return ":2525"
+	}
+	return addr
+}
+
+func MailIngestProtocol() string {
+	return strings.ToLower(viper.GetString("mail_ingest.protocol"))
+}
+
+func MailIngestDomain() string {
+	domain := viper.GetString("mail_ingest.domain")
+	if domain == "" {
+		return "localhost"
+	}
+	return domain
+}
+
+func MailIngestRecipients() []string {
+	return viper.GetStringSlice("mail_ingest.recipients")
+}
+
+// MailIngestAllowedIPs lists the relays (IPs or CIDRs) that may hand over
+// mail without authenticating.
+func MailIngestAllowedIPs() []string {
+	return viper.GetStringSlice("mail_ingest.allowed_ips")
+}
+
+func MailIngestUsername() string {
+	return viper.GetString("mail_ingest.username")
+}
+
+func MailIngestPassword() string {
+	return viper.GetString("mail_ingest.password")
+}
+
+func MailIngestAllowInsecureAuth() bool {
+	return viper.GetBool("mail_ingest.allow_insecure_auth")
+}
+
+// MailIngestAuthservID is the authserv-id of the relay that checks SPF and
+// DKIM. When set, only mail carrying a passing Authentication-Results
+// header from it is accepted.
+func MailIngestAuthservID() string {
+	return viper.GetString("mail_ingest.authserv_id")
+}
+
+func MailIngestMaxMessageBytes() int64 {
+	size := viper.GetInt64("mail_ingest.max_message_bytes")
+	if size == 0 {
+		return 25 * 1024 * 1024
+	}
+	return size
+}
+
+func MailIngestDefaultProjectID() int64 {
+	return viper.GetInt64("mail_ingest.default_project_id")
+}
+
+func MailIngestDefaultLocationID() int64 {
+	return viper.GetInt64("mail_ingest.default_location_id")
+}
+
+func MailIngestDefaultPartID() int64 {
+	return viper.GetInt64("mail_ingest.default_part_id")
+}
+
+func MailIngestDefaultAssetID() int64 {
+	return viper.GetInt64("mail_ingest.default_asset_id")
+}
+
+func MailIngestDefaultPriority() string {
+	priority := viper.GetString("mail_ingest.default_priority")
+	if priority == "" {
+		return "MEDIUM"
+	}
+	return strings.ToUpper(priority)
+}
+
+func MailRetryAttempts() int {
+	attempts := viper.GetInt("mail.retry_attempts")
+	if attempts <= 0 {
+		return 3
+	}
+	return attempts
+}
+
+func MailRetryBackoff() time.Duration {
+	backoff := viper.GetDuration("mail.retry_backoff")
+	if backoff == 0 {
+		return 2 * time.Second
+	}
+	return backoff
+}
+
+func NotificationEmailEnabled() bool {
+	return viper.GetBool("notification_email.enabled")
+}
+
+func NotificationEmailDefaultLanguage() string {
+	language := viper.GetString("notification_email.default_language")
+	if language == "" {
+		return "id"
+	}
+	return language
+}
+
+func WebhookMaxAttempts() int {
+	attempts := viper.GetInt("webhook.max_attempts")
+	if attempts <= 0 {
+		return 8
+	}
+	return attempts
+}
+
+func WebhookBackoffBase() time.Duration {
+	backoff := viper.GetDuration("webhook.backoff_base")
+	if backoff == 0 {
+		return 30 * time.Second
+	}
+	return backoff
+}
+
+func WebhookBackoffMax() time.Duration {
+	backoff := viper.GetDuration("webhook.backoff_max")
+	if backoff == 0 {
+		return 6 * time.Hour
+	}
+	return backoff
+}
+
+func WebhookTimeout() time.Duration {
+	timeout := viper.GetDuration("webhook.timeout")
+	if timeout == 0 {
+		return 10 * time.Second
+	}
+	return timeout
+}
+
+func WebhookPollInterval() time.Duration {
+	interval := viper.GetDuration("webhook.poll_interval")
+	if interval == 0 {
+		return 5 * time.Second
+	}
+	return interval
+}
+
+func APIURL() string {
+	url := viper.GetString("app.api_url")
+	if url == "" {
+		return "http://localhost:3000"
+	}
+	return strings.TrimRight(url, "/")
+}
+
+func ChatActionSecret() string {
+	return viper.GetString("chat.action_secret")
+}
+
+func ChatActionTTL() time.Duration {
+	ttl := viper.GetDuration("chat.action_ttl")
+	if ttl == 0 {
+		return 24 * time.Hour
+	}
+	return ttl
+}
+
+func ChatSLACheckInterval() time.Duration {
+	interval := viper.GetDuration("chat.sla_check_interval")
+	if interval == 0 {
+		return time.Minute
+	}
+	return interval
+}
+
+func ChatWebhookEnabled() bool {
+	return viper.GetBool("chat.webhook.enabled")
+}
+
+func ChatWebhookURL() string {
+	return viper.GetString("chat.webhook.url")
+}
+
+func TelegramEnabled() bool {
+	return viper.GetBool("chat.telegram.enabled")
+}
+
+func TelegramAPIURL() string {
+	url := viper.GetString("chat.telegram.api_url")
+	if url == "" {
+		return "https://api.telegram.org"
+	}
+	return strings.TrimRight(url, "/")
+}
+
+func TelegramBotToken() string {
+	return viper.GetString("chat.telegram.bot_token")
+}
+
+func TelegramBotUsername() string {
+	return viper.GetString("chat.telegram.bot_username")
+}
+
+func TelegramWebhookSecret() string {
+	return viper.GetString("chat.telegram.webhook_secret")
+}
+
+func TelegramGroupChatID() int64 {
+	return viper.GetInt64("chat.telegram.group_chat_id")
+}
+
+func PushEnabled() bool {
+	return viper.GetBool("push.enabled")
+}
+
+func PushVAPIDPublicKey() string {
+	return viper.GetString("push.vapid_public_key")
+}
+
+func PushVAPIDPrivateKey() string {
+	return viper.GetString("push.vapid_private_key")
+}
+
+func PushSubject() string {
+	subject := viper.GetString("push.subject")
+	if subject == "" {
+		return APIURL()
+	}
+	return subject
+}
+
+func PushTTL() time.Duration {
+	ttl := viper.GetDuration("push.ttl")
+	if ttl == 0 {
+		return 24 * time.Hour
+	}
+	return ttl
+}
+
+func PushTimeout() time.Duration {
+	timeout := viper.GetDuration("push.timeout")
+	if timeout == 0 {
+		return 10 * time.Second
+	}
+	return timeout
+}
+
+func PushSubscriptionTTL() time.Duration {
+	ttl := viper.GetDuration("push.subscription_ttl")
+	if ttl == 0 {
+		return 60 * 24 * time.Hour
+	}
+	return ttl
+}
+
+func PushMaxFailures() int {
+	failures := viper.GetInt("push.max_failures")
+	if failures <= 0 {
+		return 5
+	}
+	return failures
+}
+
+func PushCleanupInterval() time.Duration {
+	interval := viper.GetDuration("push.cleanup_interval")
+	if interval == 0 {
+		return time.Hour
+	}
+	return interval
+}
+
+func NotificationReadRetention() time.Duration {
+	retention := viper.GetDuration("notification.retention_read")
+	if retention == 0 {
+		return 30 * 24 * time.Hour
+	}
+	return retention
+}
+
+func NotificationUnreadRetention() time.Duration {
+	retention := viper.GetDuration("notification.retention_unread")
+	if retention == 0 {
+		return 90 * 24 * time.Hour
+	}
+	return retention
+}
+
+func NotificationCleanupInterval() time.Duration {
+	interval := viper.GetDuration("notification.cleanup_interval")
+	if interval == 0 {
+		return time.Hour
+	}
+	return interval
+}
+
+// NotificationQuietHoursBypassPriorities lists the ticket priorities whose
+// notifications are delivered even during a user's quiet hours.
+func NotificationQuietHoursBypassPriorities() []string {
+	priorities := viper.GetStringSlice("notification.quiet_hours_bypass_priorities")
+	if len(priorities) == 0 {
+		return []string{"URGENT"}
+	}
+	return priorities
+}
+
+func ConsumerPrefetch() int {
+	prefetch := viper.GetInt("consumer.prefetch")
+	if prefetch <= 0 {
+		return 10
+	}
+	return prefetch
+}
+
+func ConsumerRetryDelays() []time.Duration {
+	var delays []time.Duration
+
+	for _, value := range viper.GetStringSlice("consumer.retry_delays") {
+		delay, err := time.ParseDuration(value)
+		if err != nil || delay <= 0 {
+			continue
+		}
+		delays = append(delays, delay)
+	}
+
+	if len(delays) == 0 {
+		return []time.Duration{
+			5 * time.Second,
+			30 * time.Second,
+			2 * time.Minute,
+			10 * time.Minute,
+		}
+	}
+	return delays
+}
+
+func ConsumerReplayLimit() int {
+	limit := viper.GetInt("consumer.replay_limit")
+	if limit <= 0 {
+		return 500
+	}
+	return limit
+}
+
+func StorageDriver() string {
+	driver := viper.GetString("storage.driver")
+	if driver != "" {
+		return driver
+	}
+
+	if CloudinaryCloudName() != "" {
+		return "cloudinary"
+	}
+	return "local"
+}
+
+// StorageDriverConfigured reports whether storage.driver was set rather
+// than guessed from the other settings.
+func StorageDriverConfigured() bool {
+	return viper.GetString("storage.driver") != ""
+}
+
+func StorageLocalRoot() string {
+	root := viper.GetString("storage.local.root")
+	if root == "" {
+		return "storage"
+	}
+	return root
+}
+
+func StorageS3Endpoint() string {
+	return viper.GetString("storage.s3.endpoint")
+}
+
+func StorageS3AccessKey() string {
+	return viper.GetString("storage.s3.access_key")
+}
+
+func StorageS3SecretKey() string {
+	return viper.GetString("storage.s3.secret_key")
+}
+
+func StorageS3Bucket() string {
+	bucket := viper.GetString("storage.s3.bucket")
+	if bucket == "" {
+		return "helpdesk"
+	}
+	return bucket
+}
+
+func StorageS3Region() string {
+	return viper.GetString("storage.s3.region")
+}
+
+func StorageS3UseSSL() bool {
+	return viper.GetBool("storage.s3.use_ssl")
+}
+
+// StorageURLSecret signs file download URLs. It has no default: sharing
+// the JWT secret would let anyone who learns one forge the other.
+func StorageURLSecret() string {
+	return viper.GetString("storage.url_secret")
+}
+
+func StorageURLTTL() time.Duration {
+	ttl := viper.GetDuration("storage.url_ttl")
+	if ttl == 0 {
+		return time.Hour
+	}
+	return ttl
+}
+
+func AttachmentMaxFileSize() int64 {
+	size := viper.GetSizeInBytes("attachment.max_file_size")
+	if size == 0 {
+		return 10 << 20
+	}
+	return int64(size)
+}
+
+func AttachmentMaxVideoSize() int64 {
+	size := viper.GetSizeInBytes("attachment.max_video_size")
+	if size == 0 {
+		return 100 << 20
+	}
+	return int64(size)
+}
+
+func AttachmentMaxFiles() int {
+	max := viper.GetInt("attachment.max_files")
+	if max <= 0 {
+		return 10
+	}
+	return max
+}
+
+// AttachmentMaxSignatureSize caps the signature images drawn when a ticket
+// is resolved.
+func AttachmentMaxSignatureSize() int64 {
+	size := viper.GetSizeInBytes("attachment.max_signature_size")
+	if size == 0 {
+		return 1 << 20
+	}
+	return int64(size)
+}
+
+// AttachmentAllowedMimeTypes is the allow-list for projects that do not
+// define their own. Entries may end in "/*" to match a whole family.
+func AttachmentAllowedMimeTypes() []string {
+	types := viper.GetStringSlice("attachment.allowed_mime_types")
+	if len(types) == 0 {
+		return []string{
+			"image/*",
+			"video/*",
+			"application/pdf",
+			"text/plain",
+			"text/csv",
+			"application/msword",
+			"application/vnd.ms-excel",
+			"application/vnd.ms-powerpoint",
+			"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+			"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			"application/vnd.openxmlformats-officedocument.presentationml.presentation",
+		}
+	}
+	return types
+}
+
+func AntivirusDriver() string {
+	driver := viper.GetString("antivirus.driver")
+	if driver == "" {
+		return "none"
+	}
+	return driver
+}
+
+// AntivirusClamAVAddress is "unix:/path/to/clamd.sock" or "tcp:host:port".
+func AntivirusClamAVAddress() string {
+	address := viper.GetString("antivirus.clamav_address")
+	if address == "" {
+		return "unix:/var/run/clamav/clamd.ctl"
+	}
+	return address
+}
+
+func AntivirusTimeout() time.Duration {
+	timeout := viper.GetDuration("antivirus.timeout")
+	if timeout == 0 {
+		return 30 * time.Second
+	}
+	return timeout
+}
+
+// AntivirusFailOpen accepts files unscanned while the scanner is down
+// instead of rejecting the upload.
+func AntivirusFailOpen() bool {
+	return viper.GetBool("antivirus.fail_open")
+}
+
+// ImageThumbnailSize is the longest side, in pixels, of the small rendition
+// used in lists and spreadsheet exports.
+func ImageThumbnailSize() int {
+	size := viper.GetInt("image.thumbnail_size")
+	if size <= 0 {
+		return 320
+	}
+	return size
+}
+
+// ImagePreviewSize is the longest side of the rendition shown when an image
+// is opened, instead of the full-size original.
+func ImagePreviewSize() int {
+	size := viper.GetInt("image.preview_size")
+	if size <= 0 {
+		return 1280
+	}
+	return size
+}
+
+func ImageJPEGQuality() int {
+	quality := viper.GetInt("image.jpeg_quality")
+	if quality <= 0 || quality > 100 {
+		return 85
+	}
+	return quality
+}
+
+// ImageMaxPixels rejects images whose decoded size would use too much
+// memory, such as decompression bombs.
+func ImageMaxPixels() int {
+	pixels := viper.GetInt("image.max_pixels")
+	if pixels <= 0 {
+		return 50_000_000
+	}
+	return pixels
+}
+
+func ExportWorkers() int {
+	workers := viper.GetInt("export.workers")
+	if workers <= 0 {
+		return 2
+	}
+	return workers
+}
+
+func ExportPollInterval() time.Duration {
+	interval := viper.GetDuration("export.poll_interval")
+	if interval == 0 {
+		return 5 * time.Second
+	}
+	return interval
+}
+
+// ExportTimeout bounds one export run. A job still marked processing after
+// this long is assumed to belong to a crashed worker and is picked up again.
+func ExportTimeout() time.Duration {
+	timeout := viper.GetDuration("export.timeout")
+	if timeout == 0 {
+		return 30 * time.Minute
+	}
+	return timeout
+}
+
+func ExportMaxAttempts() int {
+	attempts := viper.GetInt("export.max_attempts")
+	if attempts <= 0 {
+		return 3
+	}
+	return attempts
+}
+
+func ExportMaxRows() int {
+	rows := viper.GetInt("export.max_rows")
+	if rows <= 0 {
+		return 10000
+	}
+	return rows
+}
+
+// ExportDetailMaxRows caps formats with a detail page per ticket, such as
+// PDF, which load and render far more per row than a spreadsheet.
+func ExportDetailMaxRows() int {
+	rows := viper.GetInt("export.detail_max_rows")
+	if rows <= 0 {
+		return 500
+	}
+	return rows
+}
+
+func ExportMaxActivePerUser() int {
+	max := viper.GetInt("export.max_active_per_user")
+	if max <= 0 {
+		return 3
+	}
+	return max
+}
+
+// ExportImageConcurrency caps how many resolution photos one export
+// downloads at the same time.
+func ExportImageConcurrency() int {
+	concurrency := viper.GetInt("export.image_concurrency")
+	if concurrency <= 0 {
+		return 8
+	}
+	return concurrency
+}
+
+func ExportImageTimeout() time.Duration {
+	timeout := viper.GetDuration("export.image_timeout")
+	if timeout == 0 {
+		return 15 * time.Second
+	}
+	return timeout
+}
+
+// ExportRetention is how long finished exports can be downloaded before
+// the job and its file are deleted.
+func ExportRetention() time.Duration {
+	retention := viper.GetDuration("export.retention")
+	if retention == 0 {
+		return 24 * time.Hour
+	}
+	return retention
+}
+
+func ExportCleanupInterval() time.Duration {
+	interval := viper.GetDuration("export.cleanup_interval")
+	if interval == 0 {
+		return time.Hour
+	}
+	return interval
+}
+
+// ReportCompanyName heads printed service reports.
+func ReportCompanyName() string {
+	name := viper.GetString("report.company_name")
+	if name == "" {
+		return "PT Nutech Integrasi"
+	}
+	return name
+}
+
+func ReportCompanyAddress() string {
+	return viper.GetString("report.company_address")
+}
+
+// ReportLogoPath is a local JPEG or PNG printed beside the company name.
+// Reports are printed without a logo when it is empty.
+func ReportLogoPath() string {
+	return viper.GetString("report.logo_path")
+}
+
+func ReportScheduleWorkers() int {
+	workers := viper.GetInt("report_schedule.workers")
+	if workers <= 0 {
+		return 1
+	}
+	return workers
+}
+
+func ReportSchedulePollInterval() time.Duration {
+	interval := viper.GetDuration("report_schedule.poll_interval")
+	if interval == 0 {
+		return 30 * time.Second
+	}
+	return interval
+}
+
+// ReportScheduleTimeout bounds one scheduled run, including delivery. A
+// schedule still leased after this long is picked up again.
+func ReportScheduleTimeout() time.Duration {
+	timeout := viper.GetDuration("report_schedule.timeout")
+	if timeout == 0 {
+		return 30 * time.Minute
+	}
+	return timeout
+}
+
+// ReportScheduleTimezone is used for schedules saved without a timezone.
+func ReportScheduleTimezone() string {
+	timezone := viper.GetString("report_schedule.timezone")
+	if timezone == "" {
+		return "Asia/Jakarta"
+	}
+	return timezone
+}
+
+func ReportScheduleMaxPerUser() int {
+	max := viper.GetInt("report_schedule.max_per_user")
+	if max <= 0 {
+		return 10
+	}
+	return max
+}
+
+// ReportScheduleRetention is how long the file of a scheduled run can be
+// downloaded. The run stays in the history after its file is deleted.
+func ReportScheduleRetention() time.Duration {
+	retention := viper.GetDuration("report_schedule.retention")
+	if retention == 0 {
+		return 30 * 24 * time.Hour
+	}
+	return retention
+}
+
+// ReportScheduleRoles are the roles that may create report schedules.
+// Schedules whose owner no longer has one of them are disabled.
+func ReportScheduleRoles() []string {
+	roles := viper.GetStringSlice("report_schedule.roles")
+	if len(roles) == 0 {
+		return []string{"ADMINISTRATOR"}
+	}
+	return roles
+}
+
+// ReportScheduleMinInterval is the shortest gap allowed between two runs
+// of a schedule.
+func ReportScheduleMinInterval() time.Duration {
+	interval := viper.GetDuration("report_schedule.min_interval")
+	if interval == 0 {
+		return time.Hour
+	}
+	return interval
+}
+
+// ReportScheduleRecipientDomains lists the email domains reports may be
+// sent to besides the addresses of active users.
+func ReportScheduleRecipientDomains() []string {
+	return viper.GetStringSlice("report_schedule.recipient_domains")
+}
+
+func ReportScheduleCleanupInterval() time.Duration {
+	interval := viper.GetDuration("report_schedule.cleanup_interval")
+	if interval == 0 {
+		return time.Hour
+	}
+	return interval
+}
+
+// ReportScheduleMailAttachmentMaxSize caps the file attached to report
+// emails; larger reports are sent as a download link only.
+func ReportScheduleMailAttachmentMaxSize() int64 {
+	size := viper.GetSizeInBytes("report_schedule.mail_attachment_max_size")
+	if size == 0 {
+		return 10 << 20
+	}
+	return int64(size)
+}
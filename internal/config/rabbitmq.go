@@ -2,6 +2,8 @@ package config
 
 import (
 	"log"
+	"sync"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
@@ -9,15 +11,58 @@ import (
 var RabbitMQ *amqp.Connection
 var RabbitChannel *amqp.Channel
 
+var rabbitMu sync.RWMutex
+
 func InitRabbitMQ(url string) {
-	conn, err := amqp.Dial(url)
+	conn, ch, err := dialRabbitMQ(url)
 	if err != nil {
 		log.Fatal("failed connect rabbitmq:", err)
 	}
 
+	setRabbitMQ(conn, ch)
+
+	log.Println("RabbitMQ connected")
+
+	go watchRabbitMQ(url, conn, ch)
+}
+
+// RabbitConnection returns the current broker connection, which is replaced
+// after a reconnect.
+func RabbitConnection() *amqp.Connection {
+	rabbitMu.RLock()
+	defer rabbitMu.RUnlock()
+
+	return RabbitMQ
+}
+
+// CurrentRabbitChannel returns the shared publishing channel, which is
+// replaced after a reconnect.
+func CurrentRabbitChannel() *amqp.Channel {
+	rabbitMu.RLock()
+	defer rabbitMu.RUnlock()
+
+	return RabbitChannel
+}
+
+func dialRabbitMQ(url string) (*amqp.Connection, *amqp.Channel, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch, err := openRabbitChannel(conn)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	return conn, ch, nil
+}
+
+func openRabbitChannel(conn *amqp.Connection) (*amqp.Channel, error) {
 	ch, err := conn.Channel()
 	if err != nil {
-		log.Fatal("failed create channel:", err)
+		return nil, err
 	}
 
 	err = ch.ExchangeDeclare(
@@ -31,11 +76,74 @@ func InitRabbitMQ(url string) {
 	)
 
 	if err != nil {
-		log.Fatal("failed declare exchange:", err)
+		ch.Close()
+		return nil, err
 	}
 
+	return ch, nil
+}
+
+func setRabbitMQ(conn *amqp.Connection, ch *amqp.Channel) {
+	rabbitMu.Lock()
+	defer rabbitMu.Unlock()
+
 	RabbitMQ = conn
 	RabbitChannel = ch
+}
 
-	log.Println("RabbitMQ connected")
+// watchRabbitMQ reopens the shared channel when the broker closes it and
+// redials with backoff when the whole connection is lost.
+func watchRabbitMQ(url string, conn *amqp.Connection, ch *amqp.Channel) {
+	for {
+		connClosed := conn.NotifyClose(make(chan *amqp.Error, 1))
+		chClosed := ch.NotifyClose(make(chan *amqp.Error, 1))
+
+		select {
+		case reason, ok := <-connClosed:
+			if !ok {
+				return
+			}
+			log.Println("rabbitmq connection lost:", reason)
+
+		case reason := <-chClosed:
+			if !conn.IsClosed() {
+				log.Println("rabbitmq channel closed:", reason)
+
+				newCh, err := openRabbitChannel(conn)
+				if err == nil {
+					ch = newCh
+					setRabbitMQ(conn, ch)
+					continue
+				}
+
+				log.Println("failed reopen rabbitmq channel:", err)
+				conn.Close()
+			}
+		}
+
+		conn, ch = redialRabbitMQ(url)
+		setRabbitMQ(conn, ch)
+
+		log.Println("RabbitMQ reconnected")
+	}
+}
+
+func redialRabbitMQ(url string) (*amqp.Connection, *amqp.Channel) {
+	backoff := time.Second
+
+	for {
+		time.Sleep(backoff)
+
+		conn, ch, err := dialRabbitMQ(url)
+		if err == nil {
+			return conn, ch
+		}
+
+		log.Println("failed reconnect rabbitmq:", err)
+
+		backoff *= 2
+		if backoff > 30*time.Second {
+			backoff = 30 * time.Second
+		}
+	}
 }
@@ -2,16 +2,24 @@ package console
 
 import (
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"strings"
 	"sync"
 
 	"github.com/joho/godotenv"
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/db"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/antivirus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/auth"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/chat"
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/consumer"
-	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/mailer"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/mailin"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/push"
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/repository"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/storage"
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/usecase"
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/worker"
 
@@ -49,14 +57,9 @@ func httpServer(cmd *cobra.Command, args []string) {
 		os.Getenv("RABBITMQ_URL"),
 	)
 
-	err = helper.InitCloudinary(
-		config.CloudinaryCloudName(),
-		config.CloudinaryAPIKey(),
-		config.CloudinaryAPISecret(),
-	)
-
+	store, err := storage.New()
 	if err != nil {
-		log.Fatalf("Cloudinary init failed: %v", err)
+		log.Fatalf("Failed to init storage: %v", err)
 	}
 
 	postgresDB := db.NewPostgres()
@@ -78,14 +81,56 @@ func httpServer(cmd *cobra.Command, args []string) {
 	ticketComment := repository.NewTicketCommentRepo(postgresDB)
 	ticketRepo := repository.NewTicketRepo(postgresDB, ticketComment)
 	ticketResolution := repository.NewTicketResolutionRepo(postgresDB)
+	resolutionSignatureRepo := repository.NewResolutionSignatureRepo(postgresDB)
 	dashboardRepo := repository.NewDashboardRepo(postgresDB)
 	notificationRepo := repository.NewNotificationRepo(postgresDB)
+	notificationPreferenceRepo := repository.NewNotificationPreferenceRepo(postgresDB)
+	passwordResetRepo := repository.NewPasswordResetRepo(postgresDB)
+	userInvitationRepo := repository.NewUserInvitationRepo(postgresDB)
+	passwordHistoryRepo := repository.NewPasswordHistoryRepo(postgresDB)
+	auditLogRepo := repository.NewAuditLogRepo(postgresDB)
+	recoveryCodeRepo := repository.NewUserRecoveryCodeRepo(postgresDB)
+	userIdentityRepo := repository.NewUserIdentityRepo(postgresDB)
+	apiKeyRepo := repository.NewAPIKeyRepo(postgresDB)
+	webhookRepo := repository.NewWebhookRepo(postgresDB)
+	pushSubscriptionRepo := repository.NewPushSubscriptionRepo(postgresDB)
+	attachmentRepo := repository.NewAttachmentRepo(postgresDB)
+	quarantinedFileRepo := repository.NewQuarantinedFileRepo(postgresDB)
+	exportJobRepo := repository.NewExportJobRepo(postgresDB)
+	reportScheduleRepo := repository.NewReportScheduleRepo(postgresDB)
+
+	mailSender := mailer.NewSender()
+
+	passwordProviders := auth.NewPasswordProviders()
+	redirectProviders := auth.NewRedirectProviders()
+
+	chatAdapters, telegramBot := chat.NewAdapters()
+
+	pushSender := push.NewSender(
+		config.PushVAPIDPublicKey(),
+		config.PushVAPIDPrivateKey(),
+		config.PushSubject(),
+		config.PushTTL(),
+		config.PushTimeout(),
+	)
 
 	hub := ws.NewHub()
+	hub.EnableRelay(config.Rdb)
 
 	go hub.Run()
 
-	userUsecase := usecase.NewUserUsecase(userRepo, projectRepo)
+	userUsecase := usecase.NewUserUsecase(
+		userRepo,
+		projectRepo,
+		roleRepo,
+		userInvitationRepo,
+		passwordResetRepo,
+		passwordHistoryRepo,
+		auditLogRepo,
+		userIdentityRepo,
+		mailSender,
+		passwordProviders,
+	)
 	roleUsecase := usecase.NewRoleUsecase(roleRepo)
 	projectUsecase := usecase.NewProjectUsecase(projectRepo)
 	locationUsecase := usecase.NewLocationUsecase(locationRepo)
@@ -93,29 +138,132 @@ func httpServer(cmd *cobra.Command, args []string) {
 	assetIDUsecase := usecase.NewAssetIDUsecase(assetIDRepo)
 	causeUsecase := usecase.NewCauseUsecase(causeRepo)
 	solutionUsecase := usecase.NewSolutionUsecase(solutionRepo)
-	ticketUsecase := usecase.NewTicketUsecase(postgresDB, ticketRepo, ticketHistoryRepo, projectRepo, hub)
+	attachmentUsecase := usecase.NewAttachmentUsecase(
+		attachmentRepo,
+		quarantinedFileRepo,
+		ticketRepo,
+		ticketComment,
+		ticketResolution,
+		projectRepo,
+		store,
+		antivirus.New(),
+	)
+	ticketUsecase := usecase.NewTicketUsecase(postgresDB, ticketRepo, ticketHistoryRepo, projectRepo, resolutionSignatureRepo, attachmentUsecase, hub)
 	ticketHistoryUsecase := usecase.NewTicketHistoryUsecase(ticketHistoryRepo, hub)
-	ticketCommentUsecase := usecase.NewTicketCommentUsecase(ticketComment, ticketHistoryRepo, ticketRepo, hub)
-	ticketResolutionUsecase := usecase.NewTicketResolutionUsecase(postgresDB, ticketResolution, ticketHistoryRepo, ticketRepo, hub)
+	ticketCommentUsecase := usecase.NewTicketCommentUsecase(postgresDB, ticketComment, ticketHistoryRepo, ticketRepo, attachmentUsecase, hub)
+	ticketResolutionUsecase := usecase.NewTicketResolutionUsecase(postgresDB, ticketResolution, resolutionSignatureRepo, ticketHistoryRepo, ticketRepo, attachmentUsecase, store, hub)
 	dashboardUsecase := usecase.NewDashboardUsecase(dashboardRepo)
-	notificationUsecase := usecase.NewNotificationUsecase(notificationRepo)
+	notificationUsecase := usecase.NewNotificationUsecase(notificationRepo, hub)
+	notificationPreferenceUsecase := usecase.NewNotificationPreferenceUsecase(notificationPreferenceRepo)
+	auditLogUsecase := usecase.NewAuditLogUsecase(auditLogRepo)
+	twoFactorUsecase := usecase.NewTwoFactorUsecase(userRepo, recoveryCodeRepo, auditLogRepo)
+	ssoUsecase := usecase.NewSSOUsecase(userRepo, roleRepo, userIdentityRepo, redirectProviders, passwordProviders)
+	serviceAccountUsecase := usecase.NewServiceAccountUsecase(userRepo, roleRepo, apiKeyRepo, auditLogRepo)
+	webhookUsecase := usecase.NewWebhookUsecase(webhookRepo, ticketRepo)
+	chatUsecase := usecase.NewChatUsecase(userRepo, ticketRepo, ticketUsecase, notificationPreferenceUsecase, chatAdapters, telegramBot)
+	pushUsecase := usecase.NewPushUsecase(pushSubscriptionRepo, notificationPreferenceUsecase, pushSender)
+	deadLetterUsecase := usecase.NewDeadLetterUsecase([]string{
+		consumer.NotificationQueue,
+		consumer.EmailNotificationQueue,
+		consumer.WebhookQueue,
+		consumer.PushNotificationQueue,
+		consumer.ChatNotificationQueue,
+	})
+	ticketExportUsecase := usecase.NewTicketExportUsecase(ticketUsecase, ticketRepo, ticketHistoryRepo, ticketComment, ticketResolution, resolutionSignatureRepo, attachmentRepo, store)
+	exportJobUsecase := usecase.NewExportJobUsecase(exportJobRepo, ticketExportUsecase, store, hub)
+	reportScheduleUsecase := usecase.NewReportScheduleUsecase(
+		reportScheduleRepo,
+		userRepo,
+		webhookRepo,
+		ticketExportUsecase,
+		mailer.NewRetrySender(mailSender, config.MailRetryAttempts(), config.MailRetryBackoff()),
+		store,
+	)
 
 	ticketWorker := worker.NewTicketWorker(postgresDB, hub)
 	go ticketWorker.Start()
 
 	notificationCleaner := worker.NewNotificationCleaner(
 		notificationUsecase,
+		config.NotificationCleanupInterval(),
 	)
 
 	go notificationCleaner.Start()
 
+	webhookWorker := worker.NewWebhookWorker(webhookUsecase, config.WebhookPollInterval())
+	go webhookWorker.Start()
+
+	for i := 0; i < config.ExportWorkers(); i++ {
+		exportWorker := worker.NewExportWorker(exportJobUsecase, config.ExportPollInterval())
+		go exportWorker.Start()
+	}
+
+	exportCleaner := worker.NewExportCleaner(exportJobUsecase, config.ExportCleanupInterval())
+	go exportCleaner.Start()
+
+	for i := 0; i < config.ReportScheduleWorkers(); i++ {
+		reportScheduler := worker.NewReportScheduler(reportScheduleUsecase, config.ReportSchedulePollInterval())
+		go reportScheduler.Start()
+	}
+
+	reportCleaner := worker.NewReportCleaner(reportScheduleUsecase, config.ReportScheduleCleanupInterval())
+	go reportCleaner.Start()
+
+	if len(chatAdapters) > 0 {
+		slaWorker := worker.NewSLAWorker(chatUsecase, config.ChatSLACheckInterval())
+		go slaWorker.Start()
+
+		consumer.StartChatNotificationConsumer(
+			chatUsecase,
+		)
+	}
+
+	if config.PushEnabled() {
+		pushSubscriptionCleaner := worker.NewPushSubscriptionCleaner(pushUsecase, config.PushCleanupInterval())
+		go pushSubscriptionCleaner.Start()
+
+		consumer.StartPushNotificationConsumer(
+			pushUsecase,
+		)
+	}
+
+	if config.MailIngestEnabled() {
+		mailIngestUsecase := usecase.NewMailIngestUsecase(userRepo, ticketRepo, ticketUsecase, ticketCommentUsecase)
+		mailServer := mailin.NewServer(mailIngestUsecase)
+
+		go func() {
+			logrus.Infof("mail ingestion listening on %s", mailServer.Addr)
+			if err := mailServer.ListenAndServe(); err != nil {
+				logrus.Error("mail ingestion stopped:", err)
+			}
+		}()
+	}
+
 	consumer.StartNotificationConsumer(
 		notificationUsecase,
+		notificationPreferenceUsecase,
+	)
+
+	consumer.StartWebhookConsumer(
+		webhookUsecase,
 	)
 
+	if config.NotificationEmailEnabled() {
+		consumer.StartEmailNotificationConsumer(
+			userRepo,
+			notificationPreferenceUsecase,
+			mailer.NewRetrySender(mailSender, config.MailRetryAttempts(), config.MailRetryBackoff()),
+		)
+	}
+
 	e := echo.New()
+	e.IPExtractor = ipExtractor(config.TrustedProxies())
+
+	handlerHttp.InitAPIKeyAuth(serviceAccountUsecase)
 
 	handlerHttp.NewUserHandler(e, userUsecase)
+	handlerHttp.NewTwoFactorHandler(e, twoFactorUsecase)
+	handlerHttp.NewSSOHandler(e, ssoUsecase)
 	handlerHttp.NewRoleHandler(e, roleUsecase)
 	handlerHttp.NewProjectHandler(e, projectUsecase)
 	handlerHttp.NewLocationHandler(e, locationUsecase)
@@ -123,12 +271,23 @@ func httpServer(cmd *cobra.Command, args []string) {
 	handlerHttp.NewAssetIDHandler(e, assetIDUsecase)
 	handlerHttp.NewCauseHandler(e, causeUsecase)
 	handlerHttp.NewSolutionHandler(e, solutionUsecase)
-	handlerHttp.NewTicketHandler(e, ticketUsecase)
+	handlerHttp.NewTicketHandler(e, ticketUsecase, ticketExportUsecase)
 	handlerHttp.NewTicketHistoryHandler(e, ticketHistoryUsecase)
 	handlerHttp.NewTicketCommentHandler(e, ticketCommentUsecase, ticketUsecase)
 	handlerHttp.NewTicketResolutionHandler(e, ticketResolutionUsecase)
+	handlerHttp.NewAttachmentHandler(e, attachmentUsecase)
 	handlerHttp.NewDashboardHandler(e, dashboardUsecase)
 	handlerHttp.NewNotificationHandler(e, notificationUsecase)
+	handlerHttp.NewNotificationPreferenceHandler(e, notificationPreferenceUsecase)
+	handlerHttp.NewAuditLogHandler(e, auditLogUsecase)
+	handlerHttp.NewServiceAccountHandler(e, serviceAccountUsecase)
+	handlerHttp.NewWebhookHandler(e, webhookUsecase)
+	handlerHttp.NewChatHandler(e, chatUsecase)
+	handlerHttp.NewPushHandler(e, pushUsecase)
+	handlerHttp.NewDeadLetterHandler(e, deadLetterUsecase)
+	handlerHttp.NewFileHandler(e, store)
+	handlerHttp.NewExportJobHandler(e, exportJobUsecase)
+	handlerHttp.NewReportScheduleHandler(e, reportScheduleUsecase)
 
 	wsHandler := ws.NewHandler(hub)
 
@@ -183,3 +342,43 @@ func httpServer(cmd *cobra.Command, args []string) {
 		}
 	}
 }
+
+// ipExtractor resolves the client IP from X-Forwarded-For only when the
+// request comes through one of the trusted proxies, so a client cannot pick
+// its own address to slip past the per-IP login throttle.
+func ipExtractor(trustedProxies []string) echo.IPExtractor {
+	var options []echo.TrustOption
+	for _, entry := range trustedProxies {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			log.Fatalf("invalid app.trusted_proxies entry %q: %v", entry, err)
+		}
+
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+
+	if len(options) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	options = append(options,
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	)
+
+	return echo.ExtractIPFromXFFHeader(options...)
+}
@@ -0,0 +1,36 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+const ChatNotificationQueue = "chat_notification_queue"
+
+func StartChatNotificationConsumer(
+	chatUsecase model.IChatUsecase,
+) {
+
+	consumer := &reliableConsumer{
+		queue:       ChatNotificationQueue,
+		exchange:    "ticket_events",
+		routingKey:  "ticket.*",
+		retryDelays: config.ConsumerRetryDelays(),
+		prefetch:    config.ConsumerPrefetch(),
+		handle: func(ctx context.Context, body []byte) error {
+
+			var event model.NotificationEvent
+
+			if err := json.Unmarshal(body, &event); err != nil {
+				return permanent(err)
+			}
+
+			return chatUsecase.NotifyAssigned(ctx, event)
+		},
+	}
+
+	go consumer.Run()
+}
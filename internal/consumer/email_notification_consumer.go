@@ -0,0 +1,109 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/mailer"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+const EmailNotificationQueue = "email_notification_queue"
+
+func StartEmailNotificationConsumer(
+	userRepo model.IUserRepository,
+	preferenceUsecase model.INotificationPreferenceUsecase,
+	mailSender model.IMailSender,
+) {
+
+	consumer := &reliableConsumer{
+		queue:       EmailNotificationQueue,
+		exchange:    "ticket_events",
+		routingKey:  "ticket.*",
+		retryDelays: config.ConsumerRetryDelays(),
+		prefetch:    config.ConsumerPrefetch(),
+		handle: func(ctx context.Context, body []byte) error {
+
+			var event model.NotificationEvent
+
+			if err := json.Unmarshal(body, &event); err != nil {
+				return permanent(err)
+			}
+
+			if !preferenceUsecase.Allows(ctx, event, model.ChannelEmail) {
+				return nil
+			}
+
+			return sendNotificationEmail(ctx, userRepo, mailSender, event)
+		},
+	}
+
+	go consumer.Run()
+}
+
+func sendNotificationEmail(
+	ctx context.Context,
+	userRepo model.IUserRepository,
+	mailSender model.IMailSender,
+	event model.NotificationEvent,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	recipient, err := userRepo.FindByID(ctx, event.UserID)
+	if err != nil {
+		return err
+	}
+
+	if !recipient.IsActive || recipient.IsServiceAccount || recipient.Email == "" {
+		return nil
+	}
+
+	actorName := "Sistem"
+	if recipient.Language == "en" {
+		actorName = "System"
+	}
+
+	if event.ActorID != 0 {
+		if actor, err := userRepo.FindByID(ctx, event.ActorID); err == nil {
+			actorName = actor.Name
+		}
+	}
+
+	language := recipient.Language
+	if language == "" {
+		language = config.NotificationEmailDefaultLanguage()
+	}
+
+	subject, body, err := mailer.RenderNotification(
+		language,
+		config.NotificationEmailDefaultLanguage(),
+		event.EventType,
+		mailer.NotificationTemplateData{
+			RecipientName: recipient.Name,
+			ActorName:     actorName,
+			TicketCode:    event.TicketCode,
+			Title:         event.Title,
+			Message:       event.Message,
+			TicketURL:     fmt.Sprintf("%s/tickets/%d", config.FrontendURL(), event.TicketID),
+		},
+	)
+
+	if errors.Is(err, mailer.ErrTemplateNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return mailSender.Send(ctx, model.Mail{
+		To:      []string{recipient.Email},
+		Subject: subject,
+		Body:    body,
+	})
+}
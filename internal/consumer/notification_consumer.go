@@ -3,68 +3,39 @@ package consumer
 import (
 	"context"
 	"encoding/json"
-	"log"
 
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
 )
 
+const NotificationQueue = "notification_queue"
+
 func StartNotificationConsumer(
 	notificationUsecase model.INotificationUsecase,
+	preferenceUsecase model.INotificationPreferenceUsecase,
 ) {
 
-	q, err := config.RabbitChannel.QueueDeclare(
-		"notification_queue",
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
-
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	err = config.RabbitChannel.QueueBind(
-		q.Name,
-		"ticket.*",
-		"ticket_events",
-		false,
-		nil,
-	)
-
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	msgs, err := config.RabbitChannel.Consume(
-		q.Name,
-		"",
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
-
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	go func() {
-		for d := range msgs {
+	consumer := &reliableConsumer{
+		queue:       NotificationQueue,
+		exchange:    "ticket_events",
+		routingKey:  "ticket.*",
+		retryDelays: config.ConsumerRetryDelays(),
+		prefetch:    config.ConsumerPrefetch(),
+		handle: func(ctx context.Context, body []byte) error {
 
 			var event model.NotificationEvent
 
-			err := json.Unmarshal(d.Body, &event)
+			err := json.Unmarshal(body, &event)
 			if err != nil {
-				log.Println(err)
-				continue
+				return permanent(err)
+			}
+
+			if !preferenceUsecase.Allows(ctx, event, model.ChannelInApp) {
+				return nil
 			}
 
 			_, err = notificationUsecase.Create(
-				context.Background(),
+				ctx,
 				model.CreateNotificationInput{
 					UserID:        event.UserID,
 					ActorID:       event.ActorID,
@@ -77,9 +48,9 @@ func StartNotificationConsumer(
 				},
 			)
 
-			if err != nil {
-				log.Println("failed create notification:", err)
-			}
-		}
-	}()
+			return err
+		},
+	}
+
+	go consumer.Run()
 }
@@ -0,0 +1,36 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+const PushNotificationQueue = "push_notification_queue"
+
+func StartPushNotificationConsumer(
+	pushUsecase model.IPushUsecase,
+) {
+
+	consumer := &reliableConsumer{
+		queue:       PushNotificationQueue,
+		exchange:    "ticket_events",
+		routingKey:  "ticket.*",
+		retryDelays: config.ConsumerRetryDelays(),
+		prefetch:    config.ConsumerPrefetch(),
+		handle: func(ctx context.Context, body []byte) error {
+
+			var event model.NotificationEvent
+
+			if err := json.Unmarshal(body, &event); err != nil {
+				return permanent(err)
+			}
+
+			return pushUsecase.Notify(ctx, event)
+		},
+	}
+
+	go consumer.Run()
+}
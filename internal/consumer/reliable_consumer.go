@@ -0,0 +1,240 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
+)
+
+// errPermanent marks a failure that retrying cannot fix, such as a malformed
+// message; it is dead-lettered straight away.
+var errPermanent = errors.New("permanent failure")
+
+// reliableConsumer consumes a queue with manual acknowledgement. Failed
+// messages are parked in per-delay TTL queues that dead-letter back into the
+// main queue, and end up in the DLQ once every retry delay is used up.
+type reliableConsumer struct {
+	queue       string
+	exchange    string
+	routingKey  string
+	retryDelays []time.Duration
+	prefetch    int
+	handle      func(ctx context.Context, body []byte) error
+}
+
+// Run keeps the consumer alive across broker disconnects.
+func (c *reliableConsumer) Run() {
+	backoff := time.Second
+
+	for {
+		started, err := c.consume()
+		log.Printf("[CONSUMER %s] stopped: %v", c.queue, err)
+
+		if started {
+			backoff = time.Second
+		}
+
+		time.Sleep(backoff)
+
+		backoff *= 2
+		if backoff > 30*time.Second {
+			backoff = 30 * time.Second
+		}
+	}
+}
+
+func (c *reliableConsumer) consume() (bool, error) {
+	conn := config.RabbitConnection()
+	if conn == nil || conn.IsClosed() {
+		return false, errors.New("rabbitmq connection is closed")
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return false, err
+	}
+	defer ch.Close()
+
+	if err := c.setup(ch); err != nil {
+		return false, err
+	}
+
+	msgs, err := ch.Consume(
+		c.queue,
+		"",
+		false,
+		false,
+		false,
+		false,
+		nil,
+	)
+
+	if err != nil {
+		return false, err
+	}
+
+	for d := range msgs {
+		c.process(ch, d)
+	}
+
+	return true, errors.New("delivery channel closed")
+}
+
+func (c *reliableConsumer) setup(ch *amqp.Channel) error {
+	_, err := ch.QueueDeclare(
+		c.queue,
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	err = ch.QueueBind(
+		c.queue,
+		c.routingKey,
+		c.exchange,
+		false,
+		nil,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	for _, delay := range c.retryDelays {
+		_, err := ch.QueueDeclare(
+			helper.RetryQueueName(c.queue, delay),
+			true,
+			false,
+			false,
+			false,
+			amqp.Table{
+				"x-message-ttl":             delay.Milliseconds(),
+				"x-dead-letter-exchange":    "",
+				"x-dead-letter-routing-key": c.queue,
+			},
+		)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = ch.QueueDeclare(
+		helper.DeadLetterQueueName(c.queue),
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if err := ch.Confirm(false); err != nil {
+		return err
+	}
+
+	return ch.Qos(c.prefetch, 0, false)
+}
+
+// process acknowledges the original delivery only after its retry or
+// dead-letter copy is confirmed by the broker, so a crash in between
+// redelivers instead of losing the message.
+func (c *reliableConsumer) process(ch *amqp.Channel, d amqp.Delivery) {
+	err := c.handle(context.Background(), d.Body)
+	if err == nil {
+		d.Ack(false)
+		return
+	}
+
+	attempt := retryCount(d.Headers)
+
+	target := helper.DeadLetterQueueName(c.queue)
+	if !errors.Is(err, errPermanent) && attempt < len(c.retryDelays) {
+		target = helper.RetryQueueName(c.queue, c.retryDelays[attempt])
+	}
+
+	log.Printf("[CONSUMER %s] attempt %d failed, moving to %s: %v", c.queue, attempt+1, target, err)
+
+	headers := amqp.Table{}
+	for key, value := range d.Headers {
+		headers[key] = value
+	}
+
+	if _, ok := headers[helper.HeaderOriginalRoutingKey]; !ok {
+		headers[helper.HeaderOriginalRoutingKey] = d.RoutingKey
+	}
+
+	headers[helper.HeaderRetryCount] = int64(attempt + 1)
+	headers[helper.HeaderLastError] = err.Error()
+	headers[helper.HeaderFailedAt] = time.Now().Format(time.RFC3339)
+
+	messageID := d.MessageId
+	if messageID == "" {
+		messageID, _ = helper.GenerateRandomToken(16)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	confirmation, err := ch.PublishWithDeferredConfirmWithContext(
+		ctx,
+		"",
+		target,
+		false,
+		false,
+		amqp.Publishing{
+			Headers:      headers,
+			ContentType:  d.ContentType,
+			DeliveryMode: amqp.Persistent,
+			MessageId:    messageID,
+			Timestamp:    time.Now(),
+			Body:         d.Body,
+		},
+	)
+
+	if err != nil {
+		log.Printf("[CONSUMER %s] failed publish to %s: %v", c.queue, target, err)
+		d.Nack(false, true)
+		return
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil || !acked {
+		log.Printf("[CONSUMER %s] broker did not confirm publish to %s: %v", c.queue, target, err)
+		d.Nack(false, true)
+		return
+	}
+
+	d.Ack(false)
+}
+
+func retryCount(headers amqp.Table) int {
+	switch value := headers[helper.HeaderRetryCount].(type) {
+	case int64:
+		return int(value)
+	case int32:
+		return int(value)
+	case int:
+		return value
+	}
+	return 0
+}
+
+func permanent(err error) error {
+	return fmt.Errorf("%w: %v", errPermanent, err)
+}
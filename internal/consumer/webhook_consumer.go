@@ -0,0 +1,37 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+const WebhookQueue = "webhook_queue"
+
+func StartWebhookConsumer(
+	webhookUsecase model.IWebhookUsecase,
+) {
+
+	consumer := &reliableConsumer{
+		queue:       WebhookQueue,
+		exchange:    "ticket_events",
+		routingKey:  helper.LifecycleRoutingKeyPrefix + "*",
+		retryDelays: config.ConsumerRetryDelays(),
+		prefetch:    config.ConsumerPrefetch(),
+		handle: func(ctx context.Context, body []byte) error {
+
+			var event model.NotificationEvent
+
+			if err := json.Unmarshal(body, &event); err != nil {
+				return permanent(err)
+			}
+
+			return webhookUsecase.Dispatch(ctx, event)
+		},
+	}
+
+	go consumer.Run()
+}
@@ -0,0 +1,267 @@
+// Package cron parses standard five-field cron expressions (minute, hour,
+// day of month, month, day of week) and works out when they next fire.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	// Schedules are evaluated in the timezone they were saved with, which
+	// must resolve even on hosts without a zoneinfo database.
+	_ "time/tzdata"
+)
+
+var ErrEmptyExpression = errors.New("empty cron expression")
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var dayNames = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+}
+
+type field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: monthNames}
+	// Day of week accepts 7 as well as 0 for Sunday.
+	dowField = field{name: "day of week", min: 0, max: 7, names: dayNames}
+)
+
+// Schedule is a parsed expression. Each field is a bit set of the values
+// it matches.
+type Schedule struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	// When both day fields are restricted a day matches either of them,
+	// as in Vixie cron.
+	domAny bool
+	dowAny bool
+}
+
+// Parse reads a five-field expression or one of the @daily style
+// shorthands.
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, ErrEmptyExpression
+	}
+
+	if strings.HasPrefix(expr, "@") {
+		expanded, ok := descriptors[strings.ToLower(expr)]
+		if !ok {
+			return nil, fmt.Errorf("unknown descriptor %q", expr)
+		}
+		expr = expanded
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields, got %d", len(fields))
+	}
+
+	s := &Schedule{
+		domAny: fields[2] == "*" || fields[2] == "?",
+		dowAny: fields[4] == "*" || fields[4] == "?",
+	}
+
+	var err error
+
+	if s.minute, err = parseField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+
+	if s.hour, err = parseField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+
+	if s.dom, err = parseField(fields[2], domField); err != nil {
+		return nil, err
+	}
+
+	if s.month, err = parseField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+
+	if s.dow, err = parseField(fields[4], dowField); err != nil {
+		return nil, err
+	}
+
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+
+	return s, nil
+}
+
+// Next returns the first time after t the schedule fires, in t's location.
+// It returns the zero time for expressions that never fire, such as the
+// 31st of February.
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = advance(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc))
+			continue
+		}
+
+		if !s.dayMatches(t) {
+			t = advance(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc))
+			continue
+		}
+
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = advance(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc))
+			continue
+		}
+
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+// advance moves to next, stepping an hour instead when a daylight saving
+// change would otherwise send the time backwards.
+func advance(current time.Time, next time.Time) time.Time {
+	if !next.After(current) {
+		return current.Add(time.Hour)
+	}
+
+	return next
+}
+
+func parseField(expr string, f field) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(expr, ",") {
+		partBits, err := parseRange(part, f)
+		if err != nil {
+			return 0, err
+		}
+
+		bits |= partBits
+	}
+
+	return bits, nil
+}
+
+// parseRange reads one list entry: "*", "5", "1-5" or "MON-FRI", each
+// optionally followed by "/step".
+func parseRange(expr string, f field) (uint64, error) {
+	rangeExpr, stepExpr, hasStep := strings.Cut(expr, "/")
+
+	step := 1
+	if hasStep {
+		var err error
+
+		step, err = strconv.Atoi(stepExpr)
+		if err != nil || step <= 0 {
+			return 0, fmt.Errorf("invalid step %q in %s field", stepExpr, f.name)
+		}
+	}
+
+	var start, end int
+
+	switch {
+	case rangeExpr == "*" || rangeExpr == "?":
+		start, end = f.min, f.max
+
+	case strings.Contains(rangeExpr, "-"):
+		low, high, _ := strings.Cut(rangeExpr, "-")
+
+		var err error
+
+		if start, err = parseValue(low, f); err != nil {
+			return 0, err
+		}
+
+		if end, err = parseValue(high, f); err != nil {
+			return 0, err
+		}
+
+	default:
+		value, err := parseValue(rangeExpr, f)
+		if err != nil {
+			return 0, err
+		}
+
+		start, end = value, value
+
+		// "5/15" means every 15 starting at 5.
+		if hasStep {
+			end = f.max
+		}
+	}
+
+	if start > end {
+		return 0, fmt.Errorf("invalid range %q in %s field", rangeExpr, f.name)
+	}
+
+	var bits uint64
+
+	for value := start; value <= end; value += step {
+		bits |= 1 << uint(value)
+	}
+
+	return bits, nil
+}
+
+func parseValue(expr string, f field) (int, error) {
+	if value, ok := f.names[strings.ToUpper(expr)]; ok {
+		return value, nil
+	}
+
+	value, err := strconv.Atoi(expr)
+	if err != nil || value < f.min || value > f.max {
+		return 0, fmt.Errorf("invalid value %q in %s field", expr, f.name)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,252 @@
+package http
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type AttachmentHandler struct {
+	attachmentUsecase model.IAttachmentUsecase
+}
+
+func NewAttachmentHandler(e *echo.Echo, attachmentUsecase model.IAttachmentUsecase) {
+	handler := &AttachmentHandler{
+		attachmentUsecase: attachmentUsecase,
+	}
+
+	tickets := e.Group("/v1/tickets", AuthMiddleware)
+	tickets.GET("/:id/attachments", handler.FindByTicketID)
+	tickets.POST("/:id/attachments", handler.UploadTicket, UploadBodyLimit)
+	tickets.POST("/:id/comments/:commentId/attachments", handler.UploadComment, UploadBodyLimit)
+	tickets.POST("/:id/resolution/attachments", handler.UploadResolution, UploadBodyLimit)
+
+	attachments := e.Group("/v1/attachments", AuthMiddleware)
+	attachments.GET("/quarantine", handler.FindQuarantined, RoleMiddleware("ADMINISTRATOR"))
+	attachments.DELETE("/:id", handler.Delete)
+}
+
+func (h *AttachmentHandler) FindByTicketID(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	ticketID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid ticket id")
+	}
+
+	ownerType := model.AttachmentOwnerType(strings.ToUpper(c.QueryParam("owner_type")))
+
+	attachments, err := h.attachmentUsecase.FindByTicketID(
+		c.Request().Context(),
+		ticketID,
+		claim.UserID,
+		claim.Role,
+		ownerType,
+	)
+	if err != nil {
+		return attachmentError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+		"data":    attachments,
+	})
+}
+
+func (h *AttachmentHandler) UploadTicket(c echo.Context) error {
+	ticketID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid ticket id")
+	}
+
+	return h.upload(c, model.AttachmentOwner{
+		Type:     model.AttachmentOwnerTicket,
+		ID:       ticketID,
+		TicketID: ticketID,
+	})
+}
+
+func (h *AttachmentHandler) UploadComment(c echo.Context) error {
+	ticketID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid ticket id")
+	}
+
+	commentID, err := strconv.ParseInt(c.Param("commentId"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid comment id")
+	}
+
+	return h.upload(c, model.AttachmentOwner{
+		Type:     model.AttachmentOwnerComment,
+		ID:       commentID,
+		TicketID: ticketID,
+	})
+}
+
+func (h *AttachmentHandler) UploadResolution(c echo.Context) error {
+	ticketID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid ticket id")
+	}
+
+	return h.upload(c, model.AttachmentOwner{
+		Type:     model.AttachmentOwnerResolution,
+		TicketID: ticketID,
+	})
+}
+
+func (h *AttachmentHandler) Delete(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	if err := h.attachmentUsecase.Delete(c.Request().Context(), id, claim.UserID, claim.Role); err != nil {
+		return attachmentError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "attachment deleted successfully",
+	})
+}
+
+func (h *AttachmentHandler) FindQuarantined(c echo.Context) error {
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page == 0 {
+		page = 1
+	}
+
+	limit := 10
+
+	files, total, err := h.attachmentUsecase.FindQuarantined(c.Request().Context(), page, limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	totalPage := int((total + int64(limit) - 1) / int64(limit))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":       files,
+		"page":       page,
+		"total_data": total,
+		"total_page": totalPage,
+	})
+}
+
+func (h *AttachmentHandler) upload(c echo.Context, owner model.AttachmentOwner) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	files, closeFiles, err := formAttachments(c)
+	if err != nil {
+		return err
+	}
+	defer closeFiles()
+
+	if len(files) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "attachments is required")
+	}
+
+	attachments, err := h.attachmentUsecase.Upload(
+		c.Request().Context(),
+		claim.UserID,
+		claim.Role,
+		owner,
+		files,
+	)
+	if err != nil {
+		return attachmentError(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": "attachments uploaded successfully",
+		"data":    attachments,
+	})
+}
+
+// formAttachments opens every file sent as "attachments" (repeatable) or the
+// older single "attachment" field. A request that is not multipart simply
+// carries no files.
+func formAttachments(c echo.Context) ([]model.AttachmentUpload, func(), error) {
+	var opened []multipart.File
+
+	closeFiles := func() {
+		for _, file := range opened {
+			file.Close()
+		}
+	}
+
+	if !strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		return nil, closeFiles, nil
+	}
+
+	form, err := c.MultipartForm()
+
+	// UploadBodyLimit cut the request off.
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return nil, closeFiles, echo.NewHTTPError(http.StatusRequestEntityTooLarge, model.ErrAttachmentTooLarge.Error())
+	}
+
+	if err != nil {
+		return nil, closeFiles, echo.NewHTTPError(http.StatusBadRequest, "invalid multipart form")
+	}
+
+	headers := append(form.File["attachments"], form.File["attachment"]...)
+
+	files := make([]model.AttachmentUpload, 0, len(headers))
+
+	for _, header := range headers {
+		file, err := header.Open()
+		if err != nil {
+			closeFiles()
+			return nil, func() {}, echo.NewHTTPError(http.StatusInternalServerError, "failed to open file")
+		}
+
+		opened = append(opened, file)
+
+		files = append(files, model.AttachmentUpload{
+			Filename:    header.Filename,
+			ContentType: header.Header.Get(echo.HeaderContentType),
+			Size:        header.Size,
+			Content:     file,
+		})
+	}
+
+	return files, closeFiles, nil
+}
+
+func attachmentError(err error) error {
+	switch {
+	case errors.Is(err, model.ErrAttachmentNotFound),
+		errors.Is(err, model.ErrAttachmentOwnerNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+
+	case errors.Is(err, model.ErrAttachmentForbidden):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+
+	case errors.Is(err, model.ErrAttachmentTooMany),
+		errors.Is(err, model.ErrAttachmentTooLarge),
+		errors.Is(err, model.ErrSignatureTooLarge):
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, err.Error())
+
+	case errors.Is(err, model.ErrAttachmentTypeRejected),
+		errors.Is(err, model.ErrSignatureInvalid):
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType, err.Error())
+
+	case errors.Is(err, model.ErrAttachmentInfected):
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
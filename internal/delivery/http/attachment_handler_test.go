@@ -0,0 +1,107 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/viper"
+)
+
+// newUploadServer routes a multipart upload through UploadBodyLimit and
+// formAttachments with one file of 1 KB per request allowed, which puts
+// the body limit a little above 3 MB once signatures and form fields are
+// accounted for.
+func newUploadServer(t *testing.T) *echo.Echo {
+	t.Helper()
+
+	settings := map[string]interface{}{
+		"attachment.max_files":      1,
+		"attachment.max_file_size":  "1KB",
+		"attachment.max_video_size": "1KB",
+	}
+	for key, value := range settings {
+		viper.Set(key, value)
+	}
+	t.Cleanup(func() {
+		for key := range settings {
+			viper.Set(key, nil)
+		}
+	})
+
+	e := echo.New()
+	e.POST("/upload", func(c echo.Context) error {
+		files, closeFiles, err := formAttachments(c)
+		defer closeFiles()
+
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, map[string]int{"files": len(files)})
+	}, UploadBodyLimit)
+
+	return e
+}
+
+func multipartBody(t *testing.T, size int) (*bytes.Buffer, string) {
+	t.Helper()
+
+	var body bytes.Buffer
+
+	writer := multipart.NewWriter(&body)
+
+	part, err := writer.CreateFormFile("attachments", "notes.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	part.Write(bytes.Repeat([]byte("a"), size))
+	writer.Close()
+
+	return &body, writer.FormDataContentType()
+}
+
+func TestUploadBodyLimit(t *testing.T) {
+	tests := []struct {
+		name          string
+		size          int
+		contentLength bool
+		status        int
+	}{
+		{"within limit", 512, true, http.StatusOK},
+		{"declared too large", 8 << 20, true, http.StatusRequestEntityTooLarge},
+		{"chunked too large", 8 << 20, false, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newUploadServer(t)
+
+			body, contentType := multipartBody(t, tt.size)
+
+			var reader io.Reader = body
+			if !tt.contentLength {
+				// Hide the length so only MaxBytesReader can stop it.
+				reader = io.MultiReader(body)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/upload", reader)
+			req.Header.Set(echo.HeaderContentType, contentType)
+			if !tt.contentLength {
+				req.ContentLength = -1
+			}
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type AuditLogHandler struct {
+	auditLogUsecase model.IAuditLogUsecase
+}
+
+func NewAuditLogHandler(e *echo.Echo, auditLogUsecase model.IAuditLogUsecase) {
+	handler := &AuditLogHandler{
+		auditLogUsecase: auditLogUsecase,
+	}
+
+	group := e.Group("/v1/audit-logs", AuthMiddleware, RoleMiddleware("ADMINISTRATOR"))
+
+	group.GET("", handler.FindAll)
+}
+
+func (h *AuditLogHandler) FindAll(c echo.Context) error {
+	var filter model.AuditLog
+
+	filter.Action = c.QueryParam("action")
+	filter.TargetType = c.QueryParam("target_type")
+	filter.TargetID, _ = strconv.ParseInt(c.QueryParam("target_id"), 10, 64)
+
+	if actorID, err := strconv.ParseInt(c.QueryParam("actor_id"), 10, 64); err == nil {
+		filter.ActorID = &actorID
+	}
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page == 0 {
+		page = 1
+	}
+
+	limit := 10
+
+	logs, total, err := h.auditLogUsecase.FindAll(c.Request().Context(), filter, page, limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	totalPage := int((total + int64(limit) - 1) / int64(limit))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":       logs,
+		"page":       page,
+		"total_data": total,
+		"total_page": totalPage,
+	})
+}
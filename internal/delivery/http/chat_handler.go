@@ -0,0 +1,141 @@
+package http
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+var chatActionPage = template.Must(template.New("chat_action").Parse(`<!DOCTYPE html>
+<html lang="id">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Konfirmasi Aksi Tiket</title>
+</head>
+<body>
+<form method="post" action="{{.Action}}">
+<p>Jalankan aksi <strong>{{.Label}}</strong> untuk tiket <strong>{{.TicketCode}}</strong>?</p>
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">{{.Label}}</button>
+</form>
+</body>
+</html>
+`))
+
+type ChatHandler struct {
+	chatUsecase model.IChatUsecase
+}
+
+func NewChatHandler(e *echo.Echo, chatUsecase model.IChatUsecase) {
+	handler := &ChatHandler{
+		chatUsecase: chatUsecase,
+	}
+
+	group := e.Group("/v1/chat")
+
+	group.GET("/actions", handler.ConfirmSignedAction)
+	group.POST("/actions", handler.SignedAction)
+	group.POST("/telegram/webhook", handler.TelegramWebhook)
+	group.POST("/telegram/link", handler.CreateTelegramLink, AuthMiddleware)
+}
+
+// ConfirmSignedAction answers an action link from a chat message with a
+// page asking the user to confirm it. Link previews and prefetchers follow
+// GET links on their own, so the action only runs on the POST that follows.
+func (h *ChatHandler) ConfirmSignedAction(c echo.Context) error {
+	token := c.QueryParam("token")
+
+	preview, err := h.chatUsecase.PreviewSignedAction(c.Request().Context(), token)
+	if err != nil {
+		return redirectChatAction(c, http.StatusFound, 0, err)
+	}
+
+	var page bytes.Buffer
+
+	err = chatActionPage.Execute(&page, map[string]string{
+		"Action":     c.Request().URL.Path,
+		"Label":      preview.Label,
+		"TicketCode": preview.TicketCode,
+		"Token":      token,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	c.Response().Header().Set("Referrer-Policy", "no-referrer")
+
+	return c.HTML(http.StatusOK, page.String())
+}
+
+// SignedAction runs a confirmed action link and sends the browser on to the
+// ticket page with the outcome.
+func (h *ChatHandler) SignedAction(c echo.Context) error {
+	ticketID, err := h.chatUsecase.HandleSignedAction(c.Request().Context(), c.FormValue("token"))
+
+	return redirectChatAction(c, http.StatusSeeOther, ticketID, err)
+}
+
+func redirectChatAction(c echo.Context, status int, ticketID int64, err error) error {
+	query := url.Values{}
+	if err != nil {
+		query.Set("chat_action", "error")
+		query.Set("message", err.Error())
+	} else {
+		query.Set("chat_action", "success")
+	}
+
+	target := config.FrontendURL() + "/"
+	if ticketID != 0 {
+		target = fmt.Sprintf("%s/tickets/%d", config.FrontendURL(), ticketID)
+	}
+
+	return c.Redirect(status, target+"?"+query.Encode())
+}
+
+func (h *ChatHandler) TelegramWebhook(c echo.Context) error {
+	secret := config.TelegramWebhookSecret()
+	received := c.Request().Header.Get("X-Telegram-Bot-Api-Secret-Token")
+
+	if secret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(received)) != 1 {
+		return echo.NewHTTPError(http.StatusForbidden, "invalid secret token")
+	}
+
+	var update model.TelegramUpdate
+	if err := c.Bind(&update); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Telegram keeps redelivering updates that are not answered with 200,
+	// so failures are only logged.
+	if err := h.chatUsecase.HandleTelegramUpdate(c.Request().Context(), update); err != nil {
+		logrus.WithField("update_id", update.UpdateID).Error("failed handle telegram update:", err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *ChatHandler) CreateTelegramLink(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	link, err := h.chatUsecase.CreateTelegramLink(c.Request().Context(), claim.UserID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "open the link in Telegram to connect your account",
+		"data":    link,
+	})
+}
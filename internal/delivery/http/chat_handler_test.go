@@ -0,0 +1,61 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type signedActionUsecase struct {
+	model.IChatUsecase
+	handled []string
+}
+
+func (u *signedActionUsecase) PreviewSignedAction(ctx context.Context, token string) (*model.ChatActionPreview, error) {
+	return &model.ChatActionPreview{TicketID: 5, TicketCode: "NUT-20260101-0005", Label: "Take"}, nil
+}
+
+func (u *signedActionUsecase) HandleSignedAction(ctx context.Context, token string) (int64, error) {
+	u.handled = append(u.handled, token)
+	return 5, nil
+}
+
+func TestSignedActionRunsOnlyOnPost(t *testing.T) {
+	uc := &signedActionUsecase{}
+
+	e := echo.New()
+	NewChatHandler(e, uc)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/chat/actions?token=abc.def", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || len(uc.handled) != 0 {
+		t.Fatalf("GET status = %d, handled = %v; want a confirmation page only", rec.Code, uc.handled)
+	}
+
+	page := rec.Body.String()
+	if !strings.Contains(page, `method="post"`) || !strings.Contains(page, `value="abc.def"`) || !strings.Contains(page, "NUT-20260101-0005") {
+		t.Fatalf("confirmation page = %s", page)
+	}
+
+	form := url.Values{"token": {"abc.def"}}
+	req = httptest.NewRequest(http.MethodPost, "/v1/chat/actions", strings.NewReader(form.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusSeeOther || len(uc.handled) != 1 || uc.handled[0] != "abc.def" {
+		t.Fatalf("POST status = %d, handled = %v", rec.Code, uc.handled)
+	}
+
+	if location := rec.Header().Get(echo.HeaderLocation); !strings.Contains(location, "/tickets/5?chat_action=success") {
+		t.Fatalf("redirect = %s", location)
+	}
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type DeadLetterHandler struct {
+	deadLetterUsecase model.IDeadLetterUsecase
+}
+
+func NewDeadLetterHandler(e *echo.Echo, deadLetterUsecase model.IDeadLetterUsecase) {
+	handler := &DeadLetterHandler{
+		deadLetterUsecase: deadLetterUsecase,
+	}
+
+	group := e.Group("/v1/dead-letters", AuthMiddleware, RoleMiddleware("ADMINISTRATOR"))
+
+	group.GET("", handler.Queues)
+	group.GET("/:queue", handler.FindAll)
+	group.POST("/:queue/replay", handler.Replay)
+}
+
+func (h *DeadLetterHandler) Queues(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+		"data":    h.deadLetterUsecase.Queues(),
+	})
+}
+
+func (h *DeadLetterHandler) FindAll(c echo.Context) error {
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+
+	messages, err := h.deadLetterUsecase.FindAll(c.Request().Context(), c.Param("queue"), limit)
+	if errors.Is(err, model.ErrUnknownQueue) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+		"data":    messages,
+	})
+}
+
+func (h *DeadLetterHandler) Replay(c echo.Context) error {
+	var body model.ReplayDeadLetterInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	replayed, err := h.deadLetterUsecase.Replay(c.Request().Context(), c.Param("queue"), body)
+	if errors.Is(err, model.ErrUnknownQueue) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "dead letters replayed",
+		"data": map[string]int{
+			"replayed": replayed,
+		},
+	})
+}
@@ -0,0 +1,156 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type ExportJobHandler struct {
+	exportJobUsecase model.IExportJobUsecase
+}
+
+func NewExportJobHandler(e *echo.Echo, exportJobUsecase model.IExportJobUsecase) {
+	handler := &ExportJobHandler{
+		exportJobUsecase: exportJobUsecase,
+	}
+
+	group := e.Group("/v1/exports", AuthMiddleware)
+
+	group.POST("", handler.Create)
+	group.GET("", handler.FindAll)
+	group.GET("/:id", handler.FindByID)
+	group.GET("/:id/download", handler.Download)
+	group.DELETE("/:id", handler.Delete)
+}
+
+func (h *ExportJobHandler) Create(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	var body model.CreateExportJobInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	job, err := h.exportJobUsecase.Create(c.Request().Context(), claim.UserID, claim.Role, body)
+	if errors.Is(err, model.ErrExportJobLimit) {
+		return echo.NewHTTPError(http.StatusTooManyRequests, err.Error())
+	}
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"message": "export queued",
+		"data":    job,
+	})
+}
+
+func (h *ExportJobHandler) FindAll(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page == 0 {
+		page = 1
+	}
+
+	limit := 10
+
+	jobs, total, err := h.exportJobUsecase.FindByUserID(c.Request().Context(), claim.UserID, page, limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	totalPage := int((total + int64(limit) - 1) / int64(limit))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":       jobs,
+		"page":       page,
+		"total_data": total,
+		"total_page": totalPage,
+	})
+}
+
+func (h *ExportJobHandler) FindByID(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	job, err := h.exportJobUsecase.FindByID(c.Request().Context(), id, claim.UserID)
+	if err != nil {
+		return exportJobError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+		"data":    job,
+	})
+}
+
+func (h *ExportJobHandler) Download(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	job, reader, err := h.exportJobUsecase.Download(c.Request().Context(), id, claim.UserID)
+	if err != nil {
+		return exportJobError(err)
+	}
+	defer reader.Close()
+
+	c.Response().Header().Set(
+		echo.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="%s"`, *job.Filename),
+	)
+
+	return c.Stream(http.StatusOK, job.ContentType, reader)
+}
+
+func (h *ExportJobHandler) Delete(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	if err := h.exportJobUsecase.Delete(c.Request().Context(), id, claim.UserID); err != nil {
+		return exportJobError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "export deleted successfully",
+	})
+}
+
+func exportJobError(err error) error {
+	switch {
+	case errors.Is(err, model.ErrExportJobNotFound),
+		errors.Is(err, model.ErrObjectNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+
+	case errors.Is(err, model.ErrExportJobNotReady):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+
+	case errors.Is(err, model.ErrExportJobExpired):
+		return echo.NewHTTPError(http.StatusGone, err.Error())
+	}
+
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/storage"
+)
+
+type FileHandler struct {
+	store model.IStorage
+}
+
+// NewFileHandler serves stored files behind signed links, so it is not
+// behind AuthMiddleware: the signature is the authorization.
+func NewFileHandler(e *echo.Echo, store model.IStorage) {
+	handler := &FileHandler{
+		store: store,
+	}
+
+	e.GET(storage.FilesPath+"*", handler.Download)
+}
+
+func (h *FileHandler) Download(c echo.Context) error {
+	key, err := url.PathUnescape(c.Param("*"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid file key")
+	}
+
+	if err := storage.VerifyURL(key, c.QueryParam("expires"), c.QueryParam("signature")); err != nil {
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	}
+
+	ctx := c.Request().Context()
+
+	signed, err := h.store.SignedURL(ctx, key, 5*time.Minute)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if signed != "" {
+		return c.Redirect(http.StatusFound, signed)
+	}
+
+	reader, object, err := h.store.Get(ctx, key)
+	if errors.Is(err, model.ErrObjectNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	defer reader.Close()
+
+	// Only types a browser cannot run script from are shown inline; the
+	// rest, HTML and SVG included, are downloaded.
+	disposition := "attachment"
+	if inlineContentTypes[mediaType(object.ContentType)] {
+		disposition = "inline"
+	}
+
+	c.Response().Header().Set(
+		echo.HeaderContentDisposition,
+		fmt.Sprintf(`%s; filename="%s"`, disposition, path.Base(key)),
+	)
+	c.Response().Header().Set(echo.HeaderXContentTypeOptions, "nosniff")
+	c.Response().Header().Set("Cache-Control", "private, max-age=300")
+
+	return c.Stream(http.StatusOK, object.ContentType, reader)
+}
+
+var inlineContentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+func mediaType(contentType string) string {
+	value, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+
+	return value
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
 
@@ -15,13 +16,35 @@ import (
 
 var userUC model.IUserUsecase
 
+var serviceAccountUC model.IServiceAccountUsecase
+
+// apiKeyScopeResources maps the route groups service accounts may reach to
+// the resource part of their scopes; anything else is closed to API keys.
+var apiKeyScopeResources = map[string]string{
+	"/v1/tickets":   "tickets",
+	"/v1/projects":  "master",
+	"/v1/locations": "master",
+	"/v1/parts":     "master",
+	"/v1/asset-id":  "master",
+	"/v1/causes":    "master",
+	"/v1/solutions": "master",
+}
+
 func InitAuthMiddleware(uc model.IUserUsecase) {
 	userUC = uc
 }
 
+func InitAPIKeyAuth(uc model.IServiceAccountUsecase) {
+	serviceAccountUC = uc
+}
+
 func AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 
+		if apiKey := c.Request().Header.Get("X-API-Key"); apiKey != "" {
+			return authenticateAPIKey(c, next, apiKey)
+		}
+
 		authHeader := c.Request().Header.Get(echo.HeaderAuthorization)
 
 		if authHeader == "" {
@@ -35,6 +58,10 @@ func AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 
 		accessToken := splitAuth[1]
 
+		if strings.HasPrefix(accessToken, model.APIKeyPrefix) {
+			return authenticateAPIKey(c, next, accessToken)
+		}
+
 		var claim model.CustomClaims
 		err := helper.DecodeToken(accessToken, &claim)
 
@@ -56,6 +83,18 @@ func AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 			return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
 		}
 
+		if helper.IsSessionRevoked(c.Request().Context(), &claim) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "session revoked")
+		}
+
+		// Users whose role enforces 2FA may only reach the enrolment
+		// endpoints until they have finished setting it up.
+		if claim.TwoFactorSetupRequired &&
+			!strings.HasPrefix(c.Path(), "/v1/users/2fa/") &&
+			c.Path() != "/v1/users/me" {
+			return echo.NewHTTPError(http.StatusForbidden, "two factor authentication setup required")
+		}
+
 		ctx := context.WithValue(
 			c.Request().Context(),
 			model.BearerAuthKey,
@@ -67,3 +106,94 @@ func AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 		return next(c)
 	}
 }
+
+func authenticateAPIKey(c echo.Context, next echo.HandlerFunc, rawKey string) error {
+	if serviceAccountUC == nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "api keys are not supported")
+	}
+
+	claim, err := serviceAccountUC.Authenticate(c.Request().Context(), rawKey)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	}
+
+	scope := requiredScope(c)
+	if scope == "" || !hasScope(claim.Scopes, scope) {
+		return echo.NewHTTPError(http.StatusForbidden, "api key is not allowed to access this resource")
+	}
+
+	ctx := context.WithValue(
+		c.Request().Context(),
+		model.BearerAuthKey,
+		claim,
+	)
+
+	c.SetRequest(c.Request().WithContext(ctx))
+
+	return next(c)
+}
+
+func requiredScope(c echo.Context) string {
+	for prefix, resource := range apiKeyScopeResources {
+		if c.Path() != prefix && !strings.HasPrefix(c.Path(), prefix+"/") {
+			continue
+		}
+
+		if c.Request().Method == http.MethodGet {
+			return resource + ":read"
+		}
+
+		return resource + ":write"
+	}
+
+	return ""
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+func RoleMiddleware(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claim := helper.GetUserFromContext(c.Request().Context())
+			if claim == nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, "unauthorized")
+			}
+
+			for _, role := range roles {
+				if claim.Role == role {
+					return next(c)
+				}
+			}
+
+			return echo.NewHTTPError(http.StatusForbidden, "forbidden")
+		}
+	}
+}
+
+// UploadBodyLimit caps requests that carry attachments. Multipart parsing
+// buffers the whole body before the usecase checks each file, so the limit
+// has to apply while reading: the maximum number of files at the largest
+// allowed size, plus room for signatures and other form fields.
+func UploadBodyLimit(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		fileSize := max(config.AttachmentMaxFileSize(), config.AttachmentMaxVideoSize())
+		limit := int64(config.AttachmentMaxFiles())*fileSize + 2*config.AttachmentMaxSignatureSize() + 1<<20
+
+		req := c.Request()
+		if req.ContentLength > limit {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, model.ErrAttachmentTooLarge.Error())
+		}
+
+		req.Body = http.MaxBytesReader(c.Response(), req.Body, limit)
+
+		return next(c)
+	}
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type stubServiceAccountUsecase struct {
+	model.IServiceAccountUsecase
+	scopes        map[string][]string
+	authenticated []string
+}
+
+func (u *stubServiceAccountUsecase) Authenticate(ctx context.Context, rawKey string) (*model.CustomClaims, error) {
+	u.authenticated = append(u.authenticated, rawKey)
+
+	scopes, ok := u.scopes[rawKey]
+	if !ok {
+		return nil, errors.New("api key is invalid or expired")
+	}
+
+	return &model.CustomClaims{UserID: 10, Scopes: scopes}, nil
+}
+
+func TestAuthMiddlewareAPIKeys(t *testing.T) {
+	uc := &stubServiceAccountUsecase{scopes: map[string][]string{
+		"hdk_reader": {model.ScopeTicketsRead},
+		"hdk_writer": {model.ScopeTicketsRead, model.ScopeTicketsWrite},
+		"hdk_master": {model.ScopeMasterRead},
+	}}
+
+	InitAPIKeyAuth(uc)
+	t.Cleanup(func() { InitAPIKeyAuth(nil) })
+
+	e := echo.New()
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	e.GET("/v1/tickets", ok, AuthMiddleware)
+	e.POST("/v1/tickets", ok, AuthMiddleware)
+	e.GET("/v1/tickets/:id", ok, AuthMiddleware)
+	e.GET("/v1/projects", ok, AuthMiddleware)
+	e.POST("/v1/projects", ok, AuthMiddleware)
+	e.GET("/v1/users", ok, AuthMiddleware)
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		header    string
+		value     string
+		want      int
+		wantCalls int
+	}{
+		{"X-API-Key read", http.MethodGet, "/v1/tickets", "X-API-Key", "hdk_reader", http.StatusOK, 1},
+		{"Bearer api key read", http.MethodGet, "/v1/tickets/7", echo.HeaderAuthorization, "Bearer hdk_reader", http.StatusOK, 1},
+		{"read scope cannot write", http.MethodPost, "/v1/tickets", "X-API-Key", "hdk_reader", http.StatusForbidden, 1},
+		{"Bearer api key write", http.MethodPost, "/v1/tickets", echo.HeaderAuthorization, "Bearer hdk_writer", http.StatusOK, 1},
+		{"ticket scope cannot read master data", http.MethodGet, "/v1/projects", "X-API-Key", "hdk_writer", http.StatusForbidden, 1},
+		{"master read", http.MethodGet, "/v1/projects", "X-API-Key", "hdk_master", http.StatusOK, 1},
+		{"master read cannot write", http.MethodPost, "/v1/projects", "X-API-Key", "hdk_master", http.StatusForbidden, 1},
+		{"route closed to api keys", http.MethodGet, "/v1/users", "X-API-Key", "hdk_writer", http.StatusForbidden, 1},
+		{"unknown X-API-Key", http.MethodGet, "/v1/tickets", "X-API-Key", "hdk_unknown", http.StatusUnauthorized, 1},
+		{"unknown Bearer api key", http.MethodGet, "/v1/tickets", echo.HeaderAuthorization, "Bearer hdk_unknown", http.StatusUnauthorized, 1},
+		{"Bearer token without prefix is a JWT", http.MethodGet, "/v1/tickets", echo.HeaderAuthorization, "Bearer reader", http.StatusUnauthorized, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc.authenticated = nil
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set(tt.header, tt.value)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+
+			if len(uc.authenticated) != tt.wantCalls {
+				t.Fatalf("api key lookups = %v, want %d", uc.authenticated, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -22,7 +22,13 @@ func NewNotificationHandler(
 
 	group := e.Group("/v1/notifications")
 	group.GET("", handler.FindAllByUserID, AuthMiddleware)
+	group.PUT("/read-all", handler.MarkAllAsRead, AuthMiddleware)
+	group.PUT("/tickets/:ticketId/read", handler.MarkAsReadByTicket, AuthMiddleware)
+	group.POST("/bulk/archive", handler.ArchiveMany, AuthMiddleware)
+	group.POST("/bulk/delete", handler.DeleteMany, AuthMiddleware)
 	group.PUT("/:id/read", handler.MarkAsRead, AuthMiddleware)
+	group.PUT("/:id/archive", handler.Archive, AuthMiddleware)
+	group.PUT("/:id/unarchive", handler.Unarchive, AuthMiddleware)
 	group.GET("/unread/count", handler.CountUnread, AuthMiddleware)
 	group.DELETE("/:id", handler.Delete, AuthMiddleware)
 }
@@ -31,17 +37,47 @@ func (h *NotificationHandler) FindAllByUserID(c echo.Context) error {
 	claim := c.Request().Context().
 		Value(model.BearerAuthKey).(*model.CustomClaims)
 
-	notifications, err := h.notificationUsecase.FindAllByUserID(
+	var filter model.NotificationFilter
+
+	filter.Type = model.NotificationType(c.QueryParam("type"))
+	filter.TicketID, _ = strconv.ParseInt(c.QueryParam("ticket_id"), 10, 64)
+	filter.Archived, _ = strconv.ParseBool(c.QueryParam("archived"))
+
+	switch c.QueryParam("status") {
+	case "read":
+		isRead := true
+		filter.IsRead = &isRead
+	case "unread":
+		isRead := false
+		filter.IsRead = &isRead
+	}
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page == 0 {
+		page = 1
+	}
+
+	limit := 10
+
+	notifications, total, err := h.notificationUsecase.FindAllByUserID(
 		c.Request().Context(),
 		claim.UserID,
+		filter,
+		page,
+		limit,
 	)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
+	totalPage := int((total + int64(limit) - 1) / int64(limit))
+
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": notifications,
+		"data":       notifications,
+		"page":       page,
+		"total_data": total,
+		"total_page": totalPage,
 	})
 }
 
@@ -69,6 +105,148 @@ func (h *NotificationHandler) MarkAsRead(c echo.Context) error {
 	})
 }
 
+func (h *NotificationHandler) MarkAllAsRead(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	result, err := h.notificationUsecase.MarkAllAsRead(
+		c.Request().Context(),
+		claim.UserID,
+	)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "all notifications marked as read",
+		"data":    result,
+	})
+}
+
+func (h *NotificationHandler) MarkAsReadByTicket(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	ticketID, err := strconv.ParseInt(c.Param("ticketId"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid ticket id")
+	}
+
+	result, err := h.notificationUsecase.MarkAsReadByTicket(
+		c.Request().Context(),
+		claim.UserID,
+		ticketID,
+	)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "ticket notifications marked as read",
+		"data":    result,
+	})
+}
+
+func (h *NotificationHandler) ArchiveMany(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	var body model.NotificationBulkInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	result, err := h.notificationUsecase.ArchiveMany(
+		c.Request().Context(),
+		claim.UserID,
+		body,
+	)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "notifications archived",
+		"data":    result,
+	})
+}
+
+func (h *NotificationHandler) DeleteMany(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	var body model.NotificationBulkInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	result, err := h.notificationUsecase.DeleteMany(
+		c.Request().Context(),
+		claim.UserID,
+		body,
+	)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "notifications deleted",
+		"data":    result,
+	})
+}
+
+func (h *NotificationHandler) Archive(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	err = h.notificationUsecase.Archive(
+		c.Request().Context(),
+		id,
+		claim.UserID,
+	)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "notification archived",
+	})
+}
+
+func (h *NotificationHandler) Unarchive(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	err = h.notificationUsecase.Unarchive(
+		c.Request().Context(),
+		id,
+		claim.UserID,
+	)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "notification unarchived",
+	})
+}
+
 func (h *NotificationHandler) CountUnread(c echo.Context) error {
 	claim := c.Request().Context().
 		Value(model.BearerAuthKey).(*model.CustomClaims)
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type NotificationPreferenceHandler struct {
+	preferenceUsecase model.INotificationPreferenceUsecase
+}
+
+func NewNotificationPreferenceHandler(
+	e *echo.Echo,
+	preferenceUsecase model.INotificationPreferenceUsecase,
+) {
+	handler := &NotificationPreferenceHandler{
+		preferenceUsecase: preferenceUsecase,
+	}
+
+	group := e.Group("/v1/notifications/preferences")
+	group.GET("", handler.Get, AuthMiddleware)
+	group.PUT("", handler.Update, AuthMiddleware)
+}
+
+func (h *NotificationPreferenceHandler) Get(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	settings, err := h.preferenceUsecase.Get(c.Request().Context(), claim.UserID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": settings,
+	})
+}
+
+func (h *NotificationPreferenceHandler) Update(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	var body model.UpdateNotificationSettingsInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	settings, err := h.preferenceUsecase.Update(c.Request().Context(), claim.UserID, body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "notification preferences updated successfully",
+		"data":    settings,
+	})
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type PushHandler struct {
+	pushUsecase model.IPushUsecase
+}
+
+func NewPushHandler(e *echo.Echo, pushUsecase model.IPushUsecase) {
+	handler := &PushHandler{
+		pushUsecase: pushUsecase,
+	}
+
+	group := e.Group("/v1/notifications/push")
+	group.GET("/public-key", handler.PublicKey)
+	group.GET("/subscriptions", handler.FindAll, AuthMiddleware)
+	group.POST("/subscriptions", handler.Subscribe, AuthMiddleware)
+	group.DELETE("/subscriptions/:id", handler.Unsubscribe, AuthMiddleware)
+}
+
+func (h *PushHandler) PublicKey(c echo.Context) error {
+	key := h.pushUsecase.PublicKey()
+	if key == "" {
+		return echo.NewHTTPError(http.StatusNotFound, "web push is not configured")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": map[string]string{
+			"public_key": key,
+		},
+	})
+}
+
+func (h *PushHandler) FindAll(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	subscriptions, err := h.pushUsecase.FindAll(c.Request().Context(), claim.UserID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+		"data":    subscriptions,
+	})
+}
+
+func (h *PushHandler) Subscribe(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	var body model.SubscribePushInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	body.UserAgent = c.Request().UserAgent()
+
+	subscription, err := h.pushUsecase.Subscribe(c.Request().Context(), claim.UserID, body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": "push subscription registered successfully",
+		"data":    subscription,
+	})
+}
+
+func (h *PushHandler) Unsubscribe(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	if err := h.pushUsecase.Unsubscribe(c.Request().Context(), id, claim.UserID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "push subscription removed successfully",
+	})
+}
@@ -0,0 +1,240 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type ReportScheduleHandler struct {
+	reportScheduleUsecase model.IReportScheduleUsecase
+}
+
+func NewReportScheduleHandler(e *echo.Echo, reportScheduleUsecase model.IReportScheduleUsecase) {
+	handler := &ReportScheduleHandler{
+		reportScheduleUsecase: reportScheduleUsecase,
+	}
+
+	group := e.Group("/v1/report-schedules", AuthMiddleware, RoleMiddleware(config.ReportScheduleRoles()...))
+
+	group.POST("", handler.Create)
+	group.GET("", handler.FindAll)
+	group.GET("/:id", handler.FindByID)
+	group.PUT("/:id", handler.Update)
+	group.DELETE("/:id", handler.Delete)
+	group.POST("/:id/run", handler.RunNow)
+	group.GET("/:id/runs", handler.FindRuns)
+	group.GET("/runs/:runId/download", handler.DownloadRun)
+}
+
+func (h *ReportScheduleHandler) Create(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	var body model.ReportScheduleInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	schedule, err := h.reportScheduleUsecase.Create(c.Request().Context(), claim.UserID, claim.Role, body)
+	if err != nil {
+		return reportScheduleError(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": "report schedule created successfully",
+		"data":    schedule,
+	})
+}
+
+func (h *ReportScheduleHandler) FindAll(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page == 0 {
+		page = 1
+	}
+
+	limit := 10
+
+	schedules, total, err := h.reportScheduleUsecase.FindByUserID(c.Request().Context(), claim.UserID, page, limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	totalPage := int((total + int64(limit) - 1) / int64(limit))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":       schedules,
+		"page":       page,
+		"total_data": total,
+		"total_page": totalPage,
+	})
+}
+
+func (h *ReportScheduleHandler) FindByID(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	schedule, err := h.reportScheduleUsecase.FindByID(c.Request().Context(), id, claim.UserID)
+	if err != nil {
+		return reportScheduleError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+		"data":    schedule,
+	})
+}
+
+func (h *ReportScheduleHandler) Update(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	var body model.ReportScheduleInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	schedule, err := h.reportScheduleUsecase.Update(c.Request().Context(), id, claim.UserID, claim.Role, body)
+	if err != nil {
+		return reportScheduleError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "report schedule updated successfully",
+		"data":    schedule,
+	})
+}
+
+func (h *ReportScheduleHandler) Delete(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	if err := h.reportScheduleUsecase.Delete(c.Request().Context(), id, claim.UserID); err != nil {
+		return reportScheduleError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "report schedule deleted successfully",
+	})
+}
+
+func (h *ReportScheduleHandler) RunNow(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	schedule, err := h.reportScheduleUsecase.RunNow(c.Request().Context(), id, claim.UserID)
+	if err != nil {
+		return reportScheduleError(err)
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"message": "report queued",
+		"data":    schedule,
+	})
+}
+
+func (h *ReportScheduleHandler) FindRuns(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page == 0 {
+		page = 1
+	}
+
+	limit := 10
+
+	runs, total, err := h.reportScheduleUsecase.FindRuns(c.Request().Context(), id, claim.UserID, page, limit)
+	if err != nil {
+		return reportScheduleError(err)
+	}
+
+	totalPage := int((total + int64(limit) - 1) / int64(limit))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":       runs,
+		"page":       page,
+		"total_data": total,
+		"total_page": totalPage,
+	})
+}
+
+func (h *ReportScheduleHandler) DownloadRun(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	runID, err := strconv.ParseInt(c.Param("runId"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid run id")
+	}
+
+	run, reader, err := h.reportScheduleUsecase.DownloadRun(c.Request().Context(), runID, claim.UserID)
+	if err != nil {
+		return reportScheduleError(err)
+	}
+	defer reader.Close()
+
+	c.Response().Header().Set(
+		echo.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="%s"`, *run.Filename),
+	)
+
+	return c.Stream(http.StatusOK, run.ContentType, reader)
+}
+
+func reportScheduleError(err error) error {
+	switch {
+	case errors.Is(err, model.ErrReportScheduleNotFound),
+		errors.Is(err, model.ErrReportRunNotFound),
+		errors.Is(err, model.ErrObjectNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+
+	case errors.Is(err, model.ErrReportWebhookForbidden):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+
+	case errors.Is(err, model.ErrReportScheduleRunning),
+		errors.Is(err, model.ErrReportRunNotReady):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+
+	case errors.Is(err, model.ErrReportRunExpired):
+		return echo.NewHTTPError(http.StatusGone, err.Error())
+
+	case errors.Is(err, model.ErrReportScheduleLimit):
+		return echo.NewHTTPError(http.StatusTooManyRequests, err.Error())
+	}
+
+	return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+}
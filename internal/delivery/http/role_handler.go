@@ -24,6 +24,7 @@ func NewRoleHandler(e *echo.Echo, roleUsecase model.IRoleUsecase) {
 	group.GET("/:id", handler.FindByID, AuthMiddleware)
 	group.PUT("/update/:id", handler.Update, AuthMiddleware)
 	group.DELETE("/delete/:id", handler.Delete, AuthMiddleware)
+	group.PUT("/two-factor/:id", handler.UpdateTwoFactorRequirement, AuthMiddleware, RoleMiddleware("ADMINISTRATOR"))
 }
 
 func (h *RoleHandler) Create(c echo.Context) error {
@@ -101,3 +102,23 @@ func (h *RoleHandler) Delete(c echo.Context) error {
 		"message": "role deleted successfully",
 	})
 }
+
+func (h *RoleHandler) UpdateTwoFactorRequirement(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	var body model.UpdateRoleTwoFactorInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := h.roleUsecase.UpdateTwoFactorRequirement(c.Request().Context(), id, body); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "role two factor requirement updated successfully",
+	})
+}
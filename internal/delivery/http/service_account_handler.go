@@ -0,0 +1,175 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type ServiceAccountHandler struct {
+	serviceAccountUsecase model.IServiceAccountUsecase
+}
+
+func NewServiceAccountHandler(e *echo.Echo, serviceAccountUsecase model.IServiceAccountUsecase) {
+	handler := &ServiceAccountHandler{
+		serviceAccountUsecase: serviceAccountUsecase,
+	}
+
+	group := e.Group("/v1/service-accounts", AuthMiddleware, RoleMiddleware("ADMINISTRATOR"))
+
+	group.GET("", handler.FindAll)
+	group.POST("", handler.Create)
+	group.DELETE("/:id", handler.Delete)
+	group.GET("/:id/keys", handler.FindKeys)
+	group.POST("/:id/keys", handler.CreateKey)
+	group.POST("/keys/:keyId/rotate", handler.RotateKey)
+	group.DELETE("/keys/:keyId", handler.RevokeKey)
+}
+
+func (h *ServiceAccountHandler) FindAll(c echo.Context) error {
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page == 0 {
+		page = 1
+	}
+
+	limit := 10
+
+	accounts, total, err := h.serviceAccountUsecase.FindAll(c.Request().Context(), page, limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	totalPage := int((total + int64(limit) - 1) / int64(limit))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":       accounts,
+		"page":       page,
+		"total_data": total,
+		"total_page": totalPage,
+	})
+}
+
+func (h *ServiceAccountHandler) Create(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	var body model.CreateServiceAccountInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	account, err := h.serviceAccountUsecase.Create(c.Request().Context(), claim.UserID, body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": "service account created successfully",
+		"data":    account,
+	})
+}
+
+func (h *ServiceAccountHandler) Delete(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	if err := h.serviceAccountUsecase.Delete(c.Request().Context(), claim.UserID, id); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "service account deleted successfully",
+	})
+}
+
+func (h *ServiceAccountHandler) FindKeys(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	keys, err := h.serviceAccountUsecase.FindKeys(c.Request().Context(), id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+		"data":    keys,
+	})
+}
+
+func (h *ServiceAccountHandler) CreateKey(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	var body model.CreateAPIKeyInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	key, err := h.serviceAccountUsecase.CreateKey(c.Request().Context(), claim.UserID, id, body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": "store this key now, it will not be shown again",
+		"data":    key,
+	})
+}
+
+func (h *ServiceAccountHandler) RotateKey(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	keyID, err := strconv.ParseInt(c.Param("keyId"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid key id")
+	}
+
+	var body model.RotateAPIKeyInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	key, err := h.serviceAccountUsecase.RotateKey(c.Request().Context(), claim.UserID, keyID, body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": "store this key now, it will not be shown again",
+		"data":    key,
+	})
+}
+
+func (h *ServiceAccountHandler) RevokeKey(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	keyID, err := strconv.ParseInt(c.Param("keyId"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid key id")
+	}
+
+	if err := h.serviceAccountUsecase.RevokeKey(c.Request().Context(), claim.UserID, keyID); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "api key revoked successfully",
+	})
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type SSOHandler struct {
+	ssoUsecase model.ISSOUsecase
+}
+
+func NewSSOHandler(e *echo.Echo, ssoUsecase model.ISSOUsecase) {
+	handler := &SSOHandler{
+		ssoUsecase: ssoUsecase,
+	}
+
+	group := e.Group("/v1/auth")
+
+	group.GET("/providers", handler.Providers)
+	group.GET("/:provider/login", handler.Login)
+	group.GET("/:provider/callback", handler.Callback)
+}
+
+func (h *SSOHandler) Providers(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+		"data":    h.ssoUsecase.Providers(),
+	})
+}
+
+func (h *SSOHandler) Login(c echo.Context) error {
+	redirectURL, err := h.ssoUsecase.StartLogin(c.Request().Context(), c.Param("provider"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.Redirect(http.StatusFound, redirectURL)
+}
+
+// Callback sends the browser back to the frontend with the outcome in the URL
+// fragment so the token never reaches server logs of the frontend host.
+func (h *SSOHandler) Callback(c echo.Context) error {
+	fragment := url.Values{}
+
+	if idpError := c.QueryParam("error"); idpError != "" {
+		fragment.Set("error", idpError)
+		return c.Redirect(http.StatusFound, ssoCallbackURL(fragment))
+	}
+
+	res, err := h.ssoUsecase.CompleteLogin(
+		c.Request().Context(),
+		c.Param("provider"),
+		c.QueryParam("state"),
+		c.QueryParam("code"),
+	)
+	if err != nil {
+		fragment.Set("error", err.Error())
+		return c.Redirect(http.StatusFound, ssoCallbackURL(fragment))
+	}
+
+	if res.TwoFactorRequired {
+		fragment.Set("challenge_token", res.ChallengeToken)
+	} else {
+		fragment.Set("token", res.Token)
+	}
+
+	return c.Redirect(http.StatusFound, ssoCallbackURL(fragment))
+}
+
+func ssoCallbackURL(fragment url.Values) string {
+	return config.FrontendURL() + "/sso/callback#" + fragment.Encode()
+}
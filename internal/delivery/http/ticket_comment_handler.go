@@ -20,7 +20,7 @@ func NewTicketCommentHandler(e *echo.Echo, u model.ITicketCommentUsecase, ticket
 	}
 
 	group := e.Group("/v1/tickets")
-	group.POST("/:id/comments", handler.Create, AuthMiddleware)
+	group.POST("/:id/comments", handler.Create, AuthMiddleware, UploadBodyLimit)
 	group.GET("/:id/comments", handler.GetByTicketID, AuthMiddleware)
 	group.PUT("/:id/comments/read", handler.MarkAsRead, AuthMiddleware)
 }
@@ -40,14 +40,20 @@ func (h *TicketCommentHandler) Create(c echo.Context) error {
 	}
 
 	var req struct {
-		Message string `json:"message"`
+		Message string `json:"message" form:"message"`
 	}
 
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	if req.Message == "" {
+	files, closeFiles, err := formAttachments(c)
+	if err != nil {
+		return err
+	}
+	defer closeFiles()
+
+	if req.Message == "" && len(files) == 0 {
 		return echo.NewHTTPError(http.StatusBadRequest, "message is required")
 	}
 
@@ -66,9 +72,9 @@ func (h *TicketCommentHandler) Create(c echo.Context) error {
 		Message:  req.Message,
 	}
 
-	result, err := h.usecase.Create(ctx, comment)
+	result, err := h.usecase.Create(ctx, comment, files)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return attachmentError(err)
 	}
 
 	return c.JSON(http.StatusCreated, result)
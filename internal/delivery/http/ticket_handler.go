@@ -1,34 +1,37 @@
 package http
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/labstack/echo/v4"
-	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/storage"
 )
 
 type TicketHandler struct {
-	ticketUsecase model.ITicketUsecase
+	ticketUsecase       model.ITicketUsecase
+	ticketExportUsecase model.ITicketExportUsecase
 }
 
-func NewTicketHandler(e *echo.Echo, ticketUsecase model.ITicketUsecase) {
+func NewTicketHandler(e *echo.Echo, ticketUsecase model.ITicketUsecase, ticketExportUsecase model.ITicketExportUsecase) {
 	handler := &TicketHandler{
-		ticketUsecase: ticketUsecase,
+		ticketUsecase:       ticketUsecase,
+		ticketExportUsecase: ticketExportUsecase,
 	}
 
 	group := e.Group("/v1/tickets")
 
-	group.POST("/create", handler.Create, AuthMiddleware)
+	group.POST("/create", handler.Create, AuthMiddleware, UploadBodyLimit)
 	group.GET("", handler.FindAll, AuthMiddleware)
 	group.GET("/:id", handler.FindByID, AuthMiddleware)
 	group.PUT("/update-status/:id", handler.UpdateStatus, AuthMiddleware)
 	group.DELETE("/delete/:id", handler.Delete, AuthMiddleware)
 	group.GET("/export", handler.Export, AuthMiddleware)
+	group.GET("/:id/report.pdf", handler.ServiceReport, AuthMiddleware)
 }
 
 func (h *TicketHandler) Create(c echo.Context) error {
@@ -56,36 +59,17 @@ func (h *TicketHandler) Create(c echo.Context) error {
 		Description: description,
 	}
 
-	var attachmentURL *string
-
-	fileHeader, err := c.FormFile("attachment")
-	if err == nil {
-
-		if fileHeader.Size > 2*1024*1024 {
-			return echo.NewHTTPError(http.StatusBadRequest, "file max 2MB")
-		}
-
-		file, err := fileHeader.Open()
-		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, "failed to open file")
-		}
-		defer file.Close()
-
-		folder := fmt.Sprintf("tickets/project_%d", projectID)
-		url, err := helper.UploadImage(file, folder)
-		if err != nil {
-			log.Println("Failed to upload image:", err)
-			return echo.NewHTTPError(http.StatusInternalServerError, "upload failed")
-		}
-
-		attachmentURL = &url
+	files, closeFiles, err := formAttachments(c)
+	if err != nil {
+		return err
 	}
+	defer closeFiles()
 
 	ticket, assigned, err := h.ticketUsecase.Create(
 		c.Request().Context(),
 		claim.UserID,
 		input,
-		attachmentURL,
+		files,
 	)
 
 	if err != nil {
@@ -156,6 +140,8 @@ func (h *TicketHandler) FindAll(c echo.Context) error {
         return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
     }
 
+    resolveTicketAttachments(tickets)
+
     totalPage := int((total + int64(limit) - 1) / int64(limit))
 
     return c.JSON(http.StatusOK, map[string]interface{}{
@@ -252,60 +238,90 @@ func (h *TicketHandler) Export(c echo.Context) error {
     startDate := c.QueryParam("start_date")
     endDate := c.QueryParam("end_date")
 
-    var assignedToID *int64
-
-    if staffID != 0 {
-        assignedToID = &staffID
-    }
-
-    filter := model.Ticket{
+    filter := model.ExportFilter{
         TicketCode:   ticketCode,
         ProjectID:    projectID,
-        AssignedToID: assignedToID,
+        AssignedToID: staffID,
         ReporterID:   reporterID,
-        Priority:     model.TicketPriority(priority),
-        Status:       model.TicketStatus(status),
+        Priority:     priority,
+        Status:       status,
+        Search:       search,
+        StartDate:    startDate,
+        EndDate:      endDate,
     }
 
-    tickets, _, err := h.ticketUsecase.FindAll(
+    var file bytes.Buffer
+
+    result, err := h.ticketExportUsecase.Export(
         c.Request().Context(),
-        filter,
-        search,
-        startDate,
-        endDate,
-        1,
-        10000,
-        claims.Role,
         claims.UserID,
+        claims.Role,
+        c.QueryParam("format"),
+        filter,
+        &file,
+        nil,
     )
 
-    if err != nil {
-        return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+    if errors.Is(err, model.ErrExportFormatUnsupported) {
+        return echo.NewHTTPError(http.StatusBadRequest, err.Error())
     }
 
-    file, err := helper.GenerateExcelTickets(tickets)
     if err != nil {
         return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
     }
 
-    fileName := fmt.Sprintf(
-		"ticket_export_%s.xlsx",
-		time.Now().Format("2006-01-02_150405"),
-	)
-
     c.Response().Header().Set(
 		echo.HeaderContentDisposition,
-		fmt.Sprintf(`attachment; filename="%s"`, fileName),
+		fmt.Sprintf(`attachment; filename="%s"`, result.Filename),
 	)
 
-    c.Response().Header().Set(
-        echo.HeaderContentType,
-        "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
-    )
-
     return c.Blob(
         http.StatusOK,
-        "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+        result.ContentType,
         file.Bytes(),
     )
 }
+
+func resolveTicketAttachments(tickets []*model.TicketResponse) {
+	for _, ticket := range tickets {
+		ticket.Attachment = storage.URLPtr(ticket.Attachment)
+		ticket.SolutionAttachment = storage.URLPtr(ticket.SolutionAttachment)
+		ticket.AttachmentThumbnail = storage.URLPtr(ticket.AttachmentThumbnail)
+		ticket.SolutionThumbnail = storage.URLPtr(ticket.SolutionThumbnail)
+	}
+}
+
+func (h *TicketHandler) ServiceReport(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	var file bytes.Buffer
+
+	result, err := h.ticketExportUsecase.ServiceReport(c.Request().Context(), id, claim.UserID, claim.Role, &file)
+
+	switch {
+	case errors.Is(err, model.ErrServiceReportNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+
+	case errors.Is(err, model.ErrServiceReportForbidden):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+
+	case errors.Is(err, model.ErrServiceReportUnresolved):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+
+	case err != nil:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	c.Response().Header().Set(
+		echo.HeaderContentDisposition,
+		fmt.Sprintf(`inline; filename="%s"`, result.Filename),
+	)
+
+	return c.Blob(http.StatusOK, result.ContentType, file.Bytes())
+}
@@ -1,13 +1,15 @@
 package http
 
 import (
-	"fmt"
+	"encoding/base64"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
-	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
 )
 
@@ -22,7 +24,7 @@ func NewTicketResolutionHandler(e *echo.Echo, u model.ITicketResolutionUsecase)
 
 	group := e.Group("/v1/tickets", AuthMiddleware)
 
-	group.POST("/:id/resolution", handler.Create)
+	group.POST("/:id/resolution", handler.Create, UploadBodyLimit)
 	group.GET("/:id/resolution", handler.GetByTicketID)
 	group.PUT("/:id/status", handler.UpdateStatus)
 }
@@ -53,24 +55,24 @@ func (h *TicketResolutionHandler) Create(c echo.Context) error {
 		completionTime, _ = time.Parse("2006-01-02T15:04", completionTimeStr)
 	}
 
-	fileHeader, err := c.FormFile("attachment")
-	var attachmentURL string
-
-	if err == nil && fileHeader != nil {
-		file, err := fileHeader.Open()
-		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, "failed open file")
-		}
-		defer file.Close()
+	files, closeFiles, err := formAttachments(c)
+	if err != nil {
+		return err
+	}
+	defer closeFiles()
 
-		folder := fmt.Sprintf("tickets/resolution_%d", ticketID)
+	technicianSignature, err := formSignature(c, "technician_signature")
+	if err != nil {
+		return attachmentError(err)
+	}
 
-		url, err := helper.UploadImage(file, folder)
-		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, "upload failed")
-		}
+	reporterSignature, err := formSignature(c, "reporter_signature")
+	if err != nil {
+		return attachmentError(err)
+	}
 
-		attachmentURL = url
+	if reporterSignature != nil {
+		reporterSignature.SignerName = c.FormValue("reporter_signer_name")
 	}
 
 	status := model.TicketStatus(c.FormValue("status"))
@@ -81,8 +83,11 @@ func (h *TicketResolutionHandler) Create(c echo.Context) error {
 		SolutionID:      solutionID,
 		ResolutionNotes: notes,
 		CompletionTime:  completionTime,
-		AttachmentURL:   attachmentURL,
 		Status:          status,
+		Attachments:     files,
+
+		TechnicianSignature: technicianSignature,
+		ReporterSignature:   reporterSignature,
 	}
 
 	resolution, err := h.usecase.Create(
@@ -92,7 +97,7 @@ func (h *TicketResolutionHandler) Create(c echo.Context) error {
 	)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return attachmentError(err)
 	}
 
 	return c.JSON(http.StatusCreated, resolution)
@@ -150,3 +155,49 @@ func (h *TicketResolutionHandler) UpdateStatus(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, "status updated")
 }
+
+// formSignature reads a signature sent either as an uploaded file or as
+// the data URL a canvas produces ("data:image/png;base64,...").
+func formSignature(c echo.Context, field string) (*model.SignatureInput, error) {
+	maxSize := config.AttachmentMaxSignatureSize()
+
+	if header, err := c.FormFile(field); err == nil {
+		if header.Size > maxSize {
+			return nil, model.ErrSignatureTooLarge
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+		if err != nil {
+			return nil, err
+		}
+
+		return &model.SignatureInput{Image: data}, nil
+	}
+
+	value := c.FormValue(field)
+	if value == "" {
+		return nil, nil
+	}
+
+	meta, encoded, ok := strings.Cut(value, ",")
+	if !ok || !strings.HasPrefix(meta, "data:") || !strings.HasSuffix(meta, ";base64") {
+		return nil, model.ErrSignatureInvalid
+	}
+
+	if int64(base64.StdEncoding.DecodedLen(len(encoded))) > maxSize+2 {
+		return nil, model.ErrSignatureTooLarge
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, model.ErrSignatureInvalid
+	}
+
+	return &model.SignatureInput{Image: data}, nil
+}
@@ -0,0 +1,118 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type TwoFactorHandler struct {
+	twoFactorUsecase model.ITwoFactorUsecase
+}
+
+func NewTwoFactorHandler(e *echo.Echo, twoFactorUsecase model.ITwoFactorUsecase) {
+	handler := &TwoFactorHandler{
+		twoFactorUsecase: twoFactorUsecase,
+	}
+
+	e.POST("/v1/users/login/2fa", handler.CompleteLogin)
+
+	group := e.Group("/v1/users/2fa")
+
+	group.POST("/enroll", handler.Enroll, AuthMiddleware)
+	group.POST("/verify", handler.Verify, AuthMiddleware)
+	group.POST("/disable", handler.Disable, AuthMiddleware)
+	group.DELETE("/reset/:id", handler.Reset, AuthMiddleware, RoleMiddleware("ADMINISTRATOR"))
+}
+
+func (h *TwoFactorHandler) Enroll(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	enrollment, err := h.twoFactorUsecase.Enroll(c.Request().Context(), claim.UserID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "scan the provisioning uri with your authenticator app",
+		"data":    enrollment,
+	})
+}
+
+func (h *TwoFactorHandler) Verify(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	var body model.TwoFactorCodeInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	activation, err := h.twoFactorUsecase.Verify(c.Request().Context(), claim.UserID, body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "two factor authentication enabled",
+		"data":    activation,
+	})
+}
+
+func (h *TwoFactorHandler) Disable(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	var body model.DisableTwoFactorInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := h.twoFactorUsecase.Disable(c.Request().Context(), claim.UserID, body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "two factor authentication disabled",
+	})
+}
+
+func (h *TwoFactorHandler) Reset(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	if err := h.twoFactorUsecase.Reset(c.Request().Context(), claim.UserID, id, c.RealIP()); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "two factor authentication reset successfully",
+	})
+}
+
+func (h *TwoFactorHandler) CompleteLogin(c echo.Context) error {
+	var body model.TwoFactorLoginInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	body.IP = c.RealIP()
+
+	token, err := h.twoFactorUsecase.CompleteLogin(c.Request().Context(), body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "login success",
+		"token":   token,
+	})
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -21,7 +22,10 @@ func NewUserHandler(e *echo.Echo, userUsecase model.IUserUsecase) {
 	group := e.Group("/v1/users")
 
 	group.POST("/login", handler.Login)
-	group.POST("/register", handler.Create)
+	group.POST("/register", handler.Register)
+	group.POST("/invite", handler.Invite, AuthMiddleware, RoleMiddleware("ADMINISTRATOR"))
+	group.POST("/invite/accept", handler.AcceptInvitation)
+	group.POST("/invite/resend/:id", handler.ResendInvitation, AuthMiddleware, RoleMiddleware("ADMINISTRATOR"))
 	group.GET("", handler.FindAll, AuthMiddleware)
 	group.GET("/:id", handler.FindByID, AuthMiddleware)
 	group.PUT("/update/:id", handler.Update, AuthMiddleware)
@@ -33,6 +37,9 @@ func NewUserHandler(e *echo.Echo, userUsecase model.IUserUsecase) {
 	group.PUT("/logout", handler.Logout)
 	group.GET("/profile", handler.Profile, AuthMiddleware)
 	group.PUT("/profile", handler.UpdateProfile, AuthMiddleware)
+	group.POST("/password/forgot", handler.ForgotPassword)
+	group.POST("/password/reset", handler.ResetPassword)
+	group.PUT("/unlock/:id", handler.Unlock, AuthMiddleware, RoleMiddleware("ADMINISTRATOR"))
 }
 
 func (h *UserHandler) Login(c echo.Context) error {
@@ -42,31 +49,113 @@ func (h *UserHandler) Login(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
-	token, err := h.userUsecase.Login(c.Request().Context(), body)
+	body.IP = c.RealIP()
+
+	result, err := h.userUsecase.Login(c.Request().Context(), body)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
 	}
 
+	if result.TwoFactorRequired {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"message":             "two factor authentication required",
+			"two_factor_required": true,
+			"challenge_token":     result.ChallengeToken,
+		})
+	}
+
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"message": "login success",
+		"token":   result.Token,
+	})
+}
+
+func (h *UserHandler) Register(c echo.Context) error {
+	var body model.RegisterInput
+
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	token, err := h.userUsecase.Register(c.Request().Context(), body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": "register success",
 		"token":   token,
 	})
 }
 
-func (h *UserHandler) Create(c echo.Context) error {
+func (h *UserHandler) Invite(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
 	var body model.CreateUserInput
 
 	if err := c.Bind(&body); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
-	token, err := h.userUsecase.Create(c.Request().Context(), body)
+	user, err := h.userUsecase.Invite(c.Request().Context(), claim.UserID, body)
+	if errors.Is(err, model.ErrInvitationNotSent) {
+		return c.JSON(http.StatusBadGateway, map[string]interface{}{
+			"message": "user created but " + err.Error() + ", resend the invitation",
+			"data":    user,
+		})
+	}
+
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
 	return c.JSON(http.StatusCreated, map[string]interface{}{
-		"message": "register success",
+		"message": "invitation sent successfully",
+		"data":    user,
+	})
+}
+
+func (h *UserHandler) ResendInvitation(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	err = h.userUsecase.ResendInvitation(c.Request().Context(), claim.UserID, id)
+	switch {
+	case errors.Is(err, model.ErrInvitationNotSent):
+		return echo.NewHTTPError(http.StatusBadGateway, err.Error())
+	case errors.Is(err, model.ErrInvitationNotPending):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, model.ErrUserNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case err != nil:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "invitation sent successfully",
+	})
+}
+
+func (h *UserHandler) AcceptInvitation(c echo.Context) error {
+	var body model.AcceptInvitationInput
+
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	token, err := h.userUsecase.AcceptInvitation(c.Request().Context(), body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "invitation accepted",
 		"token":   token,
 	})
 }
@@ -308,3 +397,57 @@ func (h *UserHandler) UpdateProfile(c echo.Context) error {
 		"message": "profile updated successfully",
 	})
 }
+
+func (h *UserHandler) ForgotPassword(c echo.Context) error {
+	var body model.ForgotPasswordInput
+
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := h.userUsecase.ForgotPassword(c.Request().Context(), body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "if the email is registered, a reset link has been sent",
+	})
+}
+
+func (h *UserHandler) ResetPassword(c echo.Context) error {
+	var body model.ResetPasswordInput
+
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := h.userUsecase.ResetPassword(c.Request().Context(), body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "password reset successfully",
+	})
+}
+
+func (h *UserHandler) Unlock(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	err = h.userUsecase.Unlock(c.Request().Context(), claim.UserID, id, c.RealIP())
+	switch {
+	case errors.Is(err, model.ErrUserNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case err != nil:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "account unlocked successfully",
+	})
+}
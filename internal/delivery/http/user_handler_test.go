@@ -0,0 +1,63 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type unlockUsecase struct {
+	model.IUserUsecase
+	err error
+}
+
+func (u unlockUsecase) Unlock(ctx context.Context, actorID int64, userID int64, ip string) error {
+	return u.err
+}
+
+func TestUnlockStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"unlocked", nil, http.StatusOK},
+		{"unknown user", model.ErrUserNotFound, http.StatusNotFound},
+		{"database down", errors.New("connection refused"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+
+			req := httptest.NewRequest(http.MethodPut, "/v1/users/unlock/42", nil)
+			req = req.WithContext(context.WithValue(req.Context(), model.BearerAuthKey, &model.CustomClaims{UserID: 1}))
+
+			rec := httptest.NewRecorder()
+
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("42")
+
+			handler := &UserHandler{userUsecase: unlockUsecase{err: tt.err}}
+
+			status := rec.Code
+			if err := handler.Unlock(c); err != nil {
+				var httpErr *echo.HTTPError
+				if !errors.As(err, &httpErr) {
+					t.Fatalf("err = %v, want an HTTP error", err)
+				}
+				status = httpErr.Code
+			}
+
+			if status != tt.status {
+				t.Fatalf("status = %d, want %d", status, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type WebhookHandler struct {
+	webhookUsecase model.IWebhookUsecase
+}
+
+func NewWebhookHandler(e *echo.Echo, webhookUsecase model.IWebhookUsecase) {
+	handler := &WebhookHandler{
+		webhookUsecase: webhookUsecase,
+	}
+
+	group := e.Group("/v1/webhooks", AuthMiddleware, RoleMiddleware("ADMINISTRATOR"))
+
+	group.GET("", handler.FindAll)
+	group.POST("", handler.Create)
+	group.GET("/:id", handler.FindByID)
+	group.PUT("/:id", handler.Update)
+	group.DELETE("/:id", handler.Delete)
+	group.GET("/:id/deliveries", handler.FindDeliveries)
+	group.POST("/deliveries/:deliveryId/replay", handler.Replay)
+}
+
+func (h *WebhookHandler) FindAll(c echo.Context) error {
+	webhooks, err := h.webhookUsecase.FindAll(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+		"data":    webhooks,
+	})
+}
+
+func (h *WebhookHandler) FindByID(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	webhook, err := h.webhookUsecase.FindByID(c.Request().Context(), id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+		"data":    webhook,
+	})
+}
+
+func (h *WebhookHandler) Create(c echo.Context) error {
+	claim := c.Request().Context().
+		Value(model.BearerAuthKey).(*model.CustomClaims)
+
+	var body model.CreateWebhookInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	webhook, err := h.webhookUsecase.Create(c.Request().Context(), claim.UserID, body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": "store this secret now, it will not be shown again",
+		"data":    webhook,
+	})
+}
+
+func (h *WebhookHandler) Update(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	var body model.UpdateWebhookInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	webhook, err := h.webhookUsecase.Update(c.Request().Context(), id, body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "webhook updated successfully",
+		"data":    webhook,
+	})
+}
+
+func (h *WebhookHandler) Delete(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	if err := h.webhookUsecase.Delete(c.Request().Context(), id); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "webhook deleted successfully",
+	})
+}
+
+func (h *WebhookHandler) FindDeliveries(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page == 0 {
+		page = 1
+	}
+
+	limit := 10
+
+	deliveries, total, err := h.webhookUsecase.FindDeliveries(
+		c.Request().Context(),
+		id,
+		c.QueryParam("status"),
+		page,
+		limit,
+	)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	totalPage := int((total + int64(limit) - 1) / int64(limit))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":       deliveries,
+		"page":       page,
+		"total_data": total,
+		"total_page": totalPage,
+	})
+}
+
+func (h *WebhookHandler) Replay(c echo.Context) error {
+	deliveryID, err := strconv.ParseInt(c.Param("deliveryId"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid delivery id")
+	}
+
+	delivery, err := h.webhookUsecase.Replay(c.Request().Context(), deliveryID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": "webhook delivery queued for replay",
+		"data":    delivery,
+	})
+}
@@ -0,0 +1,131 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+)
+
+var csvHeaders = []string{
+	"id",
+	"ticket_code",
+	"project",
+	"location",
+	"part",
+	"asset",
+	"reporter",
+	"assigned_to",
+	"priority",
+	"status",
+	"description",
+	"attachment_url",
+	"solution_attachment_url",
+	"created_at",
+	"due_at",
+	"technician_signed_by",
+	"technician_signed_at",
+	"reporter_signed_by",
+	"reporter_signed_at",
+}
+
+type csvExporter struct{}
+
+func (csvExporter) ContentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+func (csvExporter) Extension() string {
+	return ".csv"
+}
+
+func (csvExporter) NeedsDetails() bool {
+	return false
+}
+
+func (csvExporter) Files(data *Data) []string {
+	return nil
+}
+
+// Write emits one row per ticket with RFC 3339 timestamps, which load into
+// BI tools without a format hint. Cells that a spreadsheet would evaluate
+// as a formula are escaped.
+func (csvExporter) Write(w io.Writer, data *Data) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(csvHeaders); err != nil {
+		return err
+	}
+
+	for _, ticket := range data.Tickets {
+		if ticket == nil {
+			continue
+		}
+
+		record := []string{
+			fmt.Sprint(ticket.ID),
+			ticket.TicketCode,
+			ticket.ProjectName,
+			ticket.LocationName,
+			ticket.PartName,
+			ticket.AssetCode,
+			ticket.ReporterName,
+			ticket.AssignedToName,
+			ticket.Priority,
+			ticket.Status,
+			ticket.Description,
+			stringValue(urlOf(data, ticket.Attachment)),
+			stringValue(urlOf(data, ticket.SolutionAttachment)),
+			ticket.CreatedAt.Format(time.RFC3339),
+			ticket.DueAt.Format(time.RFC3339),
+			stringValue(ticket.TechnicianSignedBy),
+			timeValue(ticket.TechnicianSignedAt),
+			stringValue(ticket.ReporterSignedBy),
+			timeValue(ticket.ReporterSignedAt),
+		}
+
+		for i, cell := range record {
+			record[i] = escapeFormula(cell)
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// escapeFormula prefixes cells starting with a formula trigger with a
+// quote, so text such as a ticket description is shown as typed instead of
+// being run by Excel or LibreOffice.
+func escapeFormula(cell string) string {
+	if cell == "" {
+		return cell
+	}
+
+	switch cell[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + cell
+	}
+
+	return cell
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
+
+func timeValue(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+
+	return value.Format(time.RFC3339)
+}
@@ -0,0 +1,53 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+func TestCSVEscapesFormulas(t *testing.T) {
+	data := &Data{
+		Tickets: []*model.TicketResponse{{
+			ID:           1,
+			TicketCode:   "NUT-20260101-0001",
+			ReporterName: "@SUM(A1:A9)",
+			Description:  `=HYPERLINK("https://evil.example","open")`,
+			ProjectName:  "-2+3",
+			PartName:     "+cmd",
+			LocationName: "Floor 2",
+		}},
+	}
+
+	var out bytes.Buffer
+	if err := (csvExporter{}).Write(&out, data); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	row := map[string]string{}
+	for i, header := range rows[0] {
+		row[header] = rows[1][i]
+	}
+
+	want := map[string]string{
+		"ticket_code": "NUT-20260101-0001",
+		"reporter":    "'@SUM(A1:A9)",
+		"description": `'=HYPERLINK("https://evil.example","open")`,
+		"project":     "'-2+3",
+		"part":        "'+cmd",
+		"location":    "Floor 2",
+	}
+
+	for header, value := range want {
+		if row[header] != value {
+			t.Errorf("%s = %q, want %q", header, row[header], value)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"io"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/imaging"
+)
+
+const (
+	pageMargin   = 15.0
+	lineHeight   = 5.0
+	labelWidth   = 30.0
+	pdfImageSize = 600
+)
+
+var statusFills = map[string][3]int{
+	"OPEN":        {255, 204, 204},
+	"IN_PROGRESS": {255, 230, 153},
+	"RESOLVED":    {198, 239, 206},
+	"CLOSED":      {217, 217, 217},
+	"ONHOLD":      {189, 215, 238},
+}
+
+// document wraps an A4 fpdf document with the layout helpers the ticket
+// report and the service report share.
+type document struct {
+	pdf    *fpdf.Fpdf
+	tr     func(string) string
+	file   helper.FileLoader
+	images map[string]image.Config
+}
+
+func newDocument(title string, generatedAt time.Time, file helper.FileLoader) *document {
+	pdf := fpdf.New("P", "mm", "A4", "")
+
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(true, pageMargin+5)
+	pdf.SetTitle(title, true)
+	pdf.SetCreator("Helpdesk Ticketing", true)
+	pdf.AliasNbPages("")
+
+	doc := &document{
+		pdf:    pdf,
+		tr:     pdf.UnicodeTranslatorFromDescriptor(""),
+		file:   file,
+		images: make(map[string]image.Config),
+	}
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pageMargin)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(128, 128, 128)
+		pdf.CellFormat(0, lineHeight, doc.tr(fmt.Sprintf(
+			"%s - generated %s - page %d of {nb}",
+			title,
+			generatedAt.Format("2006-01-02 15:04"),
+			pdf.PageNo(),
+		)), "", 0, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+
+	return doc
+}
+
+func (d *document) contentWidth() float64 {
+	width, _ := d.pdf.GetPageSize()
+	return width - 2*pageMargin
+}
+
+// ensureSpace starts a new page when less than height is left above the
+// bottom margin, so blocks are not split across pages.
+func (d *document) ensureSpace(height float64) {
+	_, pageHeight := d.pdf.GetPageSize()
+	_, _, _, bottom := d.pdf.GetMargins()
+
+	if d.pdf.GetY()+height > pageHeight-bottom-5 {
+		d.pdf.AddPage()
+	}
+}
+
+func (d *document) title(text string) {
+	d.pdf.SetFont("Helvetica", "B", 16)
+	d.pdf.CellFormat(0, 9, d.tr(text), "", 1, "L", false, 0, "")
+}
+
+func (d *document) heading(text string) {
+	d.ensureSpace(20)
+	d.pdf.Ln(3)
+	d.pdf.SetFont("Helvetica", "B", 11)
+	d.pdf.CellFormat(0, 7, d.tr(text), "B", 1, "L", false, 0, "")
+	d.pdf.Ln(1)
+}
+
+func (d *document) paragraph(text string) {
+	d.pdf.SetFont("Helvetica", "", 9)
+	d.pdf.MultiCell(0, lineHeight, d.tr(text), "", "L", false)
+}
+
+func (d *document) muted(text string) {
+	d.pdf.SetFont("Helvetica", "I", 9)
+	d.pdf.SetTextColor(110, 110, 110)
+	d.pdf.MultiCell(0, lineHeight, d.tr(text), "", "L", false)
+	d.pdf.SetTextColor(0, 0, 0)
+}
+
+// fields lays out label/value pairs in two columns.
+func (d *document) fields(pairs [][2]string) {
+	columnWidth := d.contentWidth() / 2
+
+	for i := 0; i < len(pairs); i += 2 {
+		d.ensureSpace(lineHeight + 1)
+
+		for j := i; j < i+2 && j < len(pairs); j++ {
+			d.pdf.SetFont("Helvetica", "", 9)
+			d.pdf.SetTextColor(110, 110, 110)
+			d.pdf.CellFormat(labelWidth, lineHeight+1, d.tr(pairs[j][0]), "", 0, "L", false, 0, "")
+
+			d.pdf.SetTextColor(0, 0, 0)
+			d.pdf.SetFont("Helvetica", "B", 9)
+			d.pdf.CellFormat(columnWidth-labelWidth, lineHeight+1, d.fit(pairs[j][1], columnWidth-labelWidth-2), "", 0, "L", false, 0, "")
+		}
+
+		d.pdf.Ln(lineHeight + 1)
+	}
+}
+
+// fit shortens text with an ellipsis so it stays inside a fixed cell.
+func (d *document) fit(text string, width float64) string {
+	text = d.tr(text)
+
+	if d.pdf.GetStringWidth(text) <= width {
+		return text
+	}
+
+	// Translated text is single-byte cp1252, so it is cut by byte.
+	for len(text) > 0 && d.pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+
+	return text + "..."
+}
+
+func (d *document) statusBadge(status string, width float64) {
+	fill, ok := statusFills[status]
+	if !ok {
+		fill = [3]int{230, 230, 230}
+	}
+
+	d.pdf.SetFillColor(fill[0], fill[1], fill[2])
+	d.pdf.SetFont("Helvetica", "B", 9)
+	d.pdf.CellFormat(width, 7, d.tr(status), "", 0, "C", true, 0, "")
+	d.pdf.SetFillColor(255, 255, 255)
+}
+
+// registerImage loads a stored photo once and registers it under its ref.
+func (d *document) registerImage(ref string) (image.Config, bool) {
+	if cfg, ok := d.images[ref]; ok {
+		return cfg, cfg.Width > 0
+	}
+
+	d.images[ref] = image.Config{}
+
+	if d.file == nil {
+		return image.Config{}, false
+	}
+
+	data, err := d.file(ref)
+	if err != nil {
+		return image.Config{}, false
+	}
+
+	return d.addImage(ref, data)
+}
+
+// addImage re-encodes an image as a modest JPEG before registering it,
+// since the PDF writer only accepts a few image types and would abort the
+// whole document on one it cannot parse.
+func (d *document) addImage(name string, data []byte) (image.Config, bool) {
+	rendered, err := imaging.Render(data, pdfImageSize, imaging.Options{
+		JPEGQuality: config.ImageJPEGQuality(),
+		MaxPixels:   config.ImageMaxPixels(),
+	})
+	if err != nil {
+		return image.Config{}, false
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(rendered))
+	if err != nil || cfg.Width == 0 || cfg.Height == 0 {
+		return image.Config{}, false
+	}
+
+	d.pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: "JPG"}, bytes.NewReader(rendered))
+	if d.pdf.Err() {
+		return image.Config{}, false
+	}
+
+	d.images[name] = cfg
+
+	return cfg, true
+}
+
+// placeImage draws a registered image scaled to fit a box, anchored at
+// its top left corner, and returns the size it was drawn at.
+func (d *document) placeImage(name string, cfg image.Config, x float64, y float64, boxWidth float64, boxHeight float64) (float64, float64) {
+	width := boxWidth
+	height := boxWidth * float64(cfg.Height) / float64(cfg.Width)
+
+	if height > boxHeight {
+		height = boxHeight
+		width = boxHeight * float64(cfg.Width) / float64(cfg.Height)
+	}
+
+	d.pdf.ImageOptions(name, x, y, width, height, false, fpdf.ImageOptions{ImageType: "JPG"}, 0, "")
+
+	return width, height
+}
+
+// imageGrid places photos in rows, each scaled to fit a box of boxWidth by
+// boxHeight. Photos that cannot be loaded leave a labelled placeholder.
+func (d *document) imageGrid(refs []string, boxWidth float64, boxHeight float64) {
+	if len(refs) == 0 {
+		return
+	}
+
+	const gap = 4.0
+
+	perRow := int((d.contentWidth() + gap) / (boxWidth + gap))
+	if perRow < 1 {
+		perRow = 1
+	}
+
+	for i, ref := range refs {
+		if i%perRow == 0 {
+			if i > 0 {
+				d.pdf.Ln(boxHeight + gap)
+			}
+
+			d.ensureSpace(boxHeight + gap)
+		}
+
+		x := pageMargin + float64(i%perRow)*(boxWidth+gap)
+		y := d.pdf.GetY()
+
+		cfg, ok := d.registerImage(ref)
+		if !ok {
+			d.pdf.SetDrawColor(200, 200, 200)
+			d.pdf.Rect(x, y, boxWidth, boxHeight, "D")
+			d.pdf.SetXY(x, y+boxHeight/2-lineHeight/2)
+			d.pdf.SetFont("Helvetica", "I", 8)
+			d.pdf.CellFormat(boxWidth, lineHeight, "image unavailable", "", 0, "C", false, 0, "")
+			d.pdf.SetDrawColor(0, 0, 0)
+			d.pdf.SetXY(pageMargin, y)
+			continue
+		}
+
+		d.placeImage(ref, cfg, x, y, boxWidth, boxHeight)
+	}
+
+	d.pdf.Ln(boxHeight + gap)
+}
+
+func (d *document) output(w io.Writer) error {
+	if err := d.pdf.Error(); err != nil {
+		return fmt.Errorf("build pdf: %w", err)
+	}
+
+	return d.pdf.Output(w)
+}
@@ -0,0 +1,85 @@
+package exporter
+
+import (
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+// Data is what an export is built from. Details is only filled in for
+// exporters that ask for it, and File only serves the refs from Files.
+type Data struct {
+	Filter      model.ExportFilter
+	Tickets     []*model.TicketResponse
+	Details     map[int64]*model.TicketDetail
+	File        helper.FileLoader
+	URL         func(ref string) string
+	GeneratedAt time.Time
+}
+
+// Exporter writes a ticket list in one file format.
+type Exporter interface {
+	ContentType() string
+	Extension() string
+	// NeedsDetails asks for each ticket's history, comments, resolution
+	// and files to be loaded into Data.Details.
+	NeedsDetails() bool
+	// Files lists the stored files Write reads through Data.File, so they
+	// can be downloaded up front.
+	Files(data *Data) []string
+	Write(w io.Writer, data *Data) error
+}
+
+var exporters = map[string]Exporter{
+	"xlsx":   xlsxExporter{},
+	"csv":    csvExporter{},
+	"ndjson": ndjsonExporter{},
+	"pdf":    pdfExporter{},
+}
+
+// New returns the exporter for a format name, as used in ?format=.
+func New(format string) (Exporter, error) {
+	exporter, ok := exporters[strings.ToLower(format)]
+	if !ok {
+		return nil, model.ErrExportFormatUnsupported
+	}
+
+	return exporter, nil
+}
+
+var imageExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".gif":  true,
+	".webp": true,
+}
+
+// imageRef picks the smallest stored copy of a photo to embed: the
+// thumbnail, or the original for files uploaded before renditions existed.
+// Those older rows have no reliable MIME type, so the extension decides.
+func imageRef(attachment *model.Attachment) string {
+	if attachment.ThumbnailKey != nil && *attachment.ThumbnailKey != "" {
+		return *attachment.ThumbnailKey
+	}
+
+	if strings.HasPrefix(attachment.MimeType, "image/") ||
+		imageExtensions[strings.ToLower(path.Ext(attachment.Filename))] {
+		return attachment.StorageKey
+	}
+
+	return ""
+}
+
+func urlOf(data *Data, ref *string) *string {
+	if ref == nil || data.URL == nil {
+		return ref
+	}
+
+	url := data.URL(*ref)
+	return &url
+}
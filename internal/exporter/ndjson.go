@@ -0,0 +1,48 @@
+package exporter
+
+import (
+	"encoding/json"
+	"io"
+)
+
+type ndjsonExporter struct{}
+
+func (ndjsonExporter) ContentType() string {
+	return "application/x-ndjson"
+}
+
+func (ndjsonExporter) Extension() string {
+	return ".ndjson"
+}
+
+func (ndjsonExporter) NeedsDetails() bool {
+	return false
+}
+
+func (ndjsonExporter) Files(data *Data) []string {
+	return nil
+}
+
+// Write emits each ticket as one JSON object per line, in the same shape
+// as the ticket list API.
+func (ndjsonExporter) Write(w io.Writer, data *Data) error {
+	encoder := json.NewEncoder(w)
+
+	for _, ticket := range data.Tickets {
+		if ticket == nil {
+			continue
+		}
+
+		row := *ticket
+		row.Attachment = urlOf(data, ticket.Attachment)
+		row.AttachmentThumbnail = urlOf(data, ticket.AttachmentThumbnail)
+		row.SolutionAttachment = urlOf(data, ticket.SolutionAttachment)
+		row.SolutionThumbnail = urlOf(data, ticket.SolutionThumbnail)
+
+		if err := encoder.Encode(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,451 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+const (
+	dateFormat     = "2006-01-02"
+	dateTimeFormat = "2006-01-02 15:04"
+)
+
+var summaryColumns = []struct {
+	title string
+	width float64
+}{
+	{"Ticket Code", 34},
+	{"Project", 32},
+	{"Location", 30},
+	{"Priority", 18},
+	{"Status", 22},
+	{"Created", 22},
+	{"Due", 22},
+}
+
+var statusOrder = []model.TicketStatus{
+	model.StatusOpen,
+	model.StatusInProgress,
+	model.StatusOnHold,
+	model.StatusResolved,
+	model.StatusClosed,
+}
+
+type pdfExporter struct{}
+
+func (pdfExporter) ContentType() string {
+	return "application/pdf"
+}
+
+func (pdfExporter) Extension() string {
+	return ".pdf"
+}
+
+func (pdfExporter) NeedsDetails() bool {
+	return true
+}
+
+func (pdfExporter) Files(data *Data) []string {
+	var refs []string
+
+	for _, detail := range data.Details {
+		refs = append(refs, detailImages(detail.Attachments)...)
+
+		if detail.Resolution != nil {
+			refs = append(refs, detailImages(detail.Resolution.Attachments)...)
+
+			for _, signature := range detail.Resolution.Signatures {
+				refs = append(refs, signature.StorageKey)
+			}
+		}
+
+		for _, comment := range detail.Comments {
+			refs = append(refs, detailImages(comment.Attachments)...)
+		}
+	}
+
+	return refs
+}
+
+// Write produces a summary page with a table of every ticket, followed by
+// a detail page per ticket.
+func (pdfExporter) Write(w io.Writer, data *Data) error {
+	doc := newDocument("Ticket Report", data.GeneratedAt, data.File)
+
+	doc.pdf.AddPage()
+	writeSummary(doc, data)
+
+	for _, ticket := range data.Tickets {
+		if ticket == nil {
+			continue
+		}
+
+		doc.pdf.AddPage()
+		writeTicketDetail(doc, ticket, data.Details[ticket.ID])
+	}
+
+	return doc.output(w)
+}
+
+func writeSummary(doc *document, data *Data) {
+	doc.title("Ticket Report")
+	doc.muted(fmt.Sprintf(
+		"Generated %s - %d tickets",
+		data.GeneratedAt.Format(dateTimeFormat),
+		len(data.Tickets),
+	))
+
+	if filter := describeFilter(data.Filter); filter != "" {
+		doc.muted("Filters: " + filter)
+	}
+
+	counts := make(map[string]int)
+	for _, ticket := range data.Tickets {
+		if ticket != nil {
+			counts[ticket.Status]++
+		}
+	}
+
+	doc.heading("Status")
+
+	width := doc.contentWidth() / float64(len(statusOrder))
+
+	for _, status := range statusOrder {
+		doc.statusBadge(string(status), width-2)
+		doc.pdf.CellFormat(2, 7, "", "", 0, "L", false, 0, "")
+	}
+	doc.pdf.Ln(7)
+
+	doc.pdf.SetFont("Helvetica", "B", 12)
+	for _, status := range statusOrder {
+		doc.pdf.CellFormat(width, 8, fmt.Sprint(counts[string(status)]), "", 0, "C", false, 0, "")
+	}
+	doc.pdf.Ln(8)
+
+	doc.heading("Tickets")
+
+	if len(data.Tickets) == 0 {
+		doc.muted("No tickets match these filters.")
+		return
+	}
+
+	writeSummaryHeader(doc)
+
+	for _, ticket := range data.Tickets {
+		if ticket == nil {
+			continue
+		}
+
+		_, pageHeight := doc.pdf.GetPageSize()
+		if doc.pdf.GetY()+lineHeight+1 > pageHeight-pageMargin-10 {
+			doc.pdf.AddPage()
+			writeSummaryHeader(doc)
+		}
+
+		values := []string{
+			ticket.TicketCode,
+			ticket.ProjectName,
+			ticket.LocationName,
+			ticket.Priority,
+			ticket.Status,
+			ticket.CreatedAt.Format(dateFormat),
+			ticket.DueAt.Format(dateFormat),
+		}
+
+		doc.pdf.SetFont("Helvetica", "", 8)
+
+		for i, column := range summaryColumns {
+			doc.pdf.CellFormat(column.width, lineHeight+1, doc.fit(values[i], column.width-2), "B", 0, "L", false, 0, "")
+		}
+
+		doc.pdf.Ln(lineHeight + 1)
+	}
+}
+
+func writeSummaryHeader(doc *document) {
+	doc.pdf.SetFont("Helvetica", "B", 8)
+	doc.pdf.SetFillColor(242, 242, 242)
+
+	for _, column := range summaryColumns {
+		doc.pdf.CellFormat(column.width, lineHeight+2, column.title, "B", 0, "L", true, 0, "")
+	}
+
+	doc.pdf.SetFillColor(255, 255, 255)
+	doc.pdf.Ln(lineHeight + 2)
+}
+
+func writeTicketDetail(doc *document, ticket *model.TicketResponse, detail *model.TicketDetail) {
+	doc.pdf.SetFont("Helvetica", "B", 14)
+	doc.pdf.CellFormat(doc.contentWidth()-35, 7, doc.tr(ticket.TicketCode), "", 0, "L", false, 0, "")
+	doc.statusBadge(ticket.Status, 35)
+	doc.pdf.Ln(10)
+
+	writeTicketFields(doc, ticket)
+
+	doc.heading("Description")
+	doc.paragraph(ticket.Description)
+
+	if detail == nil {
+		return
+	}
+
+	if images := detailImages(detail.Attachments); len(images) > 0 {
+		doc.heading("Photos")
+		doc.imageGrid(images, 56, 42)
+	}
+
+	doc.heading("Resolution")
+	writeResolution(doc, detail.Resolution)
+
+	if detail.Resolution != nil && len(detail.Resolution.Signatures) > 0 {
+		doc.heading("Signatures")
+		writeCapturedSignatures(doc, detail.Resolution.Signatures)
+	}
+
+	doc.heading("Comments")
+	writeComments(doc, detail.Comments)
+
+	doc.heading("History")
+	writeHistory(doc, detail.Histories)
+}
+
+func writeTicketFields(doc *document, ticket *model.TicketResponse) {
+	assignedTo := ticket.AssignedToName
+	if assignedTo == "" {
+		assignedTo = "-"
+	}
+
+	doc.fields([][2]string{
+		{"Project", ticket.ProjectName},
+		{"Location", ticket.LocationName},
+		{"Part", ticket.PartName},
+		{"Asset", ticket.AssetCode},
+		{"Reporter", ticket.ReporterName},
+		{"Assigned to", assignedTo},
+		{"Priority", ticket.Priority},
+		{"Status", ticket.Status},
+		{"Created", ticket.CreatedAt.Format(dateTimeFormat)},
+		{"Due", ticket.DueAt.Format(dateTimeFormat)},
+	})
+}
+
+func writeResolution(doc *document, resolution *model.TicketResolution) {
+	if resolution == nil {
+		doc.muted("Not resolved yet.")
+		return
+	}
+
+	cause, solution := "-", "-"
+	if resolution.Cause != nil {
+		cause = resolution.Cause.Name
+	}
+	if resolution.Solution != nil {
+		solution = resolution.Solution.Name
+	}
+
+	doc.fields([][2]string{
+		{"Cause", cause},
+		{"Solution", solution},
+		{"Completed", formatTime(resolution.CompletionTime)},
+	})
+
+	if resolution.ResolutionNotes != "" {
+		doc.pdf.Ln(1)
+		doc.paragraph(resolution.ResolutionNotes)
+	}
+
+	if images := detailImages(resolution.Attachments); len(images) > 0 {
+		doc.pdf.Ln(2)
+		doc.imageGrid(images, 56, 42)
+	}
+}
+
+func writeComments(doc *document, comments []*model.TicketCommentResponse) {
+	if len(comments) == 0 {
+		doc.muted("No comments.")
+		return
+	}
+
+	for _, comment := range comments {
+		doc.ensureSpace(3 * lineHeight)
+
+		doc.pdf.SetFont("Helvetica", "B", 9)
+		doc.pdf.CellFormat(0, lineHeight, doc.tr(fmt.Sprintf(
+			"%s - %s",
+			comment.UserName,
+			comment.CreatedAt.Format(dateTimeFormat),
+		)), "", 1, "L", false, 0, "")
+
+		doc.paragraph(comment.Message)
+
+		if len(comment.Attachments) > 0 {
+			names := make([]string, 0, len(comment.Attachments))
+			for _, attachment := range comment.Attachments {
+				names = append(names, attachment.Filename)
+			}
+
+			doc.muted("Attachments: " + strings.Join(names, ", "))
+			doc.imageGrid(detailImages(comment.Attachments), 36, 27)
+		}
+
+		doc.pdf.Ln(2)
+	}
+}
+
+// writeCapturedSignatures shows the signatures drawn at resolution side by
+// side, each captioned with the signer and time.
+func writeCapturedSignatures(doc *document, signatures []*model.ResolutionSignature) {
+	const boxWidth, boxHeight, gap = 60.0, 22.0, 6.0
+
+	doc.ensureSpace(boxHeight + 2*lineHeight + 2)
+
+	top := doc.pdf.GetY()
+
+	for i, signature := range signatures {
+		x := pageMargin + float64(i)*(boxWidth+gap)
+
+		if cfg, ok := doc.registerImage(signature.StorageKey); ok {
+			doc.placeImage(signature.StorageKey, cfg, x, top, boxWidth, boxHeight)
+		}
+
+		doc.pdf.SetXY(x, top+boxHeight+1)
+		doc.pdf.SetFont("Helvetica", "B", 8)
+		doc.pdf.CellFormat(boxWidth, lineHeight-1, doc.fit(fmt.Sprintf("%s - %s", signerTitle(signature.SignerRole), signature.SignerName), boxWidth), "", 2, "L", false, 0, "")
+
+		doc.pdf.SetX(x)
+		doc.pdf.SetFont("Helvetica", "I", 8)
+		doc.pdf.SetTextColor(110, 110, 110)
+		doc.pdf.CellFormat(boxWidth, lineHeight-1, "Signed "+signature.SignedAt.Format(dateTimeFormat), "", 0, "L", false, 0, "")
+		doc.pdf.SetTextColor(0, 0, 0)
+	}
+
+	doc.pdf.SetXY(pageMargin, top+boxHeight+2*lineHeight+2)
+}
+
+func signerTitle(role model.SignerRole) string {
+	if role == model.SignerReporter {
+		return "Reporter"
+	}
+
+	return "Technician"
+}
+
+// writeHistory lists the timeline oldest first, the order it is read in
+// on paper.
+func writeHistory(doc *document, histories []*model.TicketHistoryResponse) {
+	if len(histories) == 0 {
+		doc.muted("No history recorded.")
+		return
+	}
+
+	const dateWidth, userWidth = 30.0, 35.0
+
+	activityWidth := doc.contentWidth() - dateWidth - userWidth
+
+	for i := len(histories) - 1; i >= 0; i-- {
+		history := histories[i]
+		activity := doc.tr(historyActivity(history))
+
+		doc.pdf.SetFont("Helvetica", "", 8)
+		lines := doc.pdf.SplitLines([]byte(activity), activityWidth)
+		doc.ensureSpace(float64(len(lines))*(lineHeight-1) + 1)
+
+		doc.pdf.SetTextColor(110, 110, 110)
+		doc.pdf.CellFormat(dateWidth, lineHeight-1, history.CreatedAt.Format(dateTimeFormat), "", 0, "L", false, 0, "")
+		doc.pdf.SetTextColor(0, 0, 0)
+		doc.pdf.CellFormat(userWidth, lineHeight-1, doc.fit(history.UserName, userWidth-2), "", 0, "L", false, 0, "")
+		doc.pdf.MultiCell(activityWidth, lineHeight-1, activity, "", "L", false)
+		doc.pdf.Ln(1)
+	}
+}
+
+func historyActivity(history *model.TicketHistoryResponse) string {
+	if history.Message != nil && *history.Message != "" {
+		return *history.Message
+	}
+
+	activity := strings.ToLower(strings.ReplaceAll(history.Action, "_", " "))
+
+	if history.FieldName == "" {
+		return activity
+	}
+
+	return fmt.Sprintf(
+		"%s: %s changed from %s to %s",
+		activity,
+		history.FieldName,
+		valueOrDash(history.OldValue),
+		valueOrDash(history.NewValue),
+	)
+}
+
+func describeFilter(filter model.ExportFilter) string {
+	var parts []string
+
+	if filter.TicketCode != "" {
+		parts = append(parts, "code "+filter.TicketCode)
+	}
+	if filter.ProjectID != 0 {
+		parts = append(parts, fmt.Sprintf("project #%d", filter.ProjectID))
+	}
+	if filter.AssignedToID != 0 {
+		parts = append(parts, fmt.Sprintf("assignee #%d", filter.AssignedToID))
+	}
+	if filter.ReporterID != 0 {
+		parts = append(parts, fmt.Sprintf("reporter #%d", filter.ReporterID))
+	}
+	if filter.Priority != "" {
+		parts = append(parts, "priority "+string(filter.Priority))
+	}
+	if filter.Status != "" {
+		parts = append(parts, "status "+string(filter.Status))
+	}
+	if filter.StartDate != "" || filter.EndDate != "" {
+		parts = append(parts, fmt.Sprintf("created %s to %s", orDash(filter.StartDate), orDash(filter.EndDate)))
+	}
+	if filter.Search != "" {
+		parts = append(parts, fmt.Sprintf("search %q", filter.Search))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func detailImages(attachments []*model.Attachment) []string {
+	var refs []string
+
+	for _, attachment := range attachments {
+		if ref := imageRef(attachment); ref != "" {
+			refs = append(refs, ref)
+		}
+	}
+
+	return refs
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return t.Format(dateTimeFormat)
+}
+
+func valueOrDash(value *string) string {
+	if value == nil {
+		return "-"
+	}
+
+	return orDash(*value)
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
@@ -0,0 +1,188 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+const (
+	logoImageName   = "report-logo"
+	signatureHeight = 25.0
+)
+
+// Signature is one signing party on a service report. Image is the stored
+// e-signature the party captured, if any; without one the block is left
+// blank to be signed on paper.
+type Signature struct {
+	Title    string
+	Name     string
+	Image    string
+	SignedAt *time.Time
+}
+
+// ServiceReport is the signed record of work done on one resolved ticket
+// (berita acara).
+type ServiceReport struct {
+	Detail         *model.TicketDetail
+	Signatures     []Signature
+	CompanyName    string
+	CompanyAddress string
+	Logo           []byte
+	File           helper.FileLoader
+	GeneratedAt    time.Time
+}
+
+// ServiceReportFiles lists the stored files WriteServiceReport reads.
+func ServiceReportFiles(report *ServiceReport) []string {
+	refs := detailImages(report.Detail.Attachments)
+
+	if report.Detail.Resolution != nil {
+		refs = append(refs, detailImages(report.Detail.Resolution.Attachments)...)
+	}
+
+	for _, signature := range report.Signatures {
+		if signature.Image != "" {
+			refs = append(refs, signature.Image)
+		}
+	}
+
+	return refs
+}
+
+func WriteServiceReport(w io.Writer, report *ServiceReport) error {
+	ticket := report.Detail.Ticket
+	doc := newDocument("Service Report "+ticket.TicketCode, report.GeneratedAt, report.File)
+
+	doc.pdf.AddPage()
+
+	writeLetterhead(doc, report)
+
+	doc.pdf.SetFont("Helvetica", "B", 14)
+	doc.pdf.CellFormat(0, 8, "SERVICE REPORT", "", 1, "C", false, 0, "")
+	doc.pdf.SetFont("Helvetica", "", 9)
+	doc.pdf.CellFormat(0, lineHeight, doc.tr("Berita Acara Pekerjaan - No. SR/"+ticket.TicketCode), "", 1, "C", false, 0, "")
+	doc.pdf.Ln(3)
+
+	doc.heading("Ticket")
+	writeTicketFields(doc, ticket)
+
+	doc.heading("Reported Problem")
+	doc.paragraph(ticket.Description)
+
+	if images := detailImages(report.Detail.Attachments); len(images) > 0 {
+		doc.pdf.Ln(2)
+		doc.imageGrid(images, 42, 32)
+	}
+
+	doc.heading("Work Performed")
+	writeResolution(doc, report.Detail.Resolution)
+
+	doc.heading("Activity")
+	writeHistory(doc, report.Detail.Histories)
+
+	writeSignatures(doc, report)
+
+	return doc.output(w)
+}
+
+func writeLetterhead(doc *document, report *ServiceReport) {
+	const logoWidth, logoHeight = 30.0, 15.0
+
+	top := doc.pdf.GetY()
+	textX := pageMargin
+
+	if len(report.Logo) > 0 {
+		if cfg, ok := doc.addImage(logoImageName, report.Logo); ok {
+			doc.placeImage(logoImageName, cfg, pageMargin, top, logoWidth, logoHeight)
+			textX += logoWidth + 4
+		}
+	}
+
+	doc.pdf.SetXY(textX, top)
+	doc.pdf.SetFont("Helvetica", "B", 13)
+	doc.pdf.CellFormat(0, 7, doc.tr(report.CompanyName), "", 2, "L", false, 0, "")
+
+	if report.CompanyAddress != "" {
+		doc.pdf.SetFont("Helvetica", "", 8)
+		doc.pdf.SetTextColor(110, 110, 110)
+		doc.pdf.MultiCell(0, 4, doc.tr(report.CompanyAddress), "", "L", false)
+		doc.pdf.SetTextColor(0, 0, 0)
+	}
+
+	bottom := top + logoHeight
+	if doc.pdf.GetY() > bottom {
+		bottom = doc.pdf.GetY()
+	}
+
+	doc.pdf.SetLineWidth(0.5)
+	doc.pdf.Line(pageMargin, bottom+2, pageMargin+doc.contentWidth(), bottom+2)
+	doc.pdf.SetLineWidth(0.2)
+	doc.pdf.SetXY(pageMargin, bottom+6)
+}
+
+// writeSignatures closes the report with a statement and one signature
+// block per party, kept together on one page.
+func writeSignatures(doc *document, report *ServiceReport) {
+	if len(report.Signatures) == 0 {
+		return
+	}
+
+	doc.ensureSpace(signatureHeight + 40)
+	doc.pdf.Ln(6)
+
+	completed := report.GeneratedAt
+	if resolution := report.Detail.Resolution; resolution != nil && !resolution.CompletionTime.IsZero() {
+		completed = resolution.CompletionTime
+	}
+
+	doc.paragraph(fmt.Sprintf(
+		"The work described above was completed and handed over on %s. By signing, both parties confirm this report.",
+		completed.Format("2 January 2006"),
+	))
+	doc.pdf.Ln(4)
+
+	const gap = 10.0
+
+	width := (doc.contentWidth() - gap*float64(len(report.Signatures)-1)) / float64(len(report.Signatures))
+	top := doc.pdf.GetY()
+
+	for i, signature := range report.Signatures {
+		x := pageMargin + float64(i)*(width+gap)
+
+		doc.pdf.SetXY(x, top)
+		doc.pdf.SetFont("Helvetica", "B", 9)
+		doc.pdf.CellFormat(width, lineHeight, doc.tr(signature.Title), "", 0, "C", false, 0, "")
+
+		if signature.Image != "" {
+			if cfg, ok := doc.registerImage(signature.Image); ok {
+				imageWidth := signatureHeight * float64(cfg.Width) / float64(cfg.Height)
+				if imageWidth > width {
+					imageWidth = width
+				}
+
+				doc.placeImage(signature.Image, cfg, x+(width-imageWidth)/2, top+lineHeight+1, imageWidth, signatureHeight)
+			}
+		}
+
+		lineY := top + lineHeight + signatureHeight + 3
+		doc.pdf.Line(x+5, lineY, x+width-5, lineY)
+
+		doc.pdf.SetXY(x, lineY+1)
+		doc.pdf.SetFont("Helvetica", "", 9)
+		doc.pdf.CellFormat(width, lineHeight, doc.fit(orDash(signature.Name), width), "", 2, "C", false, 0, "")
+
+		if signature.SignedAt != nil {
+			doc.pdf.SetX(x)
+			doc.pdf.SetFont("Helvetica", "I", 8)
+			doc.pdf.SetTextColor(110, 110, 110)
+			doc.pdf.CellFormat(width, 4, "Signed "+signature.SignedAt.Format(dateTimeFormat), "", 0, "C", false, 0, "")
+			doc.pdf.SetTextColor(0, 0, 0)
+		}
+	}
+
+	doc.pdf.SetXY(pageMargin, top+lineHeight+signatureHeight+15)
+}
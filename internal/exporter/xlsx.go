@@ -0,0 +1,43 @@
+package exporter
+
+import (
+	"io"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
+)
+
+type xlsxExporter struct{}
+
+func (xlsxExporter) ContentType() string {
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+
+func (xlsxExporter) Extension() string {
+	return ".xlsx"
+}
+
+func (xlsxExporter) NeedsDetails() bool {
+	return false
+}
+
+func (xlsxExporter) Files(data *Data) []string {
+	refs := make([]string, 0, len(data.Tickets))
+
+	for _, ticket := range data.Tickets {
+		if ticket != nil {
+			refs = append(refs, helper.ResolutionImageRef(ticket))
+		}
+	}
+
+	return refs
+}
+
+func (xlsxExporter) Write(w io.Writer, data *Data) error {
+	file, err := helper.GenerateExcelTickets(data.Tickets, data.File)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.WriteTo(w)
+	return err
+}
@@ -2,13 +2,13 @@ package helper
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"image"
 	_ "image/gif"
 	_ "image/jpeg"
 	"image/png"
-	"io"
-	"net/http"
+	"sync"
 	"time"
 
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
@@ -37,6 +37,8 @@ var ticketHeaders = []string{
 	"Foto Resolution",
 	"Created At",
 	"Due At",
+	"Technician Signed",
+	"Reporter Signed",
 }
 
 var ticketColumnWidths = map[string]float64{
@@ -51,6 +53,8 @@ var ticketColumnWidths = map[string]float64{
 	"I": 30,
 	"J": 20,
 	"K": 20,
+	"L": 30,
+	"M": 30,
 }
 
 var statusColors = map[string]string{
@@ -61,34 +65,81 @@ var statusColors = map[string]string{
 	"ONHOLD":      "BDD7EE",
 }
 
-func downloadImageFromURL(url string) ([]byte, error) {
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
+// FileLoader reads a stored attachment by its reference.
+type FileLoader func(ref string) ([]byte, error)
 
-	resp, err := client.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to download image: %w", err)
+type prefetchedFile struct {
+	data []byte
+	err  error
+}
+
+// PrefetchFiles downloads the given stored files with at most concurrency
+// downloads in flight, instead of one after another while an export is
+// written. The returned loader serves what was downloaded; onProgress, when
+// set, is called after each file.
+func PrefetchFiles(ctx context.Context, refs []string, concurrency int, loadFile func(ctx context.Context, ref string) ([]byte, error), onProgress func(done int, total int)) FileLoader {
+	unique := make([]string, 0, len(refs))
+	seen := make(map[string]bool, len(refs))
+
+	for _, ref := range refs {
+		if ref != "" && !seen[ref] {
+			seen[ref] = true
+			unique = append(unique, ref)
+		}
 	}
 
-	defer resp.Body.Close()
+	refs = unique
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(
-			"failed to download image, status: %s",
-			resp.Status,
-		)
+	if concurrency <= 0 {
+		concurrency = 1
 	}
 
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf(
-			"failed to read image: %w",
-			err,
-		)
+	files := make(map[string]prefetchedFile, len(refs))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	sem := make(chan struct{}, concurrency)
+	done := 0
+
+	for _, ref := range refs {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(ref string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			var file prefetchedFile
+
+			if err := ctx.Err(); err != nil {
+				file.err = err
+			} else {
+				file.data, file.err = loadFile(ctx, ref)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			files[ref] = file
+			done++
+
+			if onProgress != nil {
+				onProgress(done, len(refs))
+			}
+		}(ref)
 	}
 
-	return data, nil
+	wg.Wait()
+
+	return func(ref string) ([]byte, error) {
+		file, ok := files[ref]
+		if !ok {
+			return nil, fmt.Errorf("file %s was not prefetched", ref)
+		}
+
+		return file.data, file.err
+	}
 }
 
 func decodeImage(data []byte) (image.Image, error) {
@@ -150,10 +201,25 @@ func calculateImageScale(originalWidth int, originalHeight int, maxWidth float64
 	return scaleY
 }
 
-func addResolutionImage(f *excelize.File, sheet string, cell string, imageURL string) error {
-	imageBytes, err := downloadImageFromURL(imageURL)
+// ResolutionImageRef prefers the thumbnail rendition so the export does not
+// download full-size photos. Files uploaded before renditions existed only
+// have the original.
+func ResolutionImageRef(ticket *model.TicketResponse) string {
+	if ticket.SolutionThumbnail != nil && *ticket.SolutionThumbnail != "" {
+		return *ticket.SolutionThumbnail
+	}
+
+	if ticket.SolutionAttachment != nil {
+		return *ticket.SolutionAttachment
+	}
+
+	return ""
+}
+
+func addResolutionImage(f *excelize.File, sheet string, cell string, ref string, loadFile FileLoader) error {
+	imageBytes, err := loadFile(ref)
 	if err != nil {
-		return fmt.Errorf("download image: %w", err)
+		return fmt.Errorf("load image: %w", err)
 	}
 
 	originalWidth, originalHeight, err := getImageDimensions(imageBytes)
@@ -333,7 +399,17 @@ func writeTicketHeaders(f *excelize.File, sheet string, styleID int) error {
 	return nil
 }
 
-func writeTicketRow(f *excelize.File, sheet string, row int, ticket *model.TicketResponse, borderStyle int, statusStyles map[string]int) error {
+// signatureText describes a resolution signature as "name (time)", or is
+// empty when the party has not signed.
+func signatureText(name *string, signedAt *time.Time) string {
+	if name == nil || signedAt == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%s (%s)", *name, signedAt.Format("2006-01-02 15:04"))
+}
+
+func writeTicketRow(f *excelize.File, sheet string, row int, ticket *model.TicketResponse, borderStyle int, statusStyles map[string]int, loadFile FileLoader) error {
 	values := []interface{}{
 		ticket.TicketCode,
 		ticket.ProjectName,
@@ -406,14 +482,14 @@ func writeTicketRow(f *excelize.File, sheet string, row int, ticket *model.Ticke
 		)
 	}
 
-	if ticket.SolutionAttachment != nil &&
-		*ticket.SolutionAttachment != "" {
+	if ref := ResolutionImageRef(ticket); ref != "" {
 
 		if err := addResolutionImage(
 			f,
 			sheet,
 			imageCell,
-			*ticket.SolutionAttachment,
+			ref,
+			loadFile,
 		); err != nil {
 			fmt.Printf(
 				"failed to add resolution image for ticket %s: %v\n",
@@ -484,6 +560,38 @@ func writeTicketRow(f *excelize.File, sheet string, row int, ticket *model.Ticke
 		)
 	}
 
+	signatures := map[string]string{
+		fmt.Sprintf("L%d", row): signatureText(ticket.TechnicianSignedBy, ticket.TechnicianSignedAt),
+		fmt.Sprintf("M%d", row): signatureText(ticket.ReporterSignedBy, ticket.ReporterSignedAt),
+	}
+
+	for cell, value := range signatures {
+		if err := f.SetCellValue(
+			sheet,
+			cell,
+			value,
+		); err != nil {
+			return fmt.Errorf(
+				"set signature %s: %w",
+				cell,
+				err,
+			)
+		}
+
+		if err := f.SetCellStyle(
+			sheet,
+			cell,
+			cell,
+			borderStyle,
+		); err != nil {
+			return fmt.Errorf(
+				"set signature style %s: %w",
+				cell,
+				err,
+			)
+		}
+	}
+
 	if err := f.SetRowHeight(
 		sheet,
 		row,
@@ -609,7 +717,7 @@ func writeSummarySheet(f *excelize.File, tickets []*model.TicketResponse) error
 	return nil
 }
 
-func GenerateExcelTickets(tickets []*model.TicketResponse) (*bytes.Buffer, error) {
+func GenerateExcelTickets(tickets []*model.TicketResponse, loadFile FileLoader) (*bytes.Buffer, error) {
 	f := excelize.NewFile()
 
 	sheet := ticketSheetName
@@ -662,6 +770,7 @@ func GenerateExcelTickets(tickets []*model.TicketResponse) (*bytes.Buffer, error
 			ticket,
 			borderStyle,
 			statusStyles,
+			loadFile,
 		); err != nil {
 			return nil, fmt.Errorf(
 				"write ticket row %d: %w",
@@ -12,6 +12,8 @@ import (
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
 )
 
+const tokenTTL = 2 * time.Hour
+
 func HashRequestPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
 	return string(bytes), err
@@ -29,8 +31,12 @@ func GenerateToken(user model.User) (string, error) {
 		Role:   user.Role.Name,
 		Email:  user.Email,
 		Name:   user.Name,
+
+		TwoFactorSetupRequired: user.Role.RequireTwoFactor && !user.TwoFactorEnabled,
+
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(2 * time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(tokenTTL)),
 		},
 	}
 
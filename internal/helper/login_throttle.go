@@ -0,0 +1,69 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+)
+
+const (
+	LoginScopeAccount = "account"
+	LoginScopeIP      = "ip"
+)
+
+func loginFailureKey(scope string, key string) string {
+	return fmt.Sprintf("login:fail:%s:%s", scope, key)
+}
+
+func loginBlockKey(scope string, key string) string {
+	return fmt.Sprintf("login:block:%s:%s", scope, key)
+}
+
+// LoginBlockedFor returns how long the given account or IP has to wait
+// before another login attempt is accepted.
+func LoginBlockedFor(ctx context.Context, scope string, key string) time.Duration {
+	ttl, err := config.Rdb.PTTL(ctx, loginBlockKey(scope, key)).Result()
+	if err != nil || ttl < 0 {
+		return 0
+	}
+
+	return ttl
+}
+
+// RegisterLoginFailure increments the failure counter and blocks further
+// attempts for an exponentially growing duration.
+func RegisterLoginFailure(ctx context.Context, scope string, key string) (int64, error) {
+	failureKey := loginFailureKey(scope, key)
+
+	count, err := config.Rdb.Incr(ctx, failureKey).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	config.Rdb.Expire(ctx, failureKey, config.LoginFailureWindow())
+
+	backoff := config.LoginBackoffBase()
+	for i := int64(1); i < count && backoff < config.LoginBackoffMax(); i++ {
+		backoff *= 2
+	}
+
+	if backoff > config.LoginBackoffMax() {
+		backoff = config.LoginBackoffMax()
+	}
+
+	if err := config.Rdb.Set(ctx, loginBlockKey(scope, key), count, backoff).Err(); err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+func ResetLoginFailures(ctx context.Context, scope string, key string) error {
+	return config.Rdb.Del(
+		ctx,
+		loginFailureKey(scope, key),
+		loginBlockKey(scope, key),
+	).Err()
+}
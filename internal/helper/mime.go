@@ -0,0 +1,80 @@
+package helper
+
+import (
+	"bytes"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// oleSignature starts the compound file format used by pre-2007 Office.
+var oleSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+var officeOpenXMLTypes = map[string]string{
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+}
+
+var legacyOfficeTypes = map[string]string{
+	".doc": "application/msword",
+	".xls": "application/vnd.ms-excel",
+	".ppt": "application/vnd.ms-powerpoint",
+}
+
+// DetectMimeType sniffs the first bytes of a file. The client's declared
+// type is never trusted; the extension is only used to tell apart formats
+// that share a container, such as .docx and .xlsx inside a ZIP.
+func DetectMimeType(head []byte, filename string) string {
+	detected := http.DetectContentType(head)
+	detected = strings.TrimSpace(strings.Split(detected, ";")[0])
+
+	ext := strings.ToLower(path.Ext(filename))
+
+	switch {
+	case detected == "application/zip":
+		if officeType, ok := officeOpenXMLTypes[ext]; ok {
+			return officeType
+		}
+
+	case bytes.HasPrefix(head, oleSignature):
+		if officeType, ok := legacyOfficeTypes[ext]; ok {
+			return officeType
+		}
+		return "application/x-ole-storage"
+
+	case detected == "text/plain" && ext == ".csv":
+		return "text/csv"
+
+	case detected == "application/octet-stream" && len(head) >= 12 && string(head[4:8]) == "ftyp":
+		// ISO media files whose brand the standard sniffer does not know.
+		switch brand := string(head[8:12]); {
+		case brand == "qt  ":
+			return "video/quicktime"
+		case strings.HasPrefix(brand, "3g"):
+			return "video/3gpp"
+		}
+	}
+
+	return detected
+}
+
+// MatchMimeType reports whether mimeType is covered by one of the patterns,
+// which are exact types or families such as "video/*".
+func MatchMimeType(mimeType string, patterns []string) bool {
+	mimeType = strings.ToLower(mimeType)
+
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+
+		if pattern == "*/*" || pattern == mimeType {
+			return true
+		}
+
+		if strings.HasSuffix(pattern, "/*") && strings.HasPrefix(mimeType, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,54 @@
+package helper
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+)
+
+func ValidatePasswordPolicy(password string) error {
+	var missing []string
+
+	if len([]rune(password)) < config.PasswordMinLength() {
+		missing = append(missing, fmt.Sprintf("at least %d characters", config.PasswordMinLength()))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	if config.PasswordRequireUpper() && !hasUpper {
+		missing = append(missing, "an uppercase letter")
+	}
+
+	if config.PasswordRequireLower() && !hasLower {
+		missing = append(missing, "a lowercase letter")
+	}
+
+	if config.PasswordRequireDigit() && !hasDigit {
+		missing = append(missing, "a digit")
+	}
+
+	if config.PasswordRequireSymbol() && !hasSymbol {
+		missing = append(missing, "a symbol")
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("password must contain %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
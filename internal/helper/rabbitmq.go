@@ -2,6 +2,9 @@ package helper
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
@@ -9,6 +12,13 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+const (
+	HeaderRetryCount         = "x-retry-count"
+	HeaderLastError          = "x-last-error"
+	HeaderFailedAt           = "x-failed-at"
+	HeaderOriginalRoutingKey = "x-original-routing-key"
+)
+
 func PublishNotificationEvent(
 	routingKey string,
 	event model.NotificationEvent,
@@ -19,7 +29,7 @@ func PublishNotificationEvent(
 		return err
 	}
 
-	return config.RabbitChannel.Publish(
+	return config.CurrentRabbitChannel().Publish(
 		"ticket_events",
 		routingKey,
 		false,
@@ -30,3 +40,34 @@ func PublishNotificationEvent(
 		},
 	)
 }
+
+// LifecycleRoutingKeyPrefix prefixes the routing keys of lifecycle events.
+// They are published once per ticket change, without a recipient, for
+// consumers such as webhooks that react to the change itself.
+const LifecycleRoutingKeyPrefix = "lifecycle."
+
+// PublishLifecycleEvent publishes event once for the whole ticket change,
+// keyed "lifecycle.<event type>", with a fresh event ID that consumers use
+// to recognise a redelivered message.
+func PublishLifecycleEvent(event model.NotificationEvent) error {
+	eventID, err := GenerateRandomToken(16)
+	if err != nil {
+		return err
+	}
+
+	event.EventID = eventID
+	event.UserID = 0
+
+	return PublishNotificationEvent(LifecycleRoutingKeyPrefix+strings.ToLower(event.EventType), event)
+}
+
+// RetryQueueName names the delay queue for one backoff step. The delay is
+// part of the name because RabbitMQ rejects redeclaring a queue with a
+// different TTL.
+func RetryQueueName(queue string, delay time.Duration) string {
+	return fmt.Sprintf("%s.retry.%d", queue, delay.Milliseconds())
+}
+
+func DeadLetterQueueName(queue string) string {
+	return queue + ".dlq"
+}
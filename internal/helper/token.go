@@ -0,0 +1,59 @@
+package helper
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+func GenerateRandomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func sessionRevokeKey(userID int64) string {
+	return fmt.Sprintf("user:%d:sessions_revoked_at", userID)
+}
+
+func RevokeUserSessions(ctx context.Context, userID int64) error {
+	return config.Rdb.Set(
+		ctx,
+		sessionRevokeKey(userID),
+		time.Now().Unix(),
+		tokenTTL,
+	).Err()
+}
+
+func IsSessionRevoked(ctx context.Context, claim *model.CustomClaims) bool {
+	if config.Rdb == nil || claim.IssuedAt == nil {
+		return false
+	}
+
+	value, err := config.Rdb.Get(ctx, sessionRevokeKey(claim.UserID)).Result()
+	if err != nil {
+		return false
+	}
+
+	revokedAt, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	return claim.IssuedAt.Unix() < revokedAt
+}
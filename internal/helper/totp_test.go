@@ -0,0 +1,69 @@
+package helper
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 seed of the RFC 6238 test vectors,
+// "12345678901234567890", in base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestMatchTOTPRFC6238Vectors(t *testing.T) {
+	// The RFC lists 8-digit codes; the 6-digit ones are their last six digits.
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		at := time.Unix(tt.unix, 0)
+
+		step, ok := MatchTOTP(rfc6238Secret, tt.code, at)
+		if !ok {
+			t.Fatalf("MatchTOTP(%s) at %d rejected the RFC code", tt.code, tt.unix)
+		}
+
+		if step != tt.unix/totpPeriod {
+			t.Fatalf("step = %d, want %d", step, tt.unix/totpPeriod)
+		}
+	}
+}
+
+func TestMatchTOTPWindow(t *testing.T) {
+	at := time.Unix(1111111111, 0)
+
+	tests := []struct {
+		name string
+		at   time.Time
+		ok   bool
+	}{
+		{"one step of drift", at.Add(totpPeriod * time.Second), true},
+		{"two steps of drift", at.Add(2 * totpPeriod * time.Second), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := MatchTOTP(rfc6238Secret, "050471", tt.at); ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+		})
+	}
+}
+
+func TestMatchTOTPRejectsMalformedCodes(t *testing.T) {
+	at := time.Unix(59, 0)
+
+	for _, code := range []string{"", "28708", "94287082", "abcdef"} {
+		if _, ok := MatchTOTP(rfc6238Secret, code, at); ok {
+			t.Fatalf("MatchTOTP accepted %q", code)
+		}
+	}
+}
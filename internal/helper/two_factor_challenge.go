@@ -0,0 +1,65 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+)
+
+const (
+	twoFactorChallengeTTL         = 5 * time.Minute
+	twoFactorChallengeMaxAttempts = 5
+)
+
+func twoFactorChallengeKey(token string) string {
+	return fmt.Sprintf("login:2fa:%s", HashToken(token))
+}
+
+func twoFactorAttemptKey(token string) string {
+	return fmt.Sprintf("login:2fa:%s:attempts", HashToken(token))
+}
+
+func CreateTwoFactorChallenge(ctx context.Context, userID int64) (string, error) {
+	token, err := GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	if err := config.Rdb.Set(ctx, twoFactorChallengeKey(token), userID, twoFactorChallengeTTL).Err(); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func ResolveTwoFactorChallenge(ctx context.Context, token string) (int64, error) {
+	value, err := config.Rdb.Get(ctx, twoFactorChallengeKey(token)).Result()
+	if err != nil {
+		return 0, errors.New("two factor challenge is invalid or expired")
+	}
+
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// RegisterTwoFactorFailure counts a wrong code and drops the challenge once
+// the attempt limit is reached, forcing the user to log in again.
+func RegisterTwoFactorFailure(ctx context.Context, token string) {
+	attempts, err := config.Rdb.Incr(ctx, twoFactorAttemptKey(token)).Result()
+	if err != nil {
+		return
+	}
+
+	config.Rdb.Expire(ctx, twoFactorAttemptKey(token), twoFactorChallengeTTL)
+
+	if attempts >= twoFactorChallengeMaxAttempts {
+		DeleteTwoFactorChallenge(ctx, token)
+	}
+}
+
+func DeleteTwoFactorChallenge(ctx context.Context, token string) {
+	config.Rdb.Del(ctx, twoFactorChallengeKey(token), twoFactorAttemptKey(token))
+}
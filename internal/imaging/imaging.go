@@ -0,0 +1,196 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrTooManyPixels = errors.New("image has too many pixels")
+	ErrUnreadable    = errors.New("image cannot be decoded")
+)
+
+// Options sizes the renditions. Sizes are the longest side in pixels.
+type Options struct {
+	ThumbnailSize int
+	PreviewSize   int
+	JPEGQuality   int
+	MaxPixels     int
+}
+
+// Result is an uploaded photo made safe to store, plus its renditions. The
+// renditions are always JPEG.
+type Result struct {
+	Content   []byte
+	Width     int
+	Height    int
+	Thumbnail []byte
+	Preview   []byte
+}
+
+const RenditionMimeType = "image/jpeg"
+
+var supportedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Supported reports whether Process can handle the sniffed MIME type.
+func Supported(mimeType string) bool {
+	return supportedTypes[mimeType]
+}
+
+// Process strips location and camera metadata from the original, rotates
+// it upright when the EXIF orientation says so, and renders the thumbnail
+// and preview. Cameras record orientation in JPEGs, so only those are ever
+// re-encoded; everything else keeps its encoded bytes and its quality.
+func Process(data []byte, mimeType string, opts Options) (*Result, error) {
+	if !Supported(mimeType) {
+		return nil, fmt.Errorf("unsupported image type %s", mimeType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnreadable, err)
+	}
+
+	if opts.MaxPixels > 0 && cfg.Width*cfg.Height > opts.MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d", ErrTooManyPixels, cfg.Width, cfg.Height)
+	}
+
+	orientation := 1
+	if mimeType == "image/jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnreadable, err)
+	}
+
+	img := orient(toRGBA(decoded), orientation)
+
+	result := &Result{
+		Width:  img.Bounds().Dx(),
+		Height: img.Bounds().Dy(),
+	}
+
+	if orientation > 1 {
+		if result.Content, err = encodeJPEG(img, opts.JPEGQuality); err != nil {
+			return nil, err
+		}
+	} else if result.Content, err = stripMetadata(data, mimeType); err != nil {
+		return nil, err
+	}
+
+	preview := fit(img, opts.PreviewSize)
+	if result.Preview, err = encodeJPEG(preview, opts.JPEGQuality); err != nil {
+		return nil, err
+	}
+
+	if result.Thumbnail, err = encodeJPEG(fit(preview, opts.ThumbnailSize), opts.JPEGQuality); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// toRGBA copies the image into a plain RGBA buffer so rotation and scaling
+// work on raw pixels regardless of the source colour model.
+func toRGBA(src image.Image) *image.RGBA {
+	if rgba, ok := src.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Src)
+
+	return dst
+}
+
+// encodeJPEG flattens transparency onto white, since JPEG has no alpha and
+// transparent pixels would otherwise turn black.
+func encodeJPEG(img *image.RGBA, quality int) ([]byte, error) {
+	if quality <= 0 || quality > 100 {
+		quality = jpeg.DefaultQuality
+	}
+
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	var output bytes.Buffer
+
+	if err := jpeg.Encode(&output, flat, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("encode jpeg: %w", err)
+	}
+
+	return output.Bytes(), nil
+}
+
+// Render decodes an image of any supported type, turns it upright and
+// re-encodes it as a JPEG no larger than size, for embedding in documents
+// that only take a few image formats. Only the quality and pixel limit of
+// opts are used.
+func Render(data []byte, size int, opts Options) ([]byte, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnreadable, err)
+	}
+
+	if opts.MaxPixels > 0 && cfg.Width*cfg.Height > opts.MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d", ErrTooManyPixels, cfg.Width, cfg.Height)
+	}
+
+	decoded, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnreadable, err)
+	}
+
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	return encodeJPEG(fit(orient(toRGBA(decoded), orientation), size), opts.JPEGQuality)
+}
+
+// CleanPNG decodes a PNG and encodes it again, which keeps only the pixels
+// and transparency. It is meant for drawn images such as signatures, where
+// any other chunk is unexpected.
+func CleanPNG(data []byte, maxPixels int) ([]byte, image.Config, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || format != "png" {
+		return nil, cfg, fmt.Errorf("%w: not a png", ErrUnreadable)
+	}
+
+	if maxPixels > 0 && cfg.Width*cfg.Height > maxPixels {
+		return nil, cfg, fmt.Errorf("%w: %dx%d", ErrTooManyPixels, cfg.Width, cfg.Height)
+	}
+
+	decoded, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, cfg, fmt.Errorf("%w: %v", ErrUnreadable, err)
+	}
+
+	var output bytes.Buffer
+
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&output, decoded); err != nil {
+		return nil, cfg, err
+	}
+
+	return output.Bytes(), cfg, nil
+}
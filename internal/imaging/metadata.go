@@ -0,0 +1,281 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	pngSignature  = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n'}
+	exifHeader    = []byte("Exif\x00\x00")
+	iccHeader     = []byte("ICC_PROFILE\x00")
+	errBadJPEG    = errors.New("malformed jpeg")
+	errBadPNG     = errors.New("malformed png")
+	errBadWebP    = errors.New("malformed webp")
+	pngMetaChunks = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+)
+
+const (
+	markerSOS      = 0xDA
+	markerEOI      = 0xD9
+	markerAPP0     = 0xE0
+	markerAPP1     = 0xE1
+	markerAPP2     = 0xE2
+	markerAPP14    = 0xEE
+	markerCOM      = 0xFE
+	tagOrientation = 0x0112
+	webpFlagEXIF   = 0x08
+	webpFlagXMP    = 0x04
+)
+
+// stripMetadata removes EXIF, XMP, IPTC and text metadata without
+// re-encoding the pixels. GIFs carry no camera metadata and pass through.
+func stripMetadata(data []byte, mimeType string) ([]byte, error) {
+	switch mimeType {
+	case "image/jpeg":
+		return stripJPEG(data)
+	case "image/png":
+		return stripPNG(data)
+	case "image/webp":
+		return stripWebP(data)
+	}
+
+	return data, nil
+}
+
+type jpegSegment struct {
+	marker byte
+	start  int
+	end    int
+	data   []byte
+}
+
+// jpegSegments walks the header segments up to and including the start of
+// scan, which is followed by entropy-coded data instead of more segments.
+func jpegSegments(data []byte) ([]jpegSegment, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, errBadJPEG
+	}
+
+	var segments []jpegSegment
+
+	for pos := 2; pos+4 <= len(data); {
+		if data[pos] != 0xFF {
+			return nil, errBadJPEG
+		}
+
+		marker := data[pos+1]
+		if marker == 0xFF {
+			// Fill byte before a marker.
+			pos++
+			continue
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			return nil, errBadJPEG
+		}
+
+		segments = append(segments, jpegSegment{
+			marker: marker,
+			start:  pos,
+			end:    pos + 2 + length,
+			data:   data[pos+4 : pos+2+length],
+		})
+
+		if marker == markerSOS {
+			return segments, nil
+		}
+
+		pos += 2 + length
+	}
+
+	return nil, errBadJPEG
+}
+
+// stripJPEG keeps the JFIF, ICC profile and Adobe segments, which affect
+// how colours are decoded, and drops every other APPn and comment. Data
+// after the end-of-image marker, such as the extra pictures some phones
+// append, is dropped too since it can carry its own EXIF block.
+func stripJPEG(data []byte) ([]byte, error) {
+	segments, err := jpegSegments(data)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make([]byte, 0, len(data))
+	output = append(output, 0xFF, 0xD8)
+
+	for _, segment := range segments {
+		keep := true
+
+		switch {
+		case segment.marker == markerCOM:
+			keep = false
+		case segment.marker == markerAPP2:
+			keep = bytes.HasPrefix(segment.data, iccHeader)
+		case segment.marker > markerAPP0 && segment.marker <= 0xEF && segment.marker != markerAPP14:
+			keep = false
+		}
+
+		if keep {
+			output = append(output, data[segment.start:segment.end]...)
+		}
+	}
+
+	scan := data[segments[len(segments)-1].end:]
+
+	if end := bytes.Index(scan, []byte{0xFF, markerEOI}); end >= 0 {
+		scan = scan[:end+2]
+	}
+
+	return append(output, scan...), nil
+}
+
+// jpegOrientation reads the EXIF orientation tag, defaulting to 1 (upright)
+// when there is none or it cannot be parsed.
+func jpegOrientation(data []byte) int {
+	segments, err := jpegSegments(data)
+	if err != nil {
+		return 1
+	}
+
+	for _, segment := range segments {
+		if segment.marker != markerAPP1 || !bytes.HasPrefix(segment.data, exifHeader) {
+			continue
+		}
+
+		if orientation := tiffOrientation(segment.data[len(exifHeader):]); orientation != 0 {
+			return orientation
+		}
+	}
+
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+
+	count := int(order.Uint16(tiff[ifd:]))
+
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+
+		if order.Uint16(tiff[entry:]) != tagOrientation {
+			continue
+		}
+
+		orientation := int(order.Uint16(tiff[entry+8:]))
+		if orientation < 1 || orientation > 8 {
+			return 0
+		}
+
+		return orientation
+	}
+
+	return 0
+}
+
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, errBadPNG
+	}
+
+	output := make([]byte, 0, len(data))
+	output = append(output, pngSignature...)
+
+	for pos := len(pngSignature); pos < len(data); {
+		if pos+8 > len(data) {
+			return nil, errBadPNG
+		}
+
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		chunkType := string(data[pos+4 : pos+8])
+		end := pos + 12 + length
+
+		if length < 0 || end > len(data) {
+			return nil, errBadPNG
+		}
+
+		if !pngMetaChunks[chunkType] {
+			output = append(output, data[pos:end]...)
+		}
+
+		pos = end
+
+		if chunkType == "IEND" {
+			break
+		}
+	}
+
+	return output, nil
+}
+
+// stripWebP drops the EXIF and XMP chunks and clears their flags in the
+// extended header, then rewrites the RIFF size.
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errBadWebP
+	}
+
+	output := make([]byte, 12, len(data))
+	copy(output, data[:12])
+
+	for pos := 12; pos < len(data); {
+		if pos+8 > len(data) {
+			return nil, errBadWebP
+		}
+
+		fourCC := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size + size%2
+
+		if size < 0 || end > len(data) {
+			if pos+8+size != len(data) {
+				return nil, errBadWebP
+			}
+			// Some encoders omit the padding byte on the last chunk.
+			end = len(data)
+		}
+
+		switch fourCC {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte(nil), data[pos:end]...)
+			if size > 0 {
+				chunk[8] &^= webpFlagEXIF | webpFlagXMP
+			}
+			output = append(output, chunk...)
+		default:
+			output = append(output, data[pos:end]...)
+		}
+
+		pos = end
+	}
+
+	binary.LittleEndian.PutUint32(output[4:], uint32(len(output)-8))
+
+	return output, nil
+}
@@ -0,0 +1,78 @@
+package imaging
+
+import (
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+// orient applies an EXIF orientation (1-8) so the pixels are upright.
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	width := src.Bounds().Dx()
+	height := src.Bounds().Dy()
+
+	// Orientations 5-8 swap the axes.
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = width-1-x, y
+			case 3: // rotated 180
+				dx, dy = width-1-x, height-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, height-1-y
+			case 5: // mirrored along the top-left diagonal
+				dx, dy = y, x
+			case 6: // rotated 90 clockwise
+				dx, dy = height-1-y, x
+			case 7: // mirrored along the top-right diagonal
+				dx, dy = height-1-y, width-1-x
+			case 8: // rotated 90 counter-clockwise
+				dx, dy = y, width-1-x
+			}
+
+			si := src.PixOffset(x, y)
+			di := dst.PixOffset(dx, dy)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+
+	return dst
+}
+
+// fit scales the image down so its longest side is at most size, keeping
+// the aspect ratio. Smaller images are returned as they are.
+func fit(src *image.RGBA, size int) *image.RGBA {
+	width := src.Bounds().Dx()
+	height := src.Bounds().Dy()
+
+	if size <= 0 || (width <= size && height <= size) {
+		return src
+	}
+
+	dstWidth, dstHeight := size, height*size/width
+	if height > width {
+		dstWidth, dstHeight = width*size/height, size
+	}
+
+	dstWidth = max(dstWidth, 1)
+	dstHeight = max(dstHeight, 1)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Src, nil)
+
+	return dst
+}
@@ -0,0 +1,37 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type FileSender struct {
+	dir  string
+	from string
+}
+
+func NewFileSender(dir string, from string) model.IMailSender {
+	return &FileSender{
+		dir:  dir,
+		from: from,
+	}
+}
+
+func (s *FileSender) Send(ctx context.Context, mail model.Mail) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%d.eml", time.Now().UnixNano())
+
+	return os.WriteFile(
+		filepath.Join(s.dir, name),
+		buildMessage(s.from, mail),
+		0o644,
+	)
+}
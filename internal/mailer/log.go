@@ -0,0 +1,24 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type LogSender struct{}
+
+func NewLogSender() model.IMailSender {
+	return &LogSender{}
+}
+
+func (s *LogSender) Send(ctx context.Context, mail model.Mail) error {
+	logrus.WithFields(logrus.Fields{
+		"to":          mail.To,
+		"subject":     mail.Subject,
+		"attachments": len(mail.Attachments),
+	}).Infof("[MAIL] %s", mail.Body)
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package mailer
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+func NewSender() model.IMailSender {
+	switch config.MailDriver() {
+	case "smtp":
+		return NewSMTPSender(
+			config.SMTPHost(),
+			config.SMTPPort(),
+			config.SMTPUsername(),
+			config.SMTPPassword(),
+			config.MailFrom(),
+		)
+	case "file":
+		return NewFileSender(config.MailFileDir(), config.MailFrom())
+	case "log":
+		return NewLogSender()
+	default:
+		logrus.Warnf("unknown mail driver %q, falling back to log", config.MailDriver())
+		return NewLogSender()
+	}
+}
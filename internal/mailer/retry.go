@@ -0,0 +1,58 @@
+package mailer
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type RetrySender struct {
+	sender   model.IMailSender
+	attempts int
+	backoff  time.Duration
+}
+
+// NewRetrySender wraps a sender and retries failed deliveries with an
+// exponential backoff starting at the given duration.
+func NewRetrySender(sender model.IMailSender, attempts int, backoff time.Duration) model.IMailSender {
+	return &RetrySender{
+		sender:   sender,
+		attempts: attempts,
+		backoff:  backoff,
+	}
+}
+
+func (s *RetrySender) Send(ctx context.Context, mail model.Mail) error {
+	var err error
+
+	wait := s.backoff
+
+	for attempt := 1; attempt <= s.attempts; attempt++ {
+		err = s.sender.Send(ctx, mail)
+		if err == nil {
+			return nil
+		}
+
+		if attempt == s.attempts {
+			break
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"to":      mail.To,
+			"subject": mail.Subject,
+			"attempt": attempt,
+		}).Warn("failed send mail, retrying:", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+
+		wait *= 2
+	}
+
+	return err
+}
@@ -0,0 +1,141 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type SMTPSender struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPSender(host string, port int, username string, password string, from string) model.IMailSender {
+	return &SMTPSender{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (s *SMTPSender) Send(ctx context.Context, mail model.Mail) error {
+	if len(mail.To) == 0 {
+		return fmt.Errorf("mail has no recipient")
+	}
+
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	addr := fmt.Sprintf("%s:%d", s.host, s.port)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- smtp.SendMail(addr, auth, s.from, mail.To, buildMessage(s.from, mail))
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-errCh:
+		return err
+	}
+}
+
+func buildMessage(from string, mail model.Mail) []byte {
+	var msg bytes.Buffer
+
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(mail.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mail.Subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+
+	if len(mail.Attachments) == 0 {
+		writeContent(&msg, mail)
+		return msg.Bytes()
+	}
+
+	boundary := fmt.Sprintf("helpdesk-mixed-%d", time.Now().UnixNano())
+
+	fmt.Fprintf(&msg, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", boundary)
+
+	fmt.Fprintf(&msg, "--%s\r\n", boundary)
+	writeContent(&msg, mail)
+	msg.WriteString("\r\n")
+
+	for _, attachment := range mail.Attachments {
+		fmt.Fprintf(&msg, "--%s\r\n", boundary)
+		writeAttachment(&msg, attachment)
+	}
+
+	fmt.Fprintf(&msg, "--%s--\r\n", boundary)
+
+	return msg.Bytes()
+}
+
+// writeContent writes the Content-Type header and body of the readable
+// part: plain text, or plain text with an HTML alternative.
+func writeContent(msg *bytes.Buffer, mail model.Mail) {
+	if mail.HTML == "" {
+		msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+		msg.WriteString(mail.Body)
+		return
+	}
+
+	boundary := fmt.Sprintf("helpdesk-%d", time.Now().UnixNano())
+
+	fmt.Fprintf(msg, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", boundary)
+
+	fmt.Fprintf(msg, "--%s\r\n", boundary)
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(mail.Body)
+	msg.WriteString("\r\n")
+
+	fmt.Fprintf(msg, "--%s\r\n", boundary)
+	msg.WriteString("Content-Type: text/html; charset=UTF-8\r\n\r\n")
+	msg.WriteString(mail.HTML)
+	msg.WriteString("\r\n")
+
+	fmt.Fprintf(msg, "--%s--\r\n", boundary)
+}
+
+// writeAttachment base64 encodes the file in lines of 76 characters, the
+// limit MIME sets for encoded bodies.
+func writeAttachment(msg *bytes.Buffer, attachment model.MailAttachment) {
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	filename := mime.QEncoding.Encode("UTF-8", attachment.Filename)
+
+	fmt.Fprintf(msg, "Content-Type: %s; name=\"%s\"\r\n", contentType, filename)
+	msg.WriteString("Content-Transfer-Encoding: base64\r\n")
+	fmt.Fprintf(msg, "Content-Disposition: attachment; filename=\"%s\"\r\n\r\n", filename)
+
+	encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+
+	for len(encoded) > 76 {
+		msg.WriteString(encoded[:76])
+		msg.WriteString("\r\n")
+		encoded = encoded[76:]
+	}
+
+	msg.WriteString(encoded)
+	msg.WriteString("\r\n")
+}
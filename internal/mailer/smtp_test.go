@@ -0,0 +1,228 @@
+package mailer
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-sasl"
+	"github.com/emersion/go-smtp"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type receivedMail struct {
+	from string
+	to   []string
+	data []byte
+}
+
+// fakeSMTPServer accepts mail on a loopback port and keeps every message.
+// The first deliveries, as many as failures, get a temporary error so the
+// retry path can be exercised.
+type fakeSMTPServer struct {
+	host string
+	port int
+
+	mu       sync.Mutex
+	failures int
+	authed   []string
+	received []receivedMail
+}
+
+func newFakeSMTPServer(t *testing.T, failures int) *fakeSMTPServer {
+	t.Helper()
+
+	fake := &fakeSMTPServer{failures: failures}
+
+	server := smtp.NewServer(smtp.BackendFunc(func(c *smtp.Conn) (smtp.Session, error) {
+		return &fakeSMTPSession{server: fake}, nil
+	}))
+	server.Domain = "localhost"
+	server.AllowInsecureAuth = true
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+
+	addr := listener.Addr().(*net.TCPAddr)
+	fake.host = addr.IP.String()
+	fake.port = addr.Port
+
+	return fake
+}
+
+type fakeSMTPSession struct {
+	server *fakeSMTPServer
+	mail   receivedMail
+}
+
+func (s *fakeSMTPSession) AuthMechanisms() []string {
+	return []string{sasl.Plain}
+}
+
+func (s *fakeSMTPSession) Auth(mech string) (sasl.Server, error) {
+	return sasl.NewPlainServer(func(identity, username, password string) error {
+		s.server.mu.Lock()
+		defer s.server.mu.Unlock()
+
+		s.server.authed = append(s.server.authed, username+":"+password)
+		return nil
+	}), nil
+}
+
+func (s *fakeSMTPSession) Mail(from string, opts *smtp.MailOptions) error {
+	s.mail.from = from
+	return nil
+}
+
+func (s *fakeSMTPSession) Rcpt(to string, opts *smtp.RcptOptions) error {
+	s.mail.to = append(s.mail.to, to)
+	return nil
+}
+
+func (s *fakeSMTPSession) Data(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	s.server.mu.Lock()
+	defer s.server.mu.Unlock()
+
+	if s.server.failures > 0 {
+		s.server.failures--
+		return &smtp.SMTPError{Code: 451, EnhancedCode: smtp.EnhancedCode{4, 3, 0}, Message: "try again"}
+	}
+
+	s.mail.data = data
+	s.server.received = append(s.server.received, s.mail)
+
+	return nil
+}
+
+func (s *fakeSMTPSession) Reset() {
+	s.mail = receivedMail{}
+}
+
+func (s *fakeSMTPSession) Logout() error {
+	return nil
+}
+
+func renderAssigned(t *testing.T, language string) model.Mail {
+	t.Helper()
+
+	subject, body, err := RenderNotification(language, "en", "TICKET_ASSIGNED", NotificationTemplateData{
+		RecipientName: "Budi",
+		ActorName:     "Jane",
+		TicketCode:    "NUT-20260101-0001",
+		Message:       "Printer offline",
+		TicketURL:     "https://helpdesk.example.com/tickets/1",
+	})
+	if err != nil {
+		t.Fatalf("RenderNotification: %v", err)
+	}
+
+	return model.Mail{
+		To:      []string{"budi@example.com"},
+		Subject: subject,
+		Body:    body,
+	}
+}
+
+func TestSMTPSenderDeliversTemplatedMail(t *testing.T) {
+	server := newFakeSMTPServer(t, 0)
+
+	sender := NewSMTPSender(server.host, server.port, "helpdesk", "secret", "helpdesk@example.com")
+
+	if err := sender.Send(context.Background(), renderAssigned(t, "id")); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if len(server.received) != 1 {
+		t.Fatalf("received %d mails, want 1", len(server.received))
+	}
+
+	got := server.received[0]
+
+	if got.from != "helpdesk@example.com" || strings.Join(got.to, ",") != "budi@example.com" {
+		t.Fatalf("envelope = %s -> %v", got.from, got.to)
+	}
+
+	if len(server.authed) != 1 || server.authed[0] != "helpdesk:secret" {
+		t.Fatalf("auth = %v", server.authed)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(got.data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if subject := msg.Header.Get("Subject"); subject != "[NUT-20260101-0001] Tiket ditugaskan kepada Anda" {
+		t.Fatalf("Subject = %q", subject)
+	}
+
+	body, _ := io.ReadAll(msg.Body)
+	for _, want := range []string{"Halo Budi", "Printer offline", "https://helpdesk.example.com/tickets/1"} {
+		if !strings.Contains(string(body), want) {
+			t.Fatalf("body does not contain %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestRenderNotificationFallsBackToDefaultLanguage(t *testing.T) {
+	mail := renderAssigned(t, "fr")
+
+	if mail.Subject != "[NUT-20260101-0001] Ticket assigned to you" {
+		t.Fatalf("Subject = %q", mail.Subject)
+	}
+
+	if _, _, err := RenderNotification("en", "en", "TICKET_UNKNOWN", NotificationTemplateData{}); err != ErrTemplateNotFound {
+		t.Fatalf("err = %v, want ErrTemplateNotFound", err)
+	}
+}
+
+func TestRetrySenderRetriesTemporaryFailures(t *testing.T) {
+	server := newFakeSMTPServer(t, 2)
+
+	sender := NewRetrySender(
+		NewSMTPSender(server.host, server.port, "", "", "helpdesk@example.com"),
+		3,
+		time.Millisecond,
+	)
+
+	if err := sender.Send(context.Background(), renderAssigned(t, "en")); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if len(server.received) != 1 {
+		t.Fatalf("received %d mails, want 1", len(server.received))
+	}
+}
+
+func TestRetrySenderGivesUp(t *testing.T) {
+	server := newFakeSMTPServer(t, 5)
+
+	sender := NewRetrySender(
+		NewSMTPSender(server.host, server.port, "", "", "helpdesk@example.com"),
+		2,
+		time.Millisecond,
+	)
+
+	err := sender.Send(context.Background(), renderAssigned(t, "en"))
+	if err == nil || !strings.HasPrefix(err.Error(), "451") {
+		t.Fatalf("err = %v, want 451", err)
+	}
+
+	if len(server.received) != 0 || server.failures != 3 {
+		t.Fatalf("received = %d, failures left = %d", len(server.received), server.failures)
+	}
+}
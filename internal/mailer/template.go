@@ -0,0 +1,68 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*/*.tmpl
+var templateFS embed.FS
+
+var ErrTemplateNotFound = errors.New("mail template not found")
+
+type NotificationTemplateData struct {
+	RecipientName string
+	ActorName     string
+	TicketCode    string
+	Title         string
+	Message       string
+	TicketURL     string
+}
+
+// RenderNotification renders the subject and body for an event type such
+// as TICKET_ASSIGNED, falling back to the given language when the requested
+// one has no template.
+func RenderNotification(language string, fallback string, eventType string, data NotificationTemplateData) (string, string, error) {
+	name := strings.ToLower(eventType) + ".tmpl"
+
+	tmpl, err := loadTemplate(language, name)
+	if errors.Is(err, fs.ErrNotExist) && fallback != language {
+		tmpl, err = loadTemplate(fallback, name)
+	}
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", "", ErrTemplateNotFound
+	}
+
+	if err != nil {
+		return "", "", err
+	}
+
+	var subject, body bytes.Buffer
+
+	if err := tmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return "", "", err
+	}
+
+	if err := tmpl.ExecuteTemplate(&body, "body", data); err != nil {
+		return "", "", err
+	}
+
+	return strings.TrimSpace(subject.String()), body.String(), nil
+}
+
+func loadTemplate(language string, name string) (*template.Template, error) {
+	path := fmt.Sprintf("templates/%s/%s", language, name)
+
+	content, err := fs.ReadFile(templateFS, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return template.New(name).Parse(string(content))
+}
@@ -0,0 +1,200 @@
+package mailin
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+var (
+	wordDecoder = &mime.WordDecoder{}
+	htmlTagRe   = regexp.MustCompile(`(?s)<[^>]*>`)
+	replyLineRe = regexp.MustCompile(`(?i)^(on .+ wrote:|pada .+ menulis:|-----original message-----)$`)
+)
+
+// Parse reads a raw RFC 5322 message and extracts the parts the helpdesk
+// cares about: sender, subject, a plain text body and any attachments.
+func Parse(r io.Reader) (*model.InboundMail, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := mail.ParseAddress(msg.Header.Get("From"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid From header: %w", err)
+	}
+
+	subject, err := wordDecoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+
+	in := &model.InboundMail{
+		MessageID: strings.Trim(msg.Header.Get("Message-Id"), "<> "),
+		From:      strings.ToLower(from.Address),
+		FromName:  from.Name,
+		Subject:   strings.TrimSpace(subject),
+
+		AuthenticationResults: msg.Header["Authentication-Results"],
+	}
+
+	var plain, htmlBody string
+
+	err = walkPart(
+		msg.Header.Get("Content-Type"),
+		msg.Header.Get("Content-Transfer-Encoding"),
+		"",
+		msg.Body,
+		in,
+		&plain,
+		&htmlBody,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if plain == "" && htmlBody != "" {
+		plain = html.UnescapeString(htmlTagRe.ReplaceAllString(htmlBody, ""))
+	}
+
+	in.Body = strings.TrimSpace(plain)
+	in.Reply = stripQuoted(in.Body)
+
+	return in, nil
+}
+
+func walkPart(contentType, encoding, disposition string, body io.Reader, in *model.InboundMail, plain, htmlBody *string) error {
+	if contentType == "" {
+		contentType = "text/plain"
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "application/octet-stream"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		boundary := params["boundary"]
+		if boundary == "" {
+			return errors.New("multipart message without boundary")
+		}
+
+		mr := multipart.NewReader(body, boundary)
+		for {
+			part, err := mr.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			err = walkPart(
+				part.Header.Get("Content-Type"),
+				part.Header.Get("Content-Transfer-Encoding"),
+				part.Header.Get("Content-Disposition"),
+				part,
+				in,
+				plain,
+				htmlBody,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := io.ReadAll(decodeTransfer(encoding, body))
+	if err != nil {
+		return err
+	}
+
+	filename := attachmentName(disposition, params)
+
+	if filename == "" && mediaType == "text/plain" && *plain == "" {
+		*plain = string(data)
+		return nil
+	}
+
+	if filename == "" && mediaType == "text/html" && *htmlBody == "" {
+		*htmlBody = string(data)
+		return nil
+	}
+
+	if filename == "" {
+		if strings.HasPrefix(mediaType, "text/") {
+			return nil
+		}
+		filename = "attachment"
+	}
+
+	in.Attachments = append(in.Attachments, model.InboundAttachment{
+		Filename:    filename,
+		ContentType: mediaType,
+		Data:        data,
+	})
+
+	return nil
+}
+
+func decodeTransfer(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	default:
+		return r
+	}
+}
+
+func attachmentName(disposition string, typeParams map[string]string) string {
+	if disposition != "" {
+		if _, params, err := mime.ParseMediaType(disposition); err == nil && params["filename"] != "" {
+			return decodeWord(params["filename"])
+		}
+	}
+
+	return decodeWord(typeParams["name"])
+}
+
+func decodeWord(s string) string {
+	decoded, err := wordDecoder.DecodeHeader(s)
+	if err != nil {
+		return s
+	}
+	return decoded
+}
+
+// stripQuoted drops the quoted history most clients append to replies so
+// only the new text ends up in the ticket comment.
+func stripQuoted(body string) string {
+	var lines []string
+
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if replyLineRe.MatchString(trimmed) {
+			break
+		}
+
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+
+		lines = append(lines, strings.TrimRight(line, "\r"))
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
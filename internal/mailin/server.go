@@ -0,0 +1,214 @@
+package mailin
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-sasl"
+	"github.com/emersion/go-smtp"
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+// NewServer builds an SMTP (or LMTP, depending on config) listener that
+// hands every accepted message to the ingest usecase. Only relays on the
+// allow-list or clients that authenticate may send mail.
+func NewServer(ingest model.IMailIngestUsecase) *smtp.Server {
+	allowed := parseAllowedIPs(config.MailIngestAllowedIPs())
+
+	if len(allowed) == 0 && config.MailIngestUsername() == "" {
+		logrus.Warn("mail ingest has no allowed_ips or credentials, all mail will be refused")
+	}
+
+	backend := smtp.BackendFunc(func(c *smtp.Conn) (smtp.Session, error) {
+		return &session{
+			ingest:  ingest,
+			trusted: ipAllowed(c.Conn().RemoteAddr(), allowed),
+		}, nil
+	})
+
+	s := smtp.NewServer(backend)
+
+	s.Addr = config.MailIngestAddr()
+	s.Domain = config.MailIngestDomain()
+	s.LMTP = config.MailIngestProtocol() == "lmtp"
+	s.MaxMessageBytes = config.MailIngestMaxMessageBytes()
+	s.MaxRecipients = 50
+	s.AllowInsecureAuth = config.MailIngestAllowInsecureAuth()
+	s.ReadTimeout = time.Minute
+	s.WriteTimeout = time.Minute
+
+	return s
+}
+
+type session struct {
+	ingest        model.IMailIngestUsecase
+	trusted       bool
+	authenticated bool
+	from          string
+}
+
+func (s *session) AuthMechanisms() []string {
+	if config.MailIngestUsername() == "" {
+		return nil
+	}
+
+	return []string{sasl.Plain}
+}
+
+func (s *session) Auth(mech string) (sasl.Server, error) {
+	username := config.MailIngestUsername()
+	if username == "" || mech != sasl.Plain {
+		return nil, smtp.ErrAuthUnknownMechanism
+	}
+
+	return sasl.NewPlainServer(func(identity, user, password string) error {
+		userOK := subtle.ConstantTimeCompare([]byte(user), []byte(username)) == 1
+		passOK := subtle.ConstantTimeCompare([]byte(password), []byte(config.MailIngestPassword())) == 1
+
+		if !userOK || !passOK {
+			return smtp.ErrAuthFailed
+		}
+
+		s.authenticated = true
+		return nil
+	}), nil
+}
+
+func (s *session) Mail(from string, opts *smtp.MailOptions) error {
+	if !s.trusted && !s.authenticated {
+		return smtp.ErrAuthRequired
+	}
+
+	s.from = from
+	return nil
+}
+
+func (s *session) Rcpt(to string, opts *smtp.RcptOptions) error {
+	allowed := config.MailIngestRecipients()
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	for _, address := range allowed {
+		if strings.EqualFold(address, to) {
+			return nil
+		}
+	}
+
+	return &smtp.SMTPError{
+		Code:         550,
+		EnhancedCode: smtp.EnhancedCode{5, 1, 1},
+		Message:      "mailbox unavailable",
+	}
+}
+
+func (s *session) Data(r io.Reader) error {
+	log := logrus.WithFields(logrus.Fields{
+		"envelope_from": s.from,
+	})
+
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	mail, err := Parse(bytes.NewReader(raw))
+	if err != nil {
+		log.Warn("failed parse inbound mail:", err)
+		return &smtp.SMTPError{
+			Code:         550,
+			EnhancedCode: smtp.EnhancedCode{5, 6, 0},
+			Message:      "message could not be parsed",
+		}
+	}
+
+	mail.EnvelopeFrom = strings.ToLower(s.from)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	if err := s.ingest.Ingest(ctx, *mail); err != nil {
+		if errors.Is(err, model.ErrMailRejected) {
+			log.Info("inbound mail rejected: ", err)
+			return &smtp.SMTPError{
+				Code:         550,
+				EnhancedCode: smtp.EnhancedCode{5, 7, 1},
+				Message:      err.Error(),
+			}
+		}
+
+		log.Error("failed ingest inbound mail:", err)
+		return &smtp.SMTPError{
+			Code:         451,
+			EnhancedCode: smtp.EnhancedCode{4, 3, 0},
+			Message:      "temporary failure, please retry",
+		}
+	}
+
+	return nil
+}
+
+func (s *session) Reset() {
+	s.from = ""
+}
+
+func (s *session) Logout() error {
+	return nil
+}
+
+func parseAllowedIPs(entries []string) []*net.IPNet {
+	var nets []*net.IPNet
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			logrus.Warnf("mail ingest: ignoring invalid allowed_ips entry %q", entry)
+			continue
+		}
+
+		nets = append(nets, ipNet)
+	}
+
+	return nets
+}
+
+func ipAllowed(addr net.Addr, allowed []*net.IPNet) bool {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return false
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, ipNet := range allowed {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,246 @@
+package mailin_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/mailin"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/usecase"
+)
+
+const ticketCode = "NUT-20260101-0001"
+
+type fakeUserRepo struct {
+	model.IUserRepository
+	user *model.User
+}
+
+func (r *fakeUserRepo) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	if email == r.user.Email {
+		return r.user, nil
+	}
+	return nil, model.ErrUserNotFound
+}
+
+func (r *fakeUserRepo) FindByID(ctx context.Context, id int64) (*model.User, error) {
+	if id == r.user.ID {
+		return r.user, nil
+	}
+	return nil, model.ErrUserNotFound
+}
+
+type fakeTicketRepo struct {
+	model.ITicketRepository
+	ticket *model.Ticket
+}
+
+func (r *fakeTicketRepo) FindByCode(ctx context.Context, code string) (*model.Ticket, error) {
+	if code == r.ticket.TicketCode {
+		return r.ticket, nil
+	}
+	return nil, errors.New("ticket not found")
+}
+
+// recorder keeps what the ingest pipeline asked the ticket and comment
+// usecases to do.
+type recorder struct {
+	mu       sync.Mutex
+	tickets  []model.CreateTicketInput
+	comments []model.TicketComment
+}
+
+type ticketRecorder struct {
+	model.ITicketUsecase
+	*recorder
+}
+
+func (r ticketRecorder) Create(ctx context.Context, reporterID int64, in model.CreateTicketInput, files []model.AttachmentUpload) (*model.Ticket, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tickets = append(r.tickets, in)
+	return &model.Ticket{ID: 2, TicketCode: "NUT-20260101-0002"}, false, nil
+}
+
+type commentRecorder struct {
+	model.ITicketCommentUsecase
+	*recorder
+}
+
+func (r commentRecorder) Create(ctx context.Context, comment model.TicketComment, files []model.AttachmentUpload) (*model.TicketComment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.comments = append(r.comments, comment)
+	return &comment, nil
+}
+
+// startServer runs the ingest listener on a loopback port with the given
+// allow-list and returns its address.
+func startServer(t *testing.T, allowedIPs []string) (string, *recorder) {
+	t.Helper()
+
+	settings := map[string]interface{}{
+		"mail_ingest.allowed_ips":         allowedIPs,
+		"mail_ingest.default_project_id":  1,
+		"mail_ingest.default_location_id": 1,
+		"mail_ingest.default_part_id":     1,
+		"mail_ingest.default_asset_id":    1,
+	}
+	for key, value := range settings {
+		viper.Set(key, value)
+	}
+	t.Cleanup(func() {
+		for key := range settings {
+			viper.Set(key, nil)
+		}
+	})
+
+	user := &model.User{ID: 7, Email: "jane@example.com", IsActive: true}
+	ticket := &model.Ticket{ID: 1, TicketCode: ticketCode, ReporterID: user.ID, Status: model.StatusOpen}
+
+	rec := &recorder{}
+	ingest := usecase.NewMailIngestUsecase(
+		&fakeUserRepo{user: user},
+		&fakeTicketRepo{ticket: ticket},
+		ticketRecorder{recorder: rec},
+		commentRecorder{recorder: rec},
+	)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := mailin.NewServer(ingest)
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+
+	return listener.Addr().String(), rec
+}
+
+func message(from string, subject string, body string) []byte {
+	return []byte(strings.Join([]string{
+		"From: Jane <" + from + ">",
+		"To: helpdesk@example.com",
+		"Subject: " + subject,
+		"Message-Id: <" + subject + "@example.com>",
+		"Content-Type: text/plain; charset=utf-8",
+		"",
+		body,
+	}, "\r\n"))
+}
+
+func TestReplyIsThreadedByTicketCode(t *testing.T) {
+	addr, rec := startServer(t, []string{"127.0.0.1"})
+
+	body := "Printer works again, thanks.\r\n\r\nOn Mon, 1 Jan 2026 Helpdesk wrote:\r\n> Please restart the printer."
+
+	err := smtp.SendMail(addr, nil, "jane@example.com", []string{"helpdesk@example.com"},
+		message("jane@example.com", "Re: ["+ticketCode+"] Printer offline", body))
+	if err != nil {
+		t.Fatalf("SendMail: %v", err)
+	}
+
+	if len(rec.tickets) != 0 {
+		t.Fatalf("reply created %d tickets", len(rec.tickets))
+	}
+
+	if len(rec.comments) != 1 {
+		t.Fatalf("comments = %d, want 1", len(rec.comments))
+	}
+
+	comment := rec.comments[0]
+	if comment.TicketID != 1 || comment.UserID != 7 {
+		t.Fatalf("comment = %+v", comment)
+	}
+
+	if comment.Message != "Printer works again, thanks." {
+		t.Fatalf("quoted history was not stripped: %q", comment.Message)
+	}
+}
+
+func TestMailWithoutTicketCodeCreatesTicket(t *testing.T) {
+	addr, rec := startServer(t, []string{"127.0.0.1/8"})
+
+	err := smtp.SendMail(addr, nil, "jane@example.com", []string{"helpdesk@example.com"},
+		message("jane@example.com", "Printer offline", "The printer on floor 2 is offline."))
+	if err != nil {
+		t.Fatalf("SendMail: %v", err)
+	}
+
+	if len(rec.comments) != 0 || len(rec.tickets) != 1 {
+		t.Fatalf("tickets = %d, comments = %d", len(rec.tickets), len(rec.comments))
+	}
+
+	if !strings.Contains(rec.tickets[0].Description, "floor 2") {
+		t.Fatalf("description = %q", rec.tickets[0].Description)
+	}
+}
+
+func TestUntrustedClientMustAuthenticate(t *testing.T) {
+	addr, rec := startServer(t, nil)
+
+	err := smtp.SendMail(addr, nil, "jane@example.com", []string{"helpdesk@example.com"},
+		message("jane@example.com", "Printer offline", "Body"))
+
+	if err == nil || !strings.Contains(err.Error(), "authenticate") {
+		t.Fatalf("err = %v, want authentication required", err)
+	}
+
+	if len(rec.tickets) != 0 {
+		t.Fatal("unauthenticated mail created a ticket")
+	}
+}
+
+func TestAuthenticatedClientIsAccepted(t *testing.T) {
+	viper.Set("mail_ingest.username", "relay")
+	viper.Set("mail_ingest.password", "secret")
+	viper.Set("mail_ingest.allow_insecure_auth", true)
+	t.Cleanup(func() {
+		viper.Set("mail_ingest.username", nil)
+		viper.Set("mail_ingest.password", nil)
+		viper.Set("mail_ingest.allow_insecure_auth", nil)
+	})
+
+	addr, rec := startServer(t, nil)
+
+	host, _, _ := net.SplitHostPort(addr)
+
+	err := smtp.SendMail(addr, smtp.PlainAuth("", "relay", "wrong", host), "jane@example.com", []string{"helpdesk@example.com"},
+		message("jane@example.com", "Printer offline", "Body"))
+	if err == nil {
+		t.Fatal("wrong password was accepted")
+	}
+
+	err = smtp.SendMail(addr, smtp.PlainAuth("", "relay", "secret", host), "jane@example.com", []string{"helpdesk@example.com"},
+		message("jane@example.com", "Printer offline", "Body"))
+	if err != nil {
+		t.Fatalf("SendMail: %v", err)
+	}
+
+	if len(rec.tickets) != 1 {
+		t.Fatalf("tickets = %d, want 1", len(rec.tickets))
+	}
+}
+
+func TestForgedFromIsRejected(t *testing.T) {
+	addr, rec := startServer(t, []string{"127.0.0.1"})
+
+	err := smtp.SendMail(addr, nil, "mallory@evil.example", []string{"helpdesk@example.com"},
+		message("jane@example.com", "Re: ["+ticketCode+"] Printer offline", "Close it."))
+	if err == nil || !strings.HasPrefix(err.Error(), "550") {
+		t.Fatalf("err = %v, want 550", err)
+	}
+
+	if len(rec.comments) != 0 {
+		t.Fatal("forged mail was added as a comment")
+	}
+}
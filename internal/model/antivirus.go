@@ -0,0 +1,16 @@
+package model
+
+import (
+	"context"
+	"io"
+)
+
+type ScanResult struct {
+	Infected  bool
+	Signature string
+}
+
+// IVirusScanner inspects file content before it is stored.
+type IVirusScanner interface {
+	Scan(ctx context.Context, r io.Reader) (*ScanResult, error)
+}
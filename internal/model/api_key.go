@@ -0,0 +1,75 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+const APIKeyPrefix = "hdk_"
+
+const (
+	ScopeTicketsRead  = "tickets:read"
+	ScopeTicketsWrite = "tickets:write"
+	ScopeMasterRead   = "master:read"
+)
+
+type APIKey struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `gorm:"serializer:json" json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedBy  int64      `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// CreatedAPIKey carries the plaintext key, which is only shown once.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+type CreateServiceAccountInput struct {
+	Name     string           `json:"name" validate:"required"`
+	Email    string           `json:"email" validate:"required,email"`
+	RoleID   int64            `json:"role_id" validate:"required"`
+	Projects []ProjectPayload `json:"projects"`
+}
+
+type CreateAPIKeyInput struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=tickets:read tickets:write master:read"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type RotateAPIKeyInput struct {
+	GracePeriodHours int        `json:"grace_period_hours" validate:"min=0,max=168"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+}
+
+type IAPIKeyRepository interface {
+	Create(ctx context.Context, key APIKey) (*APIKey, error)
+	FindByID(ctx context.Context, id int64) (*APIKey, error)
+	FindByUserID(ctx context.Context, userID int64) ([]*APIKey, error)
+	FindActiveByHash(ctx context.Context, keyHash string) (*APIKey, error)
+	UpdateExpiry(ctx context.Context, id int64, expiresAt time.Time) error
+	Revoke(ctx context.Context, id int64) error
+	RevokeByUserID(ctx context.Context, userID int64) error
+	TouchLastUsed(ctx context.Context, id int64) error
+}
+
+type IServiceAccountUsecase interface {
+	FindAll(ctx context.Context, page int, limit int) ([]*User, int64, error)
+	Create(ctx context.Context, actorID int64, in CreateServiceAccountInput) (*User, error)
+	Delete(ctx context.Context, actorID int64, id int64) error
+	FindKeys(ctx context.Context, serviceAccountID int64) ([]*APIKey, error)
+	CreateKey(ctx context.Context, actorID int64, serviceAccountID int64, in CreateAPIKeyInput) (*CreatedAPIKey, error)
+	RotateKey(ctx context.Context, actorID int64, keyID int64, in RotateAPIKeyInput) (*CreatedAPIKey, error)
+	RevokeKey(ctx context.Context, actorID int64, keyID int64) error
+	Authenticate(ctx context.Context, rawKey string) (*CustomClaims, error)
+}
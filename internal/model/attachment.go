@@ -0,0 +1,118 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	ErrAttachmentNotFound      = errors.New("attachment not found")
+	ErrAttachmentOwnerNotFound = errors.New("ticket, comment or resolution not found")
+	ErrAttachmentForbidden     = errors.New("not allowed to manage attachments of this ticket")
+	ErrAttachmentTooMany       = errors.New("too many attachments")
+	ErrAttachmentTooLarge      = errors.New("attachment is too large")
+	ErrAttachmentTypeRejected  = errors.New("attachment type is not allowed")
+	ErrAttachmentInfected      = errors.New("attachment is infected")
+)
+
+type AttachmentOwnerType string
+
+const (
+	AttachmentOwnerTicket     AttachmentOwnerType = "TICKET"
+	AttachmentOwnerComment    AttachmentOwnerType = "COMMENT"
+	AttachmentOwnerResolution AttachmentOwnerType = "RESOLUTION"
+)
+
+// AttachmentOwner identifies the record a file belongs to. TicketID is kept
+// alongside so access checks and per-ticket listings need no joins, and
+// ProjectID selects the MIME allow-list.
+type AttachmentOwner struct {
+	Type      AttachmentOwnerType
+	ID        int64
+	TicketID  int64
+	ProjectID int64
+}
+
+// Attachment is a stored file. Photos also get a thumbnail and a preview
+// rendition; both are nil for other files and for images uploaded before
+// renditions existed.
+type Attachment struct {
+	ID           int64               `json:"id"`
+	OwnerType    AttachmentOwnerType `json:"owner_type"`
+	OwnerID      int64               `json:"owner_id"`
+	TicketID     int64               `json:"ticket_id"`
+	Filename     string              `json:"filename"`
+	MimeType     string              `json:"mime_type"`
+	Size         int64               `json:"size"`
+	Checksum     string              `json:"checksum"`
+	StorageKey   string              `json:"-"`
+	URL          string              `gorm:"-" json:"url"`
+	Width        *int                `json:"width"`
+	Height       *int                `json:"height"`
+	ThumbnailKey *string             `json:"-"`
+	PreviewKey   *string             `json:"-"`
+	ThumbnailURL *string             `gorm:"-" json:"thumbnail_url"`
+	PreviewURL   *string             `gorm:"-" json:"preview_url"`
+	UploadedBy   *int64              `json:"uploaded_by"`
+	UploaderName string              `gorm:"->" json:"uploader_name"`
+	CreatedAt    time.Time           `json:"created_at"`
+}
+
+// AttachmentUpload is one incoming file before it is stored. Content must
+// be seekable because it is sniffed and scanned before being uploaded.
+type AttachmentUpload struct {
+	Filename    string
+	ContentType string
+	Size        int64
+	Content     io.ReadSeeker
+}
+
+// QuarantinedFile records an upload the virus scanner flagged. The file is
+// kept under the quarantine/ prefix for review and never linked to a ticket.
+type QuarantinedFile struct {
+	ID         int64     `json:"id"`
+	TicketID   *int64    `json:"ticket_id"`
+	ProjectID  *int64    `json:"project_id"`
+	Filename   string    `json:"filename"`
+	MimeType   string    `json:"mime_type"`
+	Size       int64     `json:"size"`
+	Checksum   string    `json:"checksum"`
+	Signature  string    `json:"signature"`
+	StorageKey string    `json:"-"`
+	UploadedBy *int64    `json:"uploaded_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type IAttachmentRepository interface {
+	Create(ctx context.Context, tx interface{}, attachments []*Attachment) error
+	FindByID(ctx context.Context, id int64) (*Attachment, error)
+	FindByOwner(ctx context.Context, ownerType AttachmentOwnerType, ownerIDs []int64) ([]*Attachment, error)
+	FindByTicketID(ctx context.Context, ticketID int64, ownerType AttachmentOwnerType) ([]*Attachment, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+type IQuarantinedFileRepository interface {
+	Create(ctx context.Context, file QuarantinedFile) (*QuarantinedFile, error)
+	FindAll(ctx context.Context, page int, limit int) ([]*QuarantinedFile, int64, error)
+}
+
+type IAttachmentUsecase interface {
+	// Attach stores files for an owner the caller has already authorised,
+	// joining tx when one is given.
+	Attach(ctx context.Context, tx interface{}, uploaderID int64, owner AttachmentOwner, files []AttachmentUpload) ([]*Attachment, error)
+	// Prepare checks, scans and uploads files ahead of a transaction; the
+	// owner's ID may still be unknown. Save records them inside it, and
+	// Discard removes the uploads when the transaction does not commit.
+	Prepare(ctx context.Context, uploaderID int64, owner AttachmentOwner, files []AttachmentUpload) ([]*Attachment, error)
+	Save(ctx context.Context, tx interface{}, owner AttachmentOwner, attachments []*Attachment) error
+	Discard(ctx context.Context, attachments []*Attachment)
+	Upload(ctx context.Context, userID int64, role string, owner AttachmentOwner, files []AttachmentUpload) ([]*Attachment, error)
+	FindByOwner(ctx context.Context, ownerType AttachmentOwnerType, ownerID int64) ([]*Attachment, error)
+	// FindByOwners groups attachments by owner ID for list responses.
+	FindByOwners(ctx context.Context, ownerType AttachmentOwnerType, ownerIDs []int64) (map[int64][]*Attachment, error)
+	FindByTicketID(ctx context.Context, ticketID int64, userID int64, role string, ownerType AttachmentOwnerType) ([]*Attachment, error)
+	Delete(ctx context.Context, id int64, userID int64, role string) error
+	FindQuarantined(ctx context.Context, page int, limit int) ([]*QuarantinedFile, int64, error)
+}
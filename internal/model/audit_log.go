@@ -0,0 +1,45 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	AuditAccountLocked    = "ACCOUNT_LOCKED"
+	AuditAccountUnlocked  = "ACCOUNT_UNLOCKED"
+	AuditUserInvited      = "USER_INVITED"
+	AuditInvitationResent = "INVITATION_RESENT"
+	AuditTwoFactorReset   = "TWO_FACTOR_RESET"
+
+	AuditServiceAccountCreated = "SERVICE_ACCOUNT_CREATED"
+	AuditServiceAccountDeleted = "SERVICE_ACCOUNT_DELETED"
+	AuditAPIKeyCreated         = "API_KEY_CREATED"
+	AuditAPIKeyRotated         = "API_KEY_ROTATED"
+	AuditAPIKeyRevoked         = "API_KEY_REVOKED"
+)
+
+const (
+	AuditTargetUser   = "USER"
+	AuditTargetAPIKey = "API_KEY"
+)
+
+type AuditLog struct {
+	ID         int64     `json:"id"`
+	ActorID    *int64    `json:"actor_id"`
+	Action     string    `json:"action"`
+	TargetType string    `json:"target_type"`
+	TargetID   int64     `json:"target_id"`
+	Metadata   *string   `json:"metadata"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type IAuditLogRepository interface {
+	Create(ctx context.Context, log AuditLog) error
+	FindAll(ctx context.Context, filter AuditLog, page int, limit int) ([]*AuditLog, int64, error)
+}
+
+type IAuditLogUsecase interface {
+	FindAll(ctx context.Context, filter AuditLog, page int, limit int) ([]*AuditLog, int64, error)
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrIdentityAccountExists = errors.New("an account with this email already exists, sign in with your password or ask an administrator to link it")
+
+const (
+	AuthProviderLocal = "local"
+	AuthProviderOIDC  = "oidc"
+	AuthProviderLDAP  = "ldap"
+)
+
+type ExternalIdentity struct {
+	Provider string
+	Subject  string
+	Email    string
+	Name     string
+	Groups   []string
+}
+
+type UserIdentity struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type IPasswordAuthProvider interface {
+	Name() string
+	Authenticate(ctx context.Context, username string, password string) (*ExternalIdentity, error)
+}
+
+type IRedirectAuthProvider interface {
+	Name() string
+	AuthCodeURL(ctx context.Context) (string, error)
+	Exchange(ctx context.Context, state string, code string) (*ExternalIdentity, error)
+}
+
+type IUserIdentityRepository interface {
+	FindByProviderSubject(ctx context.Context, provider string, subject string) (*UserIdentity, error)
+	Create(ctx context.Context, identity UserIdentity) error
+}
+
+type ISSOUsecase interface {
+	Providers() []string
+	StartLogin(ctx context.Context, provider string) (string, error)
+	CompleteLogin(ctx context.Context, provider string, state string, code string) (*LoginResponse, error)
+}
@@ -0,0 +1,93 @@
+package model
+
+import "context"
+
+const (
+	ChatKindTicketAssigned = "TICKET_ASSIGNED"
+	ChatKindSLABreach      = "SLA_BREACH"
+)
+
+const (
+	ChatActionTake       = "TAKE"
+	ChatActionInProgress = "IN_PROGRESS"
+)
+
+type ChatAction struct {
+	Label  string
+	Action string
+	// URL is a signed link for adapters without interactive callbacks.
+	URL string
+	// CallbackData is used by adapters that identify the clicking user
+	// themselves, such as Telegram inline keyboards.
+	CallbackData string
+}
+
+type ChatMessage struct {
+	Kind       string
+	TicketID   int64
+	TicketCode string
+	Text       string
+	Link       string
+	Recipient  *User
+	Actions    []ChatAction
+}
+
+type IChatAdapter interface {
+	Name() string
+	Send(ctx context.Context, message ChatMessage) error
+}
+
+type ITelegramBot interface {
+	IChatAdapter
+	SendText(ctx context.Context, chatID int64, text string) error
+	AnswerCallback(ctx context.Context, callbackID string, text string) error
+}
+
+type TelegramUser struct {
+	ID int64 `json:"id"`
+}
+
+type TelegramChat struct {
+	ID int64 `json:"id"`
+}
+
+type TelegramMessage struct {
+	MessageID int64        `json:"message_id"`
+	From      TelegramUser `json:"from"`
+	Chat      TelegramChat `json:"chat"`
+	Text      string       `json:"text"`
+}
+
+type TelegramCallbackQuery struct {
+	ID      string           `json:"id"`
+	From    TelegramUser     `json:"from"`
+	Message *TelegramMessage `json:"message"`
+	Data    string           `json:"data"`
+}
+
+type TelegramUpdate struct {
+	UpdateID      int64                  `json:"update_id"`
+	Message       *TelegramMessage       `json:"message"`
+	CallbackQuery *TelegramCallbackQuery `json:"callback_query"`
+}
+
+type TelegramLink struct {
+	URL string `json:"url"`
+}
+
+// ChatActionPreview describes what a signed action link will do, for the
+// page that asks the user to confirm it.
+type ChatActionPreview struct {
+	TicketID   int64
+	TicketCode string
+	Label      string
+}
+
+type IChatUsecase interface {
+	NotifyAssigned(ctx context.Context, event NotificationEvent) error
+	NotifySLABreaches(ctx context.Context) error
+	PreviewSignedAction(ctx context.Context, token string) (*ChatActionPreview, error)
+	HandleSignedAction(ctx context.Context, token string) (ticketID int64, err error)
+	HandleTelegramUpdate(ctx context.Context, update TelegramUpdate) error
+	CreateTelegramLink(ctx context.Context, userID int64) (*TelegramLink, error)
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrUnknownQueue = errors.New("queue has no dead-letter queue")
+
+type DeadLetterMessage struct {
+	ID         string          `json:"id"`
+	Queue      string          `json:"queue"`
+	RoutingKey string          `json:"routing_key"`
+	Attempts   int64           `json:"attempts"`
+	Error      string          `json:"error"`
+	FailedAt   *time.Time      `json:"failed_at"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// ReplayDeadLetterInput selects the messages to move back to the work
+// queue; an empty IDs list replays the whole DLQ.
+type ReplayDeadLetterInput struct {
+	IDs []string `json:"ids"`
+}
+
+type IDeadLetterUsecase interface {
+	Queues() []string
+	FindAll(ctx context.Context, queue string, limit int) ([]*DeadLetterMessage, error)
+	Replay(ctx context.Context, queue string, in ReplayDeadLetterInput) (int, error)
+}
@@ -0,0 +1,114 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	ErrExportJobNotFound = errors.New("export job not found")
+	ErrExportJobNotReady = errors.New("export is not finished yet")
+	ErrExportJobExpired  = errors.New("export has expired")
+	ErrExportJobLimit    = errors.New("too many exports in progress, wait for one to finish")
+)
+
+type ExportJobStatus string
+
+const (
+	ExportJobPending    ExportJobStatus = "PENDING"
+	ExportJobProcessing ExportJobStatus = "PROCESSING"
+	ExportJobCompleted  ExportJobStatus = "COMPLETED"
+	ExportJobFailed     ExportJobStatus = "FAILED"
+)
+
+// ExportFilter is the ticket list filter an export was requested with. It
+// is applied with the requester's role when the job runs, so an export
+// never contains tickets its owner could not list.
+type ExportFilter struct {
+	TicketCode   string `json:"ticket_code"`
+	ProjectID    int64  `json:"project_id"`
+	AssignedToID int64  `json:"assigned_to_id"`
+	ReporterID   int64  `json:"reporter_id"`
+	Priority     string `json:"priority"`
+	Status       string `json:"status"`
+	Search       string `json:"search"`
+	StartDate    string `json:"start_date"`
+	EndDate      string `json:"end_date"`
+}
+
+// Ticket converts the filter to the form TicketRepo.FindAll takes.
+func (f ExportFilter) Ticket() Ticket {
+	filter := Ticket{
+		TicketCode: f.TicketCode,
+		ProjectID:  f.ProjectID,
+		ReporterID: f.ReporterID,
+		Priority:   TicketPriority(f.Priority),
+		Status:     TicketStatus(f.Status),
+	}
+
+	if f.AssignedToID != 0 {
+		assignedToID := f.AssignedToID
+		filter.AssignedToID = &assignedToID
+	}
+
+	return filter
+}
+
+// ExportJob is a ticket export built in the background. The file is kept
+// until ExpiresAt, after which the job and its file are deleted.
+type ExportJob struct {
+	ID          int64           `json:"id"`
+	UserID      int64           `json:"user_id"`
+	Role        string          `json:"-"`
+	Format      string          `json:"format"`
+	Filter      ExportFilter    `gorm:"serializer:json" json:"filter"`
+	Status      ExportJobStatus `json:"status"`
+	Progress    int             `json:"progress"`
+	TotalRows   int             `json:"total_rows"`
+	Filename    *string         `json:"filename"`
+	Size        int64           `json:"size"`
+	StorageKey  *string         `json:"-"`
+	Error       *string         `json:"error"`
+	Attempts    int             `json:"-"`
+	LeaseUntil  *time.Time      `json:"-"`
+	StartedAt   *time.Time      `json:"started_at"`
+	CompletedAt *time.Time      `json:"completed_at"`
+	ExpiresAt   time.Time       `json:"expires_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	DownloadURL *string         `gorm:"-" json:"download_url"`
+	ContentType string          `gorm:"-" json:"-"`
+}
+
+type CreateExportJobInput struct {
+	Format string `json:"format" validate:"omitempty,oneof=xlsx csv pdf ndjson"`
+	ExportFilter
+}
+
+type IExportJobRepository interface {
+	Create(ctx context.Context, job ExportJob) (*ExportJob, error)
+	FindByID(ctx context.Context, id int64) (*ExportJob, error)
+	FindByUserID(ctx context.Context, userID int64, page int, limit int) ([]*ExportJob, int64, error)
+	CountActiveByUserID(ctx context.Context, userID int64) (int64, error)
+	// ClaimNext marks the oldest waiting job as processing until the lease
+	// runs out, returning nil when there is nothing to do.
+	ClaimNext(ctx context.Context, lease time.Duration) (*ExportJob, error)
+	UpdateProgress(ctx context.Context, id int64, progress int, totalRows int) error
+	Update(ctx context.Context, job ExportJob) error
+	FindExpired(ctx context.Context, before time.Time, limit int) ([]*ExportJob, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+type IExportJobUsecase interface {
+	Create(ctx context.Context, userID int64, role string, in CreateExportJobInput) (*ExportJob, error)
+	FindByUserID(ctx context.Context, userID int64, page int, limit int) ([]*ExportJob, int64, error)
+	FindByID(ctx context.Context, id int64, userID int64) (*ExportJob, error)
+	// Download opens the finished file; the caller closes the reader.
+	Download(ctx context.Context, id int64, userID int64) (*ExportJob, io.ReadCloser, error)
+	Delete(ctx context.Context, id int64, userID int64) error
+	// ProcessNext runs one waiting job and reports whether there was one.
+	ProcessNext(ctx context.Context) (bool, error)
+	DeleteExpired(ctx context.Context) (int64, error)
+}
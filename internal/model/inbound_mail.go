@@ -0,0 +1,36 @@
+package model
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrMailRejected marks ingestion failures that retrying will not fix, so
+// the listener answers with a permanent SMTP error instead of a temporary one.
+var ErrMailRejected = errors.New("mail rejected")
+
+type InboundAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+type InboundMail struct {
+	MessageID string
+	// EnvelopeFrom is the MAIL FROM address given by the relay, which the
+	// From header must match.
+	EnvelopeFrom string
+	From         string
+	FromName     string
+	Subject      string
+	Body         string
+	Reply        string
+	// AuthenticationResults holds every Authentication-Results header,
+	// newest first.
+	AuthenticationResults []string
+	Attachments           []InboundAttachment
+}
+
+type IMailIngestUsecase interface {
+	Ingest(ctx context.Context, mail InboundMail) error
+}
@@ -0,0 +1,21 @@
+package model
+
+import "context"
+
+type Mail struct {
+	To          []string
+	Subject     string
+	Body        string
+	HTML        string
+	Attachments []MailAttachment
+}
+
+type MailAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+type IMailSender interface {
+	Send(ctx context.Context, mail Mail) error
+}
@@ -34,19 +34,52 @@ type Notification struct {
 	Title         string                    `json:"title"`
 	Message       string                    `json:"message"`
 	IsRead        bool                      `json:"is_read"`
+	ReadAt        *time.Time                `json:"read_at"`
+	ArchivedAt    *time.Time                `json:"archived_at"`
 	CreatedAt     time.Time                 `json:"created_at"`
 }
 
 type NotificationResponse struct {
-	ID         int64     `json:"id"`
-	TicketID   int64     `json:"ticket_id"`
-	TicketCode string    `json:"ticket_code"`
-	ActorName  string    `json:"actor_name"`
-	Type       string    `json:"type"`
-	Title      string    `json:"title"`
-	Message    string    `json:"message"`
-	IsRead     bool      `json:"is_read"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID         int64      `json:"id"`
+	TicketID   int64      `json:"ticket_id"`
+	TicketCode string     `json:"ticket_code"`
+	ActorName  string     `json:"actor_name"`
+	Type       string     `json:"type"`
+	Title      string     `json:"title"`
+	Message    string     `json:"message"`
+	IsRead     bool       `json:"is_read"`
+	ReadAt     *time.Time `json:"read_at"`
+	ArchivedAt *time.Time `json:"archived_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// NotificationFilter narrows an inbox listing. Archived notifications are
+// only listed when Archived is set, and then exclusively.
+type NotificationFilter struct {
+	Type     NotificationType
+	TicketID int64
+	IsRead   *bool
+	Archived bool
+}
+
+// NotificationBulkInput selects notifications either by explicit IDs or,
+// when IDs is empty, by Filter. One of them is required; an explicit empty
+// filter selects the whole inbox.
+type NotificationBulkInput struct {
+	IDs    []int64                 `json:"ids" validate:"max=500"`
+	Filter *NotificationBulkFilter `json:"filter"`
+}
+
+type NotificationBulkFilter struct {
+	Type     NotificationType `json:"type"`
+	TicketID int64            `json:"ticket_id"`
+	Status   string           `json:"status" validate:"omitempty,oneof=read unread"`
+	Archived bool             `json:"archived"`
+}
+
+type NotificationBulkResult struct {
+	Affected    int64 `json:"affected"`
+	UnreadCount int64 `json:"unread_count"`
 }
 
 type CreateNotificationInput struct {
@@ -62,18 +95,31 @@ type CreateNotificationInput struct {
 
 type INotificationRepository interface {
 	Create(ctx context.Context, notification Notification) (*Notification, error)
-	FindAllByUserID(ctx context.Context, userID int64) ([]*NotificationResponse, error)
+	FindResponseByID(ctx context.Context, id int64) (*NotificationResponse, error)
+	FindAllByUserID(ctx context.Context, userID int64, filter NotificationFilter, page int, limit int) ([]*NotificationResponse, int64, error)
 	MarkAsRead(ctx context.Context, id int64, userID int64) error
+	MarkAllAsRead(ctx context.Context, userID int64) (int64, error)
+	MarkAsReadByTicket(ctx context.Context, userID int64, ticketID int64) (int64, error)
+	Archive(ctx context.Context, id int64, userID int64) error
+	ArchiveMany(ctx context.Context, userID int64, ids []int64, filter NotificationFilter) (int64, error)
+	Unarchive(ctx context.Context, id int64, userID int64) error
 	CountUnread(ctx context.Context, userID int64) (int64, error)
 	Delete(ctx context.Context, id int64, userID int64) error
-	DeleteExpired(ctx context.Context, before time.Time) error
+	DeleteMany(ctx context.Context, userID int64, ids []int64, filter NotificationFilter) (int64, error)
+	DeleteExpired(ctx context.Context, readBefore time.Time, unreadBefore time.Time) (int64, error)
 }
 
 type INotificationUsecase interface {
 	Create(ctx context.Context, in CreateNotificationInput) (*Notification, error)
-	FindAllByUserID(ctx context.Context, userID int64) ([]*NotificationResponse, error)
+	FindAllByUserID(ctx context.Context, userID int64, filter NotificationFilter, page int, limit int) ([]*NotificationResponse, int64, error)
 	MarkAsRead(ctx context.Context, id int64, userID int64) error
+	MarkAllAsRead(ctx context.Context, userID int64) (*NotificationBulkResult, error)
+	MarkAsReadByTicket(ctx context.Context, userID int64, ticketID int64) (*NotificationBulkResult, error)
+	Archive(ctx context.Context, id int64, userID int64) error
+	ArchiveMany(ctx context.Context, userID int64, in NotificationBulkInput) (*NotificationBulkResult, error)
+	Unarchive(ctx context.Context, id int64, userID int64) error
 	CountUnread(ctx context.Context, userID int64) (int64, error)
 	Delete(ctx context.Context, id int64, userID int64) error
-	DeleteExpired(ctx context.Context, before time.Time) error
+	DeleteMany(ctx context.Context, userID int64, in NotificationBulkInput) (*NotificationBulkResult, error)
+	DeleteExpired(ctx context.Context) (int64, error)
 }
@@ -1,13 +1,17 @@
 package model
 
 type NotificationEvent struct {
+	// EventID identifies a lifecycle event across redeliveries.
+	EventID   string `json:"event_id,omitempty"`
 	EventType string `json:"event_type"`
 
-	UserID  int64 `json:"user_id"`
+	// UserID is the recipient; lifecycle events have none.
+	UserID  int64 `json:"user_id,omitempty"`
 	ActorID int64 `json:"actor_id"`
 
 	TicketID   int64  `json:"ticket_id"`
 	TicketCode string `json:"ticket_code"`
+	Priority   string `json:"priority,omitempty"`
 
 	ReferenceType string `json:"reference_type"`
 	ReferenceID   int64  `json:"reference_id"`
@@ -0,0 +1,91 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type NotificationChannel string
+
+const (
+	ChannelInApp NotificationChannel = "IN_APP"
+	ChannelEmail NotificationChannel = "EMAIL"
+	ChannelChat  NotificationChannel = "CHAT"
+	ChannelPush  NotificationChannel = "PUSH"
+)
+
+var NotificationChannels = []NotificationChannel{
+	ChannelInApp,
+	ChannelEmail,
+	ChannelChat,
+	ChannelPush,
+}
+
+var NotificationTypes = []NotificationType{
+	NotificationTicketCreated,
+	NotificationTicketAssigned,
+	NotificationTicketUpdated,
+	NotificationTicketComment,
+	NotificationTicketResolved,
+	NotificationTicketClosed,
+}
+
+type NotificationPreference struct {
+	ID        int64               `json:"-"`
+	UserID    int64               `json:"-"`
+	Type      NotificationType    `json:"type"`
+	Channel   NotificationChannel `json:"channel"`
+	Enabled   bool                `json:"enabled"`
+	CreatedAt time.Time           `json:"-"`
+	UpdatedAt time.Time           `json:"-"`
+}
+
+type NotificationQuietHours struct {
+	UserID    int64     `gorm:"primaryKey" json:"-"`
+	Enabled   bool      `json:"enabled"`
+	StartTime string    `json:"start_time"`
+	EndTime   string    `json:"end_time"`
+	Timezone  string    `json:"timezone"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+func (NotificationQuietHours) TableName() string {
+	return "notification_quiet_hours"
+}
+
+type NotificationSettings struct {
+	Preferences []NotificationPreference `json:"preferences"`
+	QuietHours  NotificationQuietHours   `json:"quiet_hours"`
+}
+
+type NotificationPreferenceInput struct {
+	Type    NotificationType    `json:"type" validate:"required,oneof=TICKET_CREATED TICKET_ASSIGNED TICKET_UPDATED TICKET_COMMENT TICKET_RESOLVED TICKET_CLOSED"`
+	Channel NotificationChannel `json:"channel" validate:"required,oneof=IN_APP EMAIL CHAT PUSH"`
+	Enabled *bool               `json:"enabled" validate:"required"`
+}
+
+type QuietHoursInput struct {
+	Enabled   bool   `json:"enabled"`
+	StartTime string `json:"start_time" validate:"required,datetime=15:04"`
+	EndTime   string `json:"end_time" validate:"required,datetime=15:04"`
+	Timezone  string `json:"timezone" validate:"required,timezone"`
+}
+
+type UpdateNotificationSettingsInput struct {
+	Preferences []NotificationPreferenceInput `json:"preferences" validate:"dive"`
+	QuietHours  *QuietHoursInput              `json:"quiet_hours"`
+}
+
+type INotificationPreferenceRepository interface {
+	FindByUserID(ctx context.Context, userID int64) ([]*NotificationPreference, error)
+	Upsert(ctx context.Context, preferences []NotificationPreference) error
+	FindQuietHours(ctx context.Context, userID int64) (*NotificationQuietHours, error)
+	UpsertQuietHours(ctx context.Context, quietHours NotificationQuietHours) error
+}
+
+type INotificationPreferenceUsecase interface {
+	Get(ctx context.Context, userID int64) (*NotificationSettings, error)
+	Update(ctx context.Context, userID int64, in UpdateNotificationSettingsInput) (*NotificationSettings, error)
+	Allows(ctx context.Context, event NotificationEvent, channel NotificationChannel) bool
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type PasswordHistory struct {
+	ID           int64     `json:"id"`
+	UserID       int64     `json:"user_id"`
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type IPasswordHistoryRepository interface {
+	Create(ctx context.Context, history PasswordHistory) error
+	FindRecentByUserID(ctx context.Context, userID int64, limit int) ([]*PasswordHistory, error)
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type PasswordReset struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type ForgotPasswordInput struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordInput struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,max=72"`
+}
+
+type IPasswordResetRepository interface {
+	Create(ctx context.Context, reset PasswordReset) (*PasswordReset, error)
+	FindValidByTokenHash(ctx context.Context, tokenHash string) (*PasswordReset, error)
+	MarkAsUsed(ctx context.Context, id int64) error
+	InvalidateByUserID(ctx context.Context, userID int64) error
+}
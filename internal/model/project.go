@@ -6,24 +6,30 @@ import (
 )
 
 type Project struct {
-	ID         int64      `json:"id"`
-	Name       string     `json:"name"`
-	CodePrefix string     `json:"code_prefix"`
-	CreatedAt  time.Time  `json:"created_at"`
-	UpdatedAt  time.Time  `json:"updated_at"`
-	DeletedAt  *time.Time `json:"-"`
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	CodePrefix string `json:"code_prefix"`
+	// AllowedMimeTypes limits attachment types; empty falls back to the
+	// attachment.allowed_mime_types config.
+	AllowedMimeTypes []string   `gorm:"serializer:json" json:"allowed_mime_types"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	DeletedAt        *time.Time `json:"-"`
 
 	Users []User `gorm:"many2many:user_projects;" json:"users,omitempty"`
 }
 
 type CreateProjectInput struct {
-	Name       string `json:"name" validate:"required"`
-	CodePrefix string `json:"code_prefix" validate:"required"`
+	Name             string   `json:"name" validate:"required"`
+	CodePrefix       string   `json:"code_prefix" validate:"required"`
+	AllowedMimeTypes []string `json:"allowed_mime_types" validate:"dive,contains=/"`
 }
 
+// UpdateProjectInput leaves the allow-list untouched when it is omitted.
 type UpdateProjectInput struct {
-	Name       string `json:"name" validate:"required"`
-	CodePrefix string `json:"code_prefix" validate:"required"`
+	Name             string    `json:"name" validate:"required"`
+	CodePrefix       string    `json:"code_prefix" validate:"required"`
+	AllowedMimeTypes *[]string `json:"allowed_mime_types" validate:"omitempty,dive,contains=/"`
 }
 
 type IProjectRepository interface {
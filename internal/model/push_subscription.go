@@ -0,0 +1,74 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrPushSubscriptionGone is returned by a push sender when the push service
+// reports the subscription no longer exists (404/410).
+var ErrPushSubscriptionGone = errors.New("push subscription is no longer valid")
+
+// PushSubscription is a browser Web Push registration for one user device.
+type PushSubscription struct {
+	ID           int64      `json:"id"`
+	UserID       int64      `json:"user_id"`
+	Endpoint     string     `json:"endpoint"`
+	P256dh       string     `json:"-"`
+	Auth         string     `json:"-"`
+	DeviceName   *string    `json:"device_name"`
+	UserAgent    *string    `json:"user_agent"`
+	FailureCount int        `json:"-"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	LastUsedAt   *time.Time `json:"last_used_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+type PushSubscriptionKeys struct {
+	P256dh string `json:"p256dh" validate:"required"`
+	Auth   string `json:"auth" validate:"required"`
+}
+
+// SubscribePushInput mirrors the JSON produced by PushSubscription.toJSON()
+// in the browser, plus an optional device label.
+type SubscribePushInput struct {
+	Endpoint       string               `json:"endpoint" validate:"required,url"`
+	ExpirationTime *int64               `json:"expirationTime"`
+	Keys           PushSubscriptionKeys `json:"keys" validate:"required"`
+	DeviceName     string               `json:"device_name" validate:"max=255"`
+	UserAgent      string               `json:"-"`
+}
+
+type PushMessage struct {
+	Type       string `json:"type"`
+	Title      string `json:"title"`
+	Body       string `json:"body"`
+	TicketID   int64  `json:"ticket_id,omitempty"`
+	TicketCode string `json:"ticket_code,omitempty"`
+	URL        string `json:"url,omitempty"`
+}
+
+type IPushSender interface {
+	Send(ctx context.Context, subscription PushSubscription, payload []byte) error
+}
+
+type IPushSubscriptionRepository interface {
+	Upsert(ctx context.Context, subscription PushSubscription) (*PushSubscription, error)
+	FindByUserID(ctx context.Context, userID int64) ([]*PushSubscription, error)
+	Delete(ctx context.Context, id int64, userID int64) error
+	DeleteByID(ctx context.Context, id int64) error
+	MarkSent(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64) error
+	DeleteExpired(ctx context.Context, now time.Time, staleBefore time.Time, maxFailures int) (int64, error)
+}
+
+type IPushUsecase interface {
+	PublicKey() string
+	Subscribe(ctx context.Context, userID int64, in SubscribePushInput) (*PushSubscription, error)
+	FindAll(ctx context.Context, userID int64) ([]*PushSubscription, error)
+	Unsubscribe(ctx context.Context, id int64, userID int64) error
+	Notify(ctx context.Context, event NotificationEvent) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,172 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	ErrReportScheduleNotFound = errors.New("report schedule not found")
+	ErrReportScheduleLimit    = errors.New("too many report schedules, delete one first")
+	ErrReportScheduleRunning  = errors.New("report is running, try again when it finishes")
+	ErrReportCronInvalid      = errors.New("invalid cron expression")
+	ErrReportCronTooFrequent  = errors.New("report schedule runs too often")
+	ErrReportRecipientInvalid = errors.New("report recipients must be users or in an allowed domain")
+	ErrReportTimezoneInvalid  = errors.New("invalid timezone")
+	ErrReportWebhookForbidden = errors.New("only administrators can deliver reports to webhooks")
+	ErrReportWebhookInvalid   = errors.New("webhook not found or disabled")
+	ErrReportRunNotFound      = errors.New("report run not found")
+	ErrReportRunNotReady      = errors.New("report run has no file")
+	ErrReportRunExpired       = errors.New("report file has expired")
+)
+
+type ReportChannel string
+
+const (
+	ReportChannelEmail   ReportChannel = "EMAIL"
+	ReportChannelWebhook ReportChannel = "WEBHOOK"
+)
+
+// ReportPeriod makes a schedule cover the period before each run instead
+// of the fixed dates in its filter: the previous day, the previous seven
+// days or the previous calendar month.
+type ReportPeriod string
+
+const (
+	ReportPeriodDay   ReportPeriod = "DAY"
+	ReportPeriodWeek  ReportPeriod = "WEEK"
+	ReportPeriodMonth ReportPeriod = "MONTH"
+)
+
+type ReportRunStatus string
+
+const (
+	ReportRunRunning   ReportRunStatus = "RUNNING"
+	ReportRunCompleted ReportRunStatus = "COMPLETED"
+	ReportRunFailed    ReportRunStatus = "FAILED"
+)
+
+// ReportSchedule is a saved ticket export that is built and sent on a cron
+// schedule. Like an export job it runs with its owner's role, so a report
+// never contains tickets the owner could not list.
+type ReportSchedule struct {
+	ID         int64         `json:"id"`
+	UserID     int64         `json:"user_id"`
+	Role       string        `json:"-"`
+	Name       string        `json:"name"`
+	Format     string        `json:"format"`
+	Filter     ExportFilter  `gorm:"serializer:json" json:"filter"`
+	Period     *ReportPeriod `json:"period"`
+	Cron       string        `json:"cron"`
+	Timezone   string        `json:"timezone"`
+	Channel    ReportChannel `json:"channel"`
+	Recipients []string      `gorm:"serializer:json" json:"recipients"`
+	WebhookID  *int64        `json:"webhook_id"`
+	IsActive   bool          `json:"is_active"`
+	NextRunAt  *time.Time    `json:"next_run_at"`
+	LastRunAt  *time.Time    `json:"last_run_at"`
+	LeaseUntil *time.Time    `json:"-"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+// ReportRun is one execution of a schedule. Its file stays downloadable
+// until ExpiresAt; the run itself is kept as history.
+type ReportRun struct {
+	ID          int64           `json:"id"`
+	ScheduleID  int64           `json:"schedule_id"`
+	Status      ReportRunStatus `json:"status"`
+	StartDate   *string         `json:"start_date"`
+	EndDate     *string         `json:"end_date"`
+	TotalRows   int             `json:"total_rows"`
+	Filename    *string         `json:"filename"`
+	Size        int64           `json:"size"`
+	StorageKey  *string         `json:"-"`
+	Error       *string         `json:"error"`
+	DeliveredAt *time.Time      `json:"delivered_at"`
+	StartedAt   time.Time       `json:"started_at"`
+	CompletedAt *time.Time      `json:"completed_at"`
+	ExpiresAt   time.Time       `json:"expires_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	DownloadURL *string         `gorm:"-" json:"download_url"`
+	ContentType string          `gorm:"-" json:"-"`
+}
+
+// ReportWebhookPayload is posted to the schedule's webhook after each
+// successful run.
+type ReportWebhookPayload struct {
+	Event      string            `json:"event"`
+	OccurredAt time.Time         `json:"occurred_at"`
+	Data       ReportWebhookData `json:"data"`
+}
+
+// ReportWebhookData describes the file of a run. DownloadURL works without
+// logging in until ExpiresAt.
+type ReportWebhookData struct {
+	ScheduleID  int64     `json:"schedule_id"`
+	Name        string    `json:"name"`
+	RunID       int64     `json:"run_id"`
+	Format      string    `json:"format"`
+	Filename    string    `json:"filename"`
+	TotalRows   int       `json:"total_rows"`
+	Size        int64     `json:"size"`
+	StartDate   *string   `json:"start_date"`
+	EndDate     *string   `json:"end_date"`
+	DownloadURL string    `json:"download_url"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type ReportScheduleInput struct {
+	Name       string        `json:"name" validate:"required,max=100"`
+	Format     string        `json:"format" validate:"omitempty,oneof=xlsx csv pdf ndjson"`
+	Filter     ExportFilter  `json:"filter"`
+	Period     *ReportPeriod `json:"period" validate:"omitempty,oneof=DAY WEEK MONTH"`
+	Cron       string        `json:"cron" validate:"required,max=100"`
+	Timezone   string        `json:"timezone" validate:"omitempty,max=64"`
+	Channel    ReportChannel `json:"channel" validate:"required,oneof=EMAIL WEBHOOK"`
+	Recipients []string      `json:"recipients" validate:"required_if=Channel EMAIL,max=20,dive,email"`
+	WebhookID  *int64        `json:"webhook_id" validate:"required_if=Channel WEBHOOK"`
+	IsActive   *bool         `json:"is_active"`
+}
+
+type IReportScheduleRepository interface {
+	Create(ctx context.Context, schedule ReportSchedule) (*ReportSchedule, error)
+	FindByID(ctx context.Context, id int64) (*ReportSchedule, error)
+	FindByUserID(ctx context.Context, userID int64, page int, limit int) ([]*ReportSchedule, int64, error)
+	CountByUserID(ctx context.Context, userID int64) (int64, error)
+	Update(ctx context.Context, schedule ReportSchedule) error
+	Delete(ctx context.Context, id int64) error
+	// ClaimDue leases the schedule that has waited longest past its run
+	// time, returning nil when none is due.
+	ClaimDue(ctx context.Context, lease time.Duration) (*ReportSchedule, error)
+	// Release records a run and clears the lease taken by ClaimDue.
+	Release(ctx context.Context, id int64, lastRunAt time.Time, nextRunAt *time.Time) error
+	// Disable pauses a schedule and clears its lease.
+	Disable(ctx context.Context, id int64) error
+	CreateRun(ctx context.Context, run ReportRun) (*ReportRun, error)
+	FindRunByID(ctx context.Context, id int64) (*ReportRun, error)
+	FindRunsByScheduleID(ctx context.Context, scheduleID int64, page int, limit int) ([]*ReportRun, int64, error)
+	FindRunFiles(ctx context.Context, scheduleID int64) ([]string, error)
+	UpdateRun(ctx context.Context, run ReportRun) error
+	FindExpiredRuns(ctx context.Context, before time.Time, limit int) ([]*ReportRun, error)
+	ClearRunFile(ctx context.Context, id int64) error
+}
+
+type IReportScheduleUsecase interface {
+	Create(ctx context.Context, userID int64, role string, in ReportScheduleInput) (*ReportSchedule, error)
+	FindByUserID(ctx context.Context, userID int64, page int, limit int) ([]*ReportSchedule, int64, error)
+	FindByID(ctx context.Context, id int64, userID int64) (*ReportSchedule, error)
+	Update(ctx context.Context, id int64, userID int64, role string, in ReportScheduleInput) (*ReportSchedule, error)
+	Delete(ctx context.Context, id int64, userID int64) error
+	// RunNow makes the schedule due immediately without changing its cron.
+	RunNow(ctx context.Context, id int64, userID int64) (*ReportSchedule, error)
+	FindRuns(ctx context.Context, scheduleID int64, userID int64, page int, limit int) ([]*ReportRun, int64, error)
+	// DownloadRun opens a run's file; the caller closes the reader.
+	DownloadRun(ctx context.Context, runID int64, userID int64) (*ReportRun, io.ReadCloser, error)
+	// RunDue runs one due schedule and reports whether there was one.
+	RunDue(ctx context.Context) (bool, error)
+	DeleteExpiredRuns(ctx context.Context) (int64, error)
+}
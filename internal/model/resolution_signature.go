@@ -0,0 +1,50 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrSignatureInvalid  = errors.New("signature must be a PNG image")
+	ErrSignatureTooLarge = errors.New("signature image is too large")
+)
+
+type SignerRole string
+
+const (
+	SignerTechnician SignerRole = "TECHNICIAN"
+	SignerReporter   SignerRole = "REPORTER"
+)
+
+// ResolutionSignature is a signature drawn by the technician or the
+// reporter when a ticket is resolved, replacing the paper handover form.
+type ResolutionSignature struct {
+	ID           int64      `json:"id"`
+	ResolutionID int64      `json:"resolution_id"`
+	TicketID     int64      `json:"ticket_id"`
+	SignerRole   SignerRole `json:"signer_role"`
+	SignerID     *int64     `json:"signer_id"`
+	SignerName   string     `json:"signer_name"`
+	StorageKey   string     `json:"-"`
+	Size         int64      `json:"size"`
+	Checksum     string     `json:"checksum"`
+	Width        int        `json:"width"`
+	Height       int        `json:"height"`
+	URL          string     `gorm:"-" json:"url"`
+	SignedAt     time.Time  `json:"signed_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// SignatureInput is a signature image as drawn on a canvas. SignerName is
+// only used for the reporter, who may sign through someone on site.
+type SignatureInput struct {
+	Image      []byte
+	SignerName string
+}
+
+type IResolutionSignatureRepository interface {
+	Create(ctx context.Context, tx interface{}, signatures []*ResolutionSignature) error
+	FindByTicketIDs(ctx context.Context, ticketIDs []int64) ([]*ResolutionSignature, error)
+}
@@ -6,12 +6,13 @@ import (
 )
 
 type Role struct {
-	ID        int64      `json:"id"`
-	Name      string     `json:"name"`
-	Privilege string     `json:"privilege"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `json:"-"`
+	ID               int64      `json:"id"`
+	Name             string     `json:"name"`
+	Privilege        string     `json:"privilege"`
+	RequireTwoFactor bool       `json:"require_two_factor"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	DeletedAt        *time.Time `json:"-"`
 }
 
 type CreateRoleInput struct {
@@ -24,12 +25,18 @@ type UpdateRoleInput struct {
 	Privilege string `json:"privilege" validate:"required"`
 }
 
+type UpdateRoleTwoFactorInput struct {
+	Required bool `json:"required"`
+}
+
 type IRoleRepository interface {
 	FindAll(ctx context.Context, role Role) ([]*Role, error)
 	FindByID(ctx context.Context, id int64) (*Role, error)
+	FindByName(ctx context.Context, name string) (*Role, error)
 	Create(ctx context.Context, role Role) (*Role, error)
 	Update(ctx context.Context, role Role) error
 	Delete(ctx context.Context, id int64) error
+	UpdateTwoFactorRequirement(ctx context.Context, id int64, required bool) error
 }
 
 type IRoleUsecase interface {
@@ -38,4 +45,5 @@ type IRoleUsecase interface {
 	Create(ctx context.Context, in CreateRoleInput) (*Role, error)
 	Update(ctx context.Context, id int64, in UpdateRoleInput) error
 	Delete(ctx context.Context, id int64) error
+	UpdateTwoFactorRequirement(ctx context.Context, id int64, in UpdateRoleTwoFactorInput) error
 }
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrObjectNotFound = errors.New("file not found")
+
+type StoredObject struct {
+	Key         string
+	ContentType string
+	Size        int64
+}
+
+// IStorage keeps uploaded files under opaque keys such as
+// "tickets/project_1/3f2a.jpg". Only the key is persisted; URLs are issued
+// on demand so the backend can be swapped without rewriting rows.
+type IStorage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, *StoredObject, error)
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a time-limited direct download URL, or an empty
+	// string when the backend cannot sign and the API streams the file.
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
@@ -22,24 +22,25 @@ const (
 )
 
 type Ticket struct {
-	ID           int64          `json:"id"`
-	TicketCode   string         `json:"ticket_code"`
-	ProjectID    int64          `json:"project_id"`
-	LocationID   int64          `json:"location_id"`
-	PartID       int64          `json:"part_id"`
-	AssetID      int64          `json:"asset_id"`
-	ReporterID   int64          `json:"reporter_id"`
-	AssignedToID *int64         `json:"assigned_to_id"`
-	Status       TicketStatus   `json:"status"`
-	Priority     TicketPriority `json:"priority"`
-	Description  string         `json:"description"`
-	OnholdNotes  *string        `json:"onhold_notes"`
-	Attachment   *string        `json:"attachment"`
-	DueAt        time.Time      `json:"due_at"`
-	ResolvedAt   *time.Time     `json:"resolved_at"`
-	PausedAt     *time.Time     `json:"paused_at"`
-	TotalPaused  int64          `json:"total_paused"`
-	Reporter     User           `json:"reporter"`
+	ID           int64                  `json:"id"`
+	TicketCode   string                 `json:"ticket_code"`
+	ProjectID    int64                  `json:"project_id"`
+	LocationID   int64                  `json:"location_id"`
+	PartID       int64                  `json:"part_id"`
+	AssetID      int64                  `json:"asset_id"`
+	ReporterID   int64                  `json:"reporter_id"`
+	AssignedToID *int64                 `json:"assigned_to_id"`
+	Status       TicketStatus           `json:"status"`
+	Priority     TicketPriority         `json:"priority"`
+	Description  string                 `json:"description"`
+	OnholdNotes  *string                `json:"onhold_notes"`
+	DueAt        time.Time              `json:"due_at"`
+	ResolvedAt   *time.Time             `json:"resolved_at"`
+	PausedAt     *time.Time             `json:"paused_at"`
+	TotalPaused  int64                  `json:"total_paused"`
+	Reporter     User                   `json:"reporter"`
+	Attachments  []*Attachment          `gorm:"-" json:"attachments"`
+	Signatures   []*ResolutionSignature `gorm:"-" json:"signatures"`
 
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
@@ -47,26 +48,33 @@ type Ticket struct {
 }
 
 type TicketResponse struct {
-	ID                 int64     `json:"id"`
-	TicketCode         string    `json:"ticket_code"`
-	Priority           string    `json:"priority"`
-	Status             string    `json:"status"`
-	Description        string    `json:"description"`
-	Attachment         *string   `json:"attachment_url"`
-	SolutionAttachment *string   `json:"solution_attachment_url"`
-	ProjectName        string    `json:"project_name"`
-	LocationName       string    `json:"location_name"`
-	AssetCode          string    `json:"asset_code"`
-	PartID             int64     `json:"part_id"`
-	PartName           string    `json:"part_name"`
-	AssetID            int64     `json:"asset_id"`
-	ReporterName       string    `json:"reporter_name"`
-	ReporterID         int64     `json:"reporter_id"`
-	AssignedToID       *int64    `json:"assigned_to_id"`
-	AssignedToName     string    `json:"assigned_to_name"`
-	CreatedAt          time.Time `json:"created_at"`
-	DueAt              time.Time `json:"due_at"`
-	UnreadCommentCount int64     `json:"unread_comment_count"`
+	ID                  int64     `json:"id"`
+	TicketCode          string    `json:"ticket_code"`
+	Priority            string    `json:"priority"`
+	Status              string    `json:"status"`
+	Description         string    `json:"description"`
+	Attachment          *string   `json:"attachment_url"`
+	AttachmentThumbnail *string   `json:"attachment_thumbnail_url"`
+	SolutionAttachment  *string   `json:"solution_attachment_url"`
+	SolutionThumbnail   *string   `json:"solution_thumbnail_url"`
+	ProjectName         string    `json:"project_name"`
+	LocationName        string    `json:"location_name"`
+	AssetCode           string    `json:"asset_code"`
+	PartID              int64     `json:"part_id"`
+	PartName            string    `json:"part_name"`
+	AssetID             int64     `json:"asset_id"`
+	ReporterName        string    `json:"reporter_name"`
+	ReporterID          int64     `json:"reporter_id"`
+	AssignedToID        *int64    `json:"assigned_to_id"`
+	AssignedToName      string    `json:"assigned_to_name"`
+	CreatedAt           time.Time `json:"created_at"`
+	DueAt               time.Time `json:"due_at"`
+	UnreadCommentCount  int64     `json:"unread_comment_count"`
+
+	TechnicianSignedBy *string    `json:"technician_signed_by"`
+	TechnicianSignedAt *time.Time `json:"technician_signed_at"`
+	ReporterSignedBy   *string    `json:"reporter_signed_by"`
+	ReporterSignedAt   *time.Time `json:"reporter_signed_at"`
 }
 
 type CreateTicketInput struct {
@@ -87,17 +95,23 @@ type UpdateTicketStatusInput struct {
 type ITicketRepository interface {
 	FindAll(ctx context.Context, filter Ticket, search string, startDate string, endDate string, page int, limit int, role string, userID int64) ([]*TicketResponse, int64, error)
 	FindByID(ctx context.Context, id int64) (*Ticket, error)
+	FindByCode(ctx context.Context, code string) (*Ticket, error)
 	Create(ctx context.Context, ticket Ticket) (*Ticket, error)
 	Update(ctx context.Context, ticket Ticket) error
 	Delete(ctx context.Context, id int64) error
 	CountByProjectToday(ctx context.Context, projectID int64) (int64, error)
+	NextID(ctx context.Context) (int64, error)
 	FindResponseByID(ctx context.Context, id int64) (*TicketResponse, error)
+	FindOverdue(ctx context.Context, limit int) ([]*Ticket, error)
 }
 
 type ITicketUsecase interface {
 	FindAll(ctx context.Context, filter Ticket, search string, startDate string, endDate string, page int, limit int, role string, userID int64) ([]*TicketResponse, int64, error)
 	FindByID(ctx context.Context, id int64) (*Ticket, error)
-	Create(ctx context.Context, reporterID int64, in CreateTicketInput, attachmentPath *string) (*Ticket, bool, error)
+	Create(ctx context.Context, reporterID int64, in CreateTicketInput, files []AttachmentUpload) (*Ticket, bool, error)
 	UpdateStatus(ctx context.Context, id int64, userID int64, in UpdateTicketStatusInput) error
+	// Take assigns an unassigned ticket to userID. It reports false when
+	// someone else got the ticket first.
+	Take(ctx context.Context, id int64, userID int64) (bool, error)
 	Delete(ctx context.Context, id int64) error
 }
@@ -15,6 +15,8 @@ type TicketComment struct {
 	IsReadByStaff         bool      `json:"is_read_by_staff"`
 	IsReadByAdministrator bool      `json:"is_read_by_administrator"`
 	CreatedAt             time.Time `json:"created_at"`
+
+	Attachments []*Attachment `gorm:"-" json:"attachments"`
 }
 
 type TicketCommentResponse struct {
@@ -24,17 +26,20 @@ type TicketCommentResponse struct {
 	UserName  string    `json:"user_name"`
 	Message   string    `json:"message"`
 	CreatedAt time.Time `json:"created_at"`
+
+	Attachments []*Attachment `gorm:"-" json:"attachments"`
 }
 
 type ITicketCommentRepository interface {
-	Create(ctx context.Context, comment TicketComment) (*TicketComment, error)
+	Create(ctx context.Context, tx interface{}, comment TicketComment) (*TicketComment, error)
+	FindByID(ctx context.Context, id int64) (*TicketComment, error)
 	FindByTicketID(ctx context.Context, ticketID int64) ([]*TicketCommentResponse, error)
 	CountUnreadByTicket(ctx context.Context, ticketID int64, role string, userID int64) (int64, error)
 	MarkAsRead(ctx context.Context, ticketID int64, role string) error
 }
 
 type ITicketCommentUsecase interface {
-	Create(ctx context.Context, comment TicketComment) (*TicketComment, error)
+	Create(ctx context.Context, comment TicketComment, files []AttachmentUpload) (*TicketComment, error)
 	FindByTicketID(ctx context.Context, ticketID int64) ([]*TicketCommentResponse, error)
 	MarkAsRead(ctx context.Context, ticketID int64, role string) error
 }
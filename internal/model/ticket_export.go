@@ -0,0 +1,41 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var (
+	ErrExportFormatUnsupported = errors.New("unsupported export format, use xlsx, csv, pdf or ndjson")
+	ErrServiceReportNotFound   = errors.New("ticket not found")
+	ErrServiceReportForbidden  = errors.New("you do not have access to this ticket")
+	ErrServiceReportUnresolved = errors.New("ticket has not been resolved yet")
+)
+
+// TicketDetail is everything recorded on one ticket, for report pages.
+// Attachments are the ticket's own files; comment and resolution files sit
+// on those records.
+type TicketDetail struct {
+	Ticket      *TicketResponse
+	Histories   []*TicketHistoryResponse
+	Comments    []*TicketCommentResponse
+	Resolution  *TicketResolution
+	Attachments []*Attachment
+}
+
+// TicketExportFile describes an export written by ITicketExportUsecase.
+type TicketExportFile struct {
+	Filename    string
+	ContentType string
+	Rows        int
+}
+
+type ITicketExportUsecase interface {
+	// Export writes the tickets the user may see that match filter in the
+	// given format. onProgress, when set, receives a percentage.
+	Export(ctx context.Context, userID int64, role string, format string, filter ExportFilter, w io.Writer, onProgress func(percent int)) (*TicketExportFile, error)
+	// ServiceReport writes the signed service report for a resolved ticket
+	// as a PDF.
+	ServiceReport(ctx context.Context, ticketID int64, userID int64, role string, w io.Writer) (*TicketExportFile, error)
+}
@@ -12,21 +12,25 @@ type TicketResolution struct {
 	SolutionID      int64     `json:"solution_id"`
 	ResolutionNotes string    `json:"resolution_notes"`
 	CompletionTime  time.Time `json:"completion_time"`
-	AttachmentURL   string    `json:"attachment_url"`
 	CreatedAt       time.Time `json:"created_at"`
 
-	Cause    *Cause    `json:"cause,omitempty"`
-	Solution *Solution `json:"solution,omitempty"`
+	Cause       *Cause                 `json:"cause,omitempty"`
+	Solution    *Solution              `json:"solution,omitempty"`
+	Attachments []*Attachment          `gorm:"-" json:"attachments"`
+	Signatures  []*ResolutionSignature `gorm:"-" json:"signatures"`
 }
 
 type CreateTicketResolutionInput struct {
-	TicketID        int64        `json:"ticket_id" validate:"required"`
-	CauseID         int64        `json:"cause_id"`
-	SolutionID      int64        `json:"solution_id"`
-	ResolutionNotes string       `json:"resolution_notes"`
-	CompletionTime  time.Time    `json:"completion_time"`
-	AttachmentURL   string       `json:"attachment_url"`
-	Status          TicketStatus `json:"status" validate:"required"`
+	TicketID        int64              `json:"ticket_id" validate:"required"`
+	CauseID         int64              `json:"cause_id"`
+	SolutionID      int64              `json:"solution_id"`
+	ResolutionNotes string             `json:"resolution_notes"`
+	CompletionTime  time.Time          `json:"completion_time"`
+	Status          TicketStatus       `json:"status" validate:"required"`
+	Attachments     []AttachmentUpload `json:"-"`
+
+	TechnicianSignature *SignatureInput `json:"-"`
+	ReporterSignature   *SignatureInput `json:"-"`
 }
 
 type ITicketResolutionRepository interface {
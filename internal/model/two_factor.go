@@ -0,0 +1,59 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type UserRecoveryCode struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	CodeHash  string     `json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type LoginResponse struct {
+	Token             string `json:"token,omitempty"`
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+}
+
+type TwoFactorEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type TwoFactorActivation struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	Token         string   `json:"token"`
+}
+
+type TwoFactorCodeInput struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type DisableTwoFactorInput struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type TwoFactorLoginInput struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+	IP             string `json:"-"`
+}
+
+type IUserRecoveryCodeRepository interface {
+	Replace(ctx context.Context, userID int64, codeHashes []string) error
+	Use(ctx context.Context, userID int64, codeHash string) (bool, error)
+	DeleteByUserID(ctx context.Context, userID int64) error
+}
+
+type ITwoFactorUsecase interface {
+	Enroll(ctx context.Context, userID int64) (*TwoFactorEnrollment, error)
+	Verify(ctx context.Context, userID int64, in TwoFactorCodeInput) (*TwoFactorActivation, error)
+	Disable(ctx context.Context, userID int64, in DisableTwoFactorInput) error
+	Reset(ctx context.Context, actorID int64, userID int64, ip string) error
+	CompleteLogin(ctx context.Context, in TwoFactorLoginInput) (token string, err error)
+}
@@ -2,21 +2,27 @@ package model
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
+var ErrUserNotFound = errors.New("user not found")
+
 type ContextAuthKey string
 
 const BearerAuthKey ContextAuthKey = "BearerAuth"
 
 type CustomClaims struct {
-	UserID int64  `json:"user_id"`
-	RoleID int64  `json:"role_id"`
-	Role   string `json:"role"`
-	Email  string `json:"email"`
-	Name   string `json:"name"`
+	UserID                 int64    `json:"user_id"`
+	RoleID                 int64    `json:"role_id"`
+	Role                   string   `json:"role"`
+	Email                  string   `json:"email"`
+	Name                   string   `json:"name"`
+	TwoFactorSetupRequired bool     `json:"two_factor_setup_required,omitempty"`
+	APIKeyID               int64    `json:"api_key_id,omitempty"`
+	Scopes                 []string `json:"scopes,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -31,6 +37,13 @@ type User struct {
 	IsOnline             bool       `json:"is_online"`
 	LastSeen             *time.Time `json:"last_seen"`
 	LastTicketAssignedAt *time.Time `json:"last_ticket_assigned_at"`
+	LockedAt             *time.Time `json:"locked_at"`
+	TwoFactorSecret      *string    `json:"-"`
+	TwoFactorEnabled     bool       `json:"two_factor_enabled"`
+	TwoFactorLastStep    *int64     `json:"-"`
+	IsServiceAccount     bool       `json:"is_service_account"`
+	Language             string     `gorm:"default:id" json:"language"`
+	TelegramChatID       *int64     `json:"-"`
 	CreatedAt            time.Time  `json:"created_at"`
 	UpdatedAt            time.Time  `json:"updated_at"`
 	DeletedAt            *time.Time `json:"-"`
@@ -47,33 +60,53 @@ type IUserRepository interface {
 	Delete(ctx context.Context, id int64) error
 	UpdateOnlineStatus(ctx context.Context, userID int64, isOnline bool) error
 	UpdateLastSeen(ctx context.Context, userID int64) error
+	Lock(ctx context.Context, userID int64) error
+	Unlock(ctx context.Context, userID int64) error
+	UpdateTwoFactor(ctx context.Context, userID int64, secret *string, enabled bool) error
+	EnableTwoFactor(ctx context.Context, userID int64) error
+	UseTOTPStep(ctx context.Context, userID int64, step int64) (bool, error)
+	FindByTelegramChatID(ctx context.Context, chatID int64) (*User, error)
+	UpdateTelegramChatID(ctx context.Context, userID int64, chatID *int64) error
 }
 
 type IUserUsecase interface {
 	FindAll(ctx context.Context, user User, page int, limit int) ([]*User, int64, error)
 	FindByID(ctx context.Context, id int64) (*User, error)
-	Login(ctx context.Context, in LoginInput) (token string, err error)
-	Create(ctx context.Context, in CreateUserInput) (token string, err error)
+	Login(ctx context.Context, in LoginInput) (*LoginResponse, error)
+	Register(ctx context.Context, in RegisterInput) (token string, err error)
+	Invite(ctx context.Context, actorID int64, in CreateUserInput) (*User, error)
+	ResendInvitation(ctx context.Context, actorID int64, userID int64) error
+	AcceptInvitation(ctx context.Context, in AcceptInvitationInput) (token string, err error)
 	Update(ctx context.Context, id int64, in UpdateUserInput) error
 	Delete(ctx context.Context, id int64) error
 	UpdateOnlineStatus(ctx context.Context, userID int64, isOnline bool) error
 	UpdateLastSeen(ctx context.Context, userID int64) error
 	UpdateProfile(ctx context.Context, userID int64, in UpdateProfileInput) error
+	ForgotPassword(ctx context.Context, in ForgotPasswordInput) error
+	ResetPassword(ctx context.Context, in ResetPasswordInput) error
+	Unlock(ctx context.Context, actorID int64, userID int64, ip string) error
 }
 
 type LoginInput struct {
 	ID       int64  `json:"id"`
 	Email    string `json:"email" validate:"required"`
 	Password string `json:"password" validate:"required"`
+	Provider string `json:"provider"`
+	IP       string `json:"-"`
 }
 type ProjectPayload struct {
 	ID int64 `json:"id"`
 }
 
+type RegisterInput struct {
+	Name     string `json:"name" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,max=72"`
+}
+
 type CreateUserInput struct {
 	Name     string           `json:"name" validate:"required"`
-	Email    string           `json:"email" validate:"required"`
-	Password string           `json:"password" validate:"required,min=3,max=50"`
+	Email    string           `json:"email" validate:"required,email"`
 	RoleID   int64            `json:"role_id" validate:"required"`
 	Role     string           `json:"role"`
 	Projects []ProjectPayload `json:"projects"`
@@ -82,7 +115,7 @@ type CreateUserInput struct {
 type UpdateUserInput struct {
 	Name     string           `json:"name" validate:"required"`
 	Email    string           `json:"email" validate:"required"`
-	Password string           `json:"password" validate:"omitempty,min=3,max=50"`
+	Password string           `json:"password" validate:"omitempty,max=72"`
 	RoleID   int64            `json:"role_id" validate:"required"`
 	Projects []ProjectPayload `json:"projects"`
 }
@@ -91,4 +124,5 @@ type UpdateProfileInput struct {
 	Name            string `json:"name" validate:"required"`
 	CurrentPassword string `json:"current_password"`
 	NewPassword     string `json:"new_password"`
+	Language        string `json:"language" validate:"omitempty,oneof=id en"`
 }
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrInvitationNotSent is returned with the invited user when the
+	// invitation email could not be sent; it can be sent again with
+	// ResendInvitation.
+	ErrInvitationNotSent    = errors.New("invitation email could not be sent")
+	ErrInvitationNotPending = errors.New("user has already accepted the invitation")
+)
+
+type UserInvitation struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	InvitedBy  int64      `json:"invited_by"`
+	TokenHash  string     `json:"-"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type AcceptInvitationInput struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,max=72"`
+}
+
+type IUserInvitationRepository interface {
+	Create(ctx context.Context, invitation UserInvitation) (*UserInvitation, error)
+	FindValidByTokenHash(ctx context.Context, tokenHash string) (*UserInvitation, error)
+	MarkAsAccepted(ctx context.Context, id int64) error
+	// RevokePending expires every unaccepted invitation of the user.
+	RevokePending(ctx context.Context, userID int64) error
+}
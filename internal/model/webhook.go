@@ -0,0 +1,105 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending WebhookDeliveryStatus = "PENDING"
+	WebhookDeliverySuccess WebhookDeliveryStatus = "SUCCESS"
+	WebhookDeliveryFailed  WebhookDeliveryStatus = "FAILED"
+)
+
+// Webhook subscribes an external URL to ticket events. An empty Events or
+// ProjectIDs list matches every event or project.
+type Webhook struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	URL        string     `json:"url"`
+	Secret     string     `json:"-"`
+	Events     []string   `gorm:"serializer:json" json:"events"`
+	ProjectIDs []int64    `gorm:"serializer:json" json:"project_ids"`
+	IsActive   bool       `json:"is_active"`
+	CreatedBy  int64      `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"-"`
+}
+
+// CreatedWebhook carries the signing secret, which is only shown once.
+type CreatedWebhook struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+type WebhookDelivery struct {
+	ID             int64                 `json:"id"`
+	WebhookID      int64                 `json:"webhook_id"`
+	EventID        *string               `json:"event_id"`
+	EventType      string                `json:"event_type"`
+	TicketID       *int64                `json:"ticket_id"`
+	Payload        string                `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	ResponseStatus *int                  `json:"response_status"`
+	ResponseBody   *string               `json:"response_body"`
+	Error          *string               `json:"error"`
+	ReplayOf       *int64                `json:"replay_of"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+}
+
+type WebhookPayload struct {
+	Event      string            `json:"event"`
+	OccurredAt time.Time         `json:"occurred_at"`
+	ProjectID  int64             `json:"project_id"`
+	Data       NotificationEvent `json:"data"`
+}
+
+type CreateWebhookInput struct {
+	Name       string   `json:"name" validate:"required,max=100"`
+	URL        string   `json:"url" validate:"required,url"`
+	Secret     string   `json:"secret" validate:"omitempty,min=16,max=128"`
+	Events     []string `json:"events" validate:"dive,oneof=TICKET_CREATED TICKET_ASSIGNED TICKET_UPDATED TICKET_COMMENT TICKET_RESOLVED TICKET_CLOSED"`
+	ProjectIDs []int64  `json:"project_ids"`
+}
+
+type UpdateWebhookInput struct {
+	Name       string   `json:"name" validate:"required,max=100"`
+	URL        string   `json:"url" validate:"required,url"`
+	Events     []string `json:"events" validate:"dive,oneof=TICKET_CREATED TICKET_ASSIGNED TICKET_UPDATED TICKET_COMMENT TICKET_RESOLVED TICKET_CLOSED"`
+	ProjectIDs []int64  `json:"project_ids"`
+	IsActive   bool     `json:"is_active"`
+}
+
+type IWebhookRepository interface {
+	FindAll(ctx context.Context) ([]*Webhook, error)
+	FindActive(ctx context.Context) ([]*Webhook, error)
+	FindByID(ctx context.Context, id int64) (*Webhook, error)
+	Create(ctx context.Context, webhook Webhook) (*Webhook, error)
+	Update(ctx context.Context, webhook Webhook) error
+	Delete(ctx context.Context, id int64) error
+	CreateDelivery(ctx context.Context, delivery WebhookDelivery) (*WebhookDelivery, error)
+	CreateDeliveries(ctx context.Context, deliveries []WebhookDelivery) error
+	FindDeliveryByID(ctx context.Context, id int64) (*WebhookDelivery, error)
+	FindDeliveriesByWebhookID(ctx context.Context, webhookID int64, status string, page int, limit int) ([]*WebhookDelivery, int64, error)
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery WebhookDelivery) error
+}
+
+type IWebhookUsecase interface {
+	FindAll(ctx context.Context) ([]*Webhook, error)
+	FindByID(ctx context.Context, id int64) (*Webhook, error)
+	Create(ctx context.Context, actorID int64, in CreateWebhookInput) (*CreatedWebhook, error)
+	Update(ctx context.Context, id int64, in UpdateWebhookInput) (*Webhook, error)
+	Delete(ctx context.Context, id int64) error
+	FindDeliveries(ctx context.Context, webhookID int64, status string, page int, limit int) ([]*WebhookDelivery, int64, error)
+	Replay(ctx context.Context, deliveryID int64) (*WebhookDelivery, error)
+	Dispatch(ctx context.Context, event NotificationEvent) error
+	DeliverDue(ctx context.Context) error
+}
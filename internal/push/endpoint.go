@@ -0,0 +1,64 @@
+package push
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+var ErrEndpointNotAllowed = errors.New("push endpoint is not allowed")
+
+// carrierNAT is the shared address space of RFC 6598, which is not covered
+// by net.IP.IsPrivate.
+var carrierNAT = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// ValidateEndpoint accepts only https URLs whose host is a name or a public
+// address. Names are checked again when sending, once they are resolved.
+func ValidateEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return fmt.Errorf("%w: must be an https url", ErrEndpointNotAllowed)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrEndpointNotAllowed, host)
+	}
+
+	if ip := net.ParseIP(host); ip != nil && !isPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrEndpointNotAllowed, host)
+	}
+
+	return nil
+}
+
+// refuseInternal is a net.Dialer Control hook. It sees the resolved
+// address, so a public name pointing at the internal network is refused
+// as well.
+func refuseInternal(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrEndpointNotAllowed, host)
+	}
+
+	return nil
+}
+
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!carrierNAT.Contains(ip)
+}
@@ -0,0 +1,89 @@
+package push
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	webpush "github.com/SherClockHolmes/webpush-go"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+const responseBodyLimit = 512
+
+// Sender delivers encrypted Web Push messages signed with the VAPID key pair.
+type Sender struct {
+	publicKey  string
+	privateKey string
+	subject    string
+	ttl        time.Duration
+	client     *http.Client
+}
+
+// NewSender builds a sender whose client only connects to public
+// addresses, since endpoints are supplied by users. Proxies are not used
+// so the check applies to the push service itself.
+func NewSender(publicKey, privateKey, subject string, ttl, timeout time.Duration) model.IPushSender {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: refuseInternal,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &Sender{
+		publicKey:  publicKey,
+		privateKey: privateKey,
+		subject:    subject,
+		ttl:        ttl,
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+		},
+	}
+}
+
+func (s *Sender) Send(ctx context.Context, subscription model.PushSubscription, payload []byte) error {
+	if err := ValidateEndpoint(subscription.Endpoint); err != nil {
+		return err
+	}
+
+	resp, err := webpush.SendNotificationWithContext(
+		ctx,
+		payload,
+		&webpush.Subscription{
+			Endpoint: subscription.Endpoint,
+			Keys: webpush.Keys{
+				P256dh: subscription.P256dh,
+				Auth:   subscription.Auth,
+			},
+		},
+		&webpush.Options{
+			HTTPClient:      s.client,
+			Subscriber:      s.subject,
+			TTL:             int(s.ttl.Seconds()),
+			Urgency:         webpush.UrgencyNormal,
+			VAPIDPublicKey:  s.publicKey,
+			VAPIDPrivateKey: s.privateKey,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return model.ErrPushSubscriptionGone
+	case resp.StatusCode >= 300:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, responseBodyLimit))
+		return fmt.Errorf("push service responded %d: %s", resp.StatusCode, body)
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package push
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	webpush "github.com/SherClockHolmes/webpush-go"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+// newStubSender points a sender at a push service stub answering with the
+// given status. Requests for example.com are dialled to the stub, so
+// the endpoint still passes ValidateEndpoint.
+func newStubSender(t *testing.T, status int) (*Sender, string) {
+	t.Helper()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" || r.Header.Get("Content-Encoding") != "aes128gcm" {
+			http.Error(w, "missing vapid or encryption", http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	transport := server.Client().Transport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}
+
+	privateKey, publicKey, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sender := &Sender{
+		publicKey:  publicKey,
+		privateKey: privateKey,
+		subject:    "mailto:helpdesk@example.com",
+		ttl:        time.Hour,
+		client:     &http.Client{Transport: transport},
+	}
+
+	return sender, "https://example.com/push/abc"
+}
+
+func testSubscription(t *testing.T, endpoint string) model.PushSubscription {
+	t.Helper()
+
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth := make([]byte, 16)
+	rand.Read(auth)
+
+	return model.PushSubscription{
+		ID:       1,
+		Endpoint: endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(auth),
+	}
+}
+
+func TestSendStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+		failed bool
+	}{
+		{status: http.StatusCreated},
+		{status: http.StatusGone, want: model.ErrPushSubscriptionGone},
+		{status: http.StatusNotFound, want: model.ErrPushSubscriptionGone},
+		{status: http.StatusTooManyRequests, failed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			sender, endpoint := newStubSender(t, tt.status)
+
+			err := sender.Send(context.Background(), testSubscription(t, endpoint), []byte(`{"title":"hi"}`))
+
+			switch {
+			case tt.want != nil:
+				if !errors.Is(err, tt.want) {
+					t.Fatalf("err = %v, want %v", err, tt.want)
+				}
+			case tt.failed:
+				if err == nil || errors.Is(err, model.ErrPushSubscriptionGone) {
+					t.Fatalf("err = %v, want a delivery failure", err)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("Send: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		allowed  bool
+	}{
+		{"https://fcm.googleapis.com/fcm/send/abc", true},
+		{"https://8.8.8.8/push", true},
+		{"http://fcm.googleapis.com/fcm/send/abc", false},
+		{"https://localhost/push", false},
+		{"https://api.localhost/push", false},
+		{"https://127.0.0.1/push", false},
+		{"https://10.0.0.5/push", false},
+		{"https://169.254.169.254/latest/meta-data", false},
+		{"https://100.64.0.1/push", false},
+		{"https://[::1]/push", false},
+		{"https://[fd00::1]/push", false},
+		{"not a url", false},
+	}
+
+	for _, tt := range tests {
+		err := ValidateEndpoint(tt.endpoint)
+		if tt.allowed && err != nil {
+			t.Errorf("ValidateEndpoint(%q) = %v", tt.endpoint, err)
+		}
+		if !tt.allowed && !errors.Is(err, ErrEndpointNotAllowed) {
+			t.Errorf("ValidateEndpoint(%q) = %v, want ErrEndpointNotAllowed", tt.endpoint, err)
+		}
+	}
+}
+
+func TestSenderRefusesInternalAddresses(t *testing.T) {
+	sender := NewSender("", "", "mailto:helpdesk@example.com", time.Hour, time.Second).(*Sender)
+
+	// The dial check is what stops a public name resolving to an internal
+	// address, so exercise it without going through ValidateEndpoint.
+	_, err := sender.client.Get("https://127.0.0.1:1/push")
+	if !errors.Is(err, ErrEndpointNotAllowed) {
+		t.Fatalf("err = %v, want ErrEndpointNotAllowed", err)
+	}
+}
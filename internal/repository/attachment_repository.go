@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"gorm.io/gorm"
+)
+
+type AttachmentRepo struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepo(db *gorm.DB) model.IAttachmentRepository {
+	return &AttachmentRepo{db: db}
+}
+
+func (r *AttachmentRepo) Create(ctx context.Context, tx interface{}, attachments []*model.Attachment) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	db := r.db
+
+	if tx != nil {
+		db = tx.(*gorm.DB)
+	}
+
+	return db.WithContext(ctx).Create(&attachments).Error
+}
+
+func (r *AttachmentRepo) FindByID(ctx context.Context, id int64) (*model.Attachment, error) {
+	var attachment model.Attachment
+
+	err := r.query(ctx).
+		Where("attachments.id = ?", id).
+		Take(&attachment).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.ErrAttachmentNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
+func (r *AttachmentRepo) FindByOwner(ctx context.Context, ownerType model.AttachmentOwnerType, ownerIDs []int64) ([]*model.Attachment, error) {
+	attachments := []*model.Attachment{}
+
+	if len(ownerIDs) == 0 {
+		return attachments, nil
+	}
+
+	err := r.query(ctx).
+		Where("attachments.owner_type = ? AND attachments.owner_id IN ?", ownerType, ownerIDs).
+		Order("attachments.id ASC").
+		Find(&attachments).Error
+
+	return attachments, err
+}
+
+func (r *AttachmentRepo) FindByTicketID(ctx context.Context, ticketID int64, ownerType model.AttachmentOwnerType) ([]*model.Attachment, error) {
+	attachments := []*model.Attachment{}
+
+	query := r.query(ctx).
+		Where("attachments.ticket_id = ?", ticketID)
+
+	if ownerType != "" {
+		query = query.Where("attachments.owner_type = ?", ownerType)
+	}
+
+	err := query.
+		Order("attachments.id ASC").
+		Find(&attachments).Error
+
+	return attachments, err
+}
+
+func (r *AttachmentRepo) Delete(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Delete(&model.Attachment{}).Error
+}
+
+func (r *AttachmentRepo) query(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Model(&model.Attachment{}).
+		Select("attachments.*, users.name AS uploader_name").
+		Joins("LEFT JOIN users ON users.id = attachments.uploaded_by")
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"gorm.io/gorm"
+)
+
+type AuditLogRepo struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepo(db *gorm.DB) model.IAuditLogRepository {
+	return &AuditLogRepo{db: db}
+}
+
+func (r *AuditLogRepo) Create(ctx context.Context, log model.AuditLog) error {
+	log.CreatedAt = time.Now()
+
+	return r.db.WithContext(ctx).Create(&log).Error
+}
+
+func (r *AuditLogRepo) FindAll(ctx context.Context, filter model.AuditLog, page int, limit int) ([]*model.AuditLog, int64, error) {
+	var logs []*model.AuditLog
+	var total int64
+
+	offset := (page - 1) * limit
+
+	query := r.db.WithContext(ctx).
+		Model(&model.AuditLog{})
+
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+
+	if filter.TargetID != 0 {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
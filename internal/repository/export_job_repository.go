@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"gorm.io/gorm"
+)
+
+type ExportJobRepo struct {
+	db *gorm.DB
+}
+
+func NewExportJobRepo(db *gorm.DB) model.IExportJobRepository {
+	return &ExportJobRepo{db: db}
+}
+
+func (r *ExportJobRepo) Create(ctx context.Context, job model.ExportJob) (*model.ExportJob, error) {
+	now := time.Now()
+
+	job.CreatedAt = now
+	job.UpdatedAt = now
+
+	if err := r.db.WithContext(ctx).Create(&job).Error; err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (r *ExportJobRepo) FindByID(ctx context.Context, id int64) (*model.ExportJob, error) {
+	var job model.ExportJob
+
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&job).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.ErrExportJobNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (r *ExportJobRepo) FindByUserID(ctx context.Context, userID int64, page int, limit int) ([]*model.ExportJob, int64, error) {
+	var jobs []*model.ExportJob
+	var total int64
+
+	offset := (page - 1) * limit
+
+	query := r.db.WithContext(ctx).
+		Model(&model.ExportJob{}).
+		Where("user_id = ?", userID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&jobs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return jobs, total, nil
+}
+
+func (r *ExportJobRepo) CountActiveByUserID(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+
+	err := r.db.WithContext(ctx).
+		Model(&model.ExportJob{}).
+		Where("user_id = ? AND status IN ?", userID, []model.ExportJobStatus{
+			model.ExportJobPending,
+			model.ExportJobProcessing,
+		}).
+		Count(&count).Error
+
+	return count, err
+}
+
+// ClaimNext also takes back jobs whose lease has run out, which happens
+// when the worker running them died. SKIP LOCKED keeps several workers from
+// claiming the same job.
+func (r *ExportJobRepo) ClaimNext(ctx context.Context, lease time.Duration) (*model.ExportJob, error) {
+	var ids []int64
+
+	now := time.Now()
+
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE export_jobs
+		SET status = 'PROCESSING',
+			attempts = attempts + 1,
+			lease_until = ?,
+			started_at = ?,
+			updated_at = ?
+		WHERE id = (
+			SELECT id
+			FROM export_jobs
+			WHERE status = 'PENDING'
+			OR (status = 'PROCESSING' AND lease_until < ?)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id
+	`, now.Add(lease), now, now, now).Scan(&ids).Error
+
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	return r.FindByID(ctx, ids[0])
+}
+
+func (r *ExportJobRepo) UpdateProgress(ctx context.Context, id int64, progress int, totalRows int) error {
+	return r.db.WithContext(ctx).
+		Model(&model.ExportJob{ID: id}).
+		Updates(map[string]interface{}{
+			"progress":   progress,
+			"total_rows": totalRows,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+func (r *ExportJobRepo) Update(ctx context.Context, job model.ExportJob) error {
+	job.UpdatedAt = time.Now()
+
+	return r.db.WithContext(ctx).
+		Model(&model.ExportJob{ID: job.ID}).
+		Select(
+			"status",
+			"progress",
+			"total_rows",
+			"filename",
+			"size",
+			"storage_key",
+			"error",
+			"lease_until",
+			"completed_at",
+			"expires_at",
+			"updated_at",
+		).
+		Updates(&job).Error
+}
+
+func (r *ExportJobRepo) FindExpired(ctx context.Context, before time.Time, limit int) ([]*model.ExportJob, error) {
+	var jobs []*model.ExportJob
+
+	err := r.db.WithContext(ctx).
+		Where("expires_at < ?", before).
+		Where("NOT (status = 'PROCESSING' AND lease_until > ?)", time.Now()).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&jobs).Error
+
+	return jobs, err
+}
+
+func (r *ExportJobRepo) Delete(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Delete(&model.ExportJob{}).Error
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationPreferenceRepo struct {
+	db *gorm.DB
+}
+
+func NewNotificationPreferenceRepo(db *gorm.DB) model.INotificationPreferenceRepository {
+	return &NotificationPreferenceRepo{db: db}
+}
+
+func (r *NotificationPreferenceRepo) FindByUserID(ctx context.Context, userID int64) ([]*model.NotificationPreference, error) {
+	var preferences []*model.NotificationPreference
+
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Find(&preferences).Error
+
+	return preferences, err
+}
+
+func (r *NotificationPreferenceRepo) Upsert(ctx context.Context, preferences []model.NotificationPreference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	for i := range preferences {
+		preferences[i].CreatedAt = now
+		preferences[i].UpdatedAt = now
+	}
+
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: "user_id"},
+				{Name: "type"},
+				{Name: "channel"},
+			},
+			DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+		}).
+		Create(&preferences).Error
+}
+
+func (r *NotificationPreferenceRepo) FindQuietHours(ctx context.Context, userID int64) (*model.NotificationQuietHours, error) {
+	var quietHours model.NotificationQuietHours
+
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		First(&quietHours).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &quietHours, nil
+}
+
+func (r *NotificationPreferenceRepo) UpsertQuietHours(ctx context.Context, quietHours model.NotificationQuietHours) error {
+	now := time.Now()
+
+	quietHours.CreatedAt = now
+	quietHours.UpdatedAt = now
+
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"enabled",
+				"start_time",
+				"end_time",
+				"timezone",
+				"updated_at",
+			}),
+		}).
+		Create(&quietHours).Error
+}
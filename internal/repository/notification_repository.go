@@ -8,6 +8,20 @@ import (
 	"gorm.io/gorm"
 )
 
+const notificationResponseColumns = `
+	notifications.id,
+	notifications.ticket_id,
+	tickets.ticket_code,
+	actor.name as actor_name,
+	notifications.type,
+	notifications.title,
+	notifications.message,
+	notifications.is_read,
+	notifications.read_at,
+	notifications.archived_at,
+	notifications.created_at
+`
+
 type NotificationRepo struct {
 	db *gorm.DB
 }
@@ -28,36 +42,122 @@ func (r *NotificationRepo) Create(ctx context.Context, notification model.Notifi
 	return &notification, nil
 }
 
-func (r *NotificationRepo) FindAllByUserID(ctx context.Context, userID int64) ([]*model.NotificationResponse, error) {
-	var notifications []*model.NotificationResponse
+func (r *NotificationRepo) FindResponseByID(ctx context.Context, id int64) (*model.NotificationResponse, error) {
+	var notification model.NotificationResponse
 
 	err := r.db.WithContext(ctx).
 		Table("notifications").
 		Joins("JOIN tickets ON tickets.id = notifications.ticket_id").
 		Joins("JOIN users actor ON actor.id = notifications.actor_id").
-		Where("notifications.user_id = ?", userID).
-		Select(`
-			notifications.id,
-			notifications.ticket_id,
-			tickets.ticket_code,
-			actor.name as actor_name,
-			notifications.type,
-			notifications.title,
-			notifications.message,
-			notifications.is_read,
-			notifications.created_at
-		`).
+		Where("notifications.id = ?", id).
+		Select(notificationResponseColumns).
+		Take(&notification).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &notification, nil
+}
+
+func (r *NotificationRepo) FindAllByUserID(ctx context.Context, userID int64, filter model.NotificationFilter, page int, limit int) ([]*model.NotificationResponse, int64, error) {
+	var notifications []*model.NotificationResponse
+	var total int64
+
+	offset := (page - 1) * limit
+
+	query := r.db.WithContext(ctx).
+		Table("notifications").
+		Where("notifications.user_id = ?", userID)
+
+	query = applyNotificationFilter(query, filter)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Joins("JOIN tickets ON tickets.id = notifications.ticket_id").
+		Joins("JOIN users actor ON actor.id = notifications.actor_id").
+		Select(notificationResponseColumns).
 		Order("notifications.created_at DESC").
+		Limit(limit).
+		Offset(offset).
 		Scan(&notifications).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return notifications, total, nil
+}
+
+func (r *NotificationRepo) MarkAllAsRead(ctx context.Context, userID int64) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.Notification{}).
+		Where("user_id = ? AND is_read IS NOT TRUE AND archived_at IS NULL", userID).
+		Updates(map[string]interface{}{
+			"is_read": true,
+			"read_at": time.Now(),
+		})
 
-	return notifications, err
+	return result.RowsAffected, result.Error
+}
+
+func (r *NotificationRepo) MarkAsReadByTicket(ctx context.Context, userID int64, ticketID int64) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.Notification{}).
+		Where("user_id = ? AND ticket_id = ? AND is_read IS NOT TRUE", userID, ticketID).
+		Updates(map[string]interface{}{
+			"is_read": true,
+			"read_at": time.Now(),
+		})
+
+	return result.RowsAffected, result.Error
 }
 
 func (r *NotificationRepo) MarkAsRead(ctx context.Context, id int64, userID int64) error {
 	return r.db.WithContext(ctx).
 		Model(&model.Notification{}).
 		Where("id = ? AND user_id = ?", id, userID).
-		Update("is_read", true).Error
+		Updates(map[string]interface{}{
+			"is_read": true,
+			"read_at": gorm.Expr("COALESCE(read_at, ?)", time.Now()),
+		}).Error
+}
+
+// Archive files the notification away from the inbox; an archived
+// notification no longer counts as unread.
+func (r *NotificationRepo) Archive(ctx context.Context, id int64, userID int64) error {
+	now := time.Now()
+
+	return r.db.WithContext(ctx).
+		Model(&model.Notification{}).
+		Where("id = ? AND user_id = ? AND archived_at IS NULL", id, userID).
+		Updates(map[string]interface{}{
+			"is_read":     true,
+			"read_at":     gorm.Expr("COALESCE(read_at, ?)", now),
+			"archived_at": now,
+		}).Error
+}
+
+func (r *NotificationRepo) ArchiveMany(ctx context.Context, userID int64, ids []int64, filter model.NotificationFilter) (int64, error) {
+	now := time.Now()
+
+	result := r.bulkScope(ctx, userID, ids, filter).
+		Where("notifications.archived_at IS NULL").
+		Updates(map[string]interface{}{
+			"is_read":     true,
+			"read_at":     gorm.Expr("COALESCE(read_at, ?)", now),
+			"archived_at": now,
+		})
+
+	return result.RowsAffected, result.Error
+}
+
+func (r *NotificationRepo) Unarchive(ctx context.Context, id int64, userID int64) error {
+	return r.db.WithContext(ctx).
+		Model(&model.Notification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("archived_at", nil).Error
 }
 
 func (r *NotificationRepo) CountUnread(ctx context.Context, userID int64) (int64, error) {
@@ -65,7 +165,7 @@ func (r *NotificationRepo) CountUnread(ctx context.Context, userID int64) (int64
 
 	err := r.db.WithContext(ctx).
 		Model(&model.Notification{}).
-		Where("user_id = ? AND is_read = false", userID).
+		Where("user_id = ? AND is_read = false AND archived_at IS NULL", userID).
 		Count(&total).Error
 
 	return total, err
@@ -77,8 +177,61 @@ func (r *NotificationRepo) Delete(ctx context.Context, id int64, userID int64) e
 		Delete(&model.Notification{}).Error
 }
 
-func (r *NotificationRepo) DeleteExpired(ctx context.Context, before time.Time) error {
-	return r.db.WithContext(ctx).
-		Where("created_at < ?", before).
-		Delete(&model.Notification{}).Error
+func (r *NotificationRepo) DeleteMany(ctx context.Context, userID int64, ids []int64, filter model.NotificationFilter) (int64, error) {
+	result := r.bulkScope(ctx, userID, ids, filter).
+		Delete(&model.Notification{})
+
+	return result.RowsAffected, result.Error
+}
+
+// bulkScope limits a bulk operation to the user's notifications, matched by
+// ids when given and by filter otherwise.
+func (r *NotificationRepo) bulkScope(ctx context.Context, userID int64, ids []int64, filter model.NotificationFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).
+		Model(&model.Notification{}).
+		Where("notifications.user_id = ?", userID)
+
+	if len(ids) > 0 {
+		return query.Where("notifications.id IN ?", ids)
+	}
+
+	return applyNotificationFilter(query, filter)
+}
+
+func applyNotificationFilter(query *gorm.DB, filter model.NotificationFilter) *gorm.DB {
+	if filter.Archived {
+		query = query.Where("notifications.archived_at IS NOT NULL")
+	} else {
+		query = query.Where("notifications.archived_at IS NULL")
+	}
+
+	if filter.Type != "" {
+		query = query.Where("notifications.type = ?", filter.Type)
+	}
+
+	if filter.TicketID != 0 {
+		query = query.Where("notifications.ticket_id = ?", filter.TicketID)
+	}
+
+	if filter.IsRead != nil {
+		if *filter.IsRead {
+			query = query.Where("notifications.is_read = true")
+		} else {
+			query = query.Where("notifications.is_read IS NOT TRUE")
+		}
+	}
+
+	return query
+}
+
+// DeleteExpired applies the retention policy: read notifications are kept
+// until readBefore counted from when they were read, unread ones until
+// unreadBefore counted from when they were created.
+func (r *NotificationRepo) DeleteExpired(ctx context.Context, readBefore time.Time, unreadBefore time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("is_read = true AND COALESCE(read_at, created_at) < ?", readBefore).
+		Or("is_read IS NOT TRUE AND created_at < ?", unreadBefore).
+		Delete(&model.Notification{})
+
+	return result.RowsAffected, result.Error
 }
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"gorm.io/gorm"
+)
+
+type PasswordHistoryRepo struct {
+	db *gorm.DB
+}
+
+func NewPasswordHistoryRepo(db *gorm.DB) model.IPasswordHistoryRepository {
+	return &PasswordHistoryRepo{db: db}
+}
+
+func (r *PasswordHistoryRepo) Create(ctx context.Context, history model.PasswordHistory) error {
+	history.CreatedAt = time.Now()
+
+	return r.db.WithContext(ctx).Create(&history).Error
+}
+
+func (r *PasswordHistoryRepo) FindRecentByUserID(ctx context.Context, userID int64, limit int) ([]*model.PasswordHistory, error) {
+	var histories []*model.PasswordHistory
+
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&histories).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return histories, nil
+}
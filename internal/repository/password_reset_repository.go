@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepo struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepo(db *gorm.DB) model.IPasswordResetRepository {
+	return &PasswordResetRepo{db: db}
+}
+
+func (r *PasswordResetRepo) Create(ctx context.Context, reset model.PasswordReset) (*model.PasswordReset, error) {
+	reset.CreatedAt = time.Now()
+
+	if err := r.db.WithContext(ctx).Create(&reset).Error; err != nil {
+		return nil, err
+	}
+
+	return &reset, nil
+}
+
+func (r *PasswordResetRepo) FindValidByTokenHash(ctx context.Context, tokenHash string) (*model.PasswordReset, error) {
+	var reset model.PasswordReset
+
+	err := r.db.WithContext(ctx).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, time.Now()).
+		First(&reset).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("reset token is invalid or expired")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &reset, nil
+}
+
+func (r *PasswordResetRepo) MarkAsUsed(ctx context.Context, id int64) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.PasswordReset{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("reset token is invalid or expired")
+	}
+
+	return nil
+}
+
+func (r *PasswordResetRepo) InvalidateByUserID(ctx context.Context, userID int64) error {
+	return r.db.WithContext(ctx).
+		Model(&model.PasswordReset{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+}
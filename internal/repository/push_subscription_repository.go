@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PushSubscriptionRepo struct {
+	db *gorm.DB
+}
+
+func NewPushSubscriptionRepo(db *gorm.DB) model.IPushSubscriptionRepository {
+	return &PushSubscriptionRepo{db: db}
+}
+
+// Upsert registers the subscription, re-assigning the endpoint to the given
+// user when the browser re-subscribes under a different account.
+func (r *PushSubscriptionRepo) Upsert(ctx context.Context, subscription model.PushSubscription) (*model.PushSubscription, error) {
+	now := time.Now()
+
+	subscription.FailureCount = 0
+	subscription.CreatedAt = now
+	subscription.UpdatedAt = now
+
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "endpoint"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"user_id",
+				"p256dh",
+				"auth",
+				"device_name",
+				"user_agent",
+				"failure_count",
+				"expires_at",
+				"updated_at",
+			}),
+		}).
+		Create(&subscription).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (r *PushSubscriptionRepo) FindByUserID(ctx context.Context, userID int64) ([]*model.PushSubscription, error) {
+	var subscriptions []*model.PushSubscription
+
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&subscriptions).Error
+
+	return subscriptions, err
+}
+
+func (r *PushSubscriptionRepo) Delete(ctx context.Context, id int64, userID int64) error {
+	return r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&model.PushSubscription{}).Error
+}
+
+func (r *PushSubscriptionRepo) DeleteByID(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Delete(&model.PushSubscription{}).Error
+}
+
+func (r *PushSubscriptionRepo) MarkSent(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).
+		Model(&model.PushSubscription{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"failure_count": 0,
+			"last_used_at":  time.Now(),
+		}).Error
+}
+
+func (r *PushSubscriptionRepo) MarkFailed(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).
+		Model(&model.PushSubscription{}).
+		Where("id = ?", id).
+		Update("failure_count", gorm.Expr("failure_count + 1")).Error
+}
+
+// DeleteExpired removes subscriptions past their browser-reported expiry,
+// unused since staleBefore, or failing maxFailures times in a row.
+func (r *PushSubscriptionRepo) DeleteExpired(ctx context.Context, now time.Time, staleBefore time.Time, maxFailures int) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("expires_at < ?", now).
+		Or("COALESCE(last_used_at, created_at) < ?", staleBefore).
+		Or("failure_count >= ?", maxFailures).
+		Delete(&model.PushSubscription{})
+
+	return result.RowsAffected, result.Error
+}
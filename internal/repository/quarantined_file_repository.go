@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"gorm.io/gorm"
+)
+
+type QuarantinedFileRepo struct {
+	db *gorm.DB
+}
+
+func NewQuarantinedFileRepo(db *gorm.DB) model.IQuarantinedFileRepository {
+	return &QuarantinedFileRepo{db: db}
+}
+
+func (r *QuarantinedFileRepo) Create(ctx context.Context, file model.QuarantinedFile) (*model.QuarantinedFile, error) {
+	if err := r.db.WithContext(ctx).Create(&file).Error; err != nil {
+		return nil, err
+	}
+
+	return &file, nil
+}
+
+func (r *QuarantinedFileRepo) FindAll(ctx context.Context, page int, limit int) ([]*model.QuarantinedFile, int64, error) {
+	var files []*model.QuarantinedFile
+	var total int64
+
+	offset := (page - 1) * limit
+
+	query := r.db.WithContext(ctx).
+		Model(&model.QuarantinedFile{})
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&files).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return files, total, nil
+}
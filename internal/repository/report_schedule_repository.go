@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"gorm.io/gorm"
+)
+
+type ReportScheduleRepo struct {
+	db *gorm.DB
+}
+
+func NewReportScheduleRepo(db *gorm.DB) model.IReportScheduleRepository {
+	return &ReportScheduleRepo{db: db}
+}
+
+func (r *ReportScheduleRepo) Create(ctx context.Context, schedule model.ReportSchedule) (*model.ReportSchedule, error) {
+	now := time.Now()
+
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+
+	if err := r.db.WithContext(ctx).Create(&schedule).Error; err != nil {
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+func (r *ReportScheduleRepo) FindByID(ctx context.Context, id int64) (*model.ReportSchedule, error) {
+	var schedule model.ReportSchedule
+
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&schedule).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.ErrReportScheduleNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+func (r *ReportScheduleRepo) FindByUserID(ctx context.Context, userID int64, page int, limit int) ([]*model.ReportSchedule, int64, error) {
+	var schedules []*model.ReportSchedule
+	var total int64
+
+	offset := (page - 1) * limit
+
+	query := r.db.WithContext(ctx).
+		Model(&model.ReportSchedule{}).
+		Where("user_id = ?", userID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&schedules).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return schedules, total, nil
+}
+
+func (r *ReportScheduleRepo) CountByUserID(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+
+	err := r.db.WithContext(ctx).
+		Model(&model.ReportSchedule{}).
+		Where("user_id = ?", userID).
+		Count(&count).Error
+
+	return count, err
+}
+
+func (r *ReportScheduleRepo) Update(ctx context.Context, schedule model.ReportSchedule) error {
+	schedule.UpdatedAt = time.Now()
+
+	return r.db.WithContext(ctx).
+		Model(&model.ReportSchedule{ID: schedule.ID}).
+		Select(
+			"role",
+			"name",
+			"format",
+			"filter",
+			"period",
+			"cron",
+			"timezone",
+			"channel",
+			"recipients",
+			"webhook_id",
+			"is_active",
+			"next_run_at",
+			"updated_at",
+		).
+		Updates(&schedule).Error
+}
+
+// Delete also removes the schedule's runs through the foreign key; their
+// files must be removed first.
+func (r *ReportScheduleRepo) Delete(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Delete(&model.ReportSchedule{}).Error
+}
+
+// ClaimDue also takes back schedules whose lease has run out, which happens
+// when the worker running them died. SKIP LOCKED keeps several workers from
+// claiming the same schedule. Paused schedules have no next run, so they
+// are only picked up after RunNow.
+func (r *ReportScheduleRepo) ClaimDue(ctx context.Context, lease time.Duration) (*model.ReportSchedule, error) {
+	var ids []int64
+
+	now := time.Now()
+
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE report_schedules
+		SET lease_until = ?,
+			updated_at = ?
+		WHERE id = (
+			SELECT id
+			FROM report_schedules
+			WHERE next_run_at <= ?
+			AND (lease_until IS NULL OR lease_until < ?)
+			ORDER BY next_run_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id
+	`, now.Add(lease), now, now, now).Scan(&ids).Error
+
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	return r.FindByID(ctx, ids[0])
+}
+
+// Release leaves a schedule that was paused while it ran without a next
+// run.
+func (r *ReportScheduleRepo) Release(ctx context.Context, id int64, lastRunAt time.Time, nextRunAt *time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&model.ReportSchedule{ID: id}).
+		Updates(map[string]interface{}{
+			"last_run_at": lastRunAt,
+			"next_run_at": gorm.Expr("CASE WHEN is_active THEN ?::timestamptz ELSE NULL END", nextRunAt),
+			"lease_until": nil,
+			"updated_at":  time.Now(),
+		}).Error
+}
+
+func (r *ReportScheduleRepo) Disable(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).
+		Model(&model.ReportSchedule{ID: id}).
+		Updates(map[string]interface{}{
+			"is_active":   false,
+			"next_run_at": nil,
+			"lease_until": nil,
+			"updated_at":  time.Now(),
+		}).Error
+}
+
+func (r *ReportScheduleRepo) CreateRun(ctx context.Context, run model.ReportRun) (*model.ReportRun, error) {
+	now := time.Now()
+
+	run.CreatedAt = now
+	run.UpdatedAt = now
+
+	if err := r.db.WithContext(ctx).Create(&run).Error; err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+func (r *ReportScheduleRepo) FindRunByID(ctx context.Context, id int64) (*model.ReportRun, error) {
+	var run model.ReportRun
+
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&run).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.ErrReportRunNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+func (r *ReportScheduleRepo) FindRunsByScheduleID(ctx context.Context, scheduleID int64, page int, limit int) ([]*model.ReportRun, int64, error) {
+	var runs []*model.ReportRun
+	var total int64
+
+	offset := (page - 1) * limit
+
+	query := r.db.WithContext(ctx).
+		Model(&model.ReportRun{}).
+		Where("schedule_id = ?", scheduleID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return runs, total, nil
+}
+
+func (r *ReportScheduleRepo) FindRunFiles(ctx context.Context, scheduleID int64) ([]string, error) {
+	var keys []string
+
+	err := r.db.WithContext(ctx).
+		Model(&model.ReportRun{}).
+		Where("schedule_id = ? AND storage_key IS NOT NULL", scheduleID).
+		Pluck("storage_key", &keys).Error
+
+	return keys, err
+}
+
+func (r *ReportScheduleRepo) UpdateRun(ctx context.Context, run model.ReportRun) error {
+	run.UpdatedAt = time.Now()
+
+	return r.db.WithContext(ctx).
+		Model(&model.ReportRun{ID: run.ID}).
+		Select(
+			"status",
+			"start_date",
+			"end_date",
+			"total_rows",
+			"filename",
+			"size",
+			"storage_key",
+			"error",
+			"delivered_at",
+			"completed_at",
+			"updated_at",
+		).
+		Updates(&run).Error
+}
+
+func (r *ReportScheduleRepo) FindExpiredRuns(ctx context.Context, before time.Time, limit int) ([]*model.ReportRun, error) {
+	var runs []*model.ReportRun
+
+	err := r.db.WithContext(ctx).
+		Where("expires_at < ? AND storage_key IS NOT NULL", before).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&runs).Error
+
+	return runs, err
+}
+
+func (r *ReportScheduleRepo) ClearRunFile(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).
+		Model(&model.ReportRun{ID: id}).
+		Updates(map[string]interface{}{
+			"storage_key": nil,
+			"updated_at":  time.Now(),
+		}).Error
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"gorm.io/gorm"
+)
+
+type ResolutionSignatureRepo struct {
+	db *gorm.DB
+}
+
+func NewResolutionSignatureRepo(db *gorm.DB) model.IResolutionSignatureRepository {
+	return &ResolutionSignatureRepo{db: db}
+}
+
+func (r *ResolutionSignatureRepo) Create(ctx context.Context, tx interface{}, signatures []*model.ResolutionSignature) error {
+	if len(signatures) == 0 {
+		return nil
+	}
+
+	db := r.db
+
+	if tx != nil {
+		db = tx.(*gorm.DB)
+	}
+
+	return db.WithContext(ctx).Create(&signatures).Error
+}
+
+func (r *ResolutionSignatureRepo) FindByTicketIDs(ctx context.Context, ticketIDs []int64) ([]*model.ResolutionSignature, error) {
+	signatures := []*model.ResolutionSignature{}
+
+	if len(ticketIDs) == 0 {
+		return signatures, nil
+	}
+
+	err := r.db.WithContext(ctx).
+		Where("ticket_id IN ?", ticketIDs).
+		Order("signed_at ASC").
+		Find(&signatures).Error
+
+	return signatures, err
+}
@@ -46,6 +46,24 @@ func (r *RoleRepo) FindByID(ctx context.Context, id int64) (*model.Role, error)
 	return &role, nil
 }
 
+func (r *RoleRepo) FindByName(ctx context.Context, name string) (*model.Role, error) {
+	var role model.Role
+
+	err := r.db.WithContext(ctx).
+		Where("name = ? AND deleted_at IS NULL", name).
+		First(&role).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("role not found")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
 func (r *RoleRepo) FindAll(ctx context.Context, filter model.Role) ([]*model.Role, error) {
 	var roles []*model.Role
 
@@ -83,3 +101,13 @@ func (r *RoleRepo) Delete(ctx context.Context, id int64) error {
 		Where("id = ?", id).
 		Update("deleted_at", time.Now()).Error
 }
+
+func (r *RoleRepo) UpdateTwoFactorRequirement(ctx context.Context, id int64, required bool) error {
+	return r.db.WithContext(ctx).
+		Model(&model.Role{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"require_two_factor": required,
+			"updated_at":         time.Now(),
+		}).Error
+}
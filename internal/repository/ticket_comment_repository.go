@@ -15,13 +15,19 @@ func NewTicketCommentRepo(db *gorm.DB) model.ITicketCommentRepository {
 	return &TicketCommentRepo{db: db}
 }
 
-func (r *TicketCommentRepo) Create(ctx context.Context, comment model.TicketComment) (*model.TicketComment, error) {
-	if err := r.db.WithContext(ctx).
+func (r *TicketCommentRepo) Create(ctx context.Context, tx interface{}, comment model.TicketComment) (*model.TicketComment, error) {
+	db := r.db
+
+	if tx != nil {
+		db = tx.(*gorm.DB)
+	}
+
+	if err := db.WithContext(ctx).
 		Create(&comment).Error; err != nil {
 		return nil, err
 	}
 
-	if err := r.db.WithContext(ctx).
+	if err := db.WithContext(ctx).
 		Preload("User").
 		First(&comment, comment.ID).Error; err != nil {
 		return nil, err
@@ -30,6 +36,20 @@ func (r *TicketCommentRepo) Create(ctx context.Context, comment model.TicketComm
 	return &comment, nil
 }
 
+func (r *TicketCommentRepo) FindByID(ctx context.Context, id int64) (*model.TicketComment, error) {
+	var comment model.TicketComment
+
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&comment).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
 func (r *TicketCommentRepo) FindByTicketID(ctx context.Context, ticketID int64) ([]*model.TicketCommentResponse, error) {
 	var comments []*model.TicketCommentResponse
 
@@ -47,6 +47,20 @@ func (r *TicketRepo) FindByID(ctx context.Context, id int64) (*model.Ticket, err
 	return &ticket, nil
 }
 
+func (r *TicketRepo) FindByCode(ctx context.Context, code string) (*model.Ticket, error) {
+	var ticket model.Ticket
+
+	err := r.db.WithContext(ctx).
+		Where("ticket_code = ? AND deleted_at IS NULL", code).
+		First(&ticket).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &ticket, nil
+}
+
 func (r *TicketRepo) FindAll(ctx context.Context, filter model.Ticket, search string, startDate string, endDate string, page int, limit int, role string, userID int64) ([]*model.TicketResponse, int64, error) {
 	var tickets []*model.TicketResponse
 	var total int64
@@ -61,7 +75,6 @@ func (r *TicketRepo) FindAll(ctx context.Context, filter model.Ticket, search st
 		Joins("LEFT JOIN asset_ids ON asset_ids.id = tickets.asset_id").
 		Joins("LEFT JOIN users as reporter ON reporter.id = tickets.reporter_id").
 		Joins("LEFT JOIN users as assigned ON assigned.id = tickets.assigned_to_id").
-		Joins("LEFT JOIN ticket_resolutions ON ticket_resolutions.ticket_id = tickets.id").
 		Where("tickets.deleted_at IS NULL")
 
 	if search != "" {
@@ -178,9 +191,15 @@ func (r *TicketRepo) FindAll(ctx context.Context, filter model.Ticket, search st
 			tickets.reporter_id,
 			tickets.part_id,
 			tickets.asset_id,
-			tickets.attachment,
 			tickets.assigned_to_id,
-			ticket_resolutions.attachment_url AS solution_attachment,
+			` + firstAttachmentColumn(model.AttachmentOwnerTicket, "attachment") + `,
+			` + firstAttachmentColumn(model.AttachmentOwnerResolution, "solution_attachment") + `,
+			` + firstThumbnailColumn(model.AttachmentOwnerTicket, "attachment_thumbnail") + `,
+			` + firstThumbnailColumn(model.AttachmentOwnerResolution, "solution_thumbnail") + `,
+			` + signatureColumn(model.SignerTechnician, "signer_name", "technician_signed_by") + `,
+			` + signatureColumn(model.SignerTechnician, "signed_at", "technician_signed_at") + `,
+			` + signatureColumn(model.SignerReporter, "signer_name", "reporter_signed_by") + `,
+			` + signatureColumn(model.SignerReporter, "signed_at", "reporter_signed_at") + `,
 
 			projects.name as project_name,
 			locations.name as location_name,
@@ -246,6 +265,18 @@ func (r *TicketRepo) CountByProjectToday(ctx context.Context, projectID int64) (
 	return count, err
 }
 
+// NextID reserves an ID from the tickets sequence, so files can be stored
+// under the ticket's folder before the ticket row is inserted.
+func (r *TicketRepo) NextID(ctx context.Context) (int64, error) {
+	var id int64
+
+	err := r.db.WithContext(ctx).
+		Raw("SELECT nextval(pg_get_serial_sequence('tickets', 'id'))").
+		Scan(&id).Error
+
+	return id, err
+}
+
 func (r *TicketRepo) FindResponseByID(ctx context.Context, id int64) (*model.TicketResponse, error) {
 	var ticket model.TicketResponse
 
@@ -262,8 +293,13 @@ func (r *TicketRepo) FindResponseByID(ctx context.Context, id int64) (*model.Tic
 			tickets.reporter_id,
 			tickets.part_id,
 			tickets.asset_id,
-			tickets.attachment,
 			tickets.assigned_to_id,
+			`+firstAttachmentColumn(model.AttachmentOwnerTicket, "attachment")+`,
+			`+firstThumbnailColumn(model.AttachmentOwnerTicket, "attachment_thumbnail")+`,
+			`+signatureColumn(model.SignerTechnician, "signer_name", "technician_signed_by")+`,
+			`+signatureColumn(model.SignerTechnician, "signed_at", "technician_signed_at")+`,
+			`+signatureColumn(model.SignerReporter, "signer_name", "reporter_signed_by")+`,
+			`+signatureColumn(model.SignerReporter, "signed_at", "reporter_signed_at")+`,
 
 			projects.name as project_name,
 			locations.name as location_name,
@@ -287,3 +323,56 @@ func (r *TicketRepo) FindResponseByID(ctx context.Context, id int64) (*model.Tic
 
 	return &ticket, nil
 }
+
+func (r *TicketRepo) FindOverdue(ctx context.Context, limit int) ([]*model.Ticket, error) {
+	var tickets []*model.Ticket
+
+	err := r.db.WithContext(ctx).
+		Preload("Reporter").
+		Where("status = ? AND due_at < ? AND deleted_at IS NULL", model.StatusOpen, time.Now()).
+		Order("due_at ASC").
+		Limit(limit).
+		Find(&tickets).Error
+
+	return tickets, err
+}
+
+// firstAttachmentColumn selects the storage key of the earliest file of the
+// given owner type on the ticket, which list views show as its preview.
+func firstAttachmentColumn(ownerType model.AttachmentOwnerType, alias string) string {
+	return fmt.Sprintf(`(
+				SELECT a.storage_key
+				FROM attachments a
+				WHERE a.ticket_id = tickets.id
+				AND a.owner_type = '%s'
+				ORDER BY a.id ASC
+				LIMIT 1
+			) AS %s`, ownerType, alias)
+}
+
+// firstThumbnailColumn selects the thumbnail of the earliest photo of the
+// given owner type, skipping files that have no rendition.
+func firstThumbnailColumn(ownerType model.AttachmentOwnerType, alias string) string {
+	return fmt.Sprintf(`(
+				SELECT a.thumbnail_key
+				FROM attachments a
+				WHERE a.ticket_id = tickets.id
+				AND a.owner_type = '%s'
+				AND a.thumbnail_key IS NOT NULL
+				ORDER BY a.id ASC
+				LIMIT 1
+			) AS %s`, ownerType, alias)
+}
+
+// signatureColumn selects a column of the latest signature the given
+// party left on the ticket's resolutions.
+func signatureColumn(role model.SignerRole, column string, alias string) string {
+	return fmt.Sprintf(`(
+				SELECT s.%s
+				FROM resolution_signatures s
+				WHERE s.ticket_id = tickets.id
+				AND s.signer_role = '%s'
+				ORDER BY s.signed_at DESC
+				LIMIT 1
+			) AS %s`, column, role, alias)
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"gorm.io/gorm"
+)
+
+type UserIdentityRepo struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepo(db *gorm.DB) model.IUserIdentityRepository {
+	return &UserIdentityRepo{db: db}
+}
+
+func (r *UserIdentityRepo) FindByProviderSubject(ctx context.Context, provider string, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+
+	err := r.db.WithContext(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("identity not found")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+func (r *UserIdentityRepo) Create(ctx context.Context, identity model.UserIdentity) error {
+	identity.CreatedAt = time.Now()
+
+	return r.db.WithContext(ctx).Create(&identity).Error
+}
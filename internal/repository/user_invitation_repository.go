@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"gorm.io/gorm"
+)
+
+type UserInvitationRepo struct {
+	db *gorm.DB
+}
+
+func NewUserInvitationRepo(db *gorm.DB) model.IUserInvitationRepository {
+	return &UserInvitationRepo{db: db}
+}
+
+func (r *UserInvitationRepo) Create(ctx context.Context, invitation model.UserInvitation) (*model.UserInvitation, error) {
+	invitation.CreatedAt = time.Now()
+
+	if err := r.db.WithContext(ctx).Create(&invitation).Error; err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+func (r *UserInvitationRepo) FindValidByTokenHash(ctx context.Context, tokenHash string) (*model.UserInvitation, error) {
+	var invitation model.UserInvitation
+
+	err := r.db.WithContext(ctx).
+		Where("token_hash = ? AND accepted_at IS NULL AND expires_at > ?", tokenHash, time.Now()).
+		First(&invitation).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("invitation is invalid or expired")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+func (r *UserInvitationRepo) RevokePending(ctx context.Context, userID int64) error {
+	return r.db.WithContext(ctx).
+		Model(&model.UserInvitation{}).
+		Where("user_id = ? AND accepted_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Update("expires_at", time.Now()).Error
+}
+
+func (r *UserInvitationRepo) MarkAsAccepted(ctx context.Context, id int64) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.UserInvitation{}).
+		Where("id = ? AND accepted_at IS NULL", id).
+		Update("accepted_at", time.Now())
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("invitation is invalid or expired")
+	}
+
+	return nil
+}
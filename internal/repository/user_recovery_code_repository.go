@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"gorm.io/gorm"
+)
+
+type UserRecoveryCodeRepo struct {
+	db *gorm.DB
+}
+
+func NewUserRecoveryCodeRepo(db *gorm.DB) model.IUserRecoveryCodeRepository {
+	return &UserRecoveryCodeRepo{db: db}
+}
+
+func (r *UserRecoveryCodeRepo) Replace(ctx context.Context, userID int64, codeHashes []string) error {
+	tx := r.db.WithContext(ctx).Begin()
+
+	if err := tx.Where("user_id = ?", userID).
+		Delete(&model.UserRecoveryCode{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	now := time.Now()
+
+	for _, hash := range codeHashes {
+		code := model.UserRecoveryCode{
+			UserID:    userID,
+			CodeHash:  hash,
+			CreatedAt: now,
+		}
+
+		if err := tx.Create(&code).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *UserRecoveryCodeRepo) Use(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *UserRecoveryCodeRepo) DeleteByUserID(ctx context.Context, userID int64) error {
+	return r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Delete(&model.UserRecoveryCode{}).Error
+}
@@ -62,7 +62,7 @@ func (r *UserRepo) FindByID(ctx context.Context, id int64) (*model.User, error)
 		First(&user).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errors.New("user not found")
+		return nil, model.ErrUserNotFound
 	}
 
 	if err != nil {
@@ -80,7 +80,7 @@ func (r *UserRepo) FindByEmail(ctx context.Context, email string) (*model.User,
 		First(&user).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errors.New("user not found")
+		return nil, model.ErrUserNotFound
 	}
 	if err != nil {
 		return nil, err
@@ -129,6 +129,10 @@ func (r *UserRepo) FindAll(ctx context.Context, filter model.User, page int, lim
 		query = query.Where("users.role_id = ?", filter.RoleID)
 	}
 
+	if filter.IsServiceAccount {
+		query = query.Where("users.is_service_account = true")
+	}
+
 	if filter.IsActive {
 		query = query.Where("users.is_active = ?", true)
 	}
@@ -167,6 +171,7 @@ func (r *UserRepo) Update(ctx context.Context, user model.User) error {
 			"password":   user.Password,
 			"role_id":    user.RoleID,
 			"is_active":  user.IsActive,
+			"language":   user.Language,
 			"updated_at": now,
 		}).Error; err != nil {
 		tx.Rollback()
@@ -237,3 +242,105 @@ func (r *UserRepo) UpdateLastSeen(ctx context.Context, userID int64) error {
 		Where("id = ?", userID).
 		Update("last_seen", time.Now()).Error
 }
+
+func (r *UserRepo) Lock(ctx context.Context, userID int64) error {
+	return r.db.WithContext(ctx).
+		Model(&model.User{}).
+		Where("id = ? AND locked_at IS NULL", userID).
+		Update("locked_at", time.Now()).Error
+}
+
+func (r *UserRepo) Unlock(ctx context.Context, userID int64) error {
+	return r.db.WithContext(ctx).
+		Model(&model.User{}).
+		Where("id = ?", userID).
+		Update("locked_at", nil).Error
+}
+
+// UpdateTwoFactor stores a new secret, or clears it, and forgets the last
+// used TOTP step since it belonged to the previous secret.
+func (r *UserRepo) UpdateTwoFactor(ctx context.Context, userID int64, secret *string, enabled bool) error {
+	return r.db.WithContext(ctx).
+		Model(&model.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"two_factor_secret":    secret,
+			"two_factor_enabled":   enabled,
+			"two_factor_last_step": nil,
+			"updated_at":           time.Now(),
+		}).Error
+}
+
+// EnableTwoFactor turns on two factor authentication for the secret already
+// enrolled. It keeps the last used TOTP step, so the code that confirmed the
+// enrolment cannot be replayed to log in.
+func (r *UserRepo) EnableTwoFactor(ctx context.Context, userID int64) error {
+	return r.db.WithContext(ctx).
+		Model(&model.User{}).
+		Where("id = ? AND two_factor_secret IS NOT NULL", userID).
+		Updates(map[string]interface{}{
+			"two_factor_enabled": true,
+			"updated_at":         time.Now(),
+		}).Error
+}
+
+// UseTOTPStep records step as the user's last accepted TOTP time step. It
+// reports false when that step or a later one was already used, so a code
+// cannot be replayed within its validity window.
+func (r *UserRepo) UseTOTPStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&model.User{}).
+		Where("id = ? AND (two_factor_last_step IS NULL OR two_factor_last_step < ?)", userID, step).
+		Update("two_factor_last_step", step)
+
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+func (r *UserRepo) FindByTelegramChatID(ctx context.Context, chatID int64) (*model.User, error) {
+	var user model.User
+
+	err := r.db.WithContext(ctx).
+		Preload("Role").
+		Where("telegram_chat_id = ? AND deleted_at IS NULL", chatID).
+		First(&user).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.ErrUserNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *UserRepo) UpdateTelegramChatID(ctx context.Context, userID int64, chatID *int64) error {
+	tx := r.db.WithContext(ctx).Begin()
+
+	// A Telegram chat can only be linked to one account at a time.
+	if chatID != nil {
+		if err := tx.Model(&model.User{}).
+			Where("telegram_chat_id = ? AND id <> ?", *chatID, userID).
+			Update("telegram_chat_id", nil).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Model(&model.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"telegram_chat_id": chatID,
+			"updated_at":       time.Now(),
+		}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
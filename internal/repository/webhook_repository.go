@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepo struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepo(db *gorm.DB) model.IWebhookRepository {
+	return &WebhookRepo{db: db}
+}
+
+func (r *WebhookRepo) FindAll(ctx context.Context) ([]*model.Webhook, error) {
+	var webhooks []*model.Webhook
+
+	err := r.db.WithContext(ctx).
+		Where("deleted_at IS NULL").
+		Order("id ASC").
+		Find(&webhooks).Error
+
+	return webhooks, err
+}
+
+func (r *WebhookRepo) FindActive(ctx context.Context) ([]*model.Webhook, error) {
+	var webhooks []*model.Webhook
+
+	err := r.db.WithContext(ctx).
+		Where("deleted_at IS NULL AND is_active = true").
+		Find(&webhooks).Error
+
+	return webhooks, err
+}
+
+func (r *WebhookRepo) FindByID(ctx context.Context, id int64) (*model.Webhook, error) {
+	var webhook model.Webhook
+
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND deleted_at IS NULL", id).
+		First(&webhook).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("webhook not found")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func (r *WebhookRepo) Create(ctx context.Context, webhook model.Webhook) (*model.Webhook, error) {
+	now := time.Now()
+
+	webhook.CreatedAt = now
+	webhook.UpdatedAt = now
+
+	if err := r.db.WithContext(ctx).Create(&webhook).Error; err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func (r *WebhookRepo) Update(ctx context.Context, webhook model.Webhook) error {
+	webhook.UpdatedAt = time.Now()
+
+	return r.db.WithContext(ctx).
+		Model(&model.Webhook{ID: webhook.ID}).
+		Select("name", "url", "events", "project_ids", "is_active", "updated_at").
+		Updates(&webhook).Error
+}
+
+func (r *WebhookRepo) Delete(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).
+		Model(&model.Webhook{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Update("deleted_at", time.Now()).Error
+}
+
+func (r *WebhookRepo) CreateDelivery(ctx context.Context, delivery model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	now := time.Now()
+
+	delivery.CreatedAt = now
+	delivery.UpdatedAt = now
+
+	if delivery.NextAttemptAt.IsZero() {
+		delivery.NextAttemptAt = now
+	}
+
+	if err := r.db.WithContext(ctx).Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// CreateDeliveries queues the deliveries of one event in a single insert. A
+// webhook that already has a delivery for the event is skipped, so an event
+// redelivered by the broker is not sent twice.
+func (r *WebhookRepo) CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+
+	for i := range deliveries {
+		deliveries[i].CreatedAt = now
+		deliveries[i].UpdatedAt = now
+
+		if deliveries[i].NextAttemptAt.IsZero() {
+			deliveries[i].NextAttemptAt = now
+		}
+	}
+
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: "webhook_id"},
+				{Name: "event_id"},
+			},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{
+				clause.Expr{SQL: "event_id IS NOT NULL"},
+			}},
+			DoNothing: true,
+		}).
+		Create(&deliveries).Error
+}
+
+func (r *WebhookRepo) FindDeliveryByID(ctx context.Context, id int64) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&delivery).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("webhook delivery not found")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+func (r *WebhookRepo) FindDeliveriesByWebhookID(ctx context.Context, webhookID int64, status string, page int, limit int) ([]*model.WebhookDelivery, int64, error) {
+	var deliveries []*model.WebhookDelivery
+	var total int64
+
+	offset := (page - 1) * limit
+
+	query := r.db.WithContext(ctx).
+		Model(&model.WebhookDelivery{}).
+		Where("webhook_id = ?", webhookID)
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&deliveries).Error
+
+	return deliveries, total, err
+}
+
+// ClaimDueDeliveries pushes next_attempt_at forward by the lease while
+// selecting, so several replicas polling at once never send the same
+// delivery twice.
+func (r *WebhookRepo) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*model.WebhookDelivery, error) {
+	var deliveries []*model.WebhookDelivery
+
+	now := time.Now()
+
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE webhook_deliveries
+		SET next_attempt_at = ?
+		WHERE id IN (
+			SELECT id
+			FROM webhook_deliveries
+			WHERE status = 'PENDING' AND next_attempt_at <= ?
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`, now.Add(lease), now, limit).Scan(&deliveries).Error
+
+	return deliveries, err
+}
+
+func (r *WebhookRepo) UpdateDelivery(ctx context.Context, delivery model.WebhookDelivery) error {
+	delivery.UpdatedAt = time.Now()
+
+	return r.db.WithContext(ctx).
+		Model(&model.WebhookDelivery{ID: delivery.ID}).
+		Select(
+			"status",
+			"attempts",
+			"response_status",
+			"response_body",
+			"error",
+			"next_attempt_at",
+			"delivered_at",
+			"updated_at",
+		).
+		Updates(&delivery).Error
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+// CloudinaryStorage stores every file as a raw asset whose public ID is the
+// storage key, so the delivery URL can be rebuilt from the key alone.
+type CloudinaryStorage struct {
+	cld    *cloudinary.Cloudinary
+	client *http.Client
+}
+
+func NewCloudinaryStorage(cloudName, apiKey, apiSecret string) (model.IStorage, error) {
+	cld, err := cloudinary.NewFromParams(cloudName, apiKey, apiSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	cld.Config.URL.Secure = true
+
+	return &CloudinaryStorage{
+		cld: cld,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}, nil
+}
+
+func (s *CloudinaryStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := s.cld.Upload.Upload(ctx, r, uploader.UploadParams{
+		PublicID:       key,
+		ResourceType:   api.File,
+		Overwrite:      api.Bool(true),
+		UniqueFilename: api.Bool(false),
+	})
+
+	return err
+}
+
+func (s *CloudinaryStorage) Get(ctx context.Context, key string) (io.ReadCloser, *model.StoredObject, error) {
+	url, err := s.SignedURL(ctx, key, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, nil, model.ErrObjectNotFound
+	case resp.StatusCode != http.StatusOK:
+		resp.Body.Close()
+		return nil, nil, fmt.Errorf("cloudinary responded %s", resp.Status)
+	}
+
+	return resp.Body, &model.StoredObject{
+		Key:         key,
+		ContentType: resp.Header.Get("Content-Type"),
+		Size:        resp.ContentLength,
+	}, nil
+}
+
+func (s *CloudinaryStorage) Delete(ctx context.Context, key string) error {
+	_, err := s.cld.Upload.Destroy(ctx, uploader.DestroyParams{
+		PublicID:     key,
+		ResourceType: api.File,
+	})
+
+	return err
+}
+
+func (s *CloudinaryStorage) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	asset, err := s.cld.File(key)
+	if err != nil {
+		return "", err
+	}
+
+	return asset.String()
+}
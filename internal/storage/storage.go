@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+// New builds the backend selected by storage.driver. A driver that is set
+// explicitly has to work, so a typo or a broken cloud account stops the
+// API from starting instead of silently writing to local disk. Only when
+// storage.driver is unset and the guessed driver cannot be initialised is
+// the local filesystem used instead.
+func New() (model.IStorage, error) {
+	if config.StorageURLSecret() == "" {
+		return nil, errors.New("storage.url_secret is required to sign file links")
+	}
+
+	driver := config.StorageDriver()
+
+	store, err := newDriver(driver)
+	if err == nil {
+		logrus.Infof("storage driver: %s", driver)
+		return store, nil
+	}
+
+	if config.StorageDriverConfigured() {
+		return nil, fmt.Errorf("storage driver %q: %w", driver, err)
+	}
+
+	logrus.Errorf("storage driver %q init failed, falling back to local: %v", driver, err)
+
+	return NewLocalStorage(config.StorageLocalRoot())
+}
+
+func newDriver(driver string) (model.IStorage, error) {
+	switch driver {
+	case "cloudinary":
+		return NewCloudinaryStorage(
+			config.CloudinaryCloudName(),
+			config.CloudinaryAPIKey(),
+			config.CloudinaryAPISecret(),
+		)
+	case "s3":
+		return NewS3Storage(
+			config.StorageS3Endpoint(),
+			config.StorageS3AccessKey(),
+			config.StorageS3SecretKey(),
+			config.StorageS3Bucket(),
+			config.StorageS3Region(),
+			config.StorageS3UseSSL(),
+		)
+	case "local":
+		return NewLocalStorage(config.StorageLocalRoot())
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", driver)
+	}
+}
+
+// preferredExtensions picks the usual extension for types that have
+// several registered.
+var preferredExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+	"text/plain":      ".txt",
+	"text/csv":        ".csv",
+}
+
+// Upload stores the file under a fresh random key inside folder and
+// returns the key. The extension follows contentType, which callers sniff
+// from the content, so a file named "x.html" cannot be stored as HTML.
+func Upload(ctx context.Context, store model.IStorage, folder string, filename string, r io.Reader, size int64, contentType string) (string, error) {
+	token, err := helper.GenerateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	key := path.Join(folder, token+extension(filename, contentType))
+
+	if err := store.Put(ctx, key, r, size, contentType); err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+// extension keeps the filename's extension when it is registered for
+// contentType and otherwise derives one from contentType.
+func extension(filename string, contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+
+	exts, _ := mime.ExtensionsByType(mediaType)
+
+	ext := strings.ToLower(path.Ext(filename))
+	if ext != "" && slices.Contains(exts, ext) {
+		return ext
+	}
+
+	if ext, ok := preferredExtensions[mediaType]; ok {
+		return ext
+	}
+
+	if len(exts) > 0 {
+		return exts[0]
+	}
+
+	return ""
+}
+
+// ReadAll loads a stored file. Absolute URLs saved before the storage
+// backends existed are downloaded directly.
+func ReadAll(ctx context.Context, store model.IStorage, ref string) ([]byte, error) {
+	if isAbsoluteURL(ref) {
+		return helper.DownloadFile(ctx, ref)
+	}
+
+	reader, _, err := store.Get(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+// Remove deletes a stored file. Legacy absolute URLs are left alone since
+// they do not live in the configured backend.
+func Remove(ctx context.Context, store model.IStorage, ref string) error {
+	if ref == "" || isAbsoluteURL(ref) {
+		return nil
+	}
+
+	return store.Delete(ctx, ref)
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+)
+
+const FilesPath = "/v1/files/"
+
+var ErrInvalidSignature = errors.New("file link is invalid or expired")
+
+// URL resolves a stored file reference for API responses. References saved
+// before the storage backends existed are absolute URLs and pass through.
+func URL(ref string) string {
+	if ref == "" || isAbsoluteURL(ref) {
+		return ref
+	}
+
+	return SignedURL(ref, config.StorageURLTTL())
+}
+
+func URLPtr(ref *string) *string {
+	if ref == nil {
+		return nil
+	}
+
+	resolved := URL(*ref)
+	return &resolved
+}
+
+// SignedURL links to the API file endpoint, which checks the signature and
+// then redirects to the backend or streams the file itself.
+func SignedURL(key string, ttl time.Duration) string {
+	expires := time.Now().Add(ttl).Unix()
+
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return fmt.Sprintf(
+		"%s%s%s?expires=%d&signature=%s",
+		config.APIURL(),
+		FilesPath,
+		strings.Join(segments, "/"),
+		expires,
+		urlSignature(key, expires),
+	)
+}
+
+func VerifyURL(key string, expires string, signature string) error {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return ErrInvalidSignature
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(urlSignature(key, expiresAt))) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func urlSignature(key string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(config.StorageURLSecret()))
+	mac.Write([]byte(fmt.Sprintf("%s:%d", key, expires)))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func isAbsoluteURL(ref string) bool {
+	return strings.HasPrefix(ref, "https://") || strings.HasPrefix(ref, "http://")
+}
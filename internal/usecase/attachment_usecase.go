@@ -0,0 +1,622 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/imaging"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/storage"
+	"gorm.io/gorm"
+)
+
+type AttachmentUsecase struct {
+	attachmentRepo model.IAttachmentRepository
+	quarantineRepo model.IQuarantinedFileRepository
+	ticketRepo     model.ITicketRepository
+	commentRepo    model.ITicketCommentRepository
+	resolutionRepo model.ITicketResolutionRepository
+	projectRepo    model.IProjectRepository
+	store          model.IStorage
+	scanner        model.IVirusScanner
+}
+
+func NewAttachmentUsecase(
+	attachmentRepo model.IAttachmentRepository,
+	quarantineRepo model.IQuarantinedFileRepository,
+	ticketRepo model.ITicketRepository,
+	commentRepo model.ITicketCommentRepository,
+	resolutionRepo model.ITicketResolutionRepository,
+	projectRepo model.IProjectRepository,
+	store model.IStorage,
+	scanner model.IVirusScanner,
+) model.IAttachmentUsecase {
+	return &AttachmentUsecase{
+		attachmentRepo: attachmentRepo,
+		quarantineRepo: quarantineRepo,
+		ticketRepo:     ticketRepo,
+		commentRepo:    commentRepo,
+		resolutionRepo: resolutionRepo,
+		projectRepo:    projectRepo,
+		store:          store,
+		scanner:        scanner,
+	}
+}
+
+// Attach uploads the files and records them, removing the uploads again
+// when the insert fails.
+func (u *AttachmentUsecase) Attach(ctx context.Context, tx interface{}, uploaderID int64, owner model.AttachmentOwner, files []model.AttachmentUpload) ([]*model.Attachment, error) {
+	attachments, err := u.Prepare(ctx, uploaderID, owner, files)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.Save(ctx, tx, owner, attachments); err != nil {
+		u.Discard(ctx, attachments)
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+// Prepare validates, scans and prepares every file before uploading any of
+// them, then removes what was already uploaded when a later upload fails.
+// Scanning and uploading can take a while, so callers run it before they
+// open a transaction.
+func (u *AttachmentUsecase) Prepare(ctx context.Context, uploaderID int64, owner model.AttachmentOwner, files []model.AttachmentUpload) ([]*model.Attachment, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"owner_type": owner.Type,
+		"owner_id":   owner.ID,
+		"ticket_id":  owner.TicketID,
+	})
+
+	if len(files) > config.AttachmentMaxFiles() {
+		return nil, fmt.Errorf("%w: at most %d files per upload", model.ErrAttachmentTooMany, config.AttachmentMaxFiles())
+	}
+
+	allowed := u.allowedMimeTypes(ctx, owner.ProjectID)
+
+	inspected := make([]*inspectedFile, 0, len(files))
+
+	for _, file := range files {
+		checked, err := inspectAttachment(file, allowed)
+		if err != nil {
+			return nil, err
+		}
+
+		inspected = append(inspected, checked)
+	}
+
+	for _, file := range inspected {
+		if err := u.scan(ctx, uploaderID, owner, file); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, file := range inspected {
+		if err := processImage(file); err != nil {
+			return nil, err
+		}
+	}
+
+	attachments := make([]*model.Attachment, 0, len(files))
+
+	for _, file := range inspected {
+		attachment, err := u.upload(ctx, uploaderID, owner, file)
+		if err != nil {
+			log.Error("Failed upload attachment:", err)
+			u.Discard(ctx, attachments)
+			return nil, fmt.Errorf("failed upload attachment %s: %w", file.filename, err)
+		}
+
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, nil
+}
+
+// Save records prepared attachments for owner, whose ID is known by now,
+// joining tx when one is given.
+func (u *AttachmentUsecase) Save(ctx context.Context, tx interface{}, owner model.AttachmentOwner, attachments []*model.Attachment) error {
+	for _, attachment := range attachments {
+		attachment.OwnerID = owner.ID
+		attachment.TicketID = owner.TicketID
+	}
+
+	if err := u.attachmentRepo.Create(ctx, tx, attachments); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"owner_type": owner.Type,
+			"owner_id":   owner.ID,
+			"ticket_id":  owner.TicketID,
+		}).Error("Failed save attachments:", err)
+		return err
+	}
+
+	resolveAttachmentURLs(attachments)
+
+	return nil
+}
+
+// Discard removes the uploads of attachments that were not recorded.
+func (u *AttachmentUsecase) Discard(ctx context.Context, attachments []*model.Attachment) {
+	for _, attachment := range attachments {
+		u.removeFiles(ctx, attachment)
+	}
+}
+
+func (u *AttachmentUsecase) Upload(ctx context.Context, userID int64, role string, owner model.AttachmentOwner, files []model.AttachmentUpload) ([]*model.Attachment, error) {
+	if len(files) == 0 {
+		return nil, errors.New("no files uploaded")
+	}
+
+	owner, err := u.authorizeUpload(ctx, userID, role, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.Attach(ctx, nil, userID, owner, files)
+}
+
+func (u *AttachmentUsecase) FindByOwner(ctx context.Context, ownerType model.AttachmentOwnerType, ownerID int64) ([]*model.Attachment, error) {
+	attachments, err := u.attachmentRepo.FindByOwner(ctx, ownerType, []int64{ownerID})
+	if err != nil {
+		return nil, err
+	}
+
+	resolveAttachmentURLs(attachments)
+
+	return attachments, nil
+}
+
+func (u *AttachmentUsecase) FindByOwners(ctx context.Context, ownerType model.AttachmentOwnerType, ownerIDs []int64) (map[int64][]*model.Attachment, error) {
+	attachments, err := u.attachmentRepo.FindByOwner(ctx, ownerType, ownerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	resolveAttachmentURLs(attachments)
+
+	grouped := make(map[int64][]*model.Attachment)
+	for _, attachment := range attachments {
+		grouped[attachment.OwnerID] = append(grouped[attachment.OwnerID], attachment)
+	}
+
+	return grouped, nil
+}
+
+func (u *AttachmentUsecase) FindByTicketID(ctx context.Context, ticketID int64, userID int64, role string, ownerType model.AttachmentOwnerType) ([]*model.Attachment, error) {
+	if _, err := u.findAccessibleTicket(ctx, ticketID, userID, role); err != nil {
+		return nil, err
+	}
+
+	attachments, err := u.attachmentRepo.FindByTicketID(ctx, ticketID, ownerType)
+	if err != nil {
+		return nil, err
+	}
+
+	resolveAttachmentURLs(attachments)
+
+	return attachments, nil
+}
+
+// Delete lets the uploader or an administrator remove a file while the
+// ticket is still open.
+func (u *AttachmentUsecase) Delete(ctx context.Context, id int64, userID int64, role string) error {
+	log := logrus.WithFields(logrus.Fields{
+		"attachment_id": id,
+		"user_id":       userID,
+	})
+
+	attachment, err := u.attachmentRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	ticket, err := u.findAccessibleTicket(ctx, attachment.TicketID, userID, role)
+	if err != nil {
+		return err
+	}
+
+	if role != "ADMINISTRATOR" {
+		if attachment.UploadedBy == nil || *attachment.UploadedBy != userID {
+			return model.ErrAttachmentForbidden
+		}
+
+		if ticket.Status == model.StatusClosed {
+			return fmt.Errorf("%w: ticket is already closed", model.ErrAttachmentForbidden)
+		}
+	}
+
+	if err := u.attachmentRepo.Delete(ctx, id); err != nil {
+		log.Error("Failed delete attachment:", err)
+		return err
+	}
+
+	u.removeFiles(ctx, attachment)
+
+	return nil
+}
+
+// authorizeUpload checks the uploader may add files to the owner and fills
+// in the resolution ID, which callers only know by ticket.
+func (u *AttachmentUsecase) authorizeUpload(ctx context.Context, userID int64, role string, owner model.AttachmentOwner) (model.AttachmentOwner, error) {
+	ticket, err := u.findAccessibleTicket(ctx, owner.TicketID, userID, role)
+	if err != nil {
+		return owner, err
+	}
+
+	if ticket.Status == model.StatusClosed && role != "ADMINISTRATOR" {
+		return owner, fmt.Errorf("%w: ticket is already closed", model.ErrAttachmentForbidden)
+	}
+
+	owner.ProjectID = ticket.ProjectID
+
+	switch owner.Type {
+	case model.AttachmentOwnerTicket:
+		owner.ID = ticket.ID
+
+	case model.AttachmentOwnerComment:
+		comment, err := u.commentRepo.FindByID(ctx, owner.ID)
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && comment.TicketID != ticket.ID) {
+			return owner, model.ErrAttachmentOwnerNotFound
+		}
+
+		if err != nil {
+			return owner, err
+		}
+
+		if comment.UserID != userID && role != "ADMINISTRATOR" {
+			return owner, model.ErrAttachmentForbidden
+		}
+
+	case model.AttachmentOwnerResolution:
+		if role != "STAFF" && role != "ADMINISTRATOR" {
+			return owner, model.ErrAttachmentForbidden
+		}
+
+		resolution, err := u.resolutionRepo.FindByTicketID(ctx, ticket.ID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return owner, model.ErrAttachmentOwnerNotFound
+		}
+
+		if err != nil {
+			return owner, err
+		}
+
+		owner.ID = resolution.ID
+
+	default:
+		return owner, errors.New("unknown attachment owner type")
+	}
+
+	return owner, nil
+}
+
+// findAccessibleTicket applies the same visibility as the ticket list:
+// reporters see their own tickets, staff the ones assigned to them.
+func (u *AttachmentUsecase) findAccessibleTicket(ctx context.Context, ticketID int64, userID int64, role string) (*model.Ticket, error) {
+	ticket, err := u.ticketRepo.FindByID(ctx, ticketID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.ErrAttachmentOwnerNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	isAssignee := ticket.AssignedToID != nil && *ticket.AssignedToID == userID
+
+	if role != "ADMINISTRATOR" && ticket.ReporterID != userID && !isAssignee {
+		return nil, model.ErrAttachmentForbidden
+	}
+
+	return ticket, nil
+}
+
+func (u *AttachmentUsecase) FindQuarantined(ctx context.Context, page int, limit int) ([]*model.QuarantinedFile, int64, error) {
+	return u.quarantineRepo.FindAll(ctx, page, limit)
+}
+
+func (u *AttachmentUsecase) allowedMimeTypes(ctx context.Context, projectID int64) []string {
+	if projectID != 0 {
+		project, err := u.projectRepo.FindByID(ctx, projectID)
+		if err == nil && len(project.AllowedMimeTypes) > 0 {
+			return project.AllowedMimeTypes
+		}
+	}
+
+	return config.AttachmentAllowedMimeTypes()
+}
+
+// inspectedFile is an upload whose real type and size have been checked.
+// image holds the renditions of photos once processImage has run.
+type inspectedFile struct {
+	filename string
+	mimeType string
+	size     int64
+	content  io.ReadSeeker
+	image    *imaging.Result
+}
+
+// inspectAttachment sniffs the content type from the file itself and
+// applies the allow-list and size limits, leaving the reader rewound.
+func inspectAttachment(file model.AttachmentUpload, allowed []string) (*inspectedFile, error) {
+	filename := attachmentFilename(file.Filename)
+
+	head := make([]byte, 512)
+
+	n, err := io.ReadFull(file.Content, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+
+	size, err := file.Content.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := file.Content.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	mimeType := helper.DetectMimeType(head[:n], filename)
+
+	if !helper.MatchMimeType(mimeType, allowed) {
+		return nil, fmt.Errorf("%w: %s (%s)", model.ErrAttachmentTypeRejected, filename, mimeType)
+	}
+
+	limit := config.AttachmentMaxFileSize()
+	if strings.HasPrefix(mimeType, "video/") {
+		limit = config.AttachmentMaxVideoSize()
+	}
+
+	if size > limit {
+		return nil, fmt.Errorf("%w: %s exceeds %d MB", model.ErrAttachmentTooLarge, filename, limit>>20)
+	}
+
+	return &inspectedFile{
+		filename: filename,
+		mimeType: mimeType,
+		size:     size,
+		content:  file.Content,
+	}, nil
+}
+
+// scan rejects infected files, moving them to quarantine instead of the
+// ticket's folder. When the scanner is down the upload is refused unless
+// antivirus.fail_open is set.
+func (u *AttachmentUsecase) scan(ctx context.Context, uploaderID int64, owner model.AttachmentOwner, file *inspectedFile) error {
+	log := logrus.WithFields(logrus.Fields{
+		"filename":  file.filename,
+		"ticket_id": owner.TicketID,
+	})
+
+	result, err := u.scanner.Scan(ctx, file.content)
+
+	if _, seekErr := file.content.Seek(0, io.SeekStart); seekErr != nil {
+		return seekErr
+	}
+
+	if err != nil {
+		if config.AntivirusFailOpen() {
+			log.Warn("virus scan failed, accepting file unscanned:", err)
+			return nil
+		}
+
+		log.Error("virus scan failed:", err)
+		return fmt.Errorf("virus scan unavailable: %w", err)
+	}
+
+	if !result.Infected {
+		return nil
+	}
+
+	log.Warnf("infected upload quarantined: %s", result.Signature)
+
+	key, checksum, err := u.put(ctx, "quarantine", file)
+	if err != nil {
+		log.Error("Failed store quarantined file:", err)
+	} else {
+		quarantined := model.QuarantinedFile{
+			Filename:   file.filename,
+			MimeType:   file.mimeType,
+			Size:       file.size,
+			Checksum:   checksum,
+			Signature:  result.Signature,
+			StorageKey: key,
+		}
+
+		if owner.TicketID != 0 {
+			quarantined.TicketID = &owner.TicketID
+		}
+
+		if owner.ProjectID != 0 {
+			quarantined.ProjectID = &owner.ProjectID
+		}
+
+		if uploaderID != 0 {
+			quarantined.UploadedBy = &uploaderID
+		}
+
+		if _, err := u.quarantineRepo.Create(ctx, quarantined); err != nil {
+			log.Error("Failed record quarantined file:", err)
+		}
+	}
+
+	return fmt.Errorf("%w: %s (%s)", model.ErrAttachmentInfected, file.filename, result.Signature)
+}
+
+// processImage replaces a photo's content with the copy stripped of EXIF
+// and turned upright, and renders its thumbnail and preview. Other files
+// are left alone.
+func processImage(file *inspectedFile) error {
+	if !imaging.Supported(file.mimeType) {
+		return nil
+	}
+
+	data, err := io.ReadAll(file.content)
+	if err != nil {
+		return err
+	}
+
+	result, err := imaging.Process(data, file.mimeType, imaging.Options{
+		ThumbnailSize: config.ImageThumbnailSize(),
+		PreviewSize:   config.ImagePreviewSize(),
+		JPEGQuality:   config.ImageJPEGQuality(),
+		MaxPixels:     config.ImageMaxPixels(),
+	})
+
+	if errors.Is(err, imaging.ErrTooManyPixels) {
+		return fmt.Errorf("%w: %s is larger than %d megapixels", model.ErrAttachmentTooLarge, file.filename, config.ImageMaxPixels()/1_000_000)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %s is not a readable image", model.ErrAttachmentTypeRejected, file.filename)
+	}
+
+	file.content = bytes.NewReader(result.Content)
+	file.size = int64(len(result.Content))
+	file.image = result
+
+	return nil
+}
+
+func (u *AttachmentUsecase) upload(ctx context.Context, uploaderID int64, owner model.AttachmentOwner, file *inspectedFile) (*model.Attachment, error) {
+	folder := fmt.Sprintf("tickets/%d/%s", owner.TicketID, strings.ToLower(string(owner.Type)))
+
+	key, checksum, err := u.put(ctx, folder, file)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := &model.Attachment{
+		OwnerType:  owner.Type,
+		OwnerID:    owner.ID,
+		TicketID:   owner.TicketID,
+		Filename:   file.filename,
+		MimeType:   file.mimeType,
+		Size:       file.size,
+		Checksum:   checksum,
+		StorageKey: key,
+	}
+
+	if uploaderID != 0 {
+		attachment.UploadedBy = &uploaderID
+	}
+
+	if file.image != nil {
+		if err := u.uploadRenditions(ctx, folder, file.image, attachment); err != nil {
+			u.removeFiles(ctx, attachment)
+			return nil, err
+		}
+	}
+
+	return attachment, nil
+}
+
+func (u *AttachmentUsecase) uploadRenditions(ctx context.Context, folder string, image *imaging.Result, attachment *model.Attachment) error {
+	attachment.Width = &image.Width
+	attachment.Height = &image.Height
+
+	renditions := []struct {
+		name string
+		data []byte
+		key  **string
+	}{
+		{"thumbnail.jpg", image.Thumbnail, &attachment.ThumbnailKey},
+		{"preview.jpg", image.Preview, &attachment.PreviewKey},
+	}
+
+	for _, rendition := range renditions {
+		key, err := storage.Upload(
+			ctx,
+			u.store,
+			folder+"/renditions",
+			rendition.name,
+			bytes.NewReader(rendition.data),
+			int64(len(rendition.data)),
+			imaging.RenditionMimeType,
+		)
+		if err != nil {
+			return fmt.Errorf("failed upload %s: %w", rendition.name, err)
+		}
+
+		*rendition.key = &key
+	}
+
+	return nil
+}
+
+// put stores the file and returns its key and SHA-256 checksum.
+func (u *AttachmentUsecase) put(ctx context.Context, folder string, file *inspectedFile) (string, string, error) {
+	hash := sha256.New()
+
+	key, err := storage.Upload(
+		ctx,
+		u.store,
+		folder,
+		file.filename,
+		io.TeeReader(file.content, hash),
+		file.size,
+		file.mimeType,
+	)
+	if err != nil {
+		return "", "", err
+	}
+
+	return key, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// removeFiles deletes the stored file and its renditions.
+func (u *AttachmentUsecase) removeFiles(ctx context.Context, attachment *model.Attachment) {
+	keys := []string{attachment.StorageKey}
+
+	for _, key := range []*string{attachment.ThumbnailKey, attachment.PreviewKey} {
+		if key != nil {
+			keys = append(keys, *key)
+		}
+	}
+
+	for _, key := range keys {
+		if err := storage.Remove(ctx, u.store, key); err != nil {
+			logrus.Error("Failed remove attachment file:", err)
+		}
+	}
+}
+
+func resolveAttachmentURLs(attachments []*model.Attachment) {
+	for _, attachment := range attachments {
+		attachment.URL = storage.URL(attachment.StorageKey)
+		attachment.ThumbnailURL = storage.URLPtr(attachment.ThumbnailKey)
+		attachment.PreviewURL = storage.URLPtr(attachment.PreviewKey)
+	}
+}
+
+// attachmentFilename keeps only the base name of what the client sent,
+// which may be a full Windows path.
+func attachmentFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" {
+		name = "file"
+	}
+
+	if len(name) > 255 {
+		ext := path.Ext(name)
+		if len(ext) > 20 {
+			ext = ""
+		}
+		name = name[:255-len(ext)] + ext
+	}
+
+	return name
+}
@@ -0,0 +1,251 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/antivirus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+// eicar is the standard antivirus test string.
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+type memStore struct {
+	objects map[string][]byte
+}
+
+func (s *memStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	s.objects[key] = data
+	return nil
+}
+
+func (s *memStore) Get(ctx context.Context, key string) (io.ReadCloser, *model.StoredObject, error) {
+	return nil, nil, errors.New("not implemented")
+}
+
+func (s *memStore) Delete(ctx context.Context, key string) error {
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *memStore) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return "", nil
+}
+
+func (s *memStore) keys(prefix string) []string {
+	var keys []string
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// stubScanner flags content holding the EICAR string, or fails every scan
+// when err is set.
+type stubScanner struct {
+	err error
+}
+
+func (s stubScanner) Scan(ctx context.Context, r io.Reader) (*model.ScanResult, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.Contains(data, []byte("EICAR-STANDARD-ANTIVIRUS-TEST-FILE")) {
+		return &model.ScanResult{Infected: true, Signature: "Eicar-Signature"}, nil
+	}
+
+	return &model.ScanResult{}, nil
+}
+
+type fakeAttachmentRepo struct {
+	model.IAttachmentRepository
+	created []*model.Attachment
+}
+
+func (r *fakeAttachmentRepo) Create(ctx context.Context, tx interface{}, attachments []*model.Attachment) error {
+	r.created = append(r.created, attachments...)
+	return nil
+}
+
+type fakeQuarantineRepo struct {
+	model.IQuarantinedFileRepository
+	files []model.QuarantinedFile
+}
+
+func (r *fakeQuarantineRepo) Create(ctx context.Context, file model.QuarantinedFile) (*model.QuarantinedFile, error) {
+	r.files = append(r.files, file)
+	return &file, nil
+}
+
+type attachmentFixture struct {
+	usecase     model.IAttachmentUsecase
+	store       *memStore
+	attachments *fakeAttachmentRepo
+	quarantine  *fakeQuarantineRepo
+}
+
+func newAttachmentFixture(scanner model.IVirusScanner) *attachmentFixture {
+	f := &attachmentFixture{
+		store:       &memStore{objects: map[string][]byte{}},
+		attachments: &fakeAttachmentRepo{},
+		quarantine:  &fakeQuarantineRepo{},
+	}
+
+	f.usecase = NewAttachmentUsecase(f.attachments, f.quarantine, nil, nil, nil, nil, f.store, scanner)
+
+	return f
+}
+
+func textUpload(name string, content string) model.AttachmentUpload {
+	return model.AttachmentUpload{
+		Filename:    name,
+		ContentType: "text/plain",
+		Size:        int64(len(content)),
+		Content:     strings.NewReader(content),
+	}
+}
+
+var testOwner = model.AttachmentOwner{
+	Type:     model.AttachmentOwnerComment,
+	ID:       5,
+	TicketID: 1,
+}
+
+func TestAttachQuarantinesInfectedFile(t *testing.T) {
+	f := newAttachmentFixture(stubScanner{})
+
+	_, err := f.usecase.Attach(context.Background(), nil, 7, testOwner, []model.AttachmentUpload{
+		textUpload("notes.txt", "clean notes"),
+		textUpload("invoice.txt", eicar),
+	})
+	if !errors.Is(err, model.ErrAttachmentInfected) {
+		t.Fatalf("err = %v, want ErrAttachmentInfected", err)
+	}
+
+	if len(f.attachments.created) != 0 || len(f.store.keys("tickets/")) != 0 {
+		t.Fatal("files of a batch with an infected upload were attached")
+	}
+
+	if len(f.quarantine.files) != 1 {
+		t.Fatalf("quarantined = %d, want 1", len(f.quarantine.files))
+	}
+
+	quarantined := f.quarantine.files[0]
+
+	if quarantined.Filename != "invoice.txt" || quarantined.Signature != "Eicar-Signature" {
+		t.Fatalf("quarantined = %+v", quarantined)
+	}
+
+	if quarantined.TicketID == nil || *quarantined.TicketID != 1 || quarantined.UploadedBy == nil || *quarantined.UploadedBy != 7 {
+		t.Fatalf("quarantined = %+v", quarantined)
+	}
+
+	stored, ok := f.store.objects[quarantined.StorageKey]
+	if !ok || !strings.HasPrefix(quarantined.StorageKey, "quarantine/") {
+		t.Fatalf("quarantined file not stored under quarantine/: %q", quarantined.StorageKey)
+	}
+
+	sum := sha256.Sum256([]byte(eicar))
+	if string(stored) != eicar || quarantined.Checksum != hex.EncodeToString(sum[:]) {
+		t.Fatal("quarantined content or checksum does not match the upload")
+	}
+}
+
+func TestAttachStoresCleanFiles(t *testing.T) {
+	f := newAttachmentFixture(antivirus.NewNoopScanner())
+
+	attachments, err := f.usecase.Attach(context.Background(), nil, 7, testOwner, []model.AttachmentUpload{
+		textUpload("notes.txt", "clean notes"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(attachments) != 1 || len(f.attachments.created) != 1 || len(f.quarantine.files) != 0 {
+		t.Fatalf("attachments = %d, created = %d, quarantined = %d", len(attachments), len(f.attachments.created), len(f.quarantine.files))
+	}
+
+	if string(f.store.objects[attachments[0].StorageKey]) != "clean notes" {
+		t.Fatal("stored content does not match the upload")
+	}
+}
+
+func TestAttachScannerFailure(t *testing.T) {
+	scanner := stubScanner{err: errors.New("clamd: connection refused")}
+
+	f := newAttachmentFixture(scanner)
+
+	_, err := f.usecase.Attach(context.Background(), nil, 7, testOwner, []model.AttachmentUpload{
+		textUpload("notes.txt", "clean notes"),
+	})
+	if err == nil || len(f.store.objects) != 0 {
+		t.Fatalf("err = %v, stored = %d; upload must be refused while the scanner is down", err, len(f.store.objects))
+	}
+
+	viper.Set("antivirus.fail_open", true)
+	t.Cleanup(func() { viper.Set("antivirus.fail_open", nil) })
+
+	f = newAttachmentFixture(scanner)
+
+	if _, err := f.usecase.Attach(context.Background(), nil, 7, testOwner, []model.AttachmentUpload{
+		textUpload("notes.txt", "clean notes"),
+	}); err != nil {
+		t.Fatalf("fail_open: %v", err)
+	}
+}
+
+func TestPrepareUploadsBeforeTheOwnerExists(t *testing.T) {
+	ctx := context.Background()
+	f := newAttachmentFixture(antivirus.NewNoopScanner())
+
+	owner := model.AttachmentOwner{Type: model.AttachmentOwnerComment, TicketID: 1}
+
+	attachments, err := f.usecase.Prepare(ctx, 7, owner, []model.AttachmentUpload{
+		textUpload("notes.txt", "clean notes"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(f.attachments.created) != 0 || len(f.store.keys("tickets/1/comment/")) != 1 {
+		t.Fatalf("created = %d, stored = %v; want the file uploaded but not recorded", len(f.attachments.created), f.store.keys(""))
+	}
+
+	owner.ID = 5
+
+	if err := f.usecase.Save(ctx, nil, owner, attachments); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(f.attachments.created) != 1 || f.attachments.created[0].OwnerID != 5 {
+		t.Fatalf("created = %+v, want the attachment recorded for comment 5", f.attachments.created)
+	}
+
+	f.usecase.Discard(ctx, attachments)
+
+	if len(f.store.objects) != 0 {
+		t.Fatalf("stored = %v, want the uploads removed", f.store.keys(""))
+	}
+}
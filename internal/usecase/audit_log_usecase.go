@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type AuditLogUsecase struct {
+	auditLogRepo model.IAuditLogRepository
+}
+
+func NewAuditLogUsecase(auditLogRepo model.IAuditLogRepository) model.IAuditLogUsecase {
+	return &AuditLogUsecase{
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+func (u *AuditLogUsecase) FindAll(ctx context.Context, filter model.AuditLog, page int, limit int) ([]*model.AuditLog, int64, error) {
+	logs, total, err := u.auditLogRepo.FindAll(ctx, filter, page, limit)
+	if err != nil {
+		logrus.WithField("filter", filter).Error("Failed to fetch audit logs: ", err)
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
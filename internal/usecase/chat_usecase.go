@@ -0,0 +1,426 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+const telegramLinkTTL = 15 * time.Minute
+
+var chatActionLabels = map[string]string{
+	model.ChatActionTake:       "Take",
+	model.ChatActionInProgress: "In Progress",
+}
+
+var telegramCallbackActions = map[string]string{
+	"take":     model.ChatActionTake,
+	"progress": model.ChatActionInProgress,
+}
+
+type ChatUsecase struct {
+	userRepo          model.IUserRepository
+	ticketRepo        model.ITicketRepository
+	ticketUsecase     model.ITicketUsecase
+	preferenceUsecase model.INotificationPreferenceUsecase
+	adapters          []model.IChatAdapter
+	telegram          model.ITelegramBot
+}
+
+func NewChatUsecase(
+	userRepo model.IUserRepository,
+	ticketRepo model.ITicketRepository,
+	ticketUsecase model.ITicketUsecase,
+	preferenceUsecase model.INotificationPreferenceUsecase,
+	adapters []model.IChatAdapter,
+	telegram model.ITelegramBot,
+) model.IChatUsecase {
+	return &ChatUsecase{
+		userRepo:          userRepo,
+		ticketRepo:        ticketRepo,
+		ticketUsecase:     ticketUsecase,
+		preferenceUsecase: preferenceUsecase,
+		adapters:          adapters,
+		telegram:          telegram,
+	}
+}
+
+func (u *ChatUsecase) NotifyAssigned(ctx context.Context, event model.NotificationEvent) error {
+	if event.EventType != string(model.NotificationTicketAssigned) || len(u.adapters) == 0 {
+		return nil
+	}
+
+	if !u.preferenceUsecase.Allows(ctx, event, model.ChannelChat) {
+		return nil
+	}
+
+	recipient, err := u.userRepo.FindByID(ctx, event.UserID)
+	if err != nil {
+		return err
+	}
+
+	ticket, err := u.ticketRepo.FindByID(ctx, event.TicketID)
+	if err != nil {
+		return err
+	}
+
+	return u.send(ctx, model.ChatMessage{
+		Kind:       model.ChatKindTicketAssigned,
+		TicketID:   ticket.ID,
+		TicketCode: ticket.TicketCode,
+		Text: fmt.Sprintf(
+			"Tiket %s ditugaskan kepada %s\n%s",
+			ticket.TicketCode,
+			recipient.Name,
+			event.Message,
+		),
+		Link:      ticketLink(ticket.ID),
+		Recipient: recipient,
+		Actions:   u.buildActions(ticket.ID, recipient.ID, model.ChatActionTake, model.ChatActionInProgress),
+	})
+}
+
+// NotifySLABreaches alerts once per overdue ticket; the Redis marker keeps
+// other replicas and later runs from repeating the alert. The marker is
+// dropped again when no adapter could deliver it, so the alert is retried.
+func (u *ChatUsecase) NotifySLABreaches(ctx context.Context) error {
+	if len(u.adapters) == 0 {
+		return nil
+	}
+
+	tickets, err := u.ticketRepo.FindOverdue(ctx, 50)
+	if err != nil {
+		return err
+	}
+
+	for _, ticket := range tickets {
+		marker := fmt.Sprintf("chat:sla:%d", ticket.ID)
+
+		fresh, err := config.Rdb.SetNX(ctx, marker, 1, 7*24*time.Hour).Result()
+		if err != nil {
+			return err
+		}
+
+		if !fresh {
+			continue
+		}
+
+		var recipient *model.User
+		var actions []model.ChatAction
+
+		assignee := "belum ada teknisi"
+
+		if ticket.AssignedToID != nil {
+			recipient, err = u.userRepo.FindByID(ctx, *ticket.AssignedToID)
+			if err == nil {
+				assignee = recipient.Name
+				actions = u.buildActions(ticket.ID, recipient.ID, model.ChatActionInProgress)
+			}
+		} else {
+			actions = u.buildActions(ticket.ID, 0, model.ChatActionTake)
+		}
+
+		err = u.send(ctx, model.ChatMessage{
+			Kind:       model.ChatKindSLABreach,
+			TicketID:   ticket.ID,
+			TicketCode: ticket.TicketCode,
+			Text: fmt.Sprintf(
+				"SLA terlewati: tiket %s (%s) melewati batas waktu %s\nPelapor: %s | Teknisi: %s",
+				ticket.TicketCode,
+				ticket.Priority,
+				ticket.DueAt.Format("02/01/2006 15:04"),
+				ticket.Reporter.Name,
+				assignee,
+			),
+			Link:      ticketLink(ticket.ID),
+			Recipient: recipient,
+			Actions:   actions,
+		})
+		if err != nil {
+			logrus.WithField("ticket_id", ticket.ID).Error("failed send sla breach alert:", err)
+
+			// Without the marker the next run tries the alert again.
+			if err := config.Rdb.Del(ctx, marker).Err(); err != nil {
+				logrus.WithField("ticket_id", ticket.ID).Error("failed clear sla breach marker:", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (u *ChatUsecase) PreviewSignedAction(ctx context.Context, token string) (*model.ChatActionPreview, error) {
+	ticketID, _, action, err := verifyChatActionToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := u.ticketRepo.FindByID(ctx, ticketID)
+	if err != nil {
+		return nil, errors.New("ticket not found")
+	}
+
+	return &model.ChatActionPreview{
+		TicketID:   ticket.ID,
+		TicketCode: ticket.TicketCode,
+		Label:      chatActionLabels[action],
+	}, nil
+}
+
+func (u *ChatUsecase) HandleSignedAction(ctx context.Context, token string) (int64, error) {
+	ticketID, userID, action, err := verifyChatActionToken(token)
+	if err != nil {
+		return 0, err
+	}
+
+	return ticketID, u.performAction(ctx, userID, ticketID, action)
+}
+
+func (u *ChatUsecase) HandleTelegramUpdate(ctx context.Context, update model.TelegramUpdate) error {
+	if u.telegram == nil {
+		return errors.New("telegram is not enabled")
+	}
+
+	if update.CallbackQuery != nil {
+		return u.handleTelegramCallback(ctx, update.CallbackQuery)
+	}
+
+	if update.Message == nil || !strings.HasPrefix(update.Message.Text, "/start") {
+		return nil
+	}
+
+	chatID := update.Message.Chat.ID
+	code := strings.TrimSpace(strings.TrimPrefix(update.Message.Text, "/start"))
+
+	if code == "" {
+		return u.telegram.SendText(ctx, chatID, "Hubungkan akun Anda melalui menu profil di aplikasi Helpdesk.")
+	}
+
+	value, err := config.Rdb.GetDel(ctx, "chat:telegram:link:"+helper.HashToken(code)).Result()
+	if err != nil {
+		return u.telegram.SendText(ctx, chatID, "Kode tautan tidak valid atau sudah kedaluwarsa.")
+	}
+
+	userID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	if err := u.userRepo.UpdateTelegramChatID(ctx, userID, &chatID); err != nil {
+		return err
+	}
+
+	return u.telegram.SendText(ctx, chatID, "Akun Helpdesk berhasil terhubung. Notifikasi tiket akan dikirim ke sini.")
+}
+
+func (u *ChatUsecase) CreateTelegramLink(ctx context.Context, userID int64) (*model.TelegramLink, error) {
+	if u.telegram == nil || config.TelegramBotUsername() == "" {
+		return nil, errors.New("telegram is not enabled")
+	}
+
+	code, err := helper.GenerateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.Rdb.Set(ctx, "chat:telegram:link:"+helper.HashToken(code), userID, telegramLinkTTL).Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TelegramLink{
+		URL: fmt.Sprintf("https://t.me/%s?start=%s", config.TelegramBotUsername(), code),
+	}, nil
+}
+
+func (u *ChatUsecase) handleTelegramCallback(ctx context.Context, query *model.TelegramCallbackQuery) error {
+	name, value, _ := strings.Cut(query.Data, ":")
+
+	action, ok := telegramCallbackActions[name]
+	ticketID, err := strconv.ParseInt(value, 10, 64)
+	if !ok || err != nil {
+		return u.telegram.AnswerCallback(ctx, query.ID, "Aksi tidak dikenal.")
+	}
+
+	user, err := u.userRepo.FindByTelegramChatID(ctx, query.From.ID)
+	if err != nil {
+		return u.telegram.AnswerCallback(ctx, query.ID, "Akun Telegram Anda belum terhubung ke Helpdesk.")
+	}
+
+	if err := u.performAction(ctx, user.ID, ticketID, action); err != nil {
+		return u.telegram.AnswerCallback(ctx, query.ID, err.Error())
+	}
+
+	return u.telegram.AnswerCallback(ctx, query.ID, "Tiket sedang dikerjakan.")
+}
+
+func (u *ChatUsecase) performAction(ctx context.Context, userID int64, ticketID int64, action string) error {
+	log := logrus.WithFields(logrus.Fields{
+		"user_id":   userID,
+		"ticket_id": ticketID,
+		"action":    action,
+	})
+
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsActive || (user.Role.Name != "STAFF" && user.Role.Name != "ADMINISTRATOR") {
+		return errors.New("only active staff can take tickets")
+	}
+
+	ticket, err := u.ticketRepo.FindByID(ctx, ticketID)
+	if err != nil {
+		return errors.New("ticket not found")
+	}
+
+	switch ticket.Status {
+	case model.StatusResolved, model.StatusClosed:
+		return errors.New("ticket is already resolved")
+	}
+
+	if action == model.ChatActionTake && ticket.AssignedToID == nil {
+		assigned, err := u.ticketUsecase.Take(ctx, ticket.ID, user.ID)
+		if err != nil {
+			return err
+		}
+
+		if assigned {
+			ticket.AssignedToID = &user.ID
+		} else if ticket, err = u.ticketRepo.FindByID(ctx, ticketID); err != nil {
+			return err
+		}
+	}
+
+	if ticket.AssignedToID == nil || (*ticket.AssignedToID != user.ID && user.Role.Name != "ADMINISTRATOR") {
+		return errors.New("ticket is assigned to another technician")
+	}
+
+	if ticket.Status == model.StatusInProgress {
+		return nil
+	}
+
+	log.Info("ticket status updated from chat")
+
+	return u.ticketUsecase.UpdateStatus(ctx, ticket.ID, user.ID, model.UpdateTicketStatusInput{
+		Status: model.StatusInProgress,
+	})
+}
+
+// send delivers the message on every adapter. A failing adapter is only
+// logged when another one got the message through, because returning an
+// error makes the consumer redeliver the event and the adapters that
+// succeeded would post it twice.
+func (u *ChatUsecase) send(ctx context.Context, message model.ChatMessage) error {
+	var errs []error
+
+	for _, adapter := range u.adapters {
+		if err := adapter.Send(ctx, message); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", adapter.Name(), err))
+		}
+	}
+
+	if len(errs) == len(u.adapters) {
+		return errors.Join(errs...)
+	}
+
+	for _, err := range errs {
+		logrus.WithField("ticket_id", message.TicketID).Error("failed send chat message:", err)
+	}
+
+	return nil
+}
+
+// buildActions creates Telegram callbacks for every action, and signed links
+// only when the acting user is known, since a link cannot tell who clicked.
+func (u *ChatUsecase) buildActions(ticketID int64, userID int64, actions ...string) []model.ChatAction {
+	var result []model.ChatAction
+
+	for _, action := range actions {
+		chatAction := model.ChatAction{
+			Label:  chatActionLabels[action],
+			Action: action,
+		}
+
+		for name, value := range telegramCallbackActions {
+			if value == action {
+				chatAction.CallbackData = fmt.Sprintf("%s:%d", name, ticketID)
+			}
+		}
+
+		if userID != 0 && config.ChatActionSecret() != "" {
+			chatAction.URL = fmt.Sprintf(
+				"%s/v1/chat/actions?token=%s",
+				config.APIURL(),
+				signChatActionToken(ticketID, userID, action, time.Now().Add(config.ChatActionTTL())),
+			)
+		}
+
+		result = append(result, chatAction)
+	}
+
+	return result
+}
+
+func ticketLink(ticketID int64) string {
+	return fmt.Sprintf("%s/tickets/%d", config.FrontendURL(), ticketID)
+}
+
+func signChatActionToken(ticketID int64, userID int64, action string, expiresAt time.Time) string {
+	payload := fmt.Sprintf("%d.%d.%s.%d", ticketID, userID, action, expiresAt.Unix())
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+
+	return encoded + "." + chatActionSignature(encoded)
+}
+
+func verifyChatActionToken(token string) (int64, int64, string, error) {
+	invalid := errors.New("action link is invalid or expired")
+
+	if config.ChatActionSecret() == "" {
+		return 0, 0, "", invalid
+	}
+
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(chatActionSignature(encoded))) {
+		return 0, 0, "", invalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, 0, "", invalid
+	}
+
+	parts := strings.Split(string(payload), ".")
+	if len(parts) != 4 {
+		return 0, 0, "", invalid
+	}
+
+	ticketID, errTicket := strconv.ParseInt(parts[0], 10, 64)
+	userID, errUser := strconv.ParseInt(parts[1], 10, 64)
+	expiresAt, errExp := strconv.ParseInt(parts[3], 10, 64)
+	if errTicket != nil || errUser != nil || errExp != nil || time.Now().Unix() > expiresAt {
+		return 0, 0, "", invalid
+	}
+
+	return ticketID, userID, parts[2], nil
+}
+
+func chatActionSignature(encoded string) string {
+	mac := hmac.New(sha256.New, []byte(config.ChatActionSecret()))
+	mac.Write([]byte(encoded))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type fakeOverdueTicketRepo struct {
+	model.ITicketRepository
+	tickets []*model.Ticket
+}
+
+func (r *fakeOverdueTicketRepo) FindOverdue(ctx context.Context, limit int) ([]*model.Ticket, error) {
+	return r.tickets, nil
+}
+
+// stubChatAdapter counts the messages it was handed and fails while err is
+// set.
+type stubChatAdapter struct {
+	name string
+	err  error
+	sent int
+}
+
+func (a *stubChatAdapter) Name() string {
+	return a.name
+}
+
+func (a *stubChatAdapter) Send(ctx context.Context, message model.ChatMessage) error {
+	if a.err != nil {
+		return a.err
+	}
+
+	a.sent++
+	return nil
+}
+
+func overdueTicket() *model.Ticket {
+	return &model.Ticket{
+		ID:         3,
+		TicketCode: "NUT-20260101-0003",
+		Priority:   "HIGH",
+		DueAt:      time.Now().Add(-time.Hour),
+		Reporter:   model.User{Name: "Jane"},
+	}
+}
+
+func TestSLABreachAlertIsRetriedAfterFailedSend(t *testing.T) {
+	startMiniredis(t)
+
+	ctx := context.Background()
+	slack := &stubChatAdapter{name: "slack", err: errors.New("slack responded 503")}
+
+	uc := NewChatUsecase(nil, &fakeOverdueTicketRepo{tickets: []*model.Ticket{overdueTicket()}}, nil, nil, []model.IChatAdapter{slack}, nil)
+
+	if err := uc.NotifySLABreaches(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	slack.err = nil
+
+	for i := 0; i < 2; i++ {
+		if err := uc.NotifySLABreaches(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if slack.sent != 1 {
+		t.Fatalf("sent = %d, want the alert delivered once after the outage", slack.sent)
+	}
+}
+
+func TestChatSendAcksPartialFailures(t *testing.T) {
+	startMiniredis(t)
+
+	ctx := context.Background()
+	slack := &stubChatAdapter{name: "slack"}
+	teams := &stubChatAdapter{name: "teams", err: errors.New("teams responded 500")}
+
+	uc := NewChatUsecase(nil, &fakeOverdueTicketRepo{tickets: []*model.Ticket{overdueTicket()}}, nil, nil, []model.IChatAdapter{slack, teams}, nil)
+
+	for i := 0; i < 2; i++ {
+		if err := uc.NotifySLABreaches(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if slack.sent != 1 {
+		t.Fatalf("slack sent = %d, want 1: a failing adapter made the others repeat the alert", slack.sent)
+	}
+
+	err := uc.(*ChatUsecase).send(ctx, model.ChatMessage{TicketID: 3})
+	if err != nil {
+		t.Fatalf("send = %v, want partial failures only logged", err)
+	}
+
+	slack.err = errors.New("slack responded 503")
+
+	if err := uc.(*ChatUsecase).send(ctx, model.ChatMessage{TicketID: 3}); err == nil {
+		t.Fatal("send hid a failure of every adapter")
+	}
+}
@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type DeadLetterUsecase struct {
+	queues []string
+}
+
+func NewDeadLetterUsecase(queues []string) model.IDeadLetterUsecase {
+	return &DeadLetterUsecase{
+		queues: queues,
+	}
+}
+
+func (u *DeadLetterUsecase) Queues() []string {
+	return u.queues
+}
+
+// FindAll peeks at the DLQ: messages are fetched unacknowledged and requeued
+// afterwards, so inspecting does not remove them.
+func (u *DeadLetterUsecase) FindAll(ctx context.Context, queue string, limit int) ([]*model.DeadLetterMessage, error) {
+	if !slices.Contains(u.queues, queue) {
+		return nil, model.ErrUnknownQueue
+	}
+
+	ch, err := openDeadLetterChannel()
+	if err != nil {
+		return nil, err
+	}
+	defer ch.Close()
+
+	messages := []*model.DeadLetterMessage{}
+
+	var lastTag uint64
+
+	for len(messages) < limit {
+		d, ok, err := ch.Get(helper.DeadLetterQueueName(queue), false)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			break
+		}
+
+		lastTag = d.DeliveryTag
+		messages = append(messages, toDeadLetterMessage(queue, d))
+	}
+
+	if lastTag > 0 {
+		if err := ch.Nack(lastTag, true, true); err != nil {
+			return nil, err
+		}
+	}
+
+	return messages, nil
+}
+
+// Replay moves dead-lettered messages back onto the work queue with a fresh
+// retry budget. Messages not selected are returned to the DLQ untouched.
+func (u *DeadLetterUsecase) Replay(ctx context.Context, queue string, in model.ReplayDeadLetterInput) (int, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"queue": queue,
+		"ids":   in.IDs,
+	})
+
+	if !slices.Contains(u.queues, queue) {
+		return 0, model.ErrUnknownQueue
+	}
+
+	ch, err := openDeadLetterChannel()
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+
+	if err := ch.Confirm(false); err != nil {
+		return 0, err
+	}
+
+	var skipped []uint64
+
+	defer func() {
+		for _, tag := range skipped {
+			ch.Nack(tag, false, true)
+		}
+	}()
+
+	replayed := 0
+
+	for i := 0; i < config.ConsumerReplayLimit(); i++ {
+		if len(in.IDs) > 0 && replayed == len(in.IDs) {
+			break
+		}
+
+		d, ok, err := ch.Get(helper.DeadLetterQueueName(queue), false)
+		if err != nil {
+			return replayed, err
+		}
+
+		if !ok {
+			break
+		}
+
+		if len(in.IDs) > 0 && !slices.Contains(in.IDs, d.MessageId) {
+			skipped = append(skipped, d.DeliveryTag)
+			continue
+		}
+
+		if err := republish(ctx, ch, queue, d); err != nil {
+			log.Error("failed replay dead letter:", err)
+			d.Nack(false, true)
+			return replayed, err
+		}
+
+		d.Ack(false)
+		replayed++
+	}
+
+	log.Infof("replayed %d dead letters", replayed)
+
+	return replayed, nil
+}
+
+func openDeadLetterChannel() (*amqp.Channel, error) {
+	conn := config.RabbitConnection()
+	if conn == nil || conn.IsClosed() {
+		return nil, errors.New("rabbitmq connection is closed")
+	}
+
+	return conn.Channel()
+}
+
+func republish(ctx context.Context, ch *amqp.Channel, queue string, d amqp.Delivery) error {
+	headers := amqp.Table{}
+	for key, value := range d.Headers {
+		headers[key] = value
+	}
+
+	delete(headers, helper.HeaderRetryCount)
+	delete(headers, helper.HeaderLastError)
+	delete(headers, helper.HeaderFailedAt)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	confirmation, err := ch.PublishWithDeferredConfirmWithContext(
+		ctx,
+		"",
+		queue,
+		false,
+		false,
+		amqp.Publishing{
+			Headers:      headers,
+			ContentType:  d.ContentType,
+			DeliveryMode: amqp.Persistent,
+			MessageId:    d.MessageId,
+			Timestamp:    time.Now(),
+			Body:         d.Body,
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !acked {
+		return errors.New("broker rejected replayed message")
+	}
+
+	return nil
+}
+
+func toDeadLetterMessage(queue string, d amqp.Delivery) *model.DeadLetterMessage {
+	message := &model.DeadLetterMessage{
+		ID:    d.MessageId,
+		Queue: queue,
+		Body:  d.Body,
+	}
+
+	if !json.Valid(d.Body) {
+		message.Body, _ = json.Marshal(string(d.Body))
+	}
+
+	message.RoutingKey, _ = d.Headers[helper.HeaderOriginalRoutingKey].(string)
+	message.Error, _ = d.Headers[helper.HeaderLastError].(string)
+
+	switch attempts := d.Headers[helper.HeaderRetryCount].(type) {
+	case int64:
+		message.Attempts = attempts
+	case int32:
+		message.Attempts = int64(attempts)
+	}
+
+	if failedAt, ok := d.Headers[helper.HeaderFailedAt].(string); ok {
+		if parsed, err := time.Parse(time.RFC3339, failedAt); err == nil {
+			message.FailedAt = &parsed
+		}
+	}
+
+	return message
+}
@@ -0,0 +1,326 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/exporter"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/storage"
+
+	ws "github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/websocket"
+)
+
+type ExportJobUsecase struct {
+	exportJobRepo       model.IExportJobRepository
+	ticketExportUsecase model.ITicketExportUsecase
+	store               model.IStorage
+	wsHub               *ws.Hub
+}
+
+func NewExportJobUsecase(
+	exportJobRepo model.IExportJobRepository,
+	ticketExportUsecase model.ITicketExportUsecase,
+	store model.IStorage,
+	wsHub *ws.Hub,
+) model.IExportJobUsecase {
+	return &ExportJobUsecase{
+		exportJobRepo:       exportJobRepo,
+		ticketExportUsecase: ticketExportUsecase,
+		store:               store,
+		wsHub:               wsHub,
+	}
+}
+
+func (u *ExportJobUsecase) Create(ctx context.Context, userID int64, role string, in model.CreateExportJobInput) (*model.ExportJob, error) {
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+
+	active, err := u.exportJobRepo.CountActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if active >= int64(config.ExportMaxActivePerUser()) {
+		return nil, model.ErrExportJobLimit
+	}
+
+	format := in.Format
+	if format == "" {
+		format = "xlsx"
+	}
+
+	job, err := u.exportJobRepo.Create(ctx, model.ExportJob{
+		UserID:    userID,
+		Role:      role,
+		Format:    format,
+		Filter:    in.ExportFilter,
+		Status:    model.ExportJobPending,
+		ExpiresAt: time.Now().Add(config.ExportRetention()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return withDownloadURL(job), nil
+}
+
+func (u *ExportJobUsecase) FindByUserID(ctx context.Context, userID int64, page int, limit int) ([]*model.ExportJob, int64, error) {
+	jobs, total, err := u.exportJobRepo.FindByUserID(ctx, userID, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, job := range jobs {
+		withDownloadURL(job)
+	}
+
+	return jobs, total, nil
+}
+
+// FindByID hides other users' jobs behind not found rather than forbidden,
+// so job IDs cannot be probed.
+func (u *ExportJobUsecase) FindByID(ctx context.Context, id int64, userID int64) (*model.ExportJob, error) {
+	job, err := u.exportJobRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.UserID != userID {
+		return nil, model.ErrExportJobNotFound
+	}
+
+	return withDownloadURL(job), nil
+}
+
+func (u *ExportJobUsecase) Download(ctx context.Context, id int64, userID int64) (*model.ExportJob, io.ReadCloser, error) {
+	job, err := u.FindByID(ctx, id, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if job.Status != model.ExportJobCompleted || job.StorageKey == nil {
+		return nil, nil, model.ErrExportJobNotReady
+	}
+
+	if time.Now().After(job.ExpiresAt) {
+		return nil, nil, model.ErrExportJobExpired
+	}
+
+	exp, err := exporter.New(job.Format)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader, _, err := u.store.Get(ctx, *job.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	job.ContentType = exp.ContentType()
+
+	return job, reader, nil
+}
+
+// Delete cancels a waiting job or removes a finished one. A job that is
+// being built cannot be deleted, since its worker would then leave the
+// file behind.
+func (u *ExportJobUsecase) Delete(ctx context.Context, id int64, userID int64) error {
+	job, err := u.FindByID(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if job.Status == model.ExportJobProcessing {
+		return model.ErrExportJobNotReady
+	}
+
+	return u.remove(ctx, job)
+}
+
+func (u *ExportJobUsecase) ProcessNext(ctx context.Context) (bool, error) {
+	job, err := u.exportJobRepo.ClaimNext(ctx, config.ExportTimeout())
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	log := logrus.WithFields(logrus.Fields{
+		"export_job_id": job.ID,
+		"user_id":       job.UserID,
+		"attempt":       job.Attempts,
+	})
+
+	runCtx, cancel := context.WithTimeout(ctx, config.ExportTimeout())
+	defer cancel()
+
+	started := time.Now()
+
+	if err := u.run(runCtx, job); err != nil {
+		log.Error("export job failed:", err)
+		u.fail(ctx, job, err)
+		return true, nil
+	}
+
+	log.Infof("export job completed: %d rows in %s", job.TotalRows, time.Since(started).Round(time.Millisecond))
+
+	return true, nil
+}
+
+func (u *ExportJobUsecase) DeleteExpired(ctx context.Context) (int64, error) {
+	var deleted int64
+
+	for {
+		jobs, err := u.exportJobRepo.FindExpired(ctx, time.Now(), 100)
+		if err != nil {
+			return deleted, err
+		}
+
+		for _, job := range jobs {
+			if err := u.remove(ctx, job); err != nil {
+				return deleted, err
+			}
+
+			deleted++
+		}
+
+		if len(jobs) < 100 {
+			return deleted, nil
+		}
+	}
+}
+
+// run builds the file in memory and stores it, reporting the builder's
+// progress on the job as it goes.
+func (u *ExportJobUsecase) run(ctx context.Context, job *model.ExportJob) error {
+	var file bytes.Buffer
+
+	result, err := u.ticketExportUsecase.Export(
+		ctx,
+		job.UserID,
+		job.Role,
+		job.Format,
+		job.Filter,
+		&file,
+		func(percent int) {
+			u.reportProgress(ctx, job, percent)
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	job.TotalRows = result.Rows
+
+	filename := result.Filename
+	size := int64(file.Len())
+
+	key, err := storage.Upload(ctx, u.store, fmt.Sprintf("exports/%d", job.UserID), filename, &file, size, result.ContentType)
+	if err != nil {
+		return fmt.Errorf("store export: %w", err)
+	}
+
+	now := time.Now()
+
+	job.Status = model.ExportJobCompleted
+	job.Progress = 100
+	job.Filename = &filename
+	job.Size = size
+	job.StorageKey = &key
+	job.Error = nil
+	job.LeaseUntil = nil
+	job.CompletedAt = &now
+	job.ExpiresAt = now.Add(config.ExportRetention())
+
+	if err := u.exportJobRepo.Update(ctx, *job); err != nil {
+		if removeErr := storage.Remove(ctx, u.store, key); removeErr != nil {
+			logrus.Error("failed remove orphaned export file:", removeErr)
+		}
+		return err
+	}
+
+	u.push(job, ws.EventExportCompleted)
+
+	return nil
+}
+
+// fail puts the job back in the queue until it has used its attempts, then
+// marks it failed and tells the owner.
+func (u *ExportJobUsecase) fail(ctx context.Context, job *model.ExportJob, cause error) {
+	message := cause.Error()
+
+	job.Error = &message
+	job.LeaseUntil = nil
+	job.Progress = 0
+
+	if job.Attempts < config.ExportMaxAttempts() {
+		job.Status = model.ExportJobPending
+	} else {
+		now := time.Now()
+		job.Status = model.ExportJobFailed
+		job.CompletedAt = &now
+		job.ExpiresAt = now.Add(config.ExportRetention())
+	}
+
+	if err := u.exportJobRepo.Update(ctx, *job); err != nil {
+		logrus.Error("failed update export job:", err)
+		return
+	}
+
+	if job.Status == model.ExportJobFailed {
+		u.push(job, ws.EventExportFailed)
+	}
+}
+
+// reportProgress only writes when the percentage moves forward, so large
+// exports do not update the row once per photo.
+func (u *ExportJobUsecase) reportProgress(ctx context.Context, job *model.ExportJob, progress int) {
+	if progress <= job.Progress {
+		return
+	}
+
+	job.Progress = progress
+
+	if err := u.exportJobRepo.UpdateProgress(ctx, job.ID, job.Progress, job.TotalRows); err != nil {
+		logrus.Error("failed update export progress:", err)
+		return
+	}
+
+	u.push(job, ws.EventExportProgress)
+}
+
+func (u *ExportJobUsecase) push(job *model.ExportJob, event string) {
+	ws.BroadcastToUser(
+		u.wsHub,
+		job.UserID,
+		ws.Message{
+			Type: event,
+			Data: withDownloadURL(job),
+		})
+}
+
+func (u *ExportJobUsecase) remove(ctx context.Context, job *model.ExportJob) error {
+	if job.StorageKey != nil {
+		if err := storage.Remove(ctx, u.store, *job.StorageKey); err != nil {
+			logrus.Error("failed remove export file:", err)
+		}
+	}
+
+	return u.exportJobRepo.Delete(ctx, job.ID)
+}
+
+func withDownloadURL(job *model.ExportJob) *model.ExportJob {
+	job.DownloadURL = nil
+
+	if job.Status == model.ExportJobCompleted {
+		url := fmt.Sprintf("%s/v1/exports/%d/download", config.APIURL(), job.ID)
+		job.DownloadURL = &url
+	}
+
+	return job
+}
@@ -0,0 +1,283 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+var ticketCodeRe = regexp.MustCompile(`\b[A-Z0-9]+-\d{8}-\d{4,}\b`)
+
+type MailIngestUsecase struct {
+	userRepo             model.IUserRepository
+	ticketRepo           model.ITicketRepository
+	ticketUsecase        model.ITicketUsecase
+	ticketCommentUsecase model.ITicketCommentUsecase
+}
+
+func NewMailIngestUsecase(
+	userRepo model.IUserRepository,
+	ticketRepo model.ITicketRepository,
+	ticketUsecase model.ITicketUsecase,
+	ticketCommentUsecase model.ITicketCommentUsecase,
+) model.IMailIngestUsecase {
+	return &MailIngestUsecase{
+		userRepo:             userRepo,
+		ticketRepo:           ticketRepo,
+		ticketUsecase:        ticketUsecase,
+		ticketCommentUsecase: ticketCommentUsecase,
+	}
+}
+
+func (u *MailIngestUsecase) Ingest(ctx context.Context, mail model.InboundMail) error {
+	log := logrus.WithFields(logrus.Fields{
+		"from":       mail.From,
+		"subject":    mail.Subject,
+		"message_id": mail.MessageID,
+	})
+
+	// Mail servers retry on temporary failures, so remember what was already
+	// turned into a ticket to avoid duplicates.
+	dedupeKey := ""
+	if mail.MessageID != "" && config.Rdb != nil {
+		dedupeKey = "mail:ingested:" + helper.HashToken(mail.MessageID)
+
+		fresh, err := config.Rdb.SetNX(ctx, dedupeKey, 1, 7*24*time.Hour).Result()
+		if err != nil {
+			return err
+		}
+
+		if !fresh {
+			log.Info("inbound mail already ingested")
+			return nil
+		}
+	}
+
+	err := u.ingest(ctx, mail)
+	if err != nil && dedupeKey != "" {
+		config.Rdb.Del(ctx, dedupeKey)
+	}
+
+	return err
+}
+
+func (u *MailIngestUsecase) ingest(ctx context.Context, mail model.InboundMail) error {
+	// The From header is whatever the sender typed, so it only identifies a
+	// user once the relay vouched for it.
+	if err := verifySender(mail); err != nil {
+		return fmt.Errorf("%w: %v", model.ErrMailRejected, err)
+	}
+
+	sender, err := u.userRepo.FindByEmail(ctx, mail.From)
+	if err != nil {
+		return fmt.Errorf("%w: sender is not registered", model.ErrMailRejected)
+	}
+
+	user, err := u.userRepo.FindByID(ctx, sender.ID)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsActive || user.LockedAt != nil {
+		return fmt.Errorf("%w: sender account is not active", model.ErrMailRejected)
+	}
+
+	if code := ticketCodeRe.FindString(strings.ToUpper(mail.Subject)); code != "" {
+		ticket, err := u.ticketRepo.FindByCode(ctx, code)
+		if err == nil {
+			return u.addComment(ctx, user, ticket, mail)
+		}
+	}
+
+	return u.createTicket(ctx, user, mail)
+}
+
+func (u *MailIngestUsecase) createTicket(ctx context.Context, user *model.User, mail model.InboundMail) error {
+	projectID := config.MailIngestDefaultProjectID()
+	if len(user.Projects) == 1 {
+		projectID = user.Projects[0].ID
+	}
+
+	if projectID == 0 {
+		return fmt.Errorf("%w: no project could be determined for the sender", model.ErrMailRejected)
+	}
+
+	description := mail.Subject
+	if mail.Body != "" {
+		description += "\n\n" + mail.Body
+	}
+
+	input := model.CreateTicketInput{
+		ProjectID:   projectID,
+		LocationID:  config.MailIngestDefaultLocationID(),
+		PartID:      config.MailIngestDefaultPartID(),
+		AssetID:     config.MailIngestDefaultAssetID(),
+		Priority:    model.TicketPriority(config.MailIngestDefaultPriority()),
+		Description: description,
+	}
+
+	if err := validate.Struct(input); err != nil {
+		return fmt.Errorf("%w: %s", model.ErrMailRejected, err.Error())
+	}
+
+	ticket, _, err := u.ticketUsecase.Create(ctx, user.ID, input, inboundUploads(mail.Attachments))
+	if err != nil {
+		return rejectedAttachment(err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"ticket_code": ticket.TicketCode,
+		"reporter_id": user.ID,
+	}).Info("ticket created from inbound mail")
+
+	return nil
+}
+
+func (u *MailIngestUsecase) addComment(ctx context.Context, user *model.User, ticket *model.Ticket, mail model.InboundMail) error {
+	isAssignee := ticket.AssignedToID != nil && *ticket.AssignedToID == user.ID
+	isStaff := user.Role.Name == "STAFF" || user.Role.Name == "ADMINISTRATOR"
+
+	if ticket.ReporterID != user.ID && !isAssignee && !isStaff {
+		return fmt.Errorf("%w: sender is not allowed to comment on %s", model.ErrMailRejected, ticket.TicketCode)
+	}
+
+	if ticket.Status == model.StatusClosed {
+		return fmt.Errorf("%w: ticket %s is already closed", model.ErrMailRejected, ticket.TicketCode)
+	}
+
+	message := strings.TrimSpace(mail.Reply)
+
+	if message == "" && len(mail.Attachments) == 0 {
+		return fmt.Errorf("%w: reply is empty", model.ErrMailRejected)
+	}
+
+	_, err := u.ticketCommentUsecase.Create(ctx, model.TicketComment{
+		TicketID: ticket.ID,
+		UserID:   user.ID,
+		Message:  message,
+	}, inboundUploads(mail.Attachments))
+
+	return rejectedAttachment(err)
+}
+
+// rejectedAttachment turns attachment validation failures into a permanent
+// rejection, since redelivering the same mail would fail the same way.
+func rejectedAttachment(err error) error {
+	switch {
+	case errors.Is(err, model.ErrAttachmentTooMany),
+		errors.Is(err, model.ErrAttachmentTooLarge),
+		errors.Is(err, model.ErrAttachmentTypeRejected),
+		errors.Is(err, model.ErrAttachmentInfected):
+		return fmt.Errorf("%w: %s", model.ErrMailRejected, err.Error())
+	}
+
+	return err
+}
+
+func inboundUploads(attachments []model.InboundAttachment) []model.AttachmentUpload {
+	files := make([]model.AttachmentUpload, 0, len(attachments))
+
+	for _, attachment := range attachments {
+		files = append(files, model.AttachmentUpload{
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Size:        int64(len(attachment.Data)),
+			Content:     bytes.NewReader(attachment.Data),
+		})
+	}
+
+	return files
+}
+
+func verifySender(mail model.InboundMail) error {
+	if mail.From == "" || !strings.EqualFold(mail.EnvelopeFrom, mail.From) {
+		return errors.New("envelope sender does not match From")
+	}
+
+	authservID := config.MailIngestAuthservID()
+	if authservID == "" {
+		return nil
+	}
+
+	_, domain, _ := strings.Cut(mail.From, "@")
+
+	for _, header := range mail.AuthenticationResults {
+		id, passed := parseAuthenticationResults(header, domain)
+
+		// Headers from other hosts could have been added by the sender.
+		if !strings.EqualFold(id, authservID) {
+			continue
+		}
+
+		if passed {
+			return nil
+		}
+
+		break
+	}
+
+	return errors.New("sender failed SPF and DKIM checks")
+}
+
+// parseAuthenticationResults returns the authserv-id of an
+// Authentication-Results header (RFC 8601) and whether it reports a DMARC,
+// DKIM or SPF pass aligned with domain.
+func parseAuthenticationResults(header string, domain string) (string, bool) {
+	parts := strings.Split(header, ";")
+
+	// The authserv-id may be followed by a version number.
+	fields := strings.Fields(parts[0])
+	if len(fields) == 0 {
+		return "", false
+	}
+
+	id := fields[0]
+
+	for _, part := range parts[1:] {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+
+		method, result, _ := strings.Cut(fields[0], "=")
+		if !strings.EqualFold(result, "pass") {
+			continue
+		}
+
+		props := map[string]string{}
+		for _, field := range fields[1:] {
+			if key, value, ok := strings.Cut(field, "="); ok {
+				props[strings.ToLower(key)] = strings.ToLower(strings.Trim(value, `"`))
+			}
+		}
+
+		var aligned string
+
+		switch strings.ToLower(method) {
+		case "dmarc":
+			aligned = props["header.from"]
+		case "dkim":
+			aligned = props["header.d"]
+		case "spf":
+			aligned = props["smtp.mailfrom"]
+			if _, mailDomain, ok := strings.Cut(aligned, "@"); ok {
+				aligned = mailDomain
+			}
+		}
+
+		if aligned != "" && strings.EqualFold(aligned, domain) {
+			return id, true
+		}
+	}
+
+	return id, false
+}
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+func TestVerifySender(t *testing.T) {
+	viper.Set("mail_ingest.authserv_id", "mx.example.com")
+	t.Cleanup(func() { viper.Set("mail_ingest.authserv_id", nil) })
+
+	tests := []struct {
+		name    string
+		mail    model.InboundMail
+		allowed bool
+	}{
+		{
+			name: "aligned dmarc pass",
+			mail: model.InboundMail{
+				From:                  "jane@example.com",
+				EnvelopeFrom:          "jane@example.com",
+				AuthenticationResults: []string{"mx.example.com; dmarc=pass header.from=example.com"},
+			},
+			allowed: true,
+		},
+		{
+			name: "aligned dkim pass",
+			mail: model.InboundMail{
+				From:                  "jane@example.com",
+				EnvelopeFrom:          "jane@example.com",
+				AuthenticationResults: []string{"mx.example.com 1; spf=fail smtp.mailfrom=jane@example.com; dkim=pass header.d=example.com"},
+			},
+			allowed: true,
+		},
+		{
+			name: "envelope differs from header",
+			mail: model.InboundMail{
+				From:                  "jane@example.com",
+				EnvelopeFrom:          "mallory@evil.example",
+				AuthenticationResults: []string{"mx.example.com; dmarc=pass header.from=example.com"},
+			},
+		},
+		{
+			name: "dkim pass for another domain",
+			mail: model.InboundMail{
+				From:                  "jane@example.com",
+				EnvelopeFrom:          "jane@example.com",
+				AuthenticationResults: []string{"mx.example.com; dkim=pass header.d=evil.example"},
+			},
+		},
+		{
+			name: "header from an untrusted host",
+			mail: model.InboundMail{
+				From:                  "jane@example.com",
+				EnvelopeFrom:          "jane@example.com",
+				AuthenticationResults: []string{"evil.example; dmarc=pass header.from=example.com"},
+			},
+		},
+		{
+			name: "no results",
+			mail: model.InboundMail{
+				From:         "jane@example.com",
+				EnvelopeFrom: "jane@example.com",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifySender(tt.mail)
+			if tt.allowed && err != nil {
+				t.Fatalf("verifySender: %v", err)
+			}
+			if !tt.allowed && err == nil {
+				t.Fatal("sender was accepted")
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+var defaultQuietHours = model.NotificationQuietHours{
+	Enabled:   false,
+	StartTime: "22:00",
+	EndTime:   "07:00",
+	Timezone:  "Asia/Jakarta",
+}
+
+type NotificationPreferenceUsecase struct {
+	preferenceRepo model.INotificationPreferenceRepository
+}
+
+func NewNotificationPreferenceUsecase(
+	preferenceRepo model.INotificationPreferenceRepository,
+) model.INotificationPreferenceUsecase {
+	return &NotificationPreferenceUsecase{
+		preferenceRepo: preferenceRepo,
+	}
+}
+
+// Get returns the full type × channel matrix; combinations the user never
+// changed are reported with their default (enabled).
+func (u *NotificationPreferenceUsecase) Get(ctx context.Context, userID int64) (*model.NotificationSettings, error) {
+	stored, err := u.preferenceRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := map[model.NotificationType]map[model.NotificationChannel]bool{}
+	for _, p := range stored {
+		if enabled[p.Type] == nil {
+			enabled[p.Type] = map[model.NotificationChannel]bool{}
+		}
+		enabled[p.Type][p.Channel] = p.Enabled
+	}
+
+	var preferences []model.NotificationPreference
+
+	for _, notificationType := range model.NotificationTypes {
+		for _, channel := range model.NotificationChannels {
+			value, ok := enabled[notificationType][channel]
+			if !ok {
+				value = true
+			}
+
+			preferences = append(preferences, model.NotificationPreference{
+				Type:    notificationType,
+				Channel: channel,
+				Enabled: value,
+			})
+		}
+	}
+
+	quietHours, err := u.preferenceRepo.FindQuietHours(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if quietHours == nil {
+		quietHours = &defaultQuietHours
+	}
+
+	return &model.NotificationSettings{
+		Preferences: preferences,
+		QuietHours:  *quietHours,
+	}, nil
+}
+
+func (u *NotificationPreferenceUsecase) Update(ctx context.Context, userID int64, in model.UpdateNotificationSettingsInput) (*model.NotificationSettings, error) {
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+
+	var preferences []model.NotificationPreference
+	for _, p := range in.Preferences {
+		preferences = append(preferences, model.NotificationPreference{
+			UserID:  userID,
+			Type:    p.Type,
+			Channel: p.Channel,
+			Enabled: *p.Enabled,
+		})
+	}
+
+	if err := u.preferenceRepo.Upsert(ctx, preferences); err != nil {
+		return nil, err
+	}
+
+	if in.QuietHours != nil {
+		err := u.preferenceRepo.UpsertQuietHours(ctx, model.NotificationQuietHours{
+			UserID:    userID,
+			Enabled:   in.QuietHours.Enabled,
+			StartTime: in.QuietHours.StartTime,
+			EndTime:   in.QuietHours.EndTime,
+			Timezone:  in.QuietHours.Timezone,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return u.Get(ctx, userID)
+}
+
+// Allows reports whether an event may be delivered on a channel right now.
+// Quiet hours silence the interrupting channels only, and not for tickets
+// whose priority is configured to bypass them; the in-app inbox always
+// receives the notification. Lookup failures fail open so a database
+// hiccup does not swallow notifications.
+func (u *NotificationPreferenceUsecase) Allows(ctx context.Context, event model.NotificationEvent, channel model.NotificationChannel) bool {
+	userID := event.UserID
+	notificationType := model.NotificationType(event.EventType)
+
+	log := logrus.WithFields(logrus.Fields{
+		"user_id": userID,
+		"type":    notificationType,
+		"channel": channel,
+	})
+
+	stored, err := u.preferenceRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		log.Error("failed load notification preferences:", err)
+		return true
+	}
+
+	for _, p := range stored {
+		if p.Type == notificationType && p.Channel == channel && !p.Enabled {
+			return false
+		}
+	}
+
+	if channel == model.ChannelInApp || bypassesQuietHours(event.Priority) {
+		return true
+	}
+
+	quietHours, err := u.preferenceRepo.FindQuietHours(ctx, userID)
+	if err != nil {
+		log.Error("failed load quiet hours:", err)
+		return true
+	}
+
+	return quietHours == nil || !inQuietHours(*quietHours, time.Now())
+}
+
+func bypassesQuietHours(priority string) bool {
+	if priority == "" {
+		return false
+	}
+
+	for _, p := range config.NotificationQuietHoursBypassPriorities() {
+		if strings.EqualFold(p, priority) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func inQuietHours(quietHours model.NotificationQuietHours, now time.Time) bool {
+	if !quietHours.Enabled {
+		return false
+	}
+
+	loc, err := time.LoadLocation(quietHours.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	start, errStart := time.Parse("15:04", quietHours.StartTime)
+	end, errEnd := time.Parse("15:04", quietHours.EndTime)
+	if errStart != nil || errEnd != nil {
+		return false
+	}
+
+	local := now.In(loc)
+	current := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+
+	switch {
+	case from == to:
+		return false
+	case from < to:
+		return current >= from && current < to
+	default:
+		return current >= from || current < to
+	}
+}
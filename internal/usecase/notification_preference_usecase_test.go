@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type fakePreferenceRepo struct {
+	model.INotificationPreferenceRepository
+	preferences []*model.NotificationPreference
+	quietHours  *model.NotificationQuietHours
+}
+
+func (r *fakePreferenceRepo) FindByUserID(ctx context.Context, userID int64) ([]*model.NotificationPreference, error) {
+	return r.preferences, nil
+}
+
+func (r *fakePreferenceRepo) FindQuietHours(ctx context.Context, userID int64) (*model.NotificationQuietHours, error) {
+	return r.quietHours, nil
+}
+
+// alwaysQuiet returns quiet hours from an hour ago until an hour from now,
+// so the test always runs inside them.
+func alwaysQuiet() *model.NotificationQuietHours {
+	now := time.Now().UTC()
+
+	return &model.NotificationQuietHours{
+		Enabled:   true,
+		StartTime: now.Add(-time.Hour).Format("15:04"),
+		EndTime:   now.Add(time.Hour).Format("15:04"),
+		Timezone:  "UTC",
+	}
+}
+
+func TestAllowsDuringQuietHours(t *testing.T) {
+	repo := &fakePreferenceRepo{quietHours: alwaysQuiet()}
+	uc := NewNotificationPreferenceUsecase(repo)
+
+	event := func(priority string) model.NotificationEvent {
+		return model.NotificationEvent{
+			EventType: string(model.NotificationTicketAssigned),
+			UserID:    7,
+			Priority:  priority,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		priority string
+		channel  model.NotificationChannel
+		allowed  bool
+	}{
+		{"in-app is never silenced", "LOW", model.ChannelInApp, true},
+		{"low priority email waits", "LOW", model.ChannelEmail, false},
+		{"event without priority waits", "", model.ChannelPush, false},
+		{"urgent push bypasses", "URGENT", model.ChannelPush, true},
+		{"urgent chat bypasses", "urgent", model.ChannelChat, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := uc.Allows(context.Background(), event(tt.priority), tt.channel); got != tt.allowed {
+				t.Fatalf("Allows = %v, want %v", got, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestAllowsBypassRespectsDisabledChannel(t *testing.T) {
+	repo := &fakePreferenceRepo{
+		quietHours: alwaysQuiet(),
+		preferences: []*model.NotificationPreference{{
+			Type:    model.NotificationTicketAssigned,
+			Channel: model.ChannelPush,
+			Enabled: false,
+		}},
+	}
+	uc := NewNotificationPreferenceUsecase(repo)
+
+	event := model.NotificationEvent{
+		EventType: string(model.NotificationTicketAssigned),
+		UserID:    7,
+		Priority:  "URGENT",
+	}
+
+	if uc.Allows(context.Background(), event, model.ChannelPush) {
+		t.Fatal("urgent event was pushed on a channel the user turned off")
+	}
+}
+
+func TestAllowsBypassPrioritiesAreConfigurable(t *testing.T) {
+	viper.Set("notification.quiet_hours_bypass_priorities", []string{"HIGH", "URGENT"})
+	t.Cleanup(func() { viper.Set("notification.quiet_hours_bypass_priorities", nil) })
+
+	uc := NewNotificationPreferenceUsecase(&fakePreferenceRepo{quietHours: alwaysQuiet()})
+
+	event := model.NotificationEvent{
+		EventType: string(model.NotificationTicketUpdated),
+		UserID:    7,
+		Priority:  "HIGH",
+	}
+
+	if !uc.Allows(context.Background(), event, model.ChannelEmail) {
+		t.Fatal("HIGH priority event was held although configured to bypass")
+	}
+}
@@ -2,20 +2,29 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"time"
 
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+
+	ws "github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/websocket"
 )
 
 type NotificationUsecase struct {
 	notificationRepo model.INotificationRepository
+	wsHub            *ws.Hub
 }
 
 func NewNotificationUsecase(
 	notificationRepo model.INotificationRepository,
+	wsHub *ws.Hub,
 ) model.INotificationUsecase {
 	return &NotificationUsecase{
 		notificationRepo: notificationRepo,
+		wsHub:            wsHub,
 	}
 }
 
@@ -32,17 +41,96 @@ func (u *NotificationUsecase) Create(ctx context.Context, in model.CreateNotific
 		IsRead:        false,
 	}
 
-	return u.notificationRepo.Create(ctx, notification)
+	created, err := u.notificationRepo.Create(ctx, notification)
+	if err != nil {
+		return nil, err
+	}
+
+	u.pushCreated(ctx, created)
+
+	return created, nil
 }
 
-func (u *NotificationUsecase) FindAllByUserID(ctx context.Context, userID int64) ([]*model.NotificationResponse, error) {
-	return u.notificationRepo.FindAllByUserID(ctx, userID)
+// pushCreated delivers the new notification and the recipient's unread
+// count to their open sessions. Failures only cost the live update, the
+// notification itself is already stored.
+func (u *NotificationUsecase) pushCreated(ctx context.Context, notification *model.Notification) {
+	response, err := u.notificationRepo.FindResponseByID(ctx, notification.ID)
+	if err != nil {
+		logrus.Error("failed load notification for websocket:", err)
+		return
+	}
+
+	unread, err := u.notificationRepo.CountUnread(ctx, notification.UserID)
+	if err != nil {
+		logrus.Error("failed count unread notifications:", err)
+		return
+	}
+
+	ws.BroadcastToUser(
+		u.wsHub,
+		notification.UserID,
+		ws.Message{
+			Type: ws.EventNewNotification,
+			Data: map[string]interface{}{
+				"notification": response,
+				"unread_count": unread,
+			},
+		})
+}
+
+func (u *NotificationUsecase) FindAllByUserID(ctx context.Context, userID int64, filter model.NotificationFilter, page int, limit int) ([]*model.NotificationResponse, int64, error) {
+	if filter.Type != "" && !slices.Contains(model.NotificationTypes, filter.Type) {
+		return nil, 0, errors.New("invalid notification type")
+	}
+
+	return u.notificationRepo.FindAllByUserID(ctx, userID, filter, page, limit)
 }
 
 func (u *NotificationUsecase) MarkAsRead(ctx context.Context, id int64, userID int64) error {
 	return u.notificationRepo.MarkAsRead(ctx, id, userID)
 }
 
+func (u *NotificationUsecase) MarkAllAsRead(ctx context.Context, userID int64) (*model.NotificationBulkResult, error) {
+	affected, err := u.notificationRepo.MarkAllAsRead(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.bulkResult(ctx, userID, affected)
+}
+
+func (u *NotificationUsecase) MarkAsReadByTicket(ctx context.Context, userID int64, ticketID int64) (*model.NotificationBulkResult, error) {
+	affected, err := u.notificationRepo.MarkAsReadByTicket(ctx, userID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.bulkResult(ctx, userID, affected)
+}
+
+func (u *NotificationUsecase) Archive(ctx context.Context, id int64, userID int64) error {
+	return u.notificationRepo.Archive(ctx, id, userID)
+}
+
+func (u *NotificationUsecase) ArchiveMany(ctx context.Context, userID int64, in model.NotificationBulkInput) (*model.NotificationBulkResult, error) {
+	filter, err := bulkNotificationFilter(in)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := u.notificationRepo.ArchiveMany(ctx, userID, in.IDs, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.bulkResult(ctx, userID, affected)
+}
+
+func (u *NotificationUsecase) Unarchive(ctx context.Context, id int64, userID int64) error {
+	return u.notificationRepo.Unarchive(ctx, id, userID)
+}
+
 func (u *NotificationUsecase) CountUnread(ctx context.Context, userID int64) (int64, error) {
 	return u.notificationRepo.CountUnread(ctx, userID)
 }
@@ -51,6 +139,89 @@ func (u *NotificationUsecase) Delete(ctx context.Context, id int64, userID int64
 	return u.notificationRepo.Delete(ctx, id, userID)
 }
 
-func (u *NotificationUsecase) DeleteExpired(ctx context.Context, before time.Time) error {
-	return u.notificationRepo.DeleteExpired(ctx, before)
+func (u *NotificationUsecase) DeleteMany(ctx context.Context, userID int64, in model.NotificationBulkInput) (*model.NotificationBulkResult, error) {
+	filter, err := bulkNotificationFilter(in)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := u.notificationRepo.DeleteMany(ctx, userID, in.IDs, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.bulkResult(ctx, userID, affected)
+}
+
+func (u *NotificationUsecase) DeleteExpired(ctx context.Context) (int64, error) {
+	now := time.Now()
+
+	return u.notificationRepo.DeleteExpired(
+		ctx,
+		now.Add(-config.NotificationReadRetention()),
+		now.Add(-config.NotificationUnreadRetention()),
+	)
+}
+
+// bulkResult reports the new unread count and pushes it to every open
+// session of the user so other tabs can update their badge.
+func (u *NotificationUsecase) bulkResult(ctx context.Context, userID int64, affected int64) (*model.NotificationBulkResult, error) {
+	unread, err := u.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.BroadcastToUser(
+		u.wsHub,
+		userID,
+		ws.Message{
+			Type: ws.EventNotificationCountUpdated,
+			Data: map[string]interface{}{
+				"unread_count": unread,
+			},
+		})
+
+	return &model.NotificationBulkResult{
+		Affected:    affected,
+		UnreadCount: unread,
+	}, nil
+}
+
+func bulkNotificationFilter(in model.NotificationBulkInput) (model.NotificationFilter, error) {
+	var filter model.NotificationFilter
+
+	if err := validate.Struct(in); err != nil {
+		return filter, err
+	}
+
+	if len(in.IDs) == 0 && in.Filter == nil {
+		return filter, errors.New("ids or filter is required")
+	}
+
+	if len(in.IDs) > 0 {
+		return filter, nil
+	}
+
+	if err := validate.Struct(in.Filter); err != nil {
+		return filter, err
+	}
+
+	if in.Filter.Type != "" && !slices.Contains(model.NotificationTypes, in.Filter.Type) {
+		return filter, errors.New("invalid notification type")
+	}
+
+	filter.Type = in.Filter.Type
+	filter.TicketID = in.Filter.TicketID
+	filter.Archived = in.Filter.Archived
+
+	switch in.Filter.Status {
+	case "read":
+		isRead := true
+		filter.IsRead = &isRead
+	case "unread":
+		isRead := false
+		filter.IsRead = &isRead
+	}
+
+	return filter, nil
 }
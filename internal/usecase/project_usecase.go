@@ -28,8 +28,9 @@ func (u *ProjectUsecase) Create(ctx context.Context, in model.CreateProjectInput
 	}
 
 	project := model.Project{
-		Name:       in.Name,
-		CodePrefix: strings.ToUpper(in.CodePrefix),
+		Name:             in.Name,
+		CodePrefix:       strings.ToUpper(in.CodePrefix),
+		AllowedMimeTypes: normalizeMimeTypes(in.AllowedMimeTypes),
 	}
 
 	created, err := u.projectRepo.Create(ctx, project)
@@ -87,6 +88,10 @@ func (u *ProjectUsecase) Update(ctx context.Context, id int64, in model.UpdatePr
 	project.Name = in.Name
 	project.CodePrefix = strings.ToUpper(in.CodePrefix)
 
+	if in.AllowedMimeTypes != nil {
+		project.AllowedMimeTypes = normalizeMimeTypes(*in.AllowedMimeTypes)
+	}
+
 	if err := u.projectRepo.Update(ctx, *project); err != nil {
 		log.Error("Failed to update project: ", err)
 		return err
@@ -112,3 +117,18 @@ func (u *ProjectUsecase) Delete(ctx context.Context, id int64) error {
 
 	return u.projectRepo.Delete(ctx, id)
 }
+
+// normalizeMimeTypes never returns nil, so an emptied list is still written
+// by Updates instead of being skipped as a zero value.
+func normalizeMimeTypes(types []string) []string {
+	normalized := []string{}
+
+	for _, t := range types {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t != "" {
+			normalized = append(normalized, t)
+		}
+	}
+
+	return normalized
+}
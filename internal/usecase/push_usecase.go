@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/push"
+)
+
+type PushUsecase struct {
+	subscriptionRepo  model.IPushSubscriptionRepository
+	preferenceUsecase model.INotificationPreferenceUsecase
+	sender            model.IPushSender
+}
+
+func NewPushUsecase(
+	subscriptionRepo model.IPushSubscriptionRepository,
+	preferenceUsecase model.INotificationPreferenceUsecase,
+	sender model.IPushSender,
+) model.IPushUsecase {
+	return &PushUsecase{
+		subscriptionRepo:  subscriptionRepo,
+		preferenceUsecase: preferenceUsecase,
+		sender:            sender,
+	}
+}
+
+func (u *PushUsecase) PublicKey() string {
+	return config.PushVAPIDPublicKey()
+}
+
+func (u *PushUsecase) Subscribe(ctx context.Context, userID int64, in model.SubscribePushInput) (*model.PushSubscription, error) {
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+
+	if err := push.ValidateEndpoint(in.Endpoint); err != nil {
+		return nil, err
+	}
+
+	subscription := model.PushSubscription{
+		UserID:   userID,
+		Endpoint: in.Endpoint,
+		P256dh:   in.Keys.P256dh,
+		Auth:     in.Keys.Auth,
+	}
+
+	if in.DeviceName != "" {
+		subscription.DeviceName = &in.DeviceName
+	}
+
+	if in.UserAgent != "" {
+		subscription.UserAgent = &in.UserAgent
+	}
+
+	if in.ExpirationTime != nil {
+		expiresAt := time.UnixMilli(*in.ExpirationTime)
+		subscription.ExpiresAt = &expiresAt
+	}
+
+	return u.subscriptionRepo.Upsert(ctx, subscription)
+}
+
+func (u *PushUsecase) FindAll(ctx context.Context, userID int64) ([]*model.PushSubscription, error) {
+	return u.subscriptionRepo.FindByUserID(ctx, userID)
+}
+
+func (u *PushUsecase) Unsubscribe(ctx context.Context, id int64, userID int64) error {
+	return u.subscriptionRepo.Delete(ctx, id, userID)
+}
+
+// Notify pushes the event to every registered device of the recipient.
+// Subscriptions the push service reports as gone, and ones saved before
+// endpoints were restricted, are removed immediately; other failures are
+// counted and left to the cleanup worker.
+func (u *PushUsecase) Notify(ctx context.Context, event model.NotificationEvent) error {
+	if !u.preferenceUsecase.Allows(ctx, event, model.ChannelPush) {
+		return nil
+	}
+
+	subscriptions, err := u.subscriptionRepo.FindByUserID(ctx, event.UserID)
+	if err != nil {
+		return err
+	}
+
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(model.PushMessage{
+		Type:       event.EventType,
+		Title:      event.Title,
+		Body:       event.Message,
+		TicketID:   event.TicketID,
+		TicketCode: event.TicketCode,
+		URL:        ticketLink(event.TicketID),
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	for _, subscription := range subscriptions {
+		if subscription.ExpiresAt != nil && subscription.ExpiresAt.Before(now) {
+			continue
+		}
+
+		err := u.sender.Send(ctx, *subscription, payload)
+
+		switch {
+		case errors.Is(err, model.ErrPushSubscriptionGone), errors.Is(err, push.ErrEndpointNotAllowed):
+			if err := u.subscriptionRepo.DeleteByID(ctx, subscription.ID); err != nil {
+				logrus.Error("failed delete push subscription:", err)
+			}
+		case err != nil:
+			logrus.WithFields(logrus.Fields{
+				"subscription_id": subscription.ID,
+				"user_id":         subscription.UserID,
+			}).Error("failed send push notification:", err)
+
+			if err := u.subscriptionRepo.MarkFailed(ctx, subscription.ID); err != nil {
+				logrus.Error("failed mark push subscription:", err)
+			}
+		default:
+			if err := u.subscriptionRepo.MarkSent(ctx, subscription.ID); err != nil {
+				logrus.Error("failed mark push subscription:", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (u *PushUsecase) DeleteExpired(ctx context.Context) (int64, error) {
+	now := time.Now()
+
+	return u.subscriptionRepo.DeleteExpired(
+		ctx,
+		now,
+		now.Add(-config.PushSubscriptionTTL()),
+		config.PushMaxFailures(),
+	)
+}
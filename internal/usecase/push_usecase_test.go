@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/push"
+)
+
+type fakePushSubscriptionRepo struct {
+	model.IPushSubscriptionRepository
+
+	subscriptions []*model.PushSubscription
+	deleted       []int64
+	failed        []int64
+	sent          []int64
+}
+
+func (r *fakePushSubscriptionRepo) FindByUserID(ctx context.Context, userID int64) ([]*model.PushSubscription, error) {
+	return r.subscriptions, nil
+}
+
+func (r *fakePushSubscriptionRepo) DeleteByID(ctx context.Context, id int64) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+func (r *fakePushSubscriptionRepo) MarkFailed(ctx context.Context, id int64) error {
+	r.failed = append(r.failed, id)
+	return nil
+}
+
+func (r *fakePushSubscriptionRepo) MarkSent(ctx context.Context, id int64) error {
+	r.sent = append(r.sent, id)
+	return nil
+}
+
+type allowAllPreferences struct {
+	model.INotificationPreferenceUsecase
+}
+
+func (allowAllPreferences) Allows(ctx context.Context, event model.NotificationEvent, channel model.NotificationChannel) bool {
+	return true
+}
+
+// stubPushSender answers per endpoint the way the push sender reports the
+// push service's response.
+type stubPushSender map[string]error
+
+func (s stubPushSender) Send(ctx context.Context, subscription model.PushSubscription, payload []byte) error {
+	return s[subscription.Endpoint]
+}
+
+func TestPushNotifyRemovesGoneSubscriptions(t *testing.T) {
+	repo := &fakePushSubscriptionRepo{
+		subscriptions: []*model.PushSubscription{
+			{ID: 1, UserID: 7, Endpoint: "https://push.example.com/ok"},
+			{ID: 2, UserID: 7, Endpoint: "https://push.example.com/gone"},
+			{ID: 3, UserID: 7, Endpoint: "https://push.example.com/busy"},
+			{ID: 4, UserID: 7, Endpoint: "http://10.0.0.5/internal"},
+		},
+	}
+
+	sender := stubPushSender{
+		"https://push.example.com/gone": model.ErrPushSubscriptionGone,
+		"https://push.example.com/busy": errors.New("push service responded 429"),
+		"http://10.0.0.5/internal":      fmt.Errorf("%w: 10.0.0.5", push.ErrEndpointNotAllowed),
+	}
+
+	uc := NewPushUsecase(repo, allowAllPreferences{}, sender)
+
+	err := uc.Notify(context.Background(), model.NotificationEvent{
+		EventType: "TICKET_ASSIGNED",
+		UserID:    7,
+		TicketID:  1,
+		Title:     "Tiket Ditugaskan",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(repo.deleted, []int64{2, 4}) {
+		t.Fatalf("deleted = %v, want [2 4]", repo.deleted)
+	}
+
+	if !slices.Equal(repo.failed, []int64{3}) {
+		t.Fatalf("failed = %v, want [3]", repo.failed)
+	}
+
+	if !slices.Equal(repo.sent, []int64{1}) {
+		t.Fatalf("sent = %v, want [1]", repo.sent)
+	}
+}
+
+func TestPushSubscribeRejectsInternalEndpoint(t *testing.T) {
+	uc := NewPushUsecase(&fakePushSubscriptionRepo{}, allowAllPreferences{}, stubPushSender{})
+
+	_, err := uc.Subscribe(context.Background(), 7, model.SubscribePushInput{
+		Endpoint: "https://169.254.169.254/latest/meta-data",
+		Keys: model.PushSubscriptionKeys{
+			P256dh: "key",
+			Auth:   "auth",
+		},
+	})
+	if !errors.Is(err, push.ErrEndpointNotAllowed) {
+		t.Fatalf("err = %v, want ErrEndpointNotAllowed", err)
+	}
+}
@@ -0,0 +1,712 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/cron"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/exporter"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/storage"
+)
+
+const reportWebhookEvent = "REPORT_DELIVERED"
+
+type ReportScheduleUsecase struct {
+	reportScheduleRepo  model.IReportScheduleRepository
+	userRepo            model.IUserRepository
+	webhookRepo         model.IWebhookRepository
+	ticketExportUsecase model.ITicketExportUsecase
+	mailSender          model.IMailSender
+	store               model.IStorage
+}
+
+func NewReportScheduleUsecase(
+	reportScheduleRepo model.IReportScheduleRepository,
+	userRepo model.IUserRepository,
+	webhookRepo model.IWebhookRepository,
+	ticketExportUsecase model.ITicketExportUsecase,
+	mailSender model.IMailSender,
+	store model.IStorage,
+) model.IReportScheduleUsecase {
+	return &ReportScheduleUsecase{
+		reportScheduleRepo:  reportScheduleRepo,
+		userRepo:            userRepo,
+		webhookRepo:         webhookRepo,
+		ticketExportUsecase: ticketExportUsecase,
+		mailSender:          mailSender,
+		store:               store,
+	}
+}
+
+func (u *ReportScheduleUsecase) Create(ctx context.Context, userID int64, role string, in model.ReportScheduleInput) (*model.ReportSchedule, error) {
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+
+	count, err := u.reportScheduleRepo.CountByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if count >= int64(config.ReportScheduleMaxPerUser()) {
+		return nil, model.ErrReportScheduleLimit
+	}
+
+	schedule := model.ReportSchedule{
+		UserID:   userID,
+		IsActive: true,
+	}
+
+	if err := u.apply(ctx, &schedule, role, in); err != nil {
+		return nil, err
+	}
+
+	return u.reportScheduleRepo.Create(ctx, schedule)
+}
+
+func (u *ReportScheduleUsecase) FindByUserID(ctx context.Context, userID int64, page int, limit int) ([]*model.ReportSchedule, int64, error) {
+	return u.reportScheduleRepo.FindByUserID(ctx, userID, page, limit)
+}
+
+// FindByID hides other users' schedules behind not found rather than
+// forbidden, so schedule IDs cannot be probed.
+func (u *ReportScheduleUsecase) FindByID(ctx context.Context, id int64, userID int64) (*model.ReportSchedule, error) {
+	schedule, err := u.reportScheduleRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if schedule.UserID != userID {
+		return nil, model.ErrReportScheduleNotFound
+	}
+
+	return schedule, nil
+}
+
+// Update also refreshes the role the schedule runs with, so a report
+// follows its owner's current access.
+func (u *ReportScheduleUsecase) Update(ctx context.Context, id int64, userID int64, role string, in model.ReportScheduleInput) (*model.ReportSchedule, error) {
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+
+	schedule, err := u.FindByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.apply(ctx, schedule, role, in); err != nil {
+		return nil, err
+	}
+
+	if err := u.reportScheduleRepo.Update(ctx, *schedule); err != nil {
+		return nil, err
+	}
+
+	return u.reportScheduleRepo.FindByID(ctx, id)
+}
+
+// Delete removes the schedule with its history and files. A schedule that
+// is running cannot be deleted, since its run would then leave the file
+// behind.
+func (u *ReportScheduleUsecase) Delete(ctx context.Context, id int64, userID int64) error {
+	schedule, err := u.FindByID(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if isLeased(schedule) {
+		return model.ErrReportScheduleRunning
+	}
+
+	keys, err := u.reportScheduleRepo.FindRunFiles(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := storage.Remove(ctx, u.store, key); err != nil {
+			logrus.Error("failed remove report file:", err)
+		}
+	}
+
+	return u.reportScheduleRepo.Delete(ctx, id)
+}
+
+func (u *ReportScheduleUsecase) RunNow(ctx context.Context, id int64, userID int64) (*model.ReportSchedule, error) {
+	schedule, err := u.FindByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if isLeased(schedule) {
+		return nil, model.ErrReportScheduleRunning
+	}
+
+	now := time.Now()
+	schedule.NextRunAt = &now
+
+	if err := u.reportScheduleRepo.Update(ctx, *schedule); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+func (u *ReportScheduleUsecase) FindRuns(ctx context.Context, scheduleID int64, userID int64, page int, limit int) ([]*model.ReportRun, int64, error) {
+	if _, err := u.FindByID(ctx, scheduleID, userID); err != nil {
+		return nil, 0, err
+	}
+
+	runs, total, err := u.reportScheduleRepo.FindRunsByScheduleID(ctx, scheduleID, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, run := range runs {
+		withRunDownloadURL(run)
+	}
+
+	return runs, total, nil
+}
+
+func (u *ReportScheduleUsecase) DownloadRun(ctx context.Context, runID int64, userID int64) (*model.ReportRun, io.ReadCloser, error) {
+	run, err := u.reportScheduleRepo.FindRunByID(ctx, runID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	schedule, err := u.FindByID(ctx, run.ScheduleID, userID)
+	if err != nil {
+		return nil, nil, model.ErrReportRunNotFound
+	}
+
+	if run.StorageKey == nil || run.Filename == nil {
+		if time.Now().After(run.ExpiresAt) {
+			return nil, nil, model.ErrReportRunExpired
+		}
+
+		return nil, nil, model.ErrReportRunNotReady
+	}
+
+	exp, err := exporter.New(schedule.Format)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader, _, err := u.store.Get(ctx, *run.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	run.ContentType = exp.ContentType()
+
+	return withRunDownloadURL(run), reader, nil
+}
+
+// RunDue builds and sends one due report. A failed run is recorded in the
+// history and the schedule moves on to its next time; it is not retried.
+// Runs missed while the server was down are caught up once, not once per
+// missed time.
+func (u *ReportScheduleUsecase) RunDue(ctx context.Context) (bool, error) {
+	schedule, err := u.reportScheduleRepo.ClaimDue(ctx, config.ReportScheduleTimeout())
+	if err != nil || schedule == nil {
+		return false, err
+	}
+
+	log := logrus.WithFields(logrus.Fields{
+		"report_schedule_id": schedule.ID,
+		"user_id":            schedule.UserID,
+	})
+
+	started := time.Now()
+
+	// The report is built with the owner's current role, and stops for
+	// good once they may no longer receive it.
+	owner, err := u.userRepo.FindByID(ctx, schedule.UserID)
+	if err != nil {
+		log.Warn("skipping scheduled report, failed load owner:", err)
+		return true, u.reportScheduleRepo.Release(ctx, schedule.ID, started, nextReportRun(schedule, started))
+	}
+
+	if reason := ownerRevoked(owner, schedule); reason != "" {
+		log.Warn("disabling scheduled report: ", reason)
+		return true, u.reportScheduleRepo.Disable(ctx, schedule.ID)
+	}
+
+	schedule.Role = owner.Role.Name
+
+	filter := reportFilter(schedule, started.In(scheduleLocation(schedule)))
+
+	run, err := u.reportScheduleRepo.CreateRun(ctx, model.ReportRun{
+		ScheduleID: schedule.ID,
+		Status:     model.ReportRunRunning,
+		StartDate:  optionalString(filter.StartDate),
+		EndDate:    optionalString(filter.EndDate),
+		StartedAt:  started,
+		ExpiresAt:  started.Add(config.ReportScheduleRetention()),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, config.ReportScheduleTimeout())
+	defer cancel()
+
+	if err := u.run(runCtx, schedule, filter, run); err != nil {
+		log.Error("scheduled report failed:", err)
+		u.failRun(ctx, run, err)
+	} else {
+		log.Infof("scheduled report sent: %d rows in %s", run.TotalRows, time.Since(started).Round(time.Millisecond))
+	}
+
+	if err := u.reportScheduleRepo.Release(ctx, schedule.ID, started, nextReportRun(schedule, time.Now())); err != nil {
+		return true, err
+	}
+
+	return true, nil
+}
+
+// DeleteExpiredRuns removes the files of old runs but keeps the runs, so
+// the history still shows when each report was sent.
+func (u *ReportScheduleUsecase) DeleteExpiredRuns(ctx context.Context) (int64, error) {
+	var deleted int64
+
+	for {
+		runs, err := u.reportScheduleRepo.FindExpiredRuns(ctx, time.Now(), 100)
+		if err != nil {
+			return deleted, err
+		}
+
+		for _, run := range runs {
+			if err := storage.Remove(ctx, u.store, *run.StorageKey); err != nil {
+				logrus.Error("failed remove report file:", err)
+			}
+
+			if err := u.reportScheduleRepo.ClearRunFile(ctx, run.ID); err != nil {
+				return deleted, err
+			}
+
+			deleted++
+		}
+
+		if len(runs) < 100 {
+			return deleted, nil
+		}
+	}
+}
+
+// apply checks the input and copies it onto the schedule, working out the
+// next run from the cron expression in the schedule's timezone.
+func (u *ReportScheduleUsecase) apply(ctx context.Context, schedule *model.ReportSchedule, role string, in model.ReportScheduleInput) error {
+	timezone := in.Timezone
+	if timezone == "" {
+		timezone = config.ReportScheduleTimezone()
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return model.ErrReportTimezoneInvalid
+	}
+
+	expr, err := cron.Parse(in.Cron)
+	if err != nil {
+		return fmt.Errorf("%w: %v", model.ErrReportCronInvalid, err)
+	}
+
+	next := expr.Next(time.Now().In(loc))
+	if next.IsZero() {
+		return fmt.Errorf("%w: it never runs", model.ErrReportCronInvalid)
+	}
+
+	if gap := shortestCronGap(expr, next); gap < config.ReportScheduleMinInterval() {
+		return fmt.Errorf("%w: runs must be at least %s apart", model.ErrReportCronTooFrequent, config.ReportScheduleMinInterval())
+	}
+
+	recipients := []string{}
+	var webhookID *int64
+
+	if in.Channel == model.ReportChannelWebhook {
+		if role != "ADMINISTRATOR" {
+			return model.ErrReportWebhookForbidden
+		}
+
+		webhook, err := u.webhookRepo.FindByID(ctx, *in.WebhookID)
+		if err != nil || !webhook.IsActive {
+			return model.ErrReportWebhookInvalid
+		}
+
+		webhookID = &webhook.ID
+	} else {
+		for _, recipient := range in.Recipients {
+			if !u.recipientAllowed(ctx, recipient) {
+				return fmt.Errorf("%w: %s", model.ErrReportRecipientInvalid, recipient)
+			}
+
+			recipients = append(recipients, strings.ToLower(recipient))
+		}
+	}
+
+	format := in.Format
+	if format == "" {
+		format = "xlsx"
+	}
+
+	if in.IsActive != nil {
+		schedule.IsActive = *in.IsActive
+	}
+
+	schedule.Role = role
+	schedule.Name = in.Name
+	schedule.Format = format
+	schedule.Filter = in.Filter
+	schedule.Period = in.Period
+	schedule.Cron = strings.TrimSpace(in.Cron)
+	schedule.Timezone = timezone
+	schedule.Channel = in.Channel
+	schedule.Recipients = recipients
+	schedule.WebhookID = webhookID
+	schedule.NextRunAt = nil
+
+	if schedule.IsActive {
+		schedule.NextRunAt = &next
+	}
+
+	return nil
+}
+
+// recipientAllowed accepts the address of an active user or any address in
+// report_schedule.recipient_domains, so reports cannot be mailed to
+// arbitrary outsiders.
+func (u *ReportScheduleUsecase) recipientAllowed(ctx context.Context, email string) bool {
+	_, domain, _ := strings.Cut(strings.ToLower(email), "@")
+
+	for _, allowed := range config.ReportScheduleRecipientDomains() {
+		if strings.EqualFold(domain, allowed) {
+			return true
+		}
+	}
+
+	user, err := u.userRepo.FindByEmail(ctx, strings.ToLower(email))
+	if err != nil {
+		return false
+	}
+
+	return user.IsActive && user.LockedAt == nil
+}
+
+// ownerRevoked says why the owner may no longer run the schedule, or ""
+// when they still may.
+func ownerRevoked(owner *model.User, schedule *model.ReportSchedule) string {
+	switch {
+	case !owner.IsActive:
+		return "owner is not active"
+	case owner.LockedAt != nil:
+		return "owner is locked"
+	case !slices.Contains(config.ReportScheduleRoles(), owner.Role.Name):
+		return "owner's role may not schedule reports"
+	case schedule.Channel == model.ReportChannelWebhook && owner.Role.Name != "ADMINISTRATOR":
+		return "owner may no longer deliver to webhooks"
+	}
+
+	return ""
+}
+
+// shortestCronGap looks at the next runs after first and returns the
+// shortest time between two of them.
+func shortestCronGap(expr *cron.Schedule, first time.Time) time.Duration {
+	shortest := time.Duration(math.MaxInt64)
+
+	current := first
+	for i := 0; i < 100; i++ {
+		next := expr.Next(current)
+		if next.IsZero() {
+			break
+		}
+
+		if gap := next.Sub(current); gap < shortest {
+			shortest = gap
+		}
+
+		current = next
+	}
+
+	return shortest
+}
+
+// run builds the report and stores it before sending, so the file can
+// still be downloaded from the history when delivery fails.
+func (u *ReportScheduleUsecase) run(ctx context.Context, schedule *model.ReportSchedule, filter model.ExportFilter, run *model.ReportRun) error {
+	var file bytes.Buffer
+
+	result, err := u.ticketExportUsecase.Export(
+		ctx,
+		schedule.UserID,
+		schedule.Role,
+		schedule.Format,
+		filter,
+		&file,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	data := file.Bytes()
+	size := int64(len(data))
+	filename := reportFilename(schedule, run.StartedAt, path.Ext(result.Filename))
+
+	key, err := storage.Upload(ctx, u.store, fmt.Sprintf("reports/%d", schedule.ID), filename, bytes.NewReader(data), size, result.ContentType)
+	if err != nil {
+		return fmt.Errorf("store report: %w", err)
+	}
+
+	run.TotalRows = result.Rows
+	run.Filename = &filename
+	run.Size = size
+	run.StorageKey = &key
+
+	if err := u.reportScheduleRepo.UpdateRun(ctx, *run); err != nil {
+		if removeErr := storage.Remove(ctx, u.store, key); removeErr != nil {
+			logrus.Error("failed remove orphaned report file:", removeErr)
+		}
+		return err
+	}
+
+	if err := u.deliver(ctx, schedule, run, data, result.ContentType); err != nil {
+		return fmt.Errorf("deliver report: %w", err)
+	}
+
+	now := time.Now()
+
+	run.Status = model.ReportRunCompleted
+	run.Error = nil
+	run.DeliveredAt = &now
+	run.CompletedAt = &now
+
+	return u.reportScheduleRepo.UpdateRun(ctx, *run)
+}
+
+// deliver emails the report, attaching the file when it is small enough,
+// or queues it on the schedule's webhook, whose delivery log takes care
+// of signing and retries.
+func (u *ReportScheduleUsecase) deliver(ctx context.Context, schedule *model.ReportSchedule, run *model.ReportRun, data []byte, contentType string) error {
+	link := storage.SignedURL(*run.StorageKey, time.Until(run.ExpiresAt))
+
+	if schedule.Channel == model.ReportChannelWebhook {
+		return u.queueWebhook(ctx, schedule, run, link)
+	}
+
+	mail := model.Mail{
+		To:      schedule.Recipients,
+		Subject: fmt.Sprintf("Laporan Helpdesk: %s", schedule.Name),
+		Body:    reportMailBody(schedule, run, link),
+	}
+
+	if run.Size <= config.ReportScheduleMailAttachmentMaxSize() {
+		mail.Attachments = []model.MailAttachment{
+			{
+				Filename:    *run.Filename,
+				ContentType: contentType,
+				Data:        data,
+			},
+		}
+	}
+
+	return u.mailSender.Send(ctx, mail)
+}
+
+func (u *ReportScheduleUsecase) queueWebhook(ctx context.Context, schedule *model.ReportSchedule, run *model.ReportRun, link string) error {
+	if schedule.WebhookID == nil {
+		return model.ErrReportWebhookInvalid
+	}
+
+	webhook, err := u.webhookRepo.FindByID(ctx, *schedule.WebhookID)
+	if err != nil || !webhook.IsActive {
+		return model.ErrReportWebhookInvalid
+	}
+
+	payload, err := json.Marshal(model.ReportWebhookPayload{
+		Event:      reportWebhookEvent,
+		OccurredAt: time.Now(),
+		Data: model.ReportWebhookData{
+			ScheduleID:  schedule.ID,
+			Name:        schedule.Name,
+			RunID:       run.ID,
+			Format:      schedule.Format,
+			Filename:    *run.Filename,
+			TotalRows:   run.TotalRows,
+			Size:        run.Size,
+			StartDate:   run.StartDate,
+			EndDate:     run.EndDate,
+			DownloadURL: link,
+			ExpiresAt:   run.ExpiresAt,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = u.webhookRepo.CreateDelivery(ctx, model.WebhookDelivery{
+		WebhookID: webhook.ID,
+		EventType: reportWebhookEvent,
+		Payload:   string(payload),
+		Status:    model.WebhookDeliveryPending,
+	})
+
+	return err
+}
+
+func (u *ReportScheduleUsecase) failRun(ctx context.Context, run *model.ReportRun, cause error) {
+	message := cause.Error()
+	now := time.Now()
+
+	run.Status = model.ReportRunFailed
+	run.Error = &message
+	run.CompletedAt = &now
+
+	if err := u.reportScheduleRepo.UpdateRun(ctx, *run); err != nil {
+		logrus.Error("failed update report run:", err)
+	}
+}
+
+func reportMailBody(schedule *model.ReportSchedule, run *model.ReportRun, link string) string {
+	var body strings.Builder
+
+	fmt.Fprintf(&body, "Halo,\n\nLaporan terjadwal \"%s\" sudah dibuat.\n\n", schedule.Name)
+
+	if run.StartDate != nil || run.EndDate != nil {
+		fmt.Fprintf(&body, "Periode: %s s/d %s\n", valueOf(run.StartDate, "awal"), valueOf(run.EndDate, "sekarang"))
+	}
+
+	fmt.Fprintf(&body, "Jumlah tiket: %d\n", run.TotalRows)
+	fmt.Fprintf(&body, "Dibuat: %s\n\n", run.StartedAt.In(scheduleLocation(schedule)).Format("02 Jan 2006 15:04 MST"))
+
+	if run.Size <= config.ReportScheduleMailAttachmentMaxSize() {
+		body.WriteString("File laporan terlampir. ")
+	}
+
+	fmt.Fprintf(&body, "Laporan juga dapat diunduh hingga %s:\n\n%s\n", run.ExpiresAt.In(scheduleLocation(schedule)).Format("02 Jan 2006"), link)
+
+	return body.String()
+}
+
+// reportFilter applies the schedule's period, if any, to its saved filter.
+// Periods end the day before now, so a report never covers a partial day.
+func reportFilter(schedule *model.ReportSchedule, now time.Time) model.ExportFilter {
+	filter := schedule.Filter
+
+	if schedule.Period == nil {
+		return filter
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var start, end time.Time
+
+	switch *schedule.Period {
+	case model.ReportPeriodDay:
+		start, end = today.AddDate(0, 0, -1), today.AddDate(0, 0, -1)
+	case model.ReportPeriodWeek:
+		start, end = today.AddDate(0, 0, -7), today.AddDate(0, 0, -1)
+	case model.ReportPeriodMonth:
+		start = time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, now.Location())
+		end = time.Date(now.Year(), now.Month(), 0, 0, 0, 0, 0, now.Location())
+	default:
+		return filter
+	}
+
+	filter.StartDate = start.Format("2006-01-02")
+	filter.EndDate = end.Format("2006-01-02")
+
+	return filter
+}
+
+// nextReportRun returns nil for a schedule that cannot run again, which
+// leaves it paused until it is fixed.
+func nextReportRun(schedule *model.ReportSchedule, after time.Time) *time.Time {
+	expr, err := cron.Parse(schedule.Cron)
+	if err != nil {
+		logrus.Error("invalid cron on report schedule:", err)
+		return nil
+	}
+
+	next := expr.Next(after.In(scheduleLocation(schedule)))
+	if next.IsZero() {
+		return nil
+	}
+
+	return &next
+}
+
+func scheduleLocation(schedule *model.ReportSchedule) *time.Location {
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return time.Local
+	}
+
+	return loc
+}
+
+// reportFilename names the file after the schedule, e.g.
+// "weekly_open_tickets_2026-10-19.xlsx".
+func reportFilename(schedule *model.ReportSchedule, startedAt time.Time, ext string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		}
+		return '_'
+	}, schedule.Name)
+
+	name = strings.Trim(name, "_")
+	if name == "" {
+		name = "report"
+	}
+
+	return fmt.Sprintf("%s_%s%s", name, startedAt.In(scheduleLocation(schedule)).Format("2006-01-02"), ext)
+}
+
+func isLeased(schedule *model.ReportSchedule) bool {
+	return schedule.LeaseUntil != nil && schedule.LeaseUntil.After(time.Now())
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
+
+func valueOf(value *string, fallback string) string {
+	if value == nil {
+		return fallback
+	}
+
+	return *value
+}
+
+func withRunDownloadURL(run *model.ReportRun) *model.ReportRun {
+	run.DownloadURL = nil
+
+	if run.StorageKey != nil && time.Now().Before(run.ExpiresAt) {
+		url := fmt.Sprintf("%s/v1/report-schedules/runs/%d/download", config.APIURL(), run.ID)
+		run.DownloadURL = &url
+	}
+
+	return run
+}
@@ -120,3 +120,22 @@ func (u *RoleUsecase) Delete(ctx context.Context, id int64) error {
 
 	return nil
 }
+
+func (u *RoleUsecase) UpdateTwoFactorRequirement(ctx context.Context, id int64, in model.UpdateRoleTwoFactorInput) error {
+	log := logrus.WithFields(logrus.Fields{
+		"id":       id,
+		"required": in.Required,
+	})
+
+	if _, err := u.roleRepo.FindByID(ctx, id); err != nil {
+		log.Error("Role not found: ", err)
+		return err
+	}
+
+	if err := u.roleRepo.UpdateTwoFactorRequirement(ctx, id, in.Required); err != nil {
+		log.Error("Failed to update two factor requirement: ", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,316 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type ServiceAccountUsecase struct {
+	userRepo     model.IUserRepository
+	roleRepo     model.IRoleRepository
+	apiKeyRepo   model.IAPIKeyRepository
+	auditLogRepo model.IAuditLogRepository
+}
+
+func NewServiceAccountUsecase(
+	userRepo model.IUserRepository,
+	roleRepo model.IRoleRepository,
+	apiKeyRepo model.IAPIKeyRepository,
+	auditLogRepo model.IAuditLogRepository,
+) model.IServiceAccountUsecase {
+	return &ServiceAccountUsecase{
+		userRepo:     userRepo,
+		roleRepo:     roleRepo,
+		apiKeyRepo:   apiKeyRepo,
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+func (u *ServiceAccountUsecase) FindAll(ctx context.Context, page int, limit int) ([]*model.User, int64, error) {
+	return u.userRepo.FindAll(ctx, model.User{IsServiceAccount: true}, page, limit)
+}
+
+func (u *ServiceAccountUsecase) Create(ctx context.Context, actorID int64, in model.CreateServiceAccountInput) (*model.User, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"actor_id": actorID,
+		"email":    in.Email,
+	})
+
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+
+	role, err := u.roleRepo.FindByID(ctx, in.RoleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if role.Name == "ADMINISTRATOR" {
+		return nil, errors.New("service accounts cannot have the ADMINISTRATOR role")
+	}
+
+	var projects []model.Project
+	for _, p := range in.Projects {
+		projects = append(projects, model.Project{
+			ID: p.ID,
+		})
+	}
+
+	account, err := u.userRepo.Create(ctx, model.User{
+		Name:             in.Name,
+		Email:            in.Email,
+		RoleID:           in.RoleID,
+		IsActive:         true,
+		IsServiceAccount: true,
+		Projects:         projects,
+	})
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	u.writeAuditLog(ctx, model.AuditLog{
+		ActorID:    &actorID,
+		Action:     model.AuditServiceAccountCreated,
+		TargetType: model.AuditTargetUser,
+		TargetID:   account.ID,
+	})
+
+	return account, nil
+}
+
+func (u *ServiceAccountUsecase) Delete(ctx context.Context, actorID int64, id int64) error {
+	if _, err := u.findServiceAccount(ctx, id); err != nil {
+		return err
+	}
+
+	if err := u.apiKeyRepo.RevokeByUserID(ctx, id); err != nil {
+		return err
+	}
+
+	if err := u.userRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	u.writeAuditLog(ctx, model.AuditLog{
+		ActorID:    &actorID,
+		Action:     model.AuditServiceAccountDeleted,
+		TargetType: model.AuditTargetUser,
+		TargetID:   id,
+	})
+
+	return nil
+}
+
+func (u *ServiceAccountUsecase) FindKeys(ctx context.Context, serviceAccountID int64) ([]*model.APIKey, error) {
+	if _, err := u.findServiceAccount(ctx, serviceAccountID); err != nil {
+		return nil, err
+	}
+
+	return u.apiKeyRepo.FindByUserID(ctx, serviceAccountID)
+}
+
+func (u *ServiceAccountUsecase) CreateKey(ctx context.Context, actorID int64, serviceAccountID int64, in model.CreateAPIKeyInput) (*model.CreatedAPIKey, error) {
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+
+	if _, err := u.findServiceAccount(ctx, serviceAccountID); err != nil {
+		return nil, err
+	}
+
+	expiresAt, err := resolveKeyExpiry(in.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := u.issueKey(ctx, actorID, serviceAccountID, in.Name, in.Scopes, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	u.writeAuditLog(ctx, model.AuditLog{
+		ActorID:    &actorID,
+		Action:     model.AuditAPIKeyCreated,
+		TargetType: model.AuditTargetAPIKey,
+		TargetID:   created.ID,
+	})
+
+	return created, nil
+}
+
+// RotateKey issues a replacement with the same name and scopes. The old key
+// keeps working for the grace period so integrations can be redeployed.
+func (u *ServiceAccountUsecase) RotateKey(ctx context.Context, actorID int64, keyID int64, in model.RotateAPIKeyInput) (*model.CreatedAPIKey, error) {
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+
+	old, err := u.apiKeyRepo.FindByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if old.RevokedAt != nil || !old.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("api key is already revoked or expired")
+	}
+
+	expiresAt, err := resolveKeyExpiry(in.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := u.issueKey(ctx, actorID, old.UserID, old.Name, old.Scopes, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.GracePeriodHours > 0 {
+		graceUntil := time.Now().Add(time.Duration(in.GracePeriodHours) * time.Hour)
+		if graceUntil.Before(old.ExpiresAt) {
+			err = u.apiKeyRepo.UpdateExpiry(ctx, old.ID, graceUntil)
+		}
+	} else {
+		err = u.apiKeyRepo.Revoke(ctx, old.ID)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := fmt.Sprintf(`{"replaced_by":%d}`, created.ID)
+
+	u.writeAuditLog(ctx, model.AuditLog{
+		ActorID:    &actorID,
+		Action:     model.AuditAPIKeyRotated,
+		TargetType: model.AuditTargetAPIKey,
+		TargetID:   old.ID,
+		Metadata:   &metadata,
+	})
+
+	return created, nil
+}
+
+func (u *ServiceAccountUsecase) RevokeKey(ctx context.Context, actorID int64, keyID int64) error {
+	if err := u.apiKeyRepo.Revoke(ctx, keyID); err != nil {
+		return err
+	}
+
+	u.writeAuditLog(ctx, model.AuditLog{
+		ActorID:    &actorID,
+		Action:     model.AuditAPIKeyRevoked,
+		TargetType: model.AuditTargetAPIKey,
+		TargetID:   keyID,
+	})
+
+	return nil
+}
+
+func (u *ServiceAccountUsecase) Authenticate(ctx context.Context, rawKey string) (*model.CustomClaims, error) {
+	if !strings.HasPrefix(rawKey, model.APIKeyPrefix) {
+		return nil, errors.New("invalid api key")
+	}
+
+	key, err := u.apiKeyRepo.FindActiveByHash(ctx, helper.HashToken(rawKey))
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := u.userRepo.FindByID(ctx, key.UserID)
+	if err != nil {
+		return nil, errors.New("invalid api key")
+	}
+
+	if !account.IsServiceAccount || !account.IsActive || account.LockedAt != nil {
+		return nil, errors.New("service account is disabled")
+	}
+
+	if err := u.apiKeyRepo.TouchLastUsed(ctx, key.ID); err != nil {
+		logrus.WithField("api_key_id", key.ID).Error("failed update api key last used:", err)
+	}
+
+	return &model.CustomClaims{
+		UserID:   account.ID,
+		RoleID:   account.RoleID,
+		Role:     account.Role.Name,
+		Email:    account.Email,
+		Name:     account.Name,
+		APIKeyID: key.ID,
+		Scopes:   key.Scopes,
+	}, nil
+}
+
+func (u *ServiceAccountUsecase) findServiceAccount(ctx context.Context, id int64) (*model.User, error) {
+	account, err := u.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !account.IsServiceAccount {
+		return nil, errors.New("service account not found")
+	}
+
+	return account, nil
+}
+
+func (u *ServiceAccountUsecase) issueKey(ctx context.Context, actorID int64, userID int64, name string, scopes []string, expiresAt time.Time) (*model.CreatedAPIKey, error) {
+	secret, err := helper.GenerateRandomToken(24)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := model.APIKeyPrefix + secret
+
+	key, err := u.apiKeyRepo.Create(ctx, model.APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    raw[:len(model.APIKeyPrefix)+8],
+		KeyHash:   helper.HashToken(raw),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedBy: actorID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CreatedAPIKey{
+		APIKey: *key,
+		Key:    raw,
+	}, nil
+}
+
+func (u *ServiceAccountUsecase) writeAuditLog(ctx context.Context, entry model.AuditLog) {
+	if err := u.auditLogRepo.Create(ctx, entry); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"action":    entry.Action,
+			"target_id": entry.TargetID,
+		}).Error("failed write audit log:", err)
+	}
+}
+
+func resolveKeyExpiry(requested *time.Time) (time.Time, error) {
+	now := time.Now()
+
+	if requested == nil {
+		return now.Add(config.APIKeyDefaultTTL()), nil
+	}
+
+	if !requested.After(now) {
+		return time.Time{}, errors.New("expires_at must be in the future")
+	}
+
+	if requested.After(now.Add(config.APIKeyMaxTTL())) {
+		return time.Time{}, fmt.Errorf("expires_at must be within %d days", int(config.APIKeyMaxTTL().Hours()/24))
+	}
+
+	return *requested, nil
+}
@@ -0,0 +1,298 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+// fakeAPIKeyRepo keeps keys in memory and applies the same active filter as
+// the api_keys query.
+type fakeAPIKeyRepo struct {
+	model.IAPIKeyRepository
+	keys map[int64]*model.APIKey
+}
+
+func (r *fakeAPIKeyRepo) Create(ctx context.Context, key model.APIKey) (*model.APIKey, error) {
+	key.ID = int64(len(r.keys) + 1)
+	r.keys[key.ID] = &key
+	return &key, nil
+}
+
+func (r *fakeAPIKeyRepo) FindByID(ctx context.Context, id int64) (*model.APIKey, error) {
+	if key, ok := r.keys[id]; ok {
+		return key, nil
+	}
+	return nil, errors.New("api key not found")
+}
+
+func (r *fakeAPIKeyRepo) FindActiveByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	for _, key := range r.keys {
+		if key.KeyHash == keyHash && key.RevokedAt == nil && key.ExpiresAt.After(time.Now()) {
+			return key, nil
+		}
+	}
+	return nil, errors.New("api key is invalid or expired")
+}
+
+func (r *fakeAPIKeyRepo) UpdateExpiry(ctx context.Context, id int64, expiresAt time.Time) error {
+	r.keys[id].ExpiresAt = expiresAt
+	return nil
+}
+
+func (r *fakeAPIKeyRepo) Revoke(ctx context.Context, id int64) error {
+	now := time.Now()
+	r.keys[id].RevokedAt = &now
+	return nil
+}
+
+func (r *fakeAPIKeyRepo) TouchLastUsed(ctx context.Context, id int64) error {
+	return nil
+}
+
+type serviceAccountFixture struct {
+	usecase model.IServiceAccountUsecase
+	users   *fakeSSOUserRepo
+	keys    *fakeAPIKeyRepo
+}
+
+func newServiceAccountFixture() *serviceAccountFixture {
+	f := &serviceAccountFixture{
+		users: &fakeSSOUserRepo{users: map[int64]*model.User{
+			10: {ID: 10, Name: "ERP", IsActive: true, IsServiceAccount: true, Role: model.Role{Name: "STAFF"}},
+			11: {ID: 11, Name: "Jane", IsActive: true},
+		}},
+		keys: &fakeAPIKeyRepo{keys: map[int64]*model.APIKey{}},
+	}
+
+	f.usecase = NewServiceAccountUsecase(f.users, nil, f.keys, &fakeAuditLogRepo{})
+
+	return f
+}
+
+func (f *serviceAccountFixture) createKey(t *testing.T, accountID int64) *model.CreatedAPIKey {
+	t.Helper()
+
+	created, err := f.usecase.CreateKey(context.Background(), 1, accountID, model.CreateAPIKeyInput{
+		Name:   "erp",
+		Scopes: []string{model.ScopeTicketsRead},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return created
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(f *serviceAccountFixture, key *model.CreatedAPIKey) string
+		wantErr bool
+	}{
+		{
+			name:    "active key",
+			prepare: func(f *serviceAccountFixture, key *model.CreatedAPIKey) string { return key.Key },
+		},
+		{
+			name: "expired key",
+			prepare: func(f *serviceAccountFixture, key *model.CreatedAPIKey) string {
+				f.keys.keys[key.ID].ExpiresAt = time.Now().Add(-time.Minute)
+				return key.Key
+			},
+			wantErr: true,
+		},
+		{
+			name: "revoked key",
+			prepare: func(f *serviceAccountFixture, key *model.CreatedAPIKey) string {
+				if err := f.usecase.RevokeKey(context.Background(), 1, key.ID); err != nil {
+					t.Fatal(err)
+				}
+				return key.Key
+			},
+			wantErr: true,
+		},
+		{
+			name:    "unknown key",
+			prepare: func(f *serviceAccountFixture, key *model.CreatedAPIKey) string { return model.APIKeyPrefix + "0000" },
+			wantErr: true,
+		},
+		{
+			name: "missing prefix",
+			prepare: func(f *serviceAccountFixture, key *model.CreatedAPIKey) string {
+				return key.Key[len(model.APIKeyPrefix):]
+			},
+			wantErr: true,
+		},
+		{
+			name: "disabled account",
+			prepare: func(f *serviceAccountFixture, key *model.CreatedAPIKey) string {
+				f.users.users[10].IsActive = false
+				return key.Key
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newServiceAccountFixture()
+			key := f.createKey(t, 10)
+
+			claims, err := f.usecase.Authenticate(context.Background(), tt.prepare(f, key))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("key was accepted")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if claims.UserID != 10 || claims.APIKeyID != key.ID || len(claims.Scopes) != 1 || claims.Scopes[0] != model.ScopeTicketsRead {
+				t.Fatalf("claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestCreateKeyOnlyForServiceAccounts(t *testing.T) {
+	f := newServiceAccountFixture()
+
+	_, err := f.usecase.CreateKey(context.Background(), 1, 11, model.CreateAPIKeyInput{
+		Name:   "personal",
+		Scopes: []string{model.ScopeTicketsRead},
+	})
+	if err == nil {
+		t.Fatal("api key was issued for a regular user")
+	}
+}
+
+func TestRotateKeyGracePeriod(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("old key works until the grace period ends", func(t *testing.T) {
+		f := newServiceAccountFixture()
+		old := f.createKey(t, 10)
+
+		replacement, err := f.usecase.RotateKey(ctx, 1, old.ID, model.RotateAPIKeyInput{GracePeriodHours: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, raw := range []string{old.Key, replacement.Key} {
+			if _, err := f.usecase.Authenticate(ctx, raw); err != nil {
+				t.Fatalf("key refused during the grace period: %v", err)
+			}
+		}
+
+		graceUntil := f.keys.keys[old.ID].ExpiresAt
+		if d := time.Until(graceUntil); d <= time.Hour || d > 2*time.Hour {
+			t.Fatalf("old key expires in %s, want the 2h grace period", d)
+		}
+
+		if replacement.Scopes[0] != model.ScopeTicketsRead || replacement.Name != old.Name {
+			t.Fatalf("replacement = %+v, want the old name and scopes", replacement.APIKey)
+		}
+
+		// The grace period has passed.
+		f.keys.keys[old.ID].ExpiresAt = time.Now().Add(-time.Second)
+
+		if _, err := f.usecase.Authenticate(ctx, old.Key); err == nil {
+			t.Fatal("old key still works after the grace period")
+		}
+
+		if _, err := f.usecase.Authenticate(ctx, replacement.Key); err != nil {
+			t.Fatalf("replacement refused: %v", err)
+		}
+	})
+
+	t.Run("no grace period revokes the old key", func(t *testing.T) {
+		f := newServiceAccountFixture()
+		old := f.createKey(t, 10)
+
+		if _, err := f.usecase.RotateKey(ctx, 1, old.ID, model.RotateAPIKeyInput{}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := f.usecase.Authenticate(ctx, old.Key); err == nil {
+			t.Fatal("old key still works after rotating without a grace period")
+		}
+	})
+
+	t.Run("grace period does not extend the old key", func(t *testing.T) {
+		f := newServiceAccountFixture()
+		old := f.createKey(t, 10)
+
+		expiresAt := time.Now().Add(30 * time.Minute)
+		f.keys.keys[old.ID].ExpiresAt = expiresAt
+
+		if _, err := f.usecase.RotateKey(ctx, 1, old.ID, model.RotateAPIKeyInput{GracePeriodHours: 24}); err != nil {
+			t.Fatal(err)
+		}
+
+		if !f.keys.keys[old.ID].ExpiresAt.Equal(expiresAt) {
+			t.Fatal("rotation pushed back the old key's expiry")
+		}
+	})
+
+	t.Run("revoked key cannot be rotated", func(t *testing.T) {
+		f := newServiceAccountFixture()
+		old := f.createKey(t, 10)
+
+		if err := f.usecase.RevokeKey(ctx, 1, old.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := f.usecase.RotateKey(ctx, 1, old.ID, model.RotateAPIKeyInput{}); err == nil {
+			t.Fatal("revoked key was rotated")
+		}
+	})
+}
+
+func TestResolveKeyExpiry(t *testing.T) {
+	at := func(d time.Duration) *time.Time {
+		t := time.Now().Add(d)
+		return &t
+	}
+
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name      string
+		requested *time.Time
+		want      time.Duration
+		wantErr   bool
+	}{
+		{"default", nil, 90 * day, false},
+		{"in the past", at(-time.Minute), 0, true},
+		{"now", at(0), 0, true},
+		{"within the maximum", at(364 * day), 364 * day, false},
+		{"beyond the maximum", at(366 * day), 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveKeyExpiry(tt.requested)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expiry %s was accepted", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if d := time.Until(got); d < tt.want-time.Minute || d > tt.want {
+				t.Fatalf("expires in %s, want %s", d, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+// rolePriority decides which role wins when a user's groups map to several.
+var rolePriority = []string{
+	"ADMINISTRATOR",
+	"STAFF",
+	"USER",
+}
+
+type SSOUsecase struct {
+	userRepo          model.IUserRepository
+	redirectProviders map[string]model.IRedirectAuthProvider
+	passwordProviders map[string]model.IPasswordAuthProvider
+	provisioner       *userProvisioner
+}
+
+func NewSSOUsecase(
+	userRepo model.IUserRepository,
+	roleRepo model.IRoleRepository,
+	identityRepo model.IUserIdentityRepository,
+	redirectProviders map[string]model.IRedirectAuthProvider,
+	passwordProviders map[string]model.IPasswordAuthProvider,
+) model.ISSOUsecase {
+	return &SSOUsecase{
+		userRepo:          userRepo,
+		redirectProviders: redirectProviders,
+		passwordProviders: passwordProviders,
+		provisioner:       newUserProvisioner(userRepo, roleRepo, identityRepo),
+	}
+}
+
+func (u *SSOUsecase) Providers() []string {
+	providers := []string{model.AuthProviderLocal}
+
+	for name := range u.passwordProviders {
+		providers = append(providers, name)
+	}
+
+	for name := range u.redirectProviders {
+		providers = append(providers, name)
+	}
+
+	sort.Strings(providers[1:])
+
+	return providers
+}
+
+func (u *SSOUsecase) StartLogin(ctx context.Context, provider string) (string, error) {
+	p, ok := u.redirectProviders[provider]
+	if !ok {
+		return "", errors.New("login provider is not available")
+	}
+
+	return p.AuthCodeURL(ctx)
+}
+
+func (u *SSOUsecase) CompleteLogin(ctx context.Context, provider string, state string, code string) (*model.LoginResponse, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"provider": provider,
+	})
+
+	p, ok := u.redirectProviders[provider]
+	if !ok {
+		return nil, errors.New("login provider is not available")
+	}
+
+	identity, err := p.Exchange(ctx, state, code)
+	if err != nil {
+		log.Warn("external authentication failed:", err)
+		return nil, err
+	}
+
+	user, err := u.provisioner.Provision(ctx, *identity)
+	if err != nil {
+		log.Error("failed provision external user:", err)
+		return nil, err
+	}
+
+	if user.LockedAt != nil {
+		return nil, errors.New("account is locked, please contact administrator")
+	}
+
+	if !user.IsActive {
+		return nil, errors.New("account is not active")
+	}
+
+	return issueLoginResponse(ctx, u.userRepo, user)
+}
+
+type userProvisioner struct {
+	userRepo     model.IUserRepository
+	roleRepo     model.IRoleRepository
+	identityRepo model.IUserIdentityRepository
+}
+
+func newUserProvisioner(
+	userRepo model.IUserRepository,
+	roleRepo model.IRoleRepository,
+	identityRepo model.IUserIdentityRepository,
+) *userProvisioner {
+	return &userProvisioner{
+		userRepo:     userRepo,
+		roleRepo:     roleRepo,
+		identityRepo: identityRepo,
+	}
+}
+
+// Provision returns the local user for an external identity, creating it on
+// first login and syncing role and projects from the directory groups. An
+// identity is only matched to an existing account by email when linking
+// is enabled and the account has no password of its own.
+func (p *userProvisioner) Provision(ctx context.Context, identity model.ExternalIdentity) (*model.User, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"provider": identity.Provider,
+		"subject":  identity.Subject,
+		"email":    identity.Email,
+	})
+
+	roleName, projectIDs := mapGroups(identity.Groups)
+
+	var user *model.User
+
+	if link, err := p.identityRepo.FindByProviderSubject(ctx, identity.Provider, identity.Subject); err == nil {
+		user, err = p.userRepo.FindByID(ctx, link.UserID)
+		if err != nil {
+			return nil, err
+		}
+	} else if existing, err := p.userRepo.FindByEmail(ctx, identity.Email); err == nil {
+		if !config.SSOLinkExistingAccounts() || existing.Password != "" {
+			log.Warn("refused to link external identity to existing account")
+			return nil, model.ErrIdentityAccountExists
+		}
+
+		user = existing
+
+		if err := p.link(ctx, user.ID, identity); err != nil {
+			return nil, err
+		}
+	}
+
+	if user == nil {
+		if roleName == "" {
+			roleName = config.SSODefaultRole()
+		}
+
+		role, err := p.roleRepo.FindByName(ctx, roleName)
+		if err != nil {
+			return nil, err
+		}
+
+		name := identity.Name
+		if name == "" {
+			name = identity.Email
+		}
+
+		var projects []model.Project
+		for _, id := range projectIDs {
+			projects = append(projects, model.Project{ID: id})
+		}
+
+		created, err := p.userRepo.Create(ctx, model.User{
+			Name:     name,
+			Email:    identity.Email,
+			RoleID:   role.ID,
+			IsActive: true,
+			Projects: projects,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.link(ctx, created.ID, identity); err != nil {
+			return nil, err
+		}
+
+		log.Info("provisioned new user from external identity")
+
+		return p.userRepo.FindByID(ctx, created.ID)
+	}
+
+	changed := false
+
+	if roleName != "" && roleName != user.Role.Name {
+		role, err := p.roleRepo.FindByName(ctx, roleName)
+		if err != nil {
+			return nil, err
+		}
+
+		user.RoleID = role.ID
+		changed = true
+	}
+
+	if len(config.SSOGroupProjects()) > 0 {
+		var projects []model.Project
+		for _, id := range projectIDs {
+			projects = append(projects, model.Project{ID: id})
+		}
+
+		user.Projects = projects
+		changed = true
+	}
+
+	if !changed {
+		return user, nil
+	}
+
+	if err := p.userRepo.Update(ctx, *user); err != nil {
+		return nil, err
+	}
+
+	return p.userRepo.FindByID(ctx, user.ID)
+}
+
+func (p *userProvisioner) link(ctx context.Context, userID int64, identity model.ExternalIdentity) error {
+	return p.identityRepo.Create(ctx, model.UserIdentity{
+		UserID:   userID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+	})
+}
+
+func mapGroups(groups []string) (string, []int64) {
+	groupRoles := config.SSOGroupRoles()
+	groupProjects := config.SSOGroupProjects()
+
+	roles := map[string]bool{}
+	seen := map[int64]bool{}
+
+	var projectIDs []int64
+
+	for _, group := range groups {
+		key := strings.ToLower(group)
+
+		if role, ok := groupRoles[key]; ok {
+			roles[strings.ToUpper(role)] = true
+		}
+
+		if value, ok := groupProjects[key]; ok {
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err == nil && !seen[id] {
+				seen[id] = true
+				projectIDs = append(projectIDs, id)
+			}
+		}
+	}
+
+	for _, role := range rolePriority {
+		if roles[role] {
+			return role, projectIDs
+		}
+	}
+
+	return "", projectIDs
+}
@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type fakeSSOUserRepo struct {
+	model.IUserRepository
+
+	users  map[int64]*model.User
+	nextID int64
+}
+
+func (r *fakeSSOUserRepo) FindByID(ctx context.Context, id int64) (*model.User, error) {
+	if user, ok := r.users[id]; ok {
+		return user, nil
+	}
+
+	return nil, model.ErrUserNotFound
+}
+
+func (r *fakeSSOUserRepo) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+
+	return nil, model.ErrUserNotFound
+}
+
+func (r *fakeSSOUserRepo) Create(ctx context.Context, user model.User) (*model.User, error) {
+	r.nextID++
+	user.ID = r.nextID
+	r.users[user.ID] = &user
+
+	return &user, nil
+}
+
+type fakeSSORoleRepo struct {
+	model.IRoleRepository
+}
+
+func (r *fakeSSORoleRepo) FindByName(ctx context.Context, name string) (*model.Role, error) {
+	return &model.Role{ID: 3, Name: name}, nil
+}
+
+type fakeIdentityRepo struct {
+	links []model.UserIdentity
+}
+
+func (r *fakeIdentityRepo) FindByProviderSubject(ctx context.Context, provider string, subject string) (*model.UserIdentity, error) {
+	for _, link := range r.links {
+		if link.Provider == provider && link.Subject == subject {
+			return &link, nil
+		}
+	}
+
+	return nil, errors.New("identity not found")
+}
+
+func (r *fakeIdentityRepo) Create(ctx context.Context, identity model.UserIdentity) error {
+	r.links = append(r.links, identity)
+	return nil
+}
+
+func newTestProvisioner(users ...*model.User) (*userProvisioner, *fakeSSOUserRepo, *fakeIdentityRepo) {
+	userRepo := &fakeSSOUserRepo{users: map[int64]*model.User{}, nextID: 100}
+	for _, user := range users {
+		userRepo.users[user.ID] = user
+	}
+
+	identityRepo := &fakeIdentityRepo{}
+
+	return newUserProvisioner(userRepo, &fakeSSORoleRepo{}, identityRepo), userRepo, identityRepo
+}
+
+func setLinkExistingAccounts(t *testing.T, enabled bool) {
+	t.Helper()
+
+	viper.Set("sso.link_existing_accounts", enabled)
+	t.Cleanup(func() { viper.Set("sso.link_existing_accounts", nil) })
+}
+
+var testIdentity = model.ExternalIdentity{
+	Provider: model.AuthProviderOIDC,
+	Subject:  "user-1",
+	Email:    "jane@example.com",
+	Name:     "Jane",
+}
+
+func TestProvisionCreatesAndLinksNewUser(t *testing.T) {
+	provisioner, _, identities := newTestProvisioner()
+
+	user, err := provisioner.Provision(context.Background(), testIdentity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Email != testIdentity.Email || !user.IsActive {
+		t.Fatalf("user = %+v", user)
+	}
+
+	if len(identities.links) != 1 || identities.links[0].UserID != user.ID {
+		t.Fatalf("links = %+v", identities.links)
+	}
+
+	again, err := provisioner.Provision(context.Background(), testIdentity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if again.ID != user.ID || len(identities.links) != 1 {
+		t.Fatal("second login did not reuse the linked account")
+	}
+}
+
+func TestProvisionDoesNotLinkByDefault(t *testing.T) {
+	provisioner, _, identities := newTestProvisioner(&model.User{ID: 1, Email: testIdentity.Email})
+
+	_, err := provisioner.Provision(context.Background(), testIdentity)
+	if !errors.Is(err, model.ErrIdentityAccountExists) {
+		t.Fatalf("err = %v, want ErrIdentityAccountExists", err)
+	}
+
+	if len(identities.links) != 0 {
+		t.Fatal("identity was linked")
+	}
+}
+
+func TestProvisionNeverLinksPasswordAccount(t *testing.T) {
+	setLinkExistingAccounts(t, true)
+
+	provisioner, _, identities := newTestProvisioner(&model.User{ID: 1, Email: testIdentity.Email, Password: "hash"})
+
+	_, err := provisioner.Provision(context.Background(), testIdentity)
+	if !errors.Is(err, model.ErrIdentityAccountExists) {
+		t.Fatalf("err = %v, want ErrIdentityAccountExists", err)
+	}
+
+	if len(identities.links) != 0 {
+		t.Fatal("identity was linked")
+	}
+}
+
+func TestProvisionLinksPasswordlessAccountWhenEnabled(t *testing.T) {
+	setLinkExistingAccounts(t, true)
+
+	provisioner, _, identities := newTestProvisioner(&model.User{ID: 1, Email: testIdentity.Email, IsActive: true})
+
+	user, err := provisioner.Provision(context.Background(), testIdentity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.ID != 1 {
+		t.Fatalf("user.ID = %d, want 1", user.ID)
+	}
+
+	if len(identities.links) != 1 || identities.links[0].UserID != 1 {
+		t.Fatalf("links = %+v", identities.links)
+	}
+}
+
+func TestMapGroups(t *testing.T) {
+	viper.Set("sso.group_roles", map[string]string{
+		"helpdesk-admins": "administrator",
+		"helpdesk-staff":  "STAFF",
+	})
+	viper.Set("sso.group_projects", map[string]string{
+		"finance":   "7",
+		"warehouse": "not-a-number",
+	})
+	t.Cleanup(func() {
+		viper.Set("sso.group_roles", nil)
+		viper.Set("sso.group_projects", nil)
+	})
+
+	tests := []struct {
+		name         string
+		groups       []string
+		wantRole     string
+		wantProjects []int64
+	}{
+		{"no groups", nil, "", nil},
+		{"unmapped group", []string{"everyone"}, "", nil},
+		{"group names are case-insensitive", []string{"Helpdesk-Staff"}, "STAFF", nil},
+		{"highest role wins", []string{"helpdesk-staff", "Helpdesk-Admins"}, "ADMINISTRATOR", nil},
+		{"projects are collected once", []string{"finance", "Finance", "warehouse"}, "", []int64{7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role, projects := mapGroups(tt.groups)
+
+			if role != tt.wantRole {
+				t.Fatalf("role = %q, want %q", role, tt.wantRole)
+			}
+
+			if len(projects) != len(tt.wantProjects) || (len(projects) > 0 && projects[0] != tt.wantProjects[0]) {
+				t.Fatalf("projects = %v, want %v", projects, tt.wantProjects)
+			}
+		})
+	}
+}
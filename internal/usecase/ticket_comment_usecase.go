@@ -9,25 +9,30 @@ import (
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/websocket"
 
 	ws "github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/websocket"
+	"gorm.io/gorm"
 )
 
 type TicketCommentUsecase struct {
+	db                *gorm.DB
 	repo              model.ITicketCommentRepository
 	ticketHistoryRepo model.ITicketHistoryRepository
 	ticketRepo        model.ITicketRepository
+	attachmentUsecase model.IAttachmentUsecase
 	wsHub             *ws.Hub
 }
 
-func NewTicketCommentUsecase(repo model.ITicketCommentRepository, ticketHistoryRepo model.ITicketHistoryRepository, ticketRepo model.ITicketRepository, wsHub *ws.Hub) model.ITicketCommentUsecase {
+func NewTicketCommentUsecase(db *gorm.DB, repo model.ITicketCommentRepository, ticketHistoryRepo model.ITicketHistoryRepository, ticketRepo model.ITicketRepository, attachmentUsecase model.IAttachmentUsecase, wsHub *ws.Hub) model.ITicketCommentUsecase {
 	return &TicketCommentUsecase{
+		db:                db,
 		repo:              repo,
 		ticketHistoryRepo: ticketHistoryRepo,
 		ticketRepo:        ticketRepo,
+		attachmentUsecase: attachmentUsecase,
 		wsHub:             wsHub,
 	}
 }
 
-func (u *TicketCommentUsecase) Create(ctx context.Context, comment model.TicketComment) (*model.TicketComment, error) {
+func (u *TicketCommentUsecase) Create(ctx context.Context, comment model.TicketComment, files []model.AttachmentUpload) (*model.TicketComment, error) {
 	log := logrus.WithFields(logrus.Fields{
 		"comment": comment,
 	})
@@ -48,23 +53,62 @@ func (u *TicketCommentUsecase) Create(ctx context.Context, comment model.TicketC
 		comment.IsReadByAdministrator = true
 	}
 
-	result, err := u.repo.Create(ctx, comment)
+	owner := model.AttachmentOwner{
+		Type:      model.AttachmentOwnerComment,
+		TicketID:  comment.TicketID,
+		ProjectID: ticket.ProjectID,
+	}
+
+	attachments, err := u.attachmentUsecase.Prepare(ctx, comment.UserID, owner, files)
 	if err != nil {
+		log.Error("Failed to attach comment files: ", err)
+		return nil, err
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			u.attachmentUsecase.Discard(ctx, attachments)
+		}
+	}()
+
+	tx := u.db.WithContext(ctx).Begin()
+
+	result, err := u.repo.Create(ctx, tx, comment)
+	if err != nil {
+		tx.Rollback()
 		log.Error("Failed to create ticket comment: ", err)
 		return nil, err
 	}
 
+	owner.ID = result.ID
+
+	if err := u.attachmentUsecase.Save(ctx, tx, owner, attachments); err != nil {
+		tx.Rollback()
+		log.Error("Failed to attach comment files: ", err)
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		log.Error("Failed to commit ticket comment: ", err)
+		return nil, err
+	}
+
+	committed = true
+	result.Attachments = attachments
+
 	ws.BroadcastToRoles(
 		u.wsHub,
 		[]string{"ADMINISTRATOR", "STAFF", "USER"},
 		websocket.Message{
 			Type: "NEW_COMMENT",
 			Data: map[string]interface{}{
-				"id":         result.ID,
-				"ticket_id":  result.TicketID,
-				"user_name":  result.User.Name,
-				"message":    result.Message,
-				"created_at": result.CreatedAt,
+				"id":          result.ID,
+				"ticket_id":   result.TicketID,
+				"user_name":   result.User.Name,
+				"message":     result.Message,
+				"attachments": result.Attachments,
+				"created_at":  result.CreatedAt,
 			},
 		})
 
@@ -97,7 +141,27 @@ func (u *TicketCommentUsecase) Create(ctx context.Context, comment model.TicketC
 		}
 	}
 
-	var receiverID int64
+	messagePreview := comment.Message
+
+	if len(messagePreview) > 80 {
+		messagePreview = messagePreview[:80] + "..."
+	}
+
+	event := model.NotificationEvent{
+		EventType:     "TICKET_COMMENT",
+		ActorID:       comment.UserID,
+		TicketID:      ticket.ID,
+		TicketCode:    ticket.TicketCode,
+		Priority:      string(ticket.Priority),
+		ReferenceType: "COMMENT",
+		ReferenceID:   result.ID,
+		Title:         "Komentar Baru",
+		Message:       "Komentar pada tiket " + ticket.TicketCode + ": " + messagePreview,
+	}
+
+	if err := helper.PublishLifecycleEvent(event); err != nil {
+		log.Error("Failed publish lifecycle event:", err)
+	}
 
 	if comment.UserID == ticket.ReporterID {
 
@@ -106,34 +170,13 @@ func (u *TicketCommentUsecase) Create(ctx context.Context, comment model.TicketC
 			return result, nil
 		}
 
-		receiverID = *ticket.AssignedToID
+		event.UserID = *ticket.AssignedToID
 
 	} else {
-		receiverID = ticket.ReporterID
-	}
-
-	messagePreview := comment.Message
-
-	if len(messagePreview) > 80 {
-		messagePreview = messagePreview[:80] + "..."
+		event.UserID = ticket.ReporterID
 	}
 
-	err = helper.PublishNotificationEvent(
-		"ticket.comment",
-		model.NotificationEvent{
-			EventType:     "TICKET_COMMENT",
-			UserID:        receiverID,
-			ActorID:       comment.UserID,
-			TicketID:      ticket.ID,
-			TicketCode:    ticket.TicketCode,
-			ReferenceType: "COMMENT",
-			ReferenceID:   result.ID,
-			Title:         "Komentar Baru",
-			Message:       "Komentar pada tiket " + ticket.TicketCode + ": " + messagePreview,
-		},
-	)
-
-	if err != nil {
+	if err := helper.PublishNotificationEvent("ticket.comment", event); err != nil {
 		log.Error("Failed publish notification:", err)
 	}
 
@@ -151,6 +194,21 @@ func (u *TicketCommentUsecase) FindByTicketID(ctx context.Context, ticketID int6
 		return nil, err
 	}
 
+	ids := make([]int64, 0, len(comment))
+	for _, c := range comment {
+		ids = append(ids, c.ID)
+	}
+
+	attachments, err := u.attachmentUsecase.FindByOwners(ctx, model.AttachmentOwnerComment, ids)
+	if err != nil {
+		log.Error("Failed to find comment attachments: ", err)
+		return nil, err
+	}
+
+	for _, c := range comment {
+		c.Attachments = attachments[c.ID]
+	}
+
 	return comment, nil
 }
 
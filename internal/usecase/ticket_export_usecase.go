@@ -0,0 +1,317 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/exporter"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/storage"
+	"gorm.io/gorm"
+)
+
+type TicketExportUsecase struct {
+	ticketUsecase  model.ITicketUsecase
+	ticketRepo     model.ITicketRepository
+	historyRepo    model.ITicketHistoryRepository
+	commentRepo    model.ITicketCommentRepository
+	resolutionRepo model.ITicketResolutionRepository
+	signatureRepo  model.IResolutionSignatureRepository
+	attachmentRepo model.IAttachmentRepository
+	store          model.IStorage
+}
+
+func NewTicketExportUsecase(
+	ticketUsecase model.ITicketUsecase,
+	ticketRepo model.ITicketRepository,
+	historyRepo model.ITicketHistoryRepository,
+	commentRepo model.ITicketCommentRepository,
+	resolutionRepo model.ITicketResolutionRepository,
+	signatureRepo model.IResolutionSignatureRepository,
+	attachmentRepo model.IAttachmentRepository,
+	store model.IStorage,
+) model.ITicketExportUsecase {
+	return &TicketExportUsecase{
+		ticketUsecase:  ticketUsecase,
+		ticketRepo:     ticketRepo,
+		historyRepo:    historyRepo,
+		commentRepo:    commentRepo,
+		resolutionRepo: resolutionRepo,
+		signatureRepo:  signatureRepo,
+		attachmentRepo: attachmentRepo,
+		store:          store,
+	}
+}
+
+// Export goes through the same FindAll as the ticket list, so the role
+// scoping matches what the user sees on screen. Progress runs from loading
+// the tickets (5%) through their details (40%) and files (90%) to writing
+// the file (95%); the caller reports completion.
+func (u *TicketExportUsecase) Export(
+	ctx context.Context,
+	userID int64,
+	role string,
+	format string,
+	filter model.ExportFilter,
+	w io.Writer,
+	onProgress func(percent int),
+) (*model.TicketExportFile, error) {
+	if format == "" {
+		format = "xlsx"
+	}
+
+	exp, err := exporter.New(format)
+	if err != nil {
+		return nil, err
+	}
+
+	if onProgress == nil {
+		onProgress = func(int) {}
+	}
+
+	maxRows := config.ExportMaxRows()
+	if exp.NeedsDetails() {
+		maxRows = config.ExportDetailMaxRows()
+	}
+
+	tickets, _, err := u.ticketUsecase.FindAll(
+		ctx,
+		filter.Ticket(),
+		filter.Search,
+		filter.StartDate,
+		filter.EndDate,
+		1,
+		maxRows,
+		role,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("load tickets: %w", err)
+	}
+
+	onProgress(5)
+
+	data := &exporter.Data{
+		Filter:      filter,
+		Tickets:     tickets,
+		URL:         storage.URL,
+		GeneratedAt: time.Now(),
+	}
+
+	if exp.NeedsDetails() {
+		data.Details = make(map[int64]*model.TicketDetail, len(tickets))
+
+		for i, ticket := range tickets {
+			detail, err := u.loadDetail(ctx, ticket)
+			if err != nil {
+				return nil, fmt.Errorf("load ticket %s: %w", ticket.TicketCode, err)
+			}
+
+			data.Details[ticket.ID] = detail
+			onProgress(5 + 35*(i+1)/len(tickets))
+		}
+	}
+
+	onProgress(40)
+
+	data.File = helper.PrefetchFiles(
+		ctx,
+		exp.Files(data),
+		config.ExportImageConcurrency(),
+		u.loadFile,
+		func(done int, total int) {
+			onProgress(40 + 50*done/total)
+		},
+	)
+
+	onProgress(90)
+
+	if err := exp.Write(w, data); err != nil {
+		return nil, fmt.Errorf("write %s: %w", format, err)
+	}
+
+	onProgress(95)
+
+	return &model.TicketExportFile{
+		Filename:    fmt.Sprintf("ticket_export_%s%s", data.GeneratedAt.Format("2006-01-02_150405"), exp.Extension()),
+		ContentType: exp.ContentType(),
+		Rows:        len(tickets),
+	}, nil
+}
+
+// ServiceReport is open to the same people as the ticket's files: the
+// reporter, the assignee and administrators.
+func (u *TicketExportUsecase) ServiceReport(ctx context.Context, ticketID int64, userID int64, role string, w io.Writer) (*model.TicketExportFile, error) {
+	ticket, err := u.ticketRepo.FindByID(ctx, ticketID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.ErrServiceReportNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	isAssignee := ticket.AssignedToID != nil && *ticket.AssignedToID == userID
+
+	if role != "ADMINISTRATOR" && ticket.ReporterID != userID && !isAssignee {
+		return nil, model.ErrServiceReportForbidden
+	}
+
+	response, err := u.ticketRepo.FindResponseByID(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail, err := u.loadDetail(ctx, response)
+	if err != nil {
+		return nil, err
+	}
+
+	if detail.Resolution == nil {
+		return nil, model.ErrServiceReportUnresolved
+	}
+
+	report := &exporter.ServiceReport{
+		Detail: detail,
+		Signatures: []exporter.Signature{
+			reportSignature("Technician", response.AssignedToName, detail.Resolution, model.SignerTechnician),
+			reportSignature("Reporter", response.ReporterName, detail.Resolution, model.SignerReporter),
+		},
+		CompanyName:    config.ReportCompanyName(),
+		CompanyAddress: config.ReportCompanyAddress(),
+		Logo:           loadReportLogo(),
+		GeneratedAt:    time.Now(),
+	}
+
+	report.File = helper.PrefetchFiles(ctx, exporter.ServiceReportFiles(report), config.ExportImageConcurrency(), u.loadFile, nil)
+
+	if err := exporter.WriteServiceReport(w, report); err != nil {
+		return nil, fmt.Errorf("write service report: %w", err)
+	}
+
+	return &model.TicketExportFile{
+		Filename:    fmt.Sprintf("service_report_%s.pdf", response.TicketCode),
+		ContentType: "application/pdf",
+		Rows:        1,
+	}, nil
+}
+
+// loadDetail reads the ticket's files in one query and hands them out to
+// the ticket, its comments and its resolution, which also gets its
+// signatures.
+func (u *TicketExportUsecase) loadDetail(ctx context.Context, ticket *model.TicketResponse) (*model.TicketDetail, error) {
+	histories, err := u.historyRepo.FindByTicketID(ctx, ticket.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := u.commentRepo.FindByTicketID(ctx, ticket.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resolution, err := u.resolutionRepo.FindByTicketID(ctx, ticket.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		resolution, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := u.attachmentRepo.FindByTicketID(ctx, ticket.ID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if resolution != nil {
+		signatures, err := u.signatureRepo.FindByTicketIDs(ctx, []int64{ticket.ID})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, signature := range signatures {
+			if signature.ResolutionID == resolution.ID {
+				resolution.Signatures = append(resolution.Signatures, signature)
+			}
+		}
+	}
+
+	detail := &model.TicketDetail{
+		Ticket:     ticket,
+		Histories:  histories,
+		Comments:   comments,
+		Resolution: resolution,
+	}
+
+	commentByID := make(map[int64]*model.TicketCommentResponse, len(comments))
+	for _, comment := range comments {
+		commentByID[comment.ID] = comment
+	}
+
+	for _, attachment := range attachments {
+		switch attachment.OwnerType {
+		case model.AttachmentOwnerTicket:
+			detail.Attachments = append(detail.Attachments, attachment)
+
+		case model.AttachmentOwnerComment:
+			if comment, ok := commentByID[attachment.OwnerID]; ok {
+				comment.Attachments = append(comment.Attachments, attachment)
+			}
+
+		case model.AttachmentOwnerResolution:
+			if resolution != nil && resolution.ID == attachment.OwnerID {
+				resolution.Attachments = append(resolution.Attachments, attachment)
+			}
+		}
+	}
+
+	return detail, nil
+}
+
+func (u *TicketExportUsecase) loadFile(ctx context.Context, ref string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.ExportImageTimeout())
+	defer cancel()
+
+	return storage.ReadAll(ctx, u.store, ref)
+}
+
+// reportSignature fills a signature block from the signature the party
+// captured on the resolution, falling back to a blank block with the name
+// on the ticket.
+func reportSignature(title string, name string, resolution *model.TicketResolution, role model.SignerRole) exporter.Signature {
+	signature := exporter.Signature{Title: title, Name: name}
+
+	for _, captured := range resolution.Signatures {
+		if captured.SignerRole == role {
+			signature.Name = captured.SignerName
+			signature.Image = captured.StorageKey
+			signature.SignedAt = &captured.SignedAt
+		}
+	}
+
+	return signature
+}
+
+// loadReportLogo reads the configured logo, printing the report without
+// one if it cannot be read.
+func loadReportLogo() []byte {
+	path := config.ReportLogoPath()
+	if path == "" {
+		return nil
+	}
+
+	logo, err := os.ReadFile(path)
+	if err != nil {
+		logrus.Warn("failed read report logo:", err)
+		return nil
+	}
+
+	return logo
+}
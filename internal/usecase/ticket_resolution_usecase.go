@@ -1,39 +1,61 @@
 package usecase
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"image"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/imaging"
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/storage"
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/websocket"
 	ws "github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/websocket"
 	"gorm.io/gorm"
 )
 
+// maxSignaturePixels is far above any signature pad, but stops a crafted
+// PNG from expanding into a huge image when decoded.
+const maxSignaturePixels = 4096 * 4096
+
 type TicketResolutionUsecase struct {
-	db             *gorm.DB
-	resolutionRepo model.ITicketResolutionRepository
-	historyRepo    model.ITicketHistoryRepository
-	ticketRepo     model.ITicketRepository
-	wsHub          *ws.Hub
+	db                *gorm.DB
+	resolutionRepo    model.ITicketResolutionRepository
+	signatureRepo     model.IResolutionSignatureRepository
+	historyRepo       model.ITicketHistoryRepository
+	ticketRepo        model.ITicketRepository
+	attachmentUsecase model.IAttachmentUsecase
+	store             model.IStorage
+	wsHub             *ws.Hub
 }
 
 func NewTicketResolutionUsecase(
 	db *gorm.DB,
 	resolutionRepo model.ITicketResolutionRepository,
+	signatureRepo model.IResolutionSignatureRepository,
 	historyRepo model.ITicketHistoryRepository,
 	ticketRepo model.ITicketRepository,
+	attachmentUsecase model.IAttachmentUsecase,
+	store model.IStorage,
 	wsHub *ws.Hub,
 ) model.ITicketResolutionUsecase {
 	return &TicketResolutionUsecase{
-		db:             db,
-		resolutionRepo: resolutionRepo,
-		historyRepo:    historyRepo,
-		ticketRepo:     ticketRepo,
-		wsHub:          wsHub,
+		db:                db,
+		resolutionRepo:    resolutionRepo,
+		signatureRepo:     signatureRepo,
+		historyRepo:       historyRepo,
+		ticketRepo:        ticketRepo,
+		attachmentUsecase: attachmentUsecase,
+		store:             store,
+		wsHub:             wsHub,
 	}
 }
 
@@ -66,9 +88,33 @@ func (u *TicketResolutionUsecase) Create(ctx context.Context, userID int64, in m
 		SolutionID:      in.SolutionID,
 		ResolutionNotes: in.ResolutionNotes,
 		CompletionTime:  completionTime,
-		AttachmentURL:   in.AttachmentURL,
 	}
 
+	owner := model.AttachmentOwner{
+		Type:      model.AttachmentOwnerResolution,
+		TicketID:  ticket.ID,
+		ProjectID: ticket.ProjectID,
+	}
+
+	attachments, err := u.attachmentUsecase.Prepare(ctx, userID, owner, in.Attachments)
+	if err != nil {
+		return nil, err
+	}
+
+	signatures, err := u.storeSignatures(ctx, &ticket, userID, in)
+	if err != nil {
+		u.attachmentUsecase.Discard(ctx, attachments)
+		return nil, err
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			u.attachmentUsecase.Discard(ctx, attachments)
+			u.removeSignatures(ctx, signatures)
+		}
+	}()
+
 	tx := u.db.Begin()
 
 	createdResolution, err := u.resolutionRepo.Create(ctx, tx, resolution)
@@ -77,6 +123,27 @@ func (u *TicketResolutionUsecase) Create(ctx context.Context, userID int64, in m
 		return nil, err
 	}
 
+	owner.ID = createdResolution.ID
+
+	if err := u.attachmentUsecase.Save(ctx, tx, owner, attachments); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	createdResolution.Attachments = attachments
+
+	for _, signature := range signatures {
+		signature.ResolutionID = createdResolution.ID
+	}
+
+	if err := u.signatureRepo.Create(ctx, tx, signatures); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	createdResolution.Signatures = signatures
+	resolveSignatureURLs(signatures)
+
 	if err := tx.Model(&model.Ticket{}).
 		Where("id = ?", ticket.ID).
 		Updates(map[string]interface{}{
@@ -109,20 +176,26 @@ func (u *TicketResolutionUsecase) Create(ctx context.Context, userID int64, in m
 		return nil, err
 	}
 
-	helper.PublishNotificationEvent(
-		"ticket.resolution",
-		model.NotificationEvent{
-			EventType:     "TICKET_RESOLVED",
-			UserID:        ticket.ReporterID,
-			ActorID:       userID,
-			TicketID:      ticket.ID,
-			TicketCode:    ticket.TicketCode,
-			ReferenceType: "RESOLUTION",
-			ReferenceID:   resolution.ID,
-			Title:         "Ticket Resolved",
-			Message:       "Ticket " + ticket.TicketCode + " resolved",
-		},
-	)
+	committed = true
+
+	event := model.NotificationEvent{
+		EventType:     "TICKET_RESOLVED",
+		UserID:        ticket.ReporterID,
+		ActorID:       userID,
+		TicketID:      ticket.ID,
+		TicketCode:    ticket.TicketCode,
+		Priority:      string(ticket.Priority),
+		ReferenceType: "RESOLUTION",
+		ReferenceID:   resolution.ID,
+		Title:         "Ticket Resolved",
+		Message:       "Ticket " + ticket.TicketCode + " resolved",
+	}
+
+	helper.PublishNotificationEvent("ticket.resolution", event)
+
+	if err := helper.PublishLifecycleEvent(event); err != nil {
+		logrus.Error("failed publish lifecycle event:", err)
+	}
 
 	ticketResp, err := u.ticketRepo.FindResponseByID(ctx, ticket.ID)
 	if err != nil {
@@ -160,7 +233,30 @@ func (u *TicketResolutionUsecase) Create(ctx context.Context, userID int64, in m
 }
 
 func (u *TicketResolutionUsecase) FindByTicketID(ctx context.Context, ticketID int64) (*model.TicketResolution, error) {
-	return u.resolutionRepo.FindByTicketID(ctx, ticketID)
+	resolution, err := u.resolutionRepo.FindByTicketID(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	resolution.Attachments, err = u.attachmentUsecase.FindByOwner(ctx, model.AttachmentOwnerResolution, resolution.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	signatures, err := u.signatureRepo.FindByTicketIDs(ctx, []int64{ticketID})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, signature := range signatures {
+		if signature.ResolutionID == resolution.ID {
+			resolution.Signatures = append(resolution.Signatures, signature)
+		}
+	}
+
+	resolveSignatureURLs(resolution.Signatures)
+
+	return resolution, nil
 }
 
 func (u *TicketResolutionUsecase) UpdateStatus(ctx context.Context, ticketID int64, userID int64, in model.UpdateTicketStatusInput) error {
@@ -234,3 +330,118 @@ func (u *TicketResolutionUsecase) UpdateStatus(ctx context.Context, ticketID int
 
 	return nil
 }
+
+// storeSignatures checks and uploads the signatures sent with a resolution.
+// The technician is the user resolving the ticket; the reporter signs as
+// the ticket's reporter unless someone else's name is given, in which case
+// the signature is not linked to any user. Each image is re-encoded so only
+// the drawing is kept.
+func (u *TicketResolutionUsecase) storeSignatures(ctx context.Context, ticket *model.Ticket, userID int64, in model.CreateTicketResolutionInput) ([]*model.ResolutionSignature, error) {
+	type pendingSignature struct {
+		role     model.SignerRole
+		signerID *int64
+		name     string
+		image    []byte
+		cfg      image.Config
+	}
+
+	var pending []pendingSignature
+
+	for _, input := range []struct {
+		role     model.SignerRole
+		signerID int64
+		in       *model.SignatureInput
+	}{
+		{model.SignerTechnician, userID, in.TechnicianSignature},
+		{model.SignerReporter, ticket.ReporterID, in.ReporterSignature},
+	} {
+		if input.in == nil || len(input.in.Image) == 0 {
+			continue
+		}
+
+		if int64(len(input.in.Image)) > config.AttachmentMaxSignatureSize() {
+			return nil, model.ErrSignatureTooLarge
+		}
+
+		cleaned, cfg, err := imaging.CleanPNG(input.in.Image, maxSignaturePixels)
+		if errors.Is(err, imaging.ErrTooManyPixels) {
+			return nil, model.ErrSignatureTooLarge
+		}
+
+		if err != nil {
+			return nil, model.ErrSignatureInvalid
+		}
+
+		var signer model.User
+		if err := u.db.WithContext(ctx).Select("id", "name").Where("id = ?", input.signerID).First(&signer).Error; err != nil {
+			return nil, err
+		}
+
+		name := signer.Name
+		signerID := &signer.ID
+
+		override := strings.TrimSpace(input.in.SignerName)
+		if input.role == model.SignerReporter && override != "" && !strings.EqualFold(override, signer.Name) {
+			name = override
+			signerID = nil
+		}
+
+		pending = append(pending, pendingSignature{
+			role:     input.role,
+			signerID: signerID,
+			name:     name,
+			image:    cleaned,
+			cfg:      cfg,
+		})
+	}
+
+	signedAt := time.Now()
+	signatures := make([]*model.ResolutionSignature, 0, len(pending))
+
+	for _, p := range pending {
+		key, err := storage.Upload(
+			ctx,
+			u.store,
+			fmt.Sprintf("tickets/%d/signatures", ticket.ID),
+			strings.ToLower(string(p.role))+".png",
+			bytes.NewReader(p.image),
+			int64(len(p.image)),
+			"image/png",
+		)
+		if err != nil {
+			u.removeSignatures(ctx, signatures)
+			return nil, err
+		}
+
+		checksum := sha256.Sum256(p.image)
+
+		signatures = append(signatures, &model.ResolutionSignature{
+			TicketID:   ticket.ID,
+			SignerRole: p.role,
+			SignerID:   p.signerID,
+			SignerName: p.name,
+			StorageKey: key,
+			Size:       int64(len(p.image)),
+			Checksum:   hex.EncodeToString(checksum[:]),
+			Width:      p.cfg.Width,
+			Height:     p.cfg.Height,
+			SignedAt:   signedAt,
+		})
+	}
+
+	return signatures, nil
+}
+
+func (u *TicketResolutionUsecase) removeSignatures(ctx context.Context, signatures []*model.ResolutionSignature) {
+	for _, signature := range signatures {
+		if err := storage.Remove(ctx, u.store, signature.StorageKey); err != nil {
+			logrus.Error("failed remove signature file:", err)
+		}
+	}
+}
+
+func resolveSignatureURLs(signatures []*model.ResolutionSignature) {
+	for _, signature := range signatures {
+		signature.URL = storage.URL(signature.StorageKey)
+	}
+}
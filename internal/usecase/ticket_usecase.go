@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
 	ws "github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/websocket"
 	"gorm.io/gorm"
@@ -18,6 +20,8 @@ type TicketUsecase struct {
 	ticketRepo        model.ITicketRepository
 	ticketHistoryRepo model.ITicketHistoryRepository
 	projectRepo       model.IProjectRepository
+	signatureRepo     model.IResolutionSignatureRepository
+	attachmentUsecase model.IAttachmentUsecase
 	db                *gorm.DB
 	hub               *ws.Hub
 }
@@ -27,6 +31,8 @@ func NewTicketUsecase(
 	ticketRepo model.ITicketRepository,
 	historyRepo model.ITicketHistoryRepository,
 	projectRepo model.IProjectRepository,
+	signatureRepo model.IResolutionSignatureRepository,
+	attachmentUsecase model.IAttachmentUsecase,
 	hub *ws.Hub,
 ) model.ITicketUsecase {
 	return &TicketUsecase{
@@ -34,6 +40,8 @@ func NewTicketUsecase(
 		ticketRepo:        ticketRepo,
 		ticketHistoryRepo: historyRepo,
 		projectRepo:       projectRepo,
+		signatureRepo:     signatureRepo,
+		attachmentUsecase: attachmentUsecase,
 		hub:               hub,
 	}
 }
@@ -59,10 +67,22 @@ func (u *TicketUsecase) FindByID(ctx context.Context, id int64) (*model.Ticket,
 		return nil, err
 	}
 
+	ticket.Attachments, err = u.attachmentUsecase.FindByOwner(ctx, model.AttachmentOwnerTicket, ticket.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket.Signatures, err = u.signatureRepo.FindByTicketIDs(ctx, []int64{ticket.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	resolveSignatureURLs(ticket.Signatures)
+
 	return ticket, nil
 }
 
-func (u *TicketUsecase) Create(ctx context.Context, reporterID int64, in model.CreateTicketInput, attachmentPath *string) (*model.Ticket, bool, error) {
+func (u *TicketUsecase) Create(ctx context.Context, reporterID int64, in model.CreateTicketInput, files []model.AttachmentUpload) (*model.Ticket, bool, error) {
 	if err := validate.Struct(in); err != nil {
 		return nil, false, err
 	}
@@ -101,7 +121,32 @@ func (u *TicketUsecase) Create(ctx context.Context, reporterID int64, in model.C
 
 	ticketCode := generateTicketCode(project.CodePrefix, int(seq))
 
+	ticketID, err := u.ticketRepo.NextID(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	owner := model.AttachmentOwner{
+		Type:      model.AttachmentOwnerTicket,
+		ID:        ticketID,
+		TicketID:  ticketID,
+		ProjectID: in.ProjectID,
+	}
+
+	attachments, err := u.attachmentUsecase.Prepare(ctx, reporterID, owner, files)
+	if err != nil {
+		return nil, false, err
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			u.attachmentUsecase.Discard(ctx, attachments)
+		}
+	}()
+
 	ticket := model.Ticket{
+		ID:           ticketID,
 		TicketCode:   ticketCode,
 		ProjectID:    in.ProjectID,
 		LocationID:   in.LocationID,
@@ -112,19 +157,34 @@ func (u *TicketUsecase) Create(ctx context.Context, reporterID int64, in model.C
 		Description:  in.Description,
 		DueAt:        dueAt,
 		Status:       model.StatusOpen,
-		Attachment:   attachmentPath,
 		AssignedToID: nil,
 	}
 
-	if err := u.db.WithContext(ctx).Create(&ticket).Error; err != nil {
+	tx := u.db.WithContext(ctx).Begin()
+
+	if err := tx.Create(&ticket).Error; err != nil {
+		tx.Rollback()
+		return nil, false, err
+	}
+
+	if err := u.attachmentUsecase.Save(ctx, tx, owner, attachments); err != nil {
+		tx.Rollback()
 		return nil, false, err
 	}
 
+	if err := tx.Commit().Error; err != nil {
+		return nil, false, err
+	}
+
+	committed = true
+
 	if err := u.db.WithContext(ctx).
 		First(&ticket, ticket.ID).Error; err != nil {
 		return nil, false, err
 	}
 
+	ticket.Attachments = attachments
+
 	history, err := u.ticketHistoryRepo.Create(
 		ctx,
 		model.TicketHistory{
@@ -188,6 +248,26 @@ func (u *TicketUsecase) Create(ctx context.Context, reporterID int64, in model.C
 		},
 	)
 
+	// Nobody is told about their own ticket; staff hear about it once the
+	// worker assigns it, so the creation only goes out as a lifecycle event.
+	err = helper.PublishLifecycleEvent(
+		model.NotificationEvent{
+			EventType:     string(model.NotificationTicketCreated),
+			ActorID:       reporterID,
+			TicketID:      ticket.ID,
+			TicketCode:    ticket.TicketCode,
+			Priority:      string(ticket.Priority),
+			ReferenceType: "TICKET",
+			ReferenceID:   ticket.ID,
+			Title:         "Tiket Dibuat",
+			Message:       "No Tiket: " + ticket.TicketCode,
+		},
+	)
+
+	if err != nil {
+		logrus.Error("failed publish notification:", err)
+	}
+
 	return &ticket, isAssigned, nil
 }
 
@@ -349,9 +429,159 @@ func (u *TicketUsecase) UpdateStatus(ctx context.Context, id int64, userID int64
 		},
 	)
 
+	publishStatusEvent(ticket, userID, oldStatus)
+
 	return nil
 }
 
+// publishStatusEvent publishes the status change as a lifecycle event and
+// tells the other side of the ticket about it: the reporter when staff
+// changed it, the assignee otherwise.
+func publishStatusEvent(ticket *model.Ticket, actorID int64, oldStatus model.TicketStatus) {
+	eventType := model.NotificationTicketUpdated
+	title := "Status Tiket Diperbarui"
+	if ticket.Status == model.StatusClosed {
+		eventType = model.NotificationTicketClosed
+		title = "Tiket Ditutup"
+	}
+
+	event := model.NotificationEvent{
+		EventType:     string(eventType),
+		ActorID:       actorID,
+		TicketID:      ticket.ID,
+		TicketCode:    ticket.TicketCode,
+		Priority:      string(ticket.Priority),
+		ReferenceType: "TICKET",
+		ReferenceID:   ticket.ID,
+		Title:         title,
+		Message:       "Status tiket " + ticket.TicketCode + ": " + string(oldStatus) + " -> " + string(ticket.Status),
+	}
+
+	if err := helper.PublishLifecycleEvent(event); err != nil {
+		logrus.Error("failed publish lifecycle event:", err)
+	}
+
+	event.UserID = ticket.ReporterID
+	if actorID == ticket.ReporterID {
+		if ticket.AssignedToID == nil {
+			return
+		}
+
+		event.UserID = *ticket.AssignedToID
+	}
+
+	if err := helper.PublishNotificationEvent("ticket.status", event); err != nil {
+		logrus.Error("failed publish notification:", err)
+	}
+}
+
+// Take records the assignment the same way the assignment worker does: the
+// ticket, the technician's last assignment time and the history row are
+// written together, then the change is broadcast and published.
+func (u *TicketUsecase) Take(ctx context.Context, id int64, userID int64) (bool, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"ticket_id": id,
+		"user_id":   userID,
+	})
+
+	now := time.Now()
+	assignee := strconv.FormatInt(userID, 10)
+
+	tx := u.db.WithContext(ctx).Begin()
+
+	result := tx.Model(&model.Ticket{}).
+		Where("id = ? AND assigned_to_id IS NULL AND deleted_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"assigned_to_id": userID,
+			"updated_at":     now,
+		})
+
+	if result.Error != nil {
+		tx.Rollback()
+		return false, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return false, nil
+	}
+
+	if err := tx.Model(&model.User{}).
+		Where("id = ?", userID).
+		Update("last_ticket_assigned_at", now).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	history := model.TicketHistory{
+		TicketID:  id,
+		UserID:    userID,
+		Action:    "ASSIGNED",
+		FieldName: "assigned_to_id",
+		NewValue:  &assignee,
+		CreatedAt: now,
+	}
+
+	if err := tx.Create(&history).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return false, err
+	}
+
+	histories, err := u.ticketHistoryRepo.FindByTicketID(ctx, id)
+	if err == nil && len(histories) > 0 {
+		latest := histories[0]
+		latest.Type = "ASSIGNED"
+
+		BroadcastTicketHistory(u.hub, latest)
+	}
+
+	ticketResp, err := u.ticketRepo.FindResponseByID(ctx, id)
+	if err != nil {
+		log.Error("failed fetch assigned ticket response:", err)
+		return true, nil
+	}
+
+	ws.BroadcastToRoles(
+		u.hub,
+		[]string{
+			"STAFF",
+			"ADMINISTRATOR",
+			"USER",
+		},
+		ws.Message{
+			Type: ws.EventTicketUpdated,
+			Data: ticketResp,
+		},
+	)
+
+	event := model.NotificationEvent{
+		EventType:     string(model.NotificationTicketAssigned),
+		UserID:        userID,
+		ActorID:       userID,
+		TicketID:      id,
+		TicketCode:    ticketResp.TicketCode,
+		Priority:      ticketResp.Priority,
+		ReferenceType: "TICKET",
+		ReferenceID:   id,
+		Title:         "Tiket Diambil",
+		Message:       "No Tiket: " + ticketResp.TicketCode + " | Pelapor: " + ticketResp.ReporterName,
+	}
+
+	if err := helper.PublishNotificationEvent("ticket.assigned", event); err != nil {
+		log.Error("failed publish notification:", err)
+	}
+
+	if err := helper.PublishLifecycleEvent(event); err != nil {
+		log.Error("failed publish lifecycle event:", err)
+	}
+
+	return true, nil
+}
+
 func (u *TicketUsecase) Delete(ctx context.Context, id int64) error {
 	log := logrus.WithFields(logrus.Fields{
 		"id": id,
@@ -0,0 +1,270 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+const recoveryCodeCount = 10
+
+type TwoFactorUsecase struct {
+	userRepo         model.IUserRepository
+	recoveryCodeRepo model.IUserRecoveryCodeRepository
+	auditLogRepo     model.IAuditLogRepository
+}
+
+func NewTwoFactorUsecase(
+	userRepo model.IUserRepository,
+	recoveryCodeRepo model.IUserRecoveryCodeRepository,
+	auditLogRepo model.IAuditLogRepository,
+) model.ITwoFactorUsecase {
+	return &TwoFactorUsecase{
+		userRepo:         userRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		auditLogRepo:     auditLogRepo,
+	}
+}
+
+func (u *TwoFactorUsecase) Enroll(ctx context.Context, userID int64) (*model.TwoFactorEnrollment, error) {
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabled {
+		return nil, errors.New("two factor authentication is already enabled")
+	}
+
+	secret, err := helper.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepo.UpdateTwoFactor(ctx, user.ID, &secret, false); err != nil {
+		logrus.WithField("user_id", user.ID).Error("failed store totp secret:", err)
+		return nil, err
+	}
+
+	return &model.TwoFactorEnrollment{
+		Secret:     secret,
+		OTPAuthURI: helper.TOTPProvisioningURI(config.TwoFactorIssuer(), user.Email, secret),
+	}, nil
+}
+
+func (u *TwoFactorUsecase) Verify(ctx context.Context, userID int64, in model.TwoFactorCodeInput) (*model.TwoFactorActivation, error) {
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabled {
+		return nil, errors.New("two factor authentication is already enabled")
+	}
+
+	if user.TwoFactorSecret == nil {
+		return nil, errors.New("two factor enrolment has not been started")
+	}
+
+	if !u.checkTOTP(ctx, user, in.Code) {
+		return nil, errors.New("invalid authentication code")
+	}
+
+	codes, err := u.issueRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepo.EnableTwoFactor(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	user.TwoFactorEnabled = true
+
+	token, err := helper.GenerateToken(*user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorActivation{
+		RecoveryCodes: codes,
+		Token:         token,
+	}, nil
+}
+
+func (u *TwoFactorUsecase) Disable(ctx context.Context, userID int64, in model.DisableTwoFactorInput) error {
+	if err := validate.Struct(in); err != nil {
+		return err
+	}
+
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.TwoFactorEnabled {
+		return errors.New("two factor authentication is not enabled")
+	}
+
+	if user.Role.RequireTwoFactor {
+		return errors.New("two factor authentication is required for your role")
+	}
+
+	if !helper.CheckPasswordHash(in.Password, user.Password) {
+		return errors.New("password is incorrect")
+	}
+
+	if !u.checkCode(ctx, user, in.Code) {
+		return errors.New("invalid authentication code")
+	}
+
+	return u.clearTwoFactor(ctx, user.ID)
+}
+
+func (u *TwoFactorUsecase) Reset(ctx context.Context, actorID int64, userID int64, ip string) error {
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := u.clearTwoFactor(ctx, user.ID); err != nil {
+		return err
+	}
+
+	if err := helper.RevokeUserSessions(ctx, user.ID); err != nil {
+		logrus.WithField("user_id", user.ID).Error("failed revoke sessions:", err)
+	}
+
+	err = u.auditLogRepo.Create(ctx, model.AuditLog{
+		ActorID:    &actorID,
+		Action:     model.AuditTwoFactorReset,
+		TargetType: model.AuditTargetUser,
+		TargetID:   user.ID,
+		IPAddress:  ip,
+	})
+	if err != nil {
+		logrus.WithField("user_id", user.ID).Error("failed write audit log:", err)
+	}
+
+	return nil
+}
+
+func (u *TwoFactorUsecase) CompleteLogin(ctx context.Context, in model.TwoFactorLoginInput) (string, error) {
+	if err := validate.Struct(in); err != nil {
+		return "", err
+	}
+
+	userID, err := helper.ResolveTwoFactorChallenge(ctx, in.ChallengeToken)
+	if err != nil {
+		return "", err
+	}
+
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	accountKey := strings.ToLower(user.Email)
+
+	if wait := helper.LoginBlockedFor(ctx, helper.LoginScopeAccount, accountKey); wait > 0 {
+		return "", fmt.Errorf("too many failed login attempts, try again in %d seconds", int(wait.Seconds())+1)
+	}
+
+	if user.LockedAt != nil {
+		helper.DeleteTwoFactorChallenge(ctx, in.ChallengeToken)
+		return "", errors.New("account is locked, please contact administrator")
+	}
+
+	// Wrong codes count toward the same lockout as wrong passwords, so
+	// starting a new challenge does not buy more guesses.
+	if !u.checkCode(ctx, user, in.Code) {
+		helper.RegisterTwoFactorFailure(ctx, in.ChallengeToken)
+		registerLoginFailure(ctx, u.userRepo, u.auditLogRepo, user, accountKey, in.IP)
+		return "", errors.New("invalid authentication code")
+	}
+
+	helper.DeleteTwoFactorChallenge(ctx, in.ChallengeToken)
+
+	if err := helper.ResetLoginFailures(ctx, helper.LoginScopeAccount, accountKey); err != nil {
+		logrus.WithField("user_id", user.ID).Error("failed reset login failures:", err)
+	}
+
+	if err := u.userRepo.UpdateOnlineStatus(ctx, user.ID, true); err != nil {
+		logrus.WithField("user_id", user.ID).Error("failed update online status:", err)
+	}
+
+	return helper.GenerateToken(*user)
+}
+
+// checkCode accepts either a current TOTP code or an unused recovery code.
+func (u *TwoFactorUsecase) checkCode(ctx context.Context, user *model.User, code string) bool {
+	if u.checkTOTP(ctx, user, code) {
+		return true
+	}
+
+	used, err := u.recoveryCodeRepo.Use(ctx, user.ID, helper.HashToken(code))
+	if err != nil {
+		logrus.WithField("user_id", user.ID).Error("failed check recovery code:", err)
+		return false
+	}
+
+	return used
+}
+
+// checkTOTP accepts a TOTP code only once: its time step has to be later
+// than the last one the user logged in with.
+func (u *TwoFactorUsecase) checkTOTP(ctx context.Context, user *model.User, code string) bool {
+	if user.TwoFactorSecret == nil {
+		return false
+	}
+
+	step, ok := helper.MatchTOTP(*user.TwoFactorSecret, code, time.Now())
+	if !ok {
+		return false
+	}
+
+	fresh, err := u.userRepo.UseTOTPStep(ctx, user.ID, step)
+	if err != nil {
+		logrus.WithField("user_id", user.ID).Error("failed record totp step:", err)
+		return false
+	}
+
+	return fresh
+}
+
+func (u *TwoFactorUsecase) issueRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	codes, err := helper.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, helper.HashToken(code))
+	}
+
+	if err := u.recoveryCodeRepo.Replace(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (u *TwoFactorUsecase) clearTwoFactor(ctx context.Context, userID int64) error {
+	if err := u.userRepo.UpdateTwoFactor(ctx, userID, nil, false); err != nil {
+		return err
+	}
+
+	return u.recoveryCodeRepo.DeleteByUserID(ctx, userID)
+}
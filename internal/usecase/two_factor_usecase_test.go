@@ -0,0 +1,246 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+// fakeTwoFactorUserRepo keeps the two factor columns of a single user the
+// way the users table does.
+type fakeTwoFactorUserRepo struct {
+	model.IUserRepository
+	user *model.User
+}
+
+func (r *fakeTwoFactorUserRepo) FindByID(ctx context.Context, id int64) (*model.User, error) {
+	if id != r.user.ID {
+		return nil, model.ErrUserNotFound
+	}
+
+	user := *r.user
+	return &user, nil
+}
+
+func (r *fakeTwoFactorUserRepo) UpdateTwoFactor(ctx context.Context, userID int64, secret *string, enabled bool) error {
+	r.user.TwoFactorSecret = secret
+	r.user.TwoFactorEnabled = enabled
+	r.user.TwoFactorLastStep = nil
+	return nil
+}
+
+func (r *fakeTwoFactorUserRepo) EnableTwoFactor(ctx context.Context, userID int64) error {
+	r.user.TwoFactorEnabled = true
+	return nil
+}
+
+func (r *fakeTwoFactorUserRepo) UseTOTPStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	if r.user.TwoFactorLastStep != nil && *r.user.TwoFactorLastStep >= step {
+		return false, nil
+	}
+
+	r.user.TwoFactorLastStep = &step
+	return true, nil
+}
+
+func (r *fakeTwoFactorUserRepo) Lock(ctx context.Context, userID int64) error {
+	now := time.Now()
+	r.user.LockedAt = &now
+	return nil
+}
+
+func (r *fakeTwoFactorUserRepo) UpdateOnlineStatus(ctx context.Context, userID int64, online bool) error {
+	return nil
+}
+
+type fakeRecoveryCodeRepo struct {
+	model.IUserRecoveryCodeRepository
+}
+
+func (fakeRecoveryCodeRepo) Replace(ctx context.Context, userID int64, hashes []string) error {
+	return nil
+}
+
+func (fakeRecoveryCodeRepo) Use(ctx context.Context, userID int64, hash string) (bool, error) {
+	return false, nil
+}
+
+type fakeAuditLogRepo struct {
+	model.IAuditLogRepository
+	logs []model.AuditLog
+}
+
+func (r *fakeAuditLogRepo) Create(ctx context.Context, log model.AuditLog) error {
+	r.logs = append(r.logs, log)
+	return nil
+}
+
+// startMiniredis points config.Rdb at an in-memory redis for the test.
+func startMiniredis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	previous := config.Rdb
+	config.Rdb = redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		config.Rdb.Close()
+		config.Rdb = previous
+	})
+
+	return mr
+}
+
+// totpCode computes the code an authenticator app shows for secret at the
+// given time.
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(at.Unix()/30))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+func newTwoFactorTest(t *testing.T) (*fakeTwoFactorUserRepo, *fakeAuditLogRepo, model.ITwoFactorUsecase) {
+	t.Helper()
+
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	userRepo := &fakeTwoFactorUserRepo{user: &model.User{ID: 7, Email: "Jane@example.com", IsActive: true}}
+	auditLogRepo := &fakeAuditLogRepo{}
+
+	return userRepo, auditLogRepo, NewTwoFactorUsecase(userRepo, fakeRecoveryCodeRepo{}, auditLogRepo)
+}
+
+func TestVerifiedCodeCannotBeReplayedToLogIn(t *testing.T) {
+	startMiniredis(t)
+
+	ctx := context.Background()
+	userRepo, _, uc := newTwoFactorTest(t)
+
+	enrollment, err := uc.Enroll(ctx, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code := totpCode(t, enrollment.Secret, time.Now())
+
+	if _, err := uc.Verify(ctx, 7, model.TwoFactorCodeInput{Code: code}); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	if !userRepo.user.TwoFactorEnabled || userRepo.user.TwoFactorLastStep == nil {
+		t.Fatalf("enable lost the replay guard: %+v", userRepo.user)
+	}
+
+	challenge, err := helper.CreateTwoFactorChallenge(ctx, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = uc.CompleteLogin(ctx, model.TwoFactorLoginInput{ChallengeToken: challenge, Code: code, IP: "203.0.113.9"})
+	if err == nil || err.Error() != "invalid authentication code" {
+		t.Fatalf("err = %v, want the replayed code rejected", err)
+	}
+}
+
+func TestEnrollForgetsTheOldSecretsStep(t *testing.T) {
+	ctx := context.Background()
+	userRepo, _, uc := newTwoFactorTest(t)
+
+	step := time.Now().Unix()/30 + 10
+	userRepo.user.TwoFactorLastStep = &step
+
+	if _, err := uc.Enroll(ctx, 7); err != nil {
+		t.Fatal(err)
+	}
+
+	if userRepo.user.TwoFactorLastStep != nil {
+		t.Fatal("the step of the previous secret still blocks the new one")
+	}
+}
+
+func TestCompleteLoginLocksAfterRepeatedWrongCodes(t *testing.T) {
+	mr := startMiniredis(t)
+
+	viper.Set("login.max_failures", 3)
+	viper.Set("login.backoff_max", time.Second)
+	t.Cleanup(func() {
+		viper.Set("login.max_failures", nil)
+		viper.Set("login.backoff_max", nil)
+	})
+
+	ctx := context.Background()
+	userRepo, auditLogRepo, uc := newTwoFactorTest(t)
+
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	userRepo.user.TwoFactorSecret = &secret
+	userRepo.user.TwoFactorEnabled = true
+
+	challenge, err := helper.CreateTwoFactorChallenge(ctx, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	login := func(code string) error {
+		_, err := uc.CompleteLogin(ctx, model.TwoFactorLoginInput{ChallengeToken: challenge, Code: code, IP: "203.0.113.9"})
+		return err
+	}
+
+	if err := login("000000"); err == nil {
+		t.Fatal("wrong code was accepted")
+	}
+
+	// The failure blocks the account for the backoff, even for a right code.
+	if err := login(totpCode(t, secret, time.Now())); err == nil || !strings.HasPrefix(err.Error(), "too many failed login attempts") {
+		t.Fatalf("err = %v, want the backoff to apply", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		mr.FastForward(2 * time.Second)
+
+		if err := login("000000"); err == nil {
+			t.Fatal("wrong code was accepted")
+		}
+	}
+
+	if userRepo.user.LockedAt == nil {
+		t.Fatal("account was not locked after the third wrong code")
+	}
+
+	if len(auditLogRepo.logs) != 1 || auditLogRepo.logs[0].Action != model.AuditAccountLocked {
+		t.Fatalf("audit logs = %+v, want the lock recorded", auditLogRepo.logs)
+	}
+
+	mr.FastForward(2 * time.Second)
+
+	if err := login(totpCode(t, secret, time.Now())); err == nil || !strings.Contains(err.Error(), "locked") {
+		t.Fatalf("err = %v, want the locked account refused", err)
+	}
+}
@@ -3,10 +3,14 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/sirupsen/logrus"
 
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
 	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
 )
@@ -14,64 +18,341 @@ import (
 var validate = validator.New()
 
 type UserUsecase struct {
-	userRepo    model.IUserRepository
-	projectRepo model.IProjectRepository
+	userRepo            model.IUserRepository
+	projectRepo         model.IProjectRepository
+	roleRepo            model.IRoleRepository
+	invitationRepo      model.IUserInvitationRepository
+	passwordResetRepo   model.IPasswordResetRepository
+	passwordHistoryRepo model.IPasswordHistoryRepository
+	auditLogRepo        model.IAuditLogRepository
+	mailSender          model.IMailSender
+	passwordProviders   map[string]model.IPasswordAuthProvider
+	provisioner         *userProvisioner
 }
 
-func NewUserUsecase(userRepo model.IUserRepository, projectRepo model.IProjectRepository) model.IUserUsecase {
+func NewUserUsecase(
+	userRepo model.IUserRepository,
+	projectRepo model.IProjectRepository,
+	roleRepo model.IRoleRepository,
+	invitationRepo model.IUserInvitationRepository,
+	passwordResetRepo model.IPasswordResetRepository,
+	passwordHistoryRepo model.IPasswordHistoryRepository,
+	auditLogRepo model.IAuditLogRepository,
+	identityRepo model.IUserIdentityRepository,
+	mailSender model.IMailSender,
+	passwordProviders map[string]model.IPasswordAuthProvider,
+) model.IUserUsecase {
 	return &UserUsecase{
-		userRepo:    userRepo,
-		projectRepo: projectRepo,
+		userRepo:            userRepo,
+		projectRepo:         projectRepo,
+		roleRepo:            roleRepo,
+		invitationRepo:      invitationRepo,
+		passwordResetRepo:   passwordResetRepo,
+		passwordHistoryRepo: passwordHistoryRepo,
+		auditLogRepo:        auditLogRepo,
+		mailSender:          mailSender,
+		passwordProviders:   passwordProviders,
+		provisioner:         newUserProvisioner(userRepo, roleRepo, identityRepo),
 	}
 }
 
-func (u *UserUsecase) Login(ctx context.Context, in model.LoginInput) (string, error) {
+func (u *UserUsecase) Login(ctx context.Context, in model.LoginInput) (*model.LoginResponse, error) {
 	log := logrus.WithFields(logrus.Fields{
-		"email": in.Email,
+		"email":    in.Email,
+		"ip":       in.IP,
+		"provider": in.Provider,
 	})
 
 	if err := validate.Struct(in); err != nil {
 		log.Error("Validation error", err)
-		return "", err
+		return nil, err
 	}
 
-	user, err := u.userRepo.FindByEmail(ctx, in.Email)
+	accountKey := strings.ToLower(in.Email)
+
+	if wait := helper.LoginBlockedFor(ctx, helper.LoginScopeIP, in.IP); wait > 0 {
+		return nil, fmt.Errorf("too many failed login attempts, try again in %d seconds", int(wait.Seconds())+1)
+	}
+
+	if wait := helper.LoginBlockedFor(ctx, helper.LoginScopeAccount, accountKey); wait > 0 {
+		return nil, fmt.Errorf("too many failed login attempts, try again in %d seconds", int(wait.Seconds())+1)
+	}
+
+	var user *model.User
+
+	if in.Provider == "" || in.Provider == model.AuthProviderLocal {
+		found, err := u.userRepo.FindByEmail(ctx, in.Email)
+		if err != nil {
+			registerLoginFailure(ctx, u.userRepo, u.auditLogRepo, nil, accountKey, in.IP)
+			return nil, errors.New("email or password is incorrect")
+		}
+
+		if found.LockedAt != nil {
+			return nil, errors.New("account is locked, please contact administrator")
+		}
+
+		if !helper.CheckPasswordHash(in.Password, found.Password) {
+			registerLoginFailure(ctx, u.userRepo, u.auditLogRepo, found, accountKey, in.IP)
+			return nil, errors.New("email or password is incorrect")
+		}
+
+		user = found
+	} else {
+		provider, ok := u.passwordProviders[in.Provider]
+		if !ok {
+			return nil, errors.New("login provider is not available")
+		}
+
+		identity, err := provider.Authenticate(ctx, in.Email, in.Password)
+		if err != nil {
+			log.Warn("external authentication failed:", err)
+			registerLoginFailure(ctx, u.userRepo, u.auditLogRepo, nil, accountKey, in.IP)
+			return nil, errors.New("email or password is incorrect")
+		}
+
+		user, err = u.provisioner.Provision(ctx, *identity)
+		if err != nil {
+			log.Error("failed provision external user:", err)
+			return nil, err
+		}
+
+		if user.LockedAt != nil {
+			return nil, errors.New("account is locked, please contact administrator")
+		}
+	}
+
+	if !user.IsActive {
+		return nil, errors.New("account is not active")
+	}
+
+	// With 2FA on the password alone does not clear the failures, or
+	// logging in again would reset the count of wrong codes.
+	if !user.TwoFactorEnabled {
+		if err := helper.ResetLoginFailures(ctx, helper.LoginScopeAccount, accountKey); err != nil {
+			log.Error("failed reset login failures:", err)
+		}
+	}
+
+	return issueLoginResponse(ctx, u.userRepo, user)
+}
+
+// issueLoginResponse finishes a successful first factor: it either hands out
+// a 2FA challenge or marks the user online and returns the access token.
+func issueLoginResponse(ctx context.Context, userRepo model.IUserRepository, user *model.User) (*model.LoginResponse, error) {
+	if user.TwoFactorEnabled {
+		challenge, err := helper.CreateTwoFactorChallenge(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		return &model.LoginResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+		}, nil
+	}
+
+	if err := userRepo.UpdateOnlineStatus(ctx, user.ID, true); err != nil {
+		logrus.WithField("user_id", user.ID).Error("failed update online status:", err)
+	}
+
+	token, err := helper.GenerateToken(*user)
 	if err != nil {
-		return "", errors.New("email or password is incorrect")
+		return nil, err
 	}
 
-	if !helper.CheckPasswordHash(in.Password, user.Password) {
-		return "", errors.New("email or password is incorrect")
+	return &model.LoginResponse{
+		Token: token,
+	}, nil
+}
+
+// registerLoginFailure counts a failed password or second factor against
+// the IP and the account, locking the account once it reaches
+// login.max_failures.
+func registerLoginFailure(
+	ctx context.Context,
+	userRepo model.IUserRepository,
+	auditLogRepo model.IAuditLogRepository,
+	user *model.User,
+	accountKey string,
+	ip string,
+) {
+	log := logrus.WithFields(logrus.Fields{
+		"email": accountKey,
+		"ip":    ip,
+	})
+
+	if _, err := helper.RegisterLoginFailure(ctx, helper.LoginScopeIP, ip); err != nil {
+		log.Error("failed register ip login failure:", err)
 	}
 
-	err = u.userRepo.UpdateOnlineStatus(ctx, user.ID, true)
+	failures, err := helper.RegisterLoginFailure(ctx, helper.LoginScopeAccount, accountKey)
 	if err != nil {
-		log.Error("failed update online status:", err)
+		log.Error("failed register account login failure:", err)
+		return
 	}
 
-	token, err := helper.GenerateToken(*user)
+	if user == nil || failures < config.LoginMaxFailures() {
+		return
+	}
+
+	if err := userRepo.Lock(ctx, user.ID); err != nil {
+		log.Error("failed lock account:", err)
+		return
+	}
+
+	log.Warnf("account locked after %d failed login attempts", failures)
+
+	metadata := fmt.Sprintf(`{"failed_attempts":%d}`, failures)
+
+	err = auditLogRepo.Create(ctx, model.AuditLog{
+		Action:     model.AuditAccountLocked,
+		TargetType: model.AuditTargetUser,
+		TargetID:   user.ID,
+		Metadata:   &metadata,
+		IPAddress:  ip,
+	})
 	if err != nil {
+		log.Error("failed write audit log:", err)
+	}
+}
+
+func (u *UserUsecase) writeAuditLog(ctx context.Context, entry model.AuditLog) {
+	if err := u.auditLogRepo.Create(ctx, entry); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"action":    entry.Action,
+			"target_id": entry.TargetID,
+		}).Error("failed write audit log:", err)
+	}
+}
+
+func (u *UserUsecase) hashNewPassword(ctx context.Context, user *model.User, password string) (string, error) {
+	if err := helper.ValidatePasswordPolicy(password); err != nil {
 		return "", err
 	}
 
-	return token, nil
+	if user != nil {
+		if helper.CheckPasswordHash(password, user.Password) {
+			return "", errors.New("new password must be different from the current password")
+		}
+
+		size := config.PasswordHistorySize()
+		if size > 0 {
+			histories, err := u.passwordHistoryRepo.FindRecentByUserID(ctx, user.ID, size)
+			if err != nil {
+				return "", err
+			}
+
+			for _, h := range histories {
+				if helper.CheckPasswordHash(password, h.PasswordHash) {
+					return "", fmt.Errorf("password must not match any of the last %d passwords", size)
+				}
+			}
+		}
+	}
+
+	return helper.HashRequestPassword(password)
+}
+
+func (u *UserUsecase) recordPasswordHistory(ctx context.Context, userID int64, hash string) {
+	err := u.passwordHistoryRepo.Create(ctx, model.PasswordHistory{
+		UserID:       userID,
+		PasswordHash: hash,
+	})
+
+	if err != nil {
+		logrus.WithField("user_id", userID).Error("failed record password history:", err)
+	}
 }
 
-func (u *UserUsecase) Create(ctx context.Context, in model.CreateUserInput) (string, error) {
+func (u *UserUsecase) Register(ctx context.Context, in model.RegisterInput) (string, error) {
 	log := logrus.WithFields(logrus.Fields{
-		"in": in,
+		"email": in.Email,
 	})
 
+	if !config.RegistrationEnabled() {
+		return "", errors.New("self-registration is disabled, please ask an administrator for an invitation")
+	}
+
 	if err := validate.Struct(in); err != nil {
 		return "", err
 	}
 
-	hashed, err := helper.HashRequestPassword(in.Password)
+	if !isAllowedRegistrationDomain(in.Email) {
+		return "", errors.New("email domain is not allowed to register")
+	}
+
+	role, err := u.roleRepo.FindByName(ctx, "USER")
+	if err != nil {
+		log.Error("failed find USER role:", err)
+		return "", err
+	}
+
+	hashed, err := u.hashNewPassword(ctx, nil, in.Password)
+	if err != nil {
+		return "", err
+	}
+
+	newUser, err := u.userRepo.Create(ctx, model.User{
+		Name:     in.Name,
+		Email:    in.Email,
+		Password: hashed,
+		RoleID:   role.ID,
+		IsActive: true,
+	})
+
 	if err != nil {
 		log.Error(err)
 		return "", err
 	}
 
+	u.recordPasswordHistory(ctx, newUser.ID, hashed)
+
+	newUser.Role = *role
+
+	accessToken, err := helper.GenerateToken(*newUser)
+	if err != nil {
+		return "", err
+	}
+
+	return accessToken, nil
+}
+
+func isAllowedRegistrationDomain(email string) bool {
+	domains := config.RegistrationAllowedDomains()
+	if len(domains) == 0 {
+		return true
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+
+	domain := strings.ToLower(email[at+1:])
+
+	for _, allowed := range domains {
+		if domain == strings.ToLower(strings.TrimSpace(allowed)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (u *UserUsecase) Invite(ctx context.Context, actorID int64, in model.CreateUserInput) (*model.User, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"actor_id": actorID,
+		"email":    in.Email,
+	})
+
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+
+	if _, err := u.roleRepo.FindByID(ctx, in.RoleID); err != nil {
+		return nil, err
+	}
+
 	var projects []model.Project
 	for _, p := range in.Projects {
 		projects = append(projects, model.Project{
@@ -82,23 +363,152 @@ func (u *UserUsecase) Create(ctx context.Context, in model.CreateUserInput) (str
 	newUser, err := u.userRepo.Create(ctx, model.User{
 		Name:     in.Name,
 		Email:    in.Email,
-		Password: hashed,
 		RoleID:   in.RoleID,
-		IsActive: true,
+		IsActive: false,
 		Projects: projects,
 	})
 
 	if err != nil {
 		log.Error(err)
+		return nil, err
+	}
+
+	sendErr := u.issueInvitation(ctx, actorID, newUser)
+	if sendErr != nil && !errors.Is(sendErr, model.ErrInvitationNotSent) {
+		return nil, sendErr
+	}
+
+	u.writeAuditLog(ctx, model.AuditLog{
+		ActorID:    &actorID,
+		Action:     model.AuditUserInvited,
+		TargetType: model.AuditTargetUser,
+		TargetID:   newUser.ID,
+	})
+
+	return newUser, sendErr
+}
+
+// ResendInvitation replaces the pending invitation of a user who has not
+// accepted yet with a fresh token, so a lost or undelivered email can be
+// sent again. The old link stops working.
+func (u *UserUsecase) ResendInvitation(ctx context.Context, actorID int64, userID int64) error {
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.IsActive || user.Password != "" {
+		return model.ErrInvitationNotPending
+	}
+
+	if err := u.invitationRepo.RevokePending(ctx, user.ID); err != nil {
+		return err
+	}
+
+	if err := u.issueInvitation(ctx, actorID, user); err != nil {
+		return err
+	}
+
+	u.writeAuditLog(ctx, model.AuditLog{
+		ActorID:    &actorID,
+		Action:     model.AuditInvitationResent,
+		TargetType: model.AuditTargetUser,
+		TargetID:   user.ID,
+	})
+
+	return nil
+}
+
+// issueInvitation stores a new invitation token for the user and mails
+// the link. A failed send is returned as ErrInvitationNotSent.
+func (u *UserUsecase) issueInvitation(ctx context.Context, actorID int64, user *model.User) error {
+	log := logrus.WithFields(logrus.Fields{
+		"actor_id": actorID,
+		"user_id":  user.ID,
+	})
+
+	token, err := helper.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	ttl := config.InvitationTTL()
+
+	_, err = u.invitationRepo.Create(ctx, model.UserInvitation{
+		UserID:    user.ID,
+		InvitedBy: actorID,
+		TokenHash: helper.HashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		log.Error("failed create invitation:", err)
+		return err
+	}
+
+	link := fmt.Sprintf("%s/accept-invitation?token=%s", config.FrontendURL(), token)
+
+	err = u.mailSender.Send(ctx, model.Mail{
+		To:      []string{user.Email},
+		Subject: "Undangan Akun Helpdesk",
+		Body: fmt.Sprintf(
+			"Halo %s,\n\nAnda diundang untuk menggunakan aplikasi Helpdesk.\n"+
+				"Buka tautan berikut untuk membuat password dan mengaktifkan akun Anda (berlaku %d jam):\n\n%s\n",
+			user.Name,
+			int(ttl.Hours()),
+			link,
+		),
+	})
+	if err != nil {
+		log.Error("failed send invitation mail:", err)
+		return fmt.Errorf("%w: %v", model.ErrInvitationNotSent, err)
+	}
+
+	return nil
+}
+
+func (u *UserUsecase) AcceptInvitation(ctx context.Context, in model.AcceptInvitationInput) (string, error) {
+	if err := validate.Struct(in); err != nil {
 		return "", err
 	}
 
-	accessToken, err := helper.GenerateToken(*newUser)
+	invitation, err := u.invitationRepo.FindValidByTokenHash(ctx, helper.HashToken(in.Token))
 	if err != nil {
 		return "", err
 	}
 
-	return accessToken, nil
+	log := logrus.WithFields(logrus.Fields{
+		"user_id": invitation.UserID,
+	})
+
+	user, err := u.userRepo.FindByID(ctx, invitation.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	hashed, err := u.hashNewPassword(ctx, nil, in.Password)
+	if err != nil {
+		return "", err
+	}
+
+	if err := u.invitationRepo.MarkAsAccepted(ctx, invitation.ID); err != nil {
+		return "", err
+	}
+
+	user.Password = hashed
+	user.IsActive = true
+
+	if err := u.userRepo.Update(ctx, *user); err != nil {
+		log.Error("failed activate invited user:", err)
+		return "", err
+	}
+
+	u.recordPasswordHistory(ctx, user.ID, hashed)
+
+	if err := u.userRepo.UpdateOnlineStatus(ctx, user.ID, true); err != nil {
+		log.Error("failed update online status:", err)
+	}
+
+	return helper.GenerateToken(*user)
 }
 
 func (u *UserUsecase) FindAll(ctx context.Context, filter model.User, page int, limit int) ([]*model.User, int64, error) {
@@ -158,13 +568,16 @@ func (u *UserUsecase) Update(ctx context.Context, id int64, in model.UpdateUserI
 	user.Email = in.Email
 	user.RoleID = in.RoleID
 
+	passwordChanged := false
+
 	if in.Password != "" {
-		hashed, err := helper.HashRequestPassword(in.Password)
+		hashed, err := u.hashNewPassword(ctx, user, in.Password)
 		if err != nil {
 			return err
 		}
 
 		user.Password = hashed
+		passwordChanged = true
 	}
 
 	var projects []model.Project
@@ -176,7 +589,15 @@ func (u *UserUsecase) Update(ctx context.Context, id int64, in model.UpdateUserI
 
 	user.Projects = projects
 
-	return u.userRepo.Update(ctx, *user)
+	if err := u.userRepo.Update(ctx, *user); err != nil {
+		return err
+	}
+
+	if passwordChanged {
+		u.recordPasswordHistory(ctx, user.ID, user.Password)
+	}
+
+	return nil
 }
 
 func (u *UserUsecase) Delete(ctx context.Context, id int64) error {
@@ -211,8 +632,16 @@ func (u *UserUsecase) UpdateProfile(ctx context.Context, userID int64, in model.
 		return err
 	}
 
+	if err := validate.Var(in.Language, "omitempty,oneof=id en"); err != nil {
+		return errors.New("language must be one of: id, en")
+	}
+
 	user.Name = in.Name
 
+	if in.Language != "" {
+		user.Language = in.Language
+	}
+
 	if in.NewPassword != "" {
 
 		if in.CurrentPassword == "" {
@@ -223,13 +652,169 @@ func (u *UserUsecase) UpdateProfile(ctx context.Context, userID int64, in model.
 			return errors.New("current password is incorrect")
 		}
 
-		hashed, err := helper.HashRequestPassword(in.NewPassword)
+		hashed, err := u.hashNewPassword(ctx, user, in.NewPassword)
 		if err != nil {
 			return err
 		}
 
 		user.Password = hashed
+
+		if err := u.userRepo.Update(ctx, *user); err != nil {
+			return err
+		}
+
+		u.recordPasswordHistory(ctx, user.ID, hashed)
+
+		return nil
 	}
 
 	return u.userRepo.Update(ctx, *user)
 }
+
+// ForgotPassword answers the same way whether or not the email belongs to
+// an account and whether or not the mail went out, so the endpoint cannot
+// be used to find registered addresses. Failures are only logged.
+func (u *UserUsecase) ForgotPassword(ctx context.Context, in model.ForgotPasswordInput) error {
+	log := logrus.WithFields(logrus.Fields{
+		"email": in.Email,
+	})
+
+	if err := validate.Struct(in); err != nil {
+		return err
+	}
+
+	user, err := u.userRepo.FindByEmail(ctx, in.Email)
+	if err != nil {
+		// Do not reveal whether the email is registered.
+		log.Info("password reset requested for unknown email")
+		return nil
+	}
+
+	if user.IsServiceAccount {
+		log.Info("password reset requested for service account")
+		return nil
+	}
+
+	if err := u.passwordResetRepo.InvalidateByUserID(ctx, user.ID); err != nil {
+		log.Error("failed invalidate previous reset tokens:", err)
+		return nil
+	}
+
+	token, err := helper.GenerateRandomToken(32)
+	if err != nil {
+		log.Error("failed generate reset token:", err)
+		return nil
+	}
+
+	ttl := config.PasswordResetTTL()
+
+	_, err = u.passwordResetRepo.Create(ctx, model.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: helper.HashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		log.Error("failed create reset token:", err)
+		return nil
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", config.FrontendURL(), token)
+
+	err = u.mailSender.Send(ctx, model.Mail{
+		To:      []string{user.Email},
+		Subject: "Reset Password Helpdesk",
+		Body: fmt.Sprintf(
+			"Halo %s,\n\nKami menerima permintaan untuk mereset password akun Anda.\n"+
+				"Buka tautan berikut untuk membuat password baru (berlaku %d menit):\n\n%s\n\n"+
+				"Abaikan email ini jika Anda tidak meminta reset password.\n",
+			user.Name,
+			int(ttl.Minutes()),
+			link,
+		),
+	})
+	if err != nil {
+		log.Error("failed send reset password mail:", err)
+		return nil
+	}
+
+	return nil
+}
+
+func (u *UserUsecase) ResetPassword(ctx context.Context, in model.ResetPasswordInput) error {
+	if err := validate.Struct(in); err != nil {
+		return err
+	}
+
+	reset, err := u.passwordResetRepo.FindValidByTokenHash(ctx, helper.HashToken(in.Token))
+	if err != nil {
+		return err
+	}
+
+	log := logrus.WithFields(logrus.Fields{
+		"user_id": reset.UserID,
+	})
+
+	user, err := u.userRepo.FindByID(ctx, reset.UserID)
+	if err != nil {
+		return err
+	}
+
+	hashed, err := u.hashNewPassword(ctx, user, in.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := u.passwordResetRepo.MarkAsUsed(ctx, reset.ID); err != nil {
+		return err
+	}
+
+	user.Password = hashed
+
+	if err := u.userRepo.Update(ctx, *user); err != nil {
+		log.Error("failed update password:", err)
+		return err
+	}
+
+	u.recordPasswordHistory(ctx, user.ID, hashed)
+
+	if err := helper.RevokeUserSessions(ctx, user.ID); err != nil {
+		log.Error("failed revoke sessions:", err)
+	}
+
+	if err := u.userRepo.UpdateOnlineStatus(ctx, user.ID, false); err != nil {
+		log.Error("failed update online status:", err)
+	}
+
+	return nil
+}
+
+func (u *UserUsecase) Unlock(ctx context.Context, actorID int64, userID int64, ip string) error {
+	log := logrus.WithFields(logrus.Fields{
+		"actor_id": actorID,
+		"user_id":  userID,
+	})
+
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := u.userRepo.Unlock(ctx, user.ID); err != nil {
+		log.Error("failed unlock account:", err)
+		return err
+	}
+
+	if err := helper.ResetLoginFailures(ctx, helper.LoginScopeAccount, strings.ToLower(user.Email)); err != nil {
+		log.Error("failed reset login failures:", err)
+	}
+
+	u.writeAuditLog(ctx, model.AuditLog{
+		ActorID:    &actorID,
+		Action:     model.AuditAccountUnlocked,
+		TargetType: model.AuditTargetUser,
+		TargetID:   user.ID,
+		IPAddress:  ip,
+	})
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type fakePasswordResetRepo struct {
+	model.IPasswordResetRepository
+	created int
+}
+
+func (r *fakePasswordResetRepo) InvalidateByUserID(ctx context.Context, userID int64) error {
+	return nil
+}
+
+func (r *fakePasswordResetRepo) Create(ctx context.Context, reset model.PasswordReset) (*model.PasswordReset, error) {
+	r.created++
+	return &reset, nil
+}
+
+type failingMailSender struct {
+	sent int
+}
+
+func (s *failingMailSender) Send(ctx context.Context, mail model.Mail) error {
+	s.sent++
+	return errors.New("smtp: connection refused")
+}
+
+func TestForgotPasswordHidesDeliveryFailures(t *testing.T) {
+	userRepo := &fakeSSOUserRepo{users: map[int64]*model.User{
+		1: {ID: 1, Email: "jane@example.com", Name: "Jane", IsActive: true},
+	}}
+	resetRepo := &fakePasswordResetRepo{}
+	mailSender := &failingMailSender{}
+
+	uc := NewUserUsecase(userRepo, nil, nil, nil, resetRepo, nil, nil, nil, mailSender, nil)
+
+	for _, email := range []string{"jane@example.com", "nobody@example.com"} {
+		if err := uc.ForgotPassword(context.Background(), model.ForgotPasswordInput{Email: email}); err != nil {
+			t.Fatalf("ForgotPassword(%s) = %v, want the generic success", email, err)
+		}
+	}
+
+	if resetRepo.created != 1 || mailSender.sent != 1 {
+		t.Fatalf("created = %d, sent = %d; want one reset mail attempt for the registered email", resetRepo.created, mailSender.sent)
+	}
+}
@@ -0,0 +1,345 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/config"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/helper"
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+const webhookResponseBodyLimit = 2048
+
+type WebhookUsecase struct {
+	webhookRepo model.IWebhookRepository
+	ticketRepo  model.ITicketRepository
+	client      *http.Client
+}
+
+func NewWebhookUsecase(
+	webhookRepo model.IWebhookRepository,
+	ticketRepo model.ITicketRepository,
+) model.IWebhookUsecase {
+	return &WebhookUsecase{
+		webhookRepo: webhookRepo,
+		ticketRepo:  ticketRepo,
+		client: &http.Client{
+			Timeout: config.WebhookTimeout(),
+		},
+	}
+}
+
+func (u *WebhookUsecase) FindAll(ctx context.Context) ([]*model.Webhook, error) {
+	return u.webhookRepo.FindAll(ctx)
+}
+
+func (u *WebhookUsecase) FindByID(ctx context.Context, id int64) (*model.Webhook, error) {
+	return u.webhookRepo.FindByID(ctx, id)
+}
+
+func (u *WebhookUsecase) Create(ctx context.Context, actorID int64, in model.CreateWebhookInput) (*model.CreatedWebhook, error) {
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+
+	secret := in.Secret
+	if secret == "" {
+		generated, err := helper.GenerateRandomToken(32)
+		if err != nil {
+			return nil, err
+		}
+		secret = generated
+	}
+
+	webhook, err := u.webhookRepo.Create(ctx, model.Webhook{
+		Name:       in.Name,
+		URL:        in.URL,
+		Secret:     secret,
+		Events:     in.Events,
+		ProjectIDs: in.ProjectIDs,
+		IsActive:   true,
+		CreatedBy:  actorID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CreatedWebhook{
+		Webhook: *webhook,
+		Secret:  secret,
+	}, nil
+}
+
+func (u *WebhookUsecase) Update(ctx context.Context, id int64, in model.UpdateWebhookInput) (*model.Webhook, error) {
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+
+	webhook, err := u.webhookRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.Name = in.Name
+	webhook.URL = in.URL
+	webhook.Events = in.Events
+	webhook.ProjectIDs = in.ProjectIDs
+	webhook.IsActive = in.IsActive
+
+	if err := u.webhookRepo.Update(ctx, *webhook); err != nil {
+		return nil, err
+	}
+
+	return u.webhookRepo.FindByID(ctx, id)
+}
+
+func (u *WebhookUsecase) Delete(ctx context.Context, id int64) error {
+	if _, err := u.webhookRepo.FindByID(ctx, id); err != nil {
+		return err
+	}
+
+	return u.webhookRepo.Delete(ctx, id)
+}
+
+func (u *WebhookUsecase) FindDeliveries(ctx context.Context, webhookID int64, status string, page int, limit int) ([]*model.WebhookDelivery, int64, error) {
+	if _, err := u.webhookRepo.FindByID(ctx, webhookID); err != nil {
+		return nil, 0, err
+	}
+
+	return u.webhookRepo.FindDeliveriesByWebhookID(ctx, webhookID, status, page, limit)
+}
+
+// Replay queues a fresh delivery with the original payload; the old entry
+// stays untouched in the log.
+func (u *WebhookUsecase) Replay(ctx context.Context, deliveryID int64) (*model.WebhookDelivery, error) {
+	original, err := u.webhookRepo.FindDeliveryByID(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := u.webhookRepo.FindByID(ctx, original.WebhookID); err != nil {
+		return nil, err
+	}
+
+	return u.webhookRepo.CreateDelivery(ctx, model.WebhookDelivery{
+		WebhookID: original.WebhookID,
+		EventType: original.EventType,
+		TicketID:  original.TicketID,
+		Payload:   original.Payload,
+		Status:    model.WebhookDeliveryPending,
+		ReplayOf:  &original.ID,
+	})
+}
+
+// Dispatch queues a delivery of a lifecycle event for every matching
+// webhook. The deliveries are inserted together and keyed by the event ID,
+// so a redelivered event neither stops halfway nor queues them twice.
+func (u *WebhookUsecase) Dispatch(ctx context.Context, event model.NotificationEvent) error {
+	webhooks, err := u.webhookRepo.FindActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	var projectID int64
+	var ticketID *int64
+
+	if event.TicketID != 0 {
+		ticket, err := u.ticketRepo.FindByID(ctx, event.TicketID)
+		if err != nil {
+			return err
+		}
+
+		projectID = ticket.ProjectID
+		ticketID = &ticket.ID
+	}
+
+	payload, err := json.Marshal(model.WebhookPayload{
+		Event:      event.EventType,
+		OccurredAt: time.Now(),
+		ProjectID:  projectID,
+		Data:       event,
+	})
+	if err != nil {
+		return err
+	}
+
+	var eventID *string
+	if event.EventID != "" {
+		eventID = &event.EventID
+	}
+
+	var deliveries []model.WebhookDelivery
+
+	for _, webhook := range webhooks {
+		if !webhookMatches(webhook, event.EventType, projectID) {
+			continue
+		}
+
+		deliveries = append(deliveries, model.WebhookDelivery{
+			WebhookID: webhook.ID,
+			EventID:   eventID,
+			EventType: event.EventType,
+			TicketID:  ticketID,
+			Payload:   string(payload),
+			Status:    model.WebhookDeliveryPending,
+		})
+	}
+
+	return u.webhookRepo.CreateDeliveries(ctx, deliveries)
+}
+
+func (u *WebhookUsecase) DeliverDue(ctx context.Context) error {
+	deliveries, err := u.webhookRepo.ClaimDueDeliveries(ctx, 20, 2*config.WebhookTimeout())
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		u.deliver(ctx, delivery)
+	}
+
+	return nil
+}
+
+func (u *WebhookUsecase) deliver(ctx context.Context, delivery *model.WebhookDelivery) {
+	log := logrus.WithFields(logrus.Fields{
+		"delivery_id": delivery.ID,
+		"webhook_id":  delivery.WebhookID,
+		"event":       delivery.EventType,
+	})
+
+	delivery.Attempts++
+
+	webhook, err := u.webhookRepo.FindByID(ctx, delivery.WebhookID)
+	if err == nil && !webhook.IsActive {
+		err = errors.New("webhook is disabled")
+	}
+
+	var status int
+	var body string
+
+	if err == nil {
+		status, body, err = u.send(ctx, webhook, delivery)
+	}
+
+	if status != 0 {
+		delivery.ResponseStatus = &status
+		delivery.ResponseBody = &body
+	}
+
+	if err == nil {
+		now := time.Now()
+		delivery.Status = model.WebhookDeliverySuccess
+		delivery.DeliveredAt = &now
+		delivery.Error = nil
+	} else {
+		message := err.Error()
+		delivery.Error = &message
+
+		if delivery.Attempts >= config.WebhookMaxAttempts() || webhook == nil || !webhook.IsActive {
+			delivery.Status = model.WebhookDeliveryFailed
+			log.Warn("webhook delivery failed permanently:", err)
+		} else {
+			delivery.NextAttemptAt = time.Now().Add(webhookBackoff(delivery.Attempts))
+			log.Info("webhook delivery failed, will retry:", err)
+		}
+	}
+
+	if err := u.webhookRepo.UpdateDelivery(ctx, *delivery); err != nil {
+		log.Error("failed update webhook delivery:", err)
+	}
+}
+
+func (u *WebhookUsecase) send(ctx context.Context, webhook *model.Webhook, delivery *model.WebhookDelivery) (int, string, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Helpdesk-Webhook/1.0")
+	req.Header.Set("X-Helpdesk-Event", delivery.EventType)
+	req.Header.Set("X-Helpdesk-Delivery", strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set("X-Helpdesk-Timestamp", timestamp)
+	req.Header.Set("X-Helpdesk-Signature", "sha256="+signWebhookPayload(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseBodyLimit))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, string(body), fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, string(body), nil
+}
+
+// signWebhookPayload signs "<timestamp>.<body>" so receivers can reject
+// replayed requests by checking the timestamp as well as the body.
+func signWebhookPayload(secret string, timestamp string, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + payload))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookBackoff(attempts int) time.Duration {
+	wait := config.WebhookBackoffBase()
+
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if wait >= config.WebhookBackoffMax() {
+			return config.WebhookBackoffMax()
+		}
+	}
+
+	return wait
+}
+
+func webhookMatches(webhook *model.Webhook, eventType string, projectID int64) bool {
+	if len(webhook.Events) > 0 {
+		matched := false
+		for _, e := range webhook.Events {
+			if e == eventType {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(webhook.ProjectIDs) > 0 {
+		for _, id := range webhook.ProjectIDs {
+			if id == projectID {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type fakeWebhookRepo struct {
+	model.IWebhookRepository
+	webhooks []*model.Webhook
+	batches  [][]model.WebhookDelivery
+}
+
+func (r *fakeWebhookRepo) FindActive(ctx context.Context) ([]*model.Webhook, error) {
+	return r.webhooks, nil
+}
+
+func (r *fakeWebhookRepo) CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error {
+	r.batches = append(r.batches, deliveries)
+	return nil
+}
+
+type fakeProjectTicketRepo struct {
+	model.ITicketRepository
+}
+
+func (fakeProjectTicketRepo) FindByID(ctx context.Context, id int64) (*model.Ticket, error) {
+	return &model.Ticket{ID: id, ProjectID: 2}, nil
+}
+
+func TestDispatchQueuesMatchingWebhooksTogether(t *testing.T) {
+	repo := &fakeWebhookRepo{webhooks: []*model.Webhook{
+		{ID: 1},
+		{ID: 2, Events: []string{"TICKET_CLOSED"}},
+		{ID: 3, Events: []string{"TICKET_COMMENT"}, ProjectIDs: []int64{2}},
+		{ID: 4, ProjectIDs: []int64{9}},
+	}}
+
+	uc := NewWebhookUsecase(repo, fakeProjectTicketRepo{})
+
+	err := uc.Dispatch(context.Background(), model.NotificationEvent{
+		EventID:   "3f2a9c",
+		EventType: "TICKET_COMMENT",
+		ActorID:   7,
+		TicketID:  5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(repo.batches) != 1 {
+		t.Fatalf("inserts = %d, want every delivery in one insert", len(repo.batches))
+	}
+
+	deliveries := repo.batches[0]
+	if len(deliveries) != 2 || deliveries[0].WebhookID != 1 || deliveries[1].WebhookID != 3 {
+		t.Fatalf("deliveries = %+v, want webhooks 1 and 3", deliveries)
+	}
+
+	for _, delivery := range deliveries {
+		if delivery.EventID == nil || *delivery.EventID != "3f2a9c" {
+			t.Fatalf("delivery for webhook %d is not keyed by the event", delivery.WebhookID)
+		}
+	}
+}
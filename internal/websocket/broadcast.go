@@ -32,3 +32,30 @@ func BroadcastToRoles(hub *Hub, roles []string, message Message) {
 		}(role)
 	}
 }
+
+func BroadcastToUser(hub *Hub, userID int64, message Message) {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		logrus.Error(
+			"failed marshal websocket message:",
+			err,
+		)
+		return
+	}
+
+	if hub.relay != nil {
+		err := hub.publishRelay(userID, payload)
+		if err == nil {
+			return
+		}
+
+		logrus.Error("failed relay websocket message, delivering locally:", err)
+	}
+
+	go func() {
+		hub.BroadcastToUser <- UserMessage{
+			UserID:  userID,
+			Message: payload,
+		}
+	}()
+}
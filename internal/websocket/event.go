@@ -8,4 +8,10 @@ const (
 	EventTicketStatusUpdate = "TICKET_STATUS_UPDATED"
 	EventTicketDeleted      = "TICKET_DELETED"
 	EventTicketHistory      = "TICKET_HISTORY"
+
+	EventNotificationCountUpdated = "NOTIFICATION_COUNT_UPDATED"
+
+	EventExportProgress  = "EXPORT_PROGRESS"
+	EventExportCompleted = "EXPORT_COMPLETED"
+	EventExportFailed    = "EXPORT_FAILED"
 )
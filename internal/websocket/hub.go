@@ -1,6 +1,9 @@
 package websocket
 
-import "github.com/sirupsen/logrus"
+import (
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
 
 type Hub struct {
 	Clients map[string]map[*Client]bool
@@ -9,6 +12,9 @@ type Hub struct {
 	Unregister chan *Client
 
 	BroadcastToRole chan RoleMessage
+	BroadcastToUser chan UserMessage
+
+	relay *redis.Client
 }
 
 type RoleMessage struct {
@@ -16,6 +22,11 @@ type RoleMessage struct {
 	Message []byte
 }
 
+type UserMessage struct {
+	UserID  int64
+	Message []byte
+}
+
 func NewHub() *Hub {
 	return &Hub{
 		Clients: map[string]map[*Client]bool{
@@ -27,6 +38,7 @@ func NewHub() *Hub {
 		Register:        make(chan *Client),
 		Unregister:      make(chan *Client),
 		BroadcastToRole: make(chan RoleMessage),
+		BroadcastToUser: make(chan UserMessage),
 	}
 }
 
@@ -74,6 +86,32 @@ func (h *Hub) Run() {
 
 					case client.Send <- msg.Message:
 
+					default:
+
+						logrus.Warn(
+							"CLIENT CHANNEL FULL, REMOVED",
+						)
+
+						close(client.Send)
+						delete(clients, client)
+					}
+				}
+			}
+
+		case msg := <-h.BroadcastToUser:
+
+			for _, clients := range h.Clients {
+
+				for client := range clients {
+
+					if client.UserID != msg.UserID {
+						continue
+					}
+
+					select {
+
+					case client.Send <- msg.Message:
+
 					default:
 
 						logrus.Warn(
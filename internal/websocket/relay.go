@@ -0,0 +1,55 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const userRelayChannel = "ws:user"
+
+type relayMessage struct {
+	UserID  int64           `json:"user_id"`
+	Message json.RawMessage `json:"message"`
+}
+
+// EnableRelay routes user-targeted messages through Redis pub/sub so a user
+// connected to another replica still receives them. Call it before Run.
+func (h *Hub) EnableRelay(rdb *redis.Client) {
+	h.relay = rdb
+
+	go h.subscribeRelay()
+}
+
+func (h *Hub) subscribeRelay() {
+	sub := h.relay.Subscribe(context.Background(), userRelayChannel)
+
+	for msg := range sub.Channel() {
+
+		var relayed relayMessage
+
+		if err := json.Unmarshal([]byte(msg.Payload), &relayed); err != nil {
+			logrus.Error("failed unmarshal relayed websocket message:", err)
+			continue
+		}
+
+		h.BroadcastToUser <- UserMessage{
+			UserID:  relayed.UserID,
+			Message: relayed.Message,
+		}
+	}
+}
+
+func (h *Hub) publishRelay(userID int64, payload []byte) error {
+	body, err := json.Marshal(relayMessage{
+		UserID:  userID,
+		Message: payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	return h.relay.Publish(context.Background(), userRelayChannel, body).Err()
+}
@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type ExportCleaner struct {
+	exportJobUsecase model.IExportJobUsecase
+	interval         time.Duration
+}
+
+func NewExportCleaner(
+	exportJobUsecase model.IExportJobUsecase,
+	interval time.Duration,
+) *ExportCleaner {
+	return &ExportCleaner{
+		exportJobUsecase: exportJobUsecase,
+		interval:         interval,
+	}
+}
+
+func (w *ExportCleaner) Start() {
+	ticker := time.NewTicker(w.interval)
+
+	defer ticker.Stop()
+
+	for range ticker.C {
+
+		_, err := w.exportJobUsecase.DeleteExpired(
+			context.Background(),
+		)
+
+		if err != nil {
+			log.Println("[EXPORT CLEANER ERROR]", err)
+			continue
+		}
+	}
+}
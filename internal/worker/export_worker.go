@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+// ExportWorker builds queued ticket exports. Several can run side by side;
+// each job is claimed by exactly one of them.
+type ExportWorker struct {
+	exportJobUsecase model.IExportJobUsecase
+	interval         time.Duration
+}
+
+func NewExportWorker(
+	exportJobUsecase model.IExportJobUsecase,
+	interval time.Duration,
+) *ExportWorker {
+	return &ExportWorker{
+		exportJobUsecase: exportJobUsecase,
+		interval:         interval,
+	}
+}
+
+func (w *ExportWorker) Start() {
+	ticker := time.NewTicker(w.interval)
+
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for {
+			processed, err := w.exportJobUsecase.ProcessNext(context.Background())
+			if err != nil {
+				log.Println("[EXPORT WORKER ERROR]", err)
+				break
+			}
+
+			if !processed {
+				break
+			}
+		}
+	}
+}
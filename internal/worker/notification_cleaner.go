@@ -10,28 +10,28 @@ import (
 
 type NotificationCleaner struct {
 	notificationUsecase model.INotificationUsecase
+	interval            time.Duration
 }
 
 func NewNotificationCleaner(
 	notificationUsecase model.INotificationUsecase,
+	interval time.Duration,
 ) *NotificationCleaner {
 	return &NotificationCleaner{
 		notificationUsecase: notificationUsecase,
+		interval:            interval,
 	}
 }
 
 func (w *NotificationCleaner) Start() {
-	ticker := time.NewTicker(1 * time.Minute)
+	ticker := time.NewTicker(w.interval)
 
 	defer ticker.Stop()
 
 	for range ticker.C {
 
-		expiredBefore := time.Now().Add(-15 * time.Minute)
-
-		err := w.notificationUsecase.DeleteExpired(
+		_, err := w.notificationUsecase.DeleteExpired(
 			context.Background(),
-			expiredBefore,
 		)
 
 		if err != nil {
@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type PushSubscriptionCleaner struct {
+	pushUsecase model.IPushUsecase
+	interval    time.Duration
+}
+
+func NewPushSubscriptionCleaner(
+	pushUsecase model.IPushUsecase,
+	interval time.Duration,
+) *PushSubscriptionCleaner {
+	return &PushSubscriptionCleaner{
+		pushUsecase: pushUsecase,
+		interval:    interval,
+	}
+}
+
+func (w *PushSubscriptionCleaner) Start() {
+	ticker := time.NewTicker(w.interval)
+
+	defer ticker.Stop()
+
+	for range ticker.C {
+		_, err := w.pushUsecase.DeleteExpired(context.Background())
+		if err != nil {
+			log.Println("[PUSH CLEANER ERROR]", err)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type ReportCleaner struct {
+	reportScheduleUsecase model.IReportScheduleUsecase
+	interval              time.Duration
+}
+
+func NewReportCleaner(
+	reportScheduleUsecase model.IReportScheduleUsecase,
+	interval time.Duration,
+) *ReportCleaner {
+	return &ReportCleaner{
+		reportScheduleUsecase: reportScheduleUsecase,
+		interval:              interval,
+	}
+}
+
+func (w *ReportCleaner) Start() {
+	ticker := time.NewTicker(w.interval)
+
+	defer ticker.Stop()
+
+	for range ticker.C {
+		_, err := w.reportScheduleUsecase.DeleteExpiredRuns(context.Background())
+		if err != nil {
+			log.Println("[REPORT CLEANER ERROR]", err)
+		}
+	}
+}
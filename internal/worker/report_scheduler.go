@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+// ReportScheduler sends scheduled reports when they are due. Several can
+// run side by side; each run is claimed by exactly one of them.
+type ReportScheduler struct {
+	reportScheduleUsecase model.IReportScheduleUsecase
+	interval              time.Duration
+}
+
+func NewReportScheduler(
+	reportScheduleUsecase model.IReportScheduleUsecase,
+	interval time.Duration,
+) *ReportScheduler {
+	return &ReportScheduler{
+		reportScheduleUsecase: reportScheduleUsecase,
+		interval:              interval,
+	}
+}
+
+func (w *ReportScheduler) Start() {
+	ticker := time.NewTicker(w.interval)
+
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for {
+			ran, err := w.reportScheduleUsecase.RunDue(context.Background())
+			if err != nil {
+				log.Println("[REPORT SCHEDULER ERROR]", err)
+				break
+			}
+
+			if !ran {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type SLAWorker struct {
+	chatUsecase model.IChatUsecase
+	interval    time.Duration
+}
+
+func NewSLAWorker(
+	chatUsecase model.IChatUsecase,
+	interval time.Duration,
+) *SLAWorker {
+	return &SLAWorker{
+		chatUsecase: chatUsecase,
+		interval:    interval,
+	}
+}
+
+func (w *SLAWorker) Start() {
+	ticker := time.NewTicker(w.interval)
+
+	defer ticker.Stop()
+
+	for range ticker.C {
+		err := w.chatUsecase.NotifySLABreaches(context.Background())
+		if err != nil {
+			log.Println("[SLA WORKER ERROR]", err)
+		}
+	}
+}
@@ -185,8 +185,15 @@ func (w *TicketWorker) process(ticket model.Ticket) {
 		tickets.reporter_id,
 		tickets.part_id,
 		tickets.asset_id,
-		tickets.attachment,
 		tickets.assigned_to_id,
+		(
+			SELECT a.storage_key
+			FROM attachments a
+			WHERE a.ticket_id = tickets.id
+			AND a.owner_type = 'TICKET'
+			ORDER BY a.id ASC
+			LIMIT 1
+		) AS attachment,
 
 		reporter.name as reporter_name,
 		assigned.name as assigned_to_name,
@@ -269,23 +276,25 @@ func (w *TicketWorker) process(ticket model.Ticket) {
 		ticket.ID,
 	)
 
-	err = helper.PublishNotificationEvent(
-		"ticket.created",
-		model.NotificationEvent{
-			EventType:     "TICKET_ASSIGNED",
-			UserID:        selected.ID,
-			ActorID:       ticket.ReporterID,
-			TicketID:      ticket.ID,
-			TicketCode:    ticket.TicketCode,
-			ReferenceType: "TICKET",
-			ReferenceID:   ticket.ID,
-			Title:         "Tiket Masuk",
-			Message:       "No Tiket: " + ticket.TicketCode + " | Pelapor: " + ticket.Reporter.Name,
-		},
-	)
+	event := model.NotificationEvent{
+		EventType:     "TICKET_ASSIGNED",
+		UserID:        selected.ID,
+		ActorID:       ticket.ReporterID,
+		TicketID:      ticket.ID,
+		TicketCode:    ticket.TicketCode,
+		Priority:      string(ticket.Priority),
+		ReferenceType: "TICKET",
+		ReferenceID:   ticket.ID,
+		Title:         "Tiket Masuk",
+		Message:       "No Tiket: " + ticket.TicketCode + " | Pelapor: " + ticket.Reporter.Name,
+	}
 
-	if err != nil {
+	if err := helper.PublishNotificationEvent("ticket.created", event); err != nil {
 		log.Println("failed publish notification:", err)
 	}
 
+	if err := helper.PublishLifecycleEvent(event); err != nil {
+		log.Println("failed publish lifecycle event:", err)
+	}
+
 }
@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/tubagusmf/helpdesk-ticketing-nutech-integrasi-be/internal/model"
+)
+
+type WebhookWorker struct {
+	webhookUsecase model.IWebhookUsecase
+	interval       time.Duration
+}
+
+func NewWebhookWorker(
+	webhookUsecase model.IWebhookUsecase,
+	interval time.Duration,
+) *WebhookWorker {
+	return &WebhookWorker{
+		webhookUsecase: webhookUsecase,
+		interval:       interval,
+	}
+}
+
+func (w *WebhookWorker) Start() {
+	ticker := time.NewTicker(w.interval)
+
+	defer ticker.Stop()
+
+	for range ticker.C {
+		err := w.webhookUsecase.DeliverDue(context.Background())
+		if err != nil {
+			log.Println("[WEBHOOK WORKER ERROR]", err)
+		}
+	}
+}